@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: GPL-2.0
+/*
+ * visibility.c counts the L4 flows on the veth of an endpoint, for the
+ * --visibility-bpf option of docker-ovs-plugin. The plugin attaches the
+ * tc/ingress and tc/egress programs to the host side of the veth and reads
+ * the pinned linker_flows map with bpftool. Build it with
+ *
+ *   clang -O2 -g -target bpf -c bpf/visibility.c -o visibility.o
+ *
+ * -g emits the BTF bpftool needs to format the map entries.
+ */
+#include <linux/bpf.h>
+#include <linux/if_ether.h>
+#include <linux/in.h>
+#include <linux/ip.h>
+#include <linux/pkt_cls.h>
+#include <linux/tcp.h>
+#include <linux/udp.h>
+#include <bpf/bpf_endian.h>
+#include <bpf/bpf_helpers.h>
+
+/* The layout ovs/visibility.go decodes: addresses in network byte order,
+ * ports in host byte order. */
+struct flow_key {
+	__u32 ifindex;
+	__u32 saddr;
+	__u32 daddr;
+	__u16 sport;
+	__u16 dport;
+	__u8 proto;
+};
+
+struct flow_counters {
+	__u64 packets;
+	__u64 bytes;
+	/* TCP segments whose data was already sent */
+	__u64 retransmits;
+	/* duplicate TCP acks, which a receiver sends for every segment that
+	 * arrives after a lost one */
+	__u64 drops;
+	/* the end of the highest TCP segment sent and the last ack */
+	__u32 seq_end;
+	__u32 last_ack;
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_LRU_HASH);
+	__uint(max_entries, 65536);
+	__type(key, struct flow_key);
+	__type(value, struct flow_counters);
+	__uint(pinning, LIBBPF_PIN_BY_NAME);
+} linker_flows SEC(".maps");
+
+static __always_inline void count_tcp(struct flow_counters *counters, struct iphdr *ip, struct tcphdr *tcp)
+{
+	__u32 payload = bpf_ntohs(ip->tot_len) - ip->ihl * 4 - tcp->doff * 4;
+	__u32 seq = bpf_ntohl(tcp->seq);
+	__u32 ack = bpf_ntohl(tcp->ack_seq);
+
+	if (payload > 0 && payload < 0x10000) {
+		__u32 end = seq + payload;
+		if (counters->seq_end && (__s32)(end - counters->seq_end) <= 0)
+			__sync_fetch_and_add(&counters->retransmits, 1);
+		else
+			counters->seq_end = end;
+		return;
+	}
+	if (tcp->ack && !tcp->syn && !tcp->fin && !tcp->rst) {
+		if (counters->last_ack && ack == counters->last_ack)
+			__sync_fetch_and_add(&counters->drops, 1);
+		counters->last_ack = ack;
+	}
+}
+
+static __always_inline int count(struct __sk_buff *skb)
+{
+	void *data = (void *)(long)skb->data;
+	void *data_end = (void *)(long)skb->data_end;
+	struct ethhdr *eth = data;
+	struct tcphdr *tcp = NULL;
+	struct flow_counters *counters;
+	struct flow_key key;
+	struct iphdr *ip;
+
+	if ((void *)(eth + 1) > data_end || eth->h_proto != bpf_htons(ETH_P_IP))
+		return TC_ACT_OK;
+	ip = (void *)(eth + 1);
+	if ((void *)(ip + 1) > data_end || ip->ihl < 5)
+		return TC_ACT_OK;
+
+	/* the padding is part of the key */
+	__builtin_memset(&key, 0, sizeof(key));
+	key.ifindex = skb->ifindex;
+	key.saddr = ip->saddr;
+	key.daddr = ip->daddr;
+	key.proto = ip->protocol;
+
+	/* only the first fragment carries the ports */
+	if (!(ip->frag_off & bpf_htons(0x1fff))) {
+		void *l4 = (void *)ip + ip->ihl * 4;
+		if (ip->protocol == IPPROTO_TCP) {
+			tcp = l4;
+			if ((void *)(tcp + 1) > data_end)
+				return TC_ACT_OK;
+			key.sport = bpf_ntohs(tcp->source);
+			key.dport = bpf_ntohs(tcp->dest);
+		} else if (ip->protocol == IPPROTO_UDP) {
+			struct udphdr *udp = l4;
+			if ((void *)(udp + 1) > data_end)
+				return TC_ACT_OK;
+			key.sport = bpf_ntohs(udp->source);
+			key.dport = bpf_ntohs(udp->dest);
+		}
+	}
+
+	counters = bpf_map_lookup_elem(&linker_flows, &key);
+	if (!counters) {
+		struct flow_counters zero = {};
+		bpf_map_update_elem(&linker_flows, &key, &zero, BPF_NOEXIST);
+		counters = bpf_map_lookup_elem(&linker_flows, &key);
+		if (!counters)
+			return TC_ACT_OK;
+	}
+	__sync_fetch_and_add(&counters->packets, 1);
+	__sync_fetch_and_add(&counters->bytes, skb->len);
+	if (tcp)
+		count_tcp(counters, ip, tcp);
+	return TC_ACT_OK;
+}
+
+SEC("tc/ingress")
+int visibility_ingress(struct __sk_buff *skb)
+{
+	return count(skb);
+}
+
+SEC("tc/egress")
+int visibility_egress(struct __sk_buff *skb)
+{
+	return count(skb);
+}
+
+char _license[] SEC("license") = "GPL";
@@ -0,0 +1,347 @@
+// Command ovs-cni is a CNI plugin that wires Kubernetes pods to the OVS
+// bridges of the docker-ovs-plugin daemon. Pods get the same bridges,
+// uplinks, NAT and sgw/pgw gateways as docker containers. The daemon sets
+// up the network and the pod's port through its admin socket. This plugin
+// moves the veth into the pod and configures it there. Addresses come from
+// the IPAM plugin of the network configuration, e.g. host-local:
+//
+//	{
+//	  "cniVersion": "0.4.0",
+//	  "name": "pods",
+//	  "type": "ovs-cni",
+//	  "options": {"linker.net.ovs.bridge.mode": "nat"},
+//	  "ipam": {"type": "host-local", "subnet": "10.42.0.0/24"}
+//	}
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/gopher-net/docker-ovs-plugin/ovs"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	defaultAdminSocket = "/run/ovs-plugin/admin.sock"
+	daemonTimeout      = 2 * time.Minute
+	// errInternal is CNI's generic error code for plugin failures
+	errInternal = 999
+)
+
+var supportedVersions = []string{"0.3.0", "0.3.1", "0.4.0"}
+
+// netConf is the network configuration the runtime passes on stdin.
+type netConf struct {
+	CNIVersion  string            `json:"cniVersion"`
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	AdminSocket string            `json:"adminSocket"`
+	Options     map[string]string `json:"options"`
+	IPAM        struct {
+		Type string `json:"type"`
+	} `json:"ipam"`
+}
+
+type cniInterface struct {
+	Name    string `json:"name"`
+	Mac     string `json:"mac,omitempty"`
+	Sandbox string `json:"sandbox,omitempty"`
+}
+
+type cniIP struct {
+	Version   string `json:"version"`
+	Interface *int   `json:"interface,omitempty"`
+	Address   string `json:"address"`
+	Gateway   string `json:"gateway,omitempty"`
+}
+
+type cniRoute struct {
+	Dst string `json:"dst"`
+	GW  string `json:"gw,omitempty"`
+}
+
+// cniResult is the result of ADD, and of the IPAM plugin.
+type cniResult struct {
+	CNIVersion string          `json:"cniVersion"`
+	Interfaces []cniInterface  `json:"interfaces,omitempty"`
+	IPs        []cniIP         `json:"ips,omitempty"`
+	Routes     []cniRoute      `json:"routes,omitempty"`
+	DNS        json.RawMessage `json:"dns,omitempty"`
+}
+
+type cniError struct {
+	CNIVersion string `json:"cniVersion"`
+	Code       int    `json:"code"`
+	Msg        string `json:"msg"`
+}
+
+func main() {
+	stdin, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fail("", err)
+	}
+	command := os.Getenv("CNI_COMMAND")
+	if command == "VERSION" {
+		write(map[string]interface{}{"cniVersion": supportedVersions[len(supportedVersions)-1], "supportedVersions": supportedVersions})
+		return
+	}
+	var conf netConf
+	if err := json.Unmarshal(stdin, &conf); err != nil {
+		fail("", fmt.Errorf("invalid network configuration: %v", err))
+	}
+	if conf.AdminSocket == "" {
+		conf.AdminSocket = defaultAdminSocket
+	}
+	switch command {
+	case "ADD":
+		err = add(conf, stdin)
+	case "DEL":
+		err = del(conf, stdin)
+	case "CHECK":
+		err = check(conf)
+	default:
+		err = fmt.Errorf("unknown CNI_COMMAND %q", command)
+	}
+	if err != nil {
+		fail(conf.CNIVersion, err)
+	}
+}
+
+func add(conf netConf, stdin []byte) error {
+	ifName, netnsPath := os.Getenv("CNI_IFNAME"), os.Getenv("CNI_NETNS")
+	if netnsPath == "" {
+		return errors.New("CNI_NETNS is required")
+	}
+	var ipam cniResult
+	if err := execIPAM(conf, "ADD", stdin, &ipam); err != nil {
+		return err
+	}
+	address, gateway, err := ipv4Config(ipam)
+	if err != nil {
+		execIPAM(conf, "DEL", stdin, nil)
+		return err
+	}
+	subnet := &net.IPNet{IP: address.IP.Mask(address.Mask), Mask: address.Mask}
+	req := request(conf, "ADD")
+	req.Network.Subnet = subnet.String()
+	req.Network.Gateway = gateway.String()
+	req.Address = address.String()
+	var a ovs.Attachment
+	if err := callDaemon(conf.AdminSocket, req, &a); err != nil {
+		execIPAM(conf, "DEL", stdin, nil)
+		return err
+	}
+	if err := setupSandbox(netnsPath, ifName, &a, gateway); err != nil {
+		callDaemon(conf.AdminSocket, request(conf, "DEL"), nil)
+		execIPAM(conf, "DEL", stdin, nil)
+		return fmt.Errorf("failed to configure %s in %s: %v", ifName, netnsPath, err)
+	}
+	index := 0
+	write(cniResult{
+		CNIVersion: conf.CNIVersion,
+		Interfaces: []cniInterface{{Name: ifName, Mac: a.MAC, Sandbox: netnsPath}},
+		IPs:        []cniIP{{Version: "4", Interface: &index, Address: address.String(), Gateway: gateway.String()}},
+		Routes:     []cniRoute{{Dst: "0.0.0.0/0", GW: gateway.String()}},
+		DNS:        ipam.DNS,
+	})
+	return nil
+}
+
+// del removes the pod's port and releases its address. Both are already
+// gone when DEL is retried, which is not an error.
+func del(conf netConf, stdin []byte) error {
+	if err := callDaemon(conf.AdminSocket, request(conf, "DEL"), nil); err != nil {
+		return err
+	}
+	return execIPAM(conf, "DEL", stdin, nil)
+}
+
+func check(conf netConf) error {
+	return callDaemon(conf.AdminSocket, request(conf, "CHECK"), nil)
+}
+
+func request(conf netConf, command string) ovs.CNIRequest {
+	return ovs.CNIRequest{
+		Command:     command,
+		ContainerID: os.Getenv("CNI_CONTAINERID"),
+		IfName:      os.Getenv("CNI_IFNAME"),
+		Network:     ovs.NetworkSpec{Name: conf.Name, Options: conf.Options},
+	}
+}
+
+// ipv4Config returns the first IPv4 address of an IPAM result and its
+// gateway, the first address of the subnet if IPAM gave none.
+func ipv4Config(ipam cniResult) (*net.IPNet, net.IP, error) {
+	for _, ip := range ipam.IPs {
+		addr, subnet, err := net.ParseCIDR(ip.Address)
+		if err != nil || addr.To4() == nil {
+			continue
+		}
+		subnet.IP = addr
+		gateway := net.ParseIP(ip.Gateway)
+		if gateway == nil {
+			gateway = make(net.IP, 4)
+			copy(gateway, addr.Mask(subnet.Mask).To4())
+			gateway[3]++
+		}
+		return subnet, gateway.To4(), nil
+	}
+	return nil, nil, errors.New("the IPAM plugin returned no IPv4 address")
+}
+
+// execIPAM runs the IPAM plugin of the network with the same environment
+// and configuration and decodes its result into out.
+func execIPAM(conf netConf, command string, stdin []byte, out interface{}) error {
+	if conf.IPAM.Type == "" {
+		return errors.New("the network configuration has no ipam plugin")
+	}
+	var plugin string
+	for _, dir := range filepath.SplitList(os.Getenv("CNI_PATH")) {
+		if _, err := os.Stat(filepath.Join(dir, conf.IPAM.Type)); err == nil {
+			plugin = filepath.Join(dir, conf.IPAM.Type)
+			break
+		}
+	}
+	if plugin == "" {
+		return fmt.Errorf("ipam plugin %s not found in CNI_PATH", conf.IPAM.Type)
+	}
+	cmd := exec.Command(plugin)
+	cmd.Env = append(os.Environ(), "CNI_COMMAND="+command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		var e cniError
+		if json.Unmarshal(stdout, &e) == nil && e.Msg != "" {
+			return fmt.Errorf("ipam plugin %s: %s", conf.IPAM.Type, e.Msg)
+		}
+		return fmt.Errorf("ipam plugin %s: %v", conf.IPAM.Type, err)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(stdout, out)
+}
+
+// callDaemon posts a request to /cni on the daemon's admin socket.
+func callDaemon(socket string, req ovs.CNIRequest, out interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Timeout: daemonTimeout,
+		Transport: &http.Transport{
+			Dial: func(_, _ string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		},
+	}
+	resp, err := client.Post("http://ovs-plugin/cni", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not reach the ovs plugin at %s: %v", socket, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct{ Err string }
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("ovs plugin: %s", e.Err)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// setupSandbox moves the pod side of the veth into the pod's network
+// namespace and configures it there as docker would: renamed, with the
+// endpoint's MAC, MTU and address, and the default route.
+func setupSandbox(netnsPath, ifName string, a *ovs.Attachment, gateway net.IP) error {
+	netns, err := os.Open(netnsPath)
+	if err != nil {
+		return err
+	}
+	defer netns.Close()
+	link, err := netlink.LinkByName(a.PeerName)
+	if err != nil {
+		return err
+	}
+	if err := netlink.LinkSetNsFd(link, int(netns.Fd())); err != nil {
+		return err
+	}
+	return inNetns(netns, func() error {
+		link, err := netlink.LinkByName(a.PeerName)
+		if err != nil {
+			return err
+		}
+		if err := netlink.LinkSetName(link, ifName); err != nil {
+			return err
+		}
+		if link, err = netlink.LinkByName(ifName); err != nil {
+			return err
+		}
+		if mac, err := net.ParseMAC(a.MAC); err == nil {
+			if err := netlink.LinkSetHardwareAddr(link, mac); err != nil {
+				return err
+			}
+		}
+		if a.MTU > 0 {
+			if err := netlink.LinkSetMTU(link, a.MTU); err != nil {
+				return err
+			}
+		}
+		addr, err := netlink.ParseAddr(a.Address)
+		if err != nil {
+			return err
+		}
+		if err := netlink.AddrAdd(link, addr); err != nil {
+			return err
+		}
+		if err := netlink.LinkSetUp(link); err != nil {
+			return err
+		}
+		if lo, err := netlink.LinkByName("lo"); err == nil {
+			netlink.LinkSetUp(lo)
+		}
+		return netlink.RouteAdd(&netlink.Route{LinkIndex: link.Attrs().Index, Gw: gateway})
+	})
+}
+
+// inNetns runs fn with the calling thread in the network namespace, and
+// moves the thread back afterwards.
+func inNetns(netns *os.File, fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	origin, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()))
+	if err != nil {
+		return err
+	}
+	defer origin.Close()
+	if err := unix.Setns(int(netns.Fd()), unix.CLONE_NEWNET); err != nil {
+		return err
+	}
+	defer unix.Setns(int(origin.Fd()), unix.CLONE_NEWNET)
+	return fn()
+}
+
+func write(v interface{}) {
+	json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// fail reports an error the way CNI expects, as JSON on stdout.
+func fail(version string, err error) {
+	write(cniError{CNIVersion: version, Code: errInternal, Msg: err.Error()})
+	os.Exit(1)
+}
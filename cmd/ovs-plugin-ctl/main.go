@@ -0,0 +1,381 @@
+// Command ovs-plugin-ctl inspects and repairs a running docker-ovs-plugin
+// through its admin socket: it lists the networks and endpoints the plugin
+// manages and the flows and learnt MAC addresses of their bridges, dumps
+// its internal state and OVSDB cache, captures the traffic of endpoints,
+// triggers reconciliation and force-cleans endpoints docker lost track of.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/gopher-net/docker-ovs-plugin/ovs"
+)
+
+const (
+	version = "0.2"
+	// shortIDLen is how much of an id the tables show, as docker does
+	shortIDLen = 12
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "ovs-plugin-ctl"
+	app.Usage = "inspect and repair a running docker-ovs-plugin"
+	app.Version = version
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:   "socket, s",
+			Value:  "/run/ovs-plugin/admin.sock",
+			Usage:  "admin socket of the plugin",
+			EnvVar: "OVS_PLUGIN_ADMIN_SOCKET",
+		},
+	}
+	networkFlag := cli.StringFlag{
+		Name:  "network, n",
+		Usage: "only the network with this id",
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:   "networks",
+			Usage:  "list the networks the plugin manages",
+			Action: listNetworks,
+		},
+		{
+			Name:   "endpoints",
+			Usage:  "list the endpoints the plugin manages",
+			Flags:  []cli.Flag{networkFlag},
+			Action: listEndpoints,
+		},
+		{
+			Name:   "state",
+			Usage:  "dump the internal state of networks and endpoints as JSON",
+			Flags:  []cli.Flag{networkFlag},
+			Action: dumpState,
+		},
+		{
+			Name:  "ovsdb",
+			Usage: "dump the plugin's OVSDB cache as JSON",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "table, t",
+					Usage: "only this table, e.g. Bridge",
+				},
+			},
+			Action: dumpOVSDB,
+		},
+		{
+			Name:  "flows",
+			Usage: "flows <network id>, list the OpenFlow flows the plugin installed on the network's bridge",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "all, a",
+					Usage: "include the flows the plugin did not install",
+				},
+			},
+			Action: listFlows,
+		},
+		{
+			Name:  "fdb",
+			Usage: "list the MAC addresses the bridges learnt and the ports they were learnt on",
+			Flags: []cli.Flag{
+				networkFlag,
+				cli.StringFlag{
+					Name:  "mac, m",
+					Usage: "only this MAC address",
+				},
+			},
+			Action: listFDB,
+		},
+		{
+			Name:  "capture",
+			Usage: "capture <endpoint id>, write the traffic of an endpoint's port to a pcap file",
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "seconds",
+					Value: 30,
+					Usage: "how long to capture",
+				},
+				cli.StringFlag{
+					Name:  "filter, f",
+					Usage: "tcpdump filter expression, e.g. \"udp port 2152\"",
+				},
+				cli.IntFlag{
+					Name:  "snaplen",
+					Usage: "bytes to keep of each packet",
+				},
+				cli.IntFlag{
+					Name:  "count, c",
+					Usage: "stop after this many packets",
+				},
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "pcap file to write, <capture id>.pcap by default",
+				},
+			},
+			Action: capture,
+		},
+		{
+			Name:   "reconcile",
+			Usage:  "repair the host now instead of at the next change or interval",
+			Action: reconcile,
+		},
+		{
+			Name:   "clean-endpoint",
+			Usage:  "clean-endpoint <endpoint id>, remove the port, veth and state of an endpoint whatever docker thinks of it",
+			Action: cleanEndpoint,
+		},
+	}
+	app.Run(os.Args)
+}
+
+func listNetworks(ctx *cli.Context) {
+	var state ovs.DriverState
+	call(ctx, "GET", "/state", nil, &state)
+	counts := make(map[string]int)
+	for _, es := range state.Endpoints {
+		counts[es.NetworkID]++
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NETWORK ID\tNAME\tBRIDGE\tMODE\tTYPE\tMTU\tENDPOINTS")
+	for _, id := range sortedKeys(state.Networks) {
+		ns := state.Networks[id]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\n", shortID(id), ns.NetworkName, ns.BridgeName, ns.Mode, ns.NetworkType, ns.MTU, counts[id])
+	}
+	w.Flush()
+}
+
+func listEndpoints(ctx *cli.Context) {
+	var state ovs.DriverState
+	call(ctx, "GET", "/state"+networkQuery(ctx), nil, &state)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ENDPOINT ID\tNETWORK ID\tBRIDGE\tADDRESS\tMAC")
+	for _, id := range sortedKeys(state.Endpoints) {
+		es := state.Endpoints[id]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", shortID(id), shortID(es.NetworkID), es.BridgeName, es.Address, es.MacAddress)
+	}
+	w.Flush()
+}
+
+func dumpState(ctx *cli.Context) {
+	var state json.RawMessage
+	call(ctx, "GET", "/state"+networkQuery(ctx), nil, &state)
+	printJSON(state)
+}
+
+func dumpOVSDB(ctx *cli.Context) {
+	path := "/ovsdb"
+	if table := ctx.String("table"); table != "" {
+		path += "?table=" + url.QueryEscape(table)
+	}
+	var dump json.RawMessage
+	call(ctx, "GET", path, nil, &dump)
+	printJSON(dump)
+}
+
+func listFlows(ctx *cli.Context) {
+	network := ctx.Args().First()
+	if network == "" {
+		fatalf("flows needs a network id")
+	}
+	path := "/flows?network=" + url.QueryEscape(network)
+	if ctx.Bool("all") {
+		path += "&all=true"
+	}
+	var dump ovs.FlowDump
+	call(ctx, "GET", path, nil, &dump)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tPRIORITY\tOWNER\tPACKETS\tBYTES\tMATCH\tACTIONS")
+	for _, flow := range dump.Flows {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%d\t%s\t%s\n", flow.Table, flow.Priority, flow.Owner, flow.Packets, flow.Bytes, flow.Match, flow.Actions)
+	}
+	w.Flush()
+}
+
+func listFDB(ctx *cli.Context) {
+	query := url.Values{}
+	if network := ctx.String("network"); network != "" {
+		query.Set("network", network)
+	}
+	if mac := ctx.String("mac"); mac != "" {
+		query.Set("mac", mac)
+	}
+	var entries []ovs.FDBEntry
+	call(ctx, "GET", "/fdb?"+query.Encode(), nil, &entries)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "MAC\tVLAN\tBRIDGE\tPORT\tENDPOINT ID\tAGE")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\n", entry.MAC, entry.VLAN, entry.BridgeName, entry.Port, shortID(entry.EndpointID), entry.Age)
+	}
+	w.Flush()
+}
+
+func capture(ctx *cli.Context) {
+	id := ctx.Args().First()
+	if id == "" {
+		fatalf("capture needs an endpoint id")
+	}
+	req := ovs.CaptureRequest{
+		EndpointID: id,
+		Seconds:    ctx.Int("seconds"),
+		Snaplen:    ctx.Int("snaplen"),
+		MaxPackets: ctx.Int("count"),
+		Filter:     ctx.String("filter"),
+	}
+	var info ovs.CaptureInfo
+	call(ctx, "POST", "/captures", req, &info)
+	fmt.Fprintf(os.Stderr, "capturing port %s of endpoint %s until %s\n", info.Port, shortID(info.EndpointID), info.Expires.Format(time.RFC3339))
+	query := "?id=" + url.QueryEscape(info.ID)
+	for info.Running {
+		time.Sleep(time.Second)
+		call(ctx, "GET", "/captures"+query, nil, &info)
+	}
+	if info.Error != "" {
+		fatalf("capture %s failed: %s", info.ID, info.Error)
+	}
+	output := ctx.String("output")
+	if output == "" {
+		output = info.ID + ".pcap"
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	resp := do(ctx, "GET", "/captures/file"+query, nil)
+	_, err = io.Copy(f, resp.Body)
+	resp.Body.Close()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fatalf("failed to write %s: %v", output, err)
+	}
+	call(ctx, "DELETE", "/captures"+query, nil, &struct{}{})
+	fmt.Printf("wrote %d bytes to %s\n", info.Size, output)
+}
+
+func reconcile(ctx *cli.Context) {
+	var result ovs.ReconcileResult
+	call(ctx, "POST", "/reconcile", nil, &result)
+	if result.Failed > 0 {
+		fatalf("reconciled at %s, %d networks could not be repaired, see the plugin's log", result.Time.Format(time.RFC3339), result.Failed)
+	}
+	fmt.Printf("reconciled at %s\n", result.Time.Format(time.RFC3339))
+}
+
+func cleanEndpoint(ctx *cli.Context) {
+	id := ctx.Args().First()
+	if id == "" {
+		fatalf("clean-endpoint needs an endpoint id")
+	}
+	var result ovs.CleanResult
+	call(ctx, "POST", "/endpoints/clean", ovs.CleanRequest{EndpointID: id}, &result)
+	if len(result.Removed) == 0 {
+		fmt.Printf("nothing left of endpoint %s\n", shortID(result.EndpointID))
+		return
+	}
+	for _, removed := range result.Removed {
+		fmt.Printf("removed %s of endpoint %s\n", removed, shortID(result.EndpointID))
+	}
+}
+
+// call sends body as JSON to path on the admin socket and decodes the
+// reply into out, exiting on errors.
+func call(ctx *cli.Context, method, path string, body, out interface{}) {
+	resp := do(ctx, method, path, body)
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		fatalf("invalid reply from the plugin: %v", err)
+	}
+}
+
+// do sends body as JSON to path on the admin socket and returns the reply,
+// exiting on errors.
+func do(ctx *cli.Context, method, path string, body interface{}) *http.Response {
+	socket := ctx.GlobalString("socket")
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, "http://ovs-plugin"+path, reader)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Dial: func(_, _ string) (net.Conn, error) {
+				return net.Dial("unix", socket)
+			},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		fatalf("could not reach the plugin at %s: %v", socket, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct{ Err string }
+		json.NewDecoder(resp.Body).Decode(&e)
+		resp.Body.Close()
+		fatalf("%s: %s", resp.Status, e.Err)
+	}
+	return resp
+}
+
+func networkQuery(ctx *cli.Context) string {
+	if network := ctx.String("network"); network != "" {
+		return "?network=" + url.QueryEscape(network)
+	}
+	return ""
+}
+
+func printJSON(raw json.RawMessage) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		fatalf("invalid reply from the plugin: %v", err)
+	}
+	out.WriteByte('\n')
+	out.WriteTo(os.Stdout)
+}
+
+// sortedKeys returns the ids of a map of networks or endpoints in order.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*ovs.NetworkState:
+		for id := range m {
+			keys = append(keys, id)
+		}
+	case map[string]*ovs.EndpointState:
+		for id := range m {
+			keys = append(keys, id)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func shortID(id string) string {
+	if len(id) > shortIDLen {
+		return id[:shortIDLen]
+	}
+	return id
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ovs-plugin-ctl: "+format+"\n", args...)
+	os.Exit(1)
+}
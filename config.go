@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/codegangsta/cli"
+)
+
+const (
+	defaultConfigPath = "/etc/docker-ovs-plugin/config.toml"
+	configEnv         = "OVS_PLUGIN_CONFIG"
+	envPrefix         = "OVS_PLUGIN_"
+)
+
+// configPath returns the config file named by --config, OVS_PLUGIN_CONFIG
+// or else the default one, and whether it was asked for. The flags are
+// scanned before the app parses them since the file sets their defaults.
+func configPath(args []string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if strings.HasPrefix(name, "config=") {
+			return strings.TrimPrefix(name, "config="), true
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	if path := os.Getenv(configEnv); path != "" {
+		return path, true
+	}
+	return defaultConfigPath, false
+}
+
+// loadConfig reads the settings of a config file and returns the flags with
+// the settings as their defaults, so environment variables and the command
+// line still override them.
+//
+// The file is TOML with the flag names as keys. Keys in a table are
+// prefixed with its name, so timeout in [ovsdb] is --ovsdb-timeout:
+//
+//	name = "ovs"
+//	firewall = "nftables"
+//	default-mtu = 9000
+//	profile = ["sgw=linker.net.ovs.bridge.type=sgw"]
+//
+//	[ovsdb]
+//	timeout = "10s"
+//	wait = true
+//
+// Settings are strings, integers, floats, booleans or arrays of strings.
+func loadConfig(path string, flags []cli.Flag) ([]cli.Flag, error) {
+	settings, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]cli.Flag, len(flags))
+	copy(out, flags)
+	for key, value := range settings {
+		i := flagIndex(out, key)
+		if i < 0 || key == "config" {
+			return nil, fmt.Errorf("%s: unknown setting %s", path, key)
+		}
+		flag, err := setFlagDefault(out[i], value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", path, key, err)
+		}
+		out[i] = flag
+	}
+	return out, nil
+}
+
+// flagIndex returns the flag with the long name, -1 if there is none.
+func flagIndex(flags []cli.Flag, name string) int {
+	for i, flag := range flags {
+		if flagName(flag) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// flagName returns the long name of a flag.
+func flagName(flag cli.Flag) string {
+	var names string
+	switch f := flag.(type) {
+	case cli.StringFlag:
+		names = f.Name
+	case cli.IntFlag:
+		names = f.Name
+	case cli.Float64Flag:
+		names = f.Name
+	case cli.BoolFlag:
+		names = f.Name
+	case cli.BoolTFlag:
+		names = f.Name
+	case cli.StringSliceFlag:
+		names = f.Name
+	}
+	return strings.TrimSpace(strings.Split(names, ",")[0])
+}
+
+// flagEnvVar is the environment variable of a flag without its own,
+// OVS_PLUGIN_ and the name in capitals, e.g. OVS_PLUGIN_OVSDB_RETRIES.
+func flagEnvVar(name string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// listsWithCommas are the list flags whose values hold commas, so the
+// comma separated value of an environment variable can't set them
+var listsWithCommas = map[string]bool{"profile": true}
+
+// withEnvVars returns the flags with an environment variable for each flag
+// that has none, so a plugin shipped as a container is configured without
+// changing its command.
+func withEnvVars(flags []cli.Flag) []cli.Flag {
+	out := make([]cli.Flag, len(flags))
+	for i, flag := range flags {
+		switch f := flag.(type) {
+		case cli.StringFlag:
+			if f.EnvVar == "" {
+				f.EnvVar = flagEnvVar(flagName(f))
+			}
+			flag = f
+		case cli.IntFlag:
+			if f.EnvVar == "" {
+				f.EnvVar = flagEnvVar(flagName(f))
+			}
+			flag = f
+		case cli.Float64Flag:
+			if f.EnvVar == "" {
+				f.EnvVar = flagEnvVar(flagName(f))
+			}
+			flag = f
+		case cli.BoolFlag:
+			if f.EnvVar == "" {
+				f.EnvVar = flagEnvVar(flagName(f))
+			}
+			flag = f
+		case cli.StringSliceFlag:
+			if f.EnvVar == "" && !listsWithCommas[flagName(f)] {
+				f.EnvVar = flagEnvVar(flagName(f))
+			}
+			flag = f
+		}
+		out[i] = flag
+	}
+	return out
+}
+
+// setFlagDefault returns the flag with value as its default.
+func setFlagDefault(flag cli.Flag, value interface{}) (cli.Flag, error) {
+	switch f := flag.(type) {
+	case cli.StringFlag:
+		switch v := value.(type) {
+		case string:
+			f.Value = v
+		case int64, float64:
+			f.Value = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("want a string")
+		}
+		return f, nil
+	case cli.IntFlag:
+		v, ok := value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("want an integer")
+		}
+		f.Value = int(v)
+		return f, nil
+	case cli.Float64Flag:
+		switch v := value.(type) {
+		case float64:
+			f.Value = v
+		case int64:
+			f.Value = float64(v)
+		default:
+			return nil, fmt.Errorf("want a number")
+		}
+		return f, nil
+	case cli.BoolFlag:
+		v, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("want true or false")
+		}
+		if v {
+			// --<name>=false still turns it off
+			return cli.BoolTFlag{Name: f.Name, Usage: f.Usage, EnvVar: f.EnvVar}, nil
+		}
+		return f, nil
+	case cli.StringSliceFlag:
+		v, ok := value.([]string)
+		if !ok {
+			return nil, fmt.Errorf("want an array of strings")
+		}
+		slice := cli.StringSlice(v)
+		f.Value = &slice
+		return f, nil
+	}
+	return nil, fmt.Errorf("can't be set in the config file")
+}
+
+// readConfig parses a config file into its settings, with the keys of
+// tables prefixed by the table's name.
+func readConfig(path string) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	if _, err := toml.DecodeFile(path, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	settings := make(map[string]interface{})
+	if err := flattenConfig(settings, "", raw); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return settings, nil
+}
+
+// flattenConfig adds the settings of a table to settings, its tables
+// included.
+func flattenConfig(settings map[string]interface{}, prefix string, table map[string]interface{}) error {
+	for k, v := range table {
+		key := configKey(k)
+		if prefix != "" {
+			key = prefix + "-" + key
+		}
+		if sub, ok := v.(map[string]interface{}); ok {
+			if err := flattenConfig(settings, key, sub); err != nil {
+				return err
+			}
+			continue
+		}
+		if items, ok := v.([]interface{}); ok {
+			strs := make([]string, len(items))
+			for i, item := range items {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("%s: only arrays of strings are supported", key)
+				}
+				strs[i] = s
+			}
+			v = strs
+		}
+		if _, ok := settings[key]; ok {
+			return fmt.Errorf("%s is set twice", key)
+		}
+		settings[key] = v
+	}
+	return nil
+}
+
+// configKey accepts TOML style keys with underscores for the flag names.
+func configKey(key string) string {
+	return strings.Replace(key, "_", "-", -1)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
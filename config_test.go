@@ -0,0 +1,148 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codegangsta/cli"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "ovs-plugin-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			content: "# nothing\n",
+			want:    map[string]interface{}{},
+		},
+		{
+			name: "values",
+			content: `name = "ovs"
+default_mtu = 9000
+ratio = 0.5
+managed = true
+profile = ["sgw=linker.net.ovs.bridge.type=sgw", "a # b"] # comment
+`,
+			want: map[string]interface{}{
+				"name":        "ovs",
+				"default-mtu": int64(9000),
+				"ratio":       0.5,
+				"managed":     true,
+				"profile":     []string{"sgw=linker.net.ovs.bridge.type=sgw", "a # b"},
+			},
+		},
+		{
+			name: "tables",
+			content: `[ovsdb]
+timeout = "10s"
+wait = true
+
+[ovsdb.tls]
+ca = '/etc/ca.pem'
+`,
+			want: map[string]interface{}{
+				"ovsdb-timeout": "10s",
+				"ovsdb-wait":    true,
+				"ovsdb-tls-ca":  "/etc/ca.pem",
+			},
+		},
+		{
+			name: "multiline array",
+			content: `profile = [
+  "a",
+  "b",
+]
+`,
+			want: map[string]interface{}{"profile": []string{"a", "b"}},
+		},
+		{
+			name:    "set twice through a table",
+			content: "ovsdb-timeout = \"1s\"\n[ovsdb]\ntimeout = \"2s\"\n",
+			wantErr: true,
+		},
+		{
+			name:    "array of integers",
+			content: "ports = [1, 2]\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			content: "name = \n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := readConfig(writeConfig(t, tt.content))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	flags := []cli.Flag{
+		cli.StringFlag{Name: "name, n", Value: "ovs"},
+		cli.IntFlag{Name: "default-mtu", Value: 1500},
+		cli.BoolFlag{Name: "managed"},
+		cli.StringSliceFlag{Name: "profile", Value: &cli.StringSlice{}},
+	}
+	path := writeConfig(t, `name = "other"
+default-mtu = 9000
+managed = true
+profile = ["a=b"]
+`)
+	out, err := loadConfig(path, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := out[0].(cli.StringFlag).Value; v != "other" {
+		t.Errorf("name = %q", v)
+	}
+	if v := out[1].(cli.IntFlag).Value; v != 9000 {
+		t.Errorf("default-mtu = %d", v)
+	}
+	if _, ok := out[2].(cli.BoolTFlag); !ok {
+		t.Errorf("managed = %#v, want a BoolTFlag", out[2])
+	}
+	if v := *out[3].(cli.StringSliceFlag).Value; !reflect.DeepEqual([]string(v), []string{"a=b"}) {
+		t.Errorf("profile = %v", v)
+	}
+	if _, ok := flags[0].(cli.StringFlag); !ok || flags[0].(cli.StringFlag).Value != "ovs" {
+		t.Errorf("loadConfig changed the flags it was given")
+	}
+
+	for _, content := range []string{
+		"unknown = 1\n",
+		"config = \"x\"\n",
+		"default-mtu = \"big\"\n",
+		"managed = 1\n",
+	} {
+		if _, err := loadConfig(writeConfig(t, content), flags); err == nil {
+			t.Errorf("loadConfig(%q) succeeded", content)
+		}
+	}
+}
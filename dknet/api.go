@@ -1,6 +1,7 @@
 package dknet
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -14,7 +15,7 @@ import (
 const (
 	defaultContentTypeV1_1        = "application/vnd.docker.plugins.v1.1+json"
 	defaultImplementationManifest = `{"Implements": ["NetworkDriver"]}`
-	defaultScope                  = `{"Scope": "local"}`
+	defaultScope                  = "local"
 	emptyResponse                 = `{}`
 
 	activatePath       = "/Plugin.Activate"
@@ -26,19 +27,23 @@ const (
 	deleteEndpointPath = "/NetworkDriver.DeleteEndpoint"
 	joinPath           = "/NetworkDriver.Join"
 	leavePath          = "/NetworkDriver.Leave"
-	//discoverNewPath    = "/NetworkDriver.DiscoverNew"
-	//discoverDeletePath = "/NetworkDriver.DiscoverDelete"
+	discoverNewPath    = "/NetworkDriver.DiscoverNew"
+	discoverDeletePath = "/NetworkDriver.DiscoverDelete"
 )
 
-// Driver represent the interface a driver must fulfill.
+// NodeDiscovery is the DiscoveryType of notifications about cluster nodes.
+const NodeDiscovery = 1
+
+// Driver represent the interface a driver must fulfill. Each call gets the
+// context of docker's request, it is done when docker hangs up.
 type Driver interface {
-	CreateNetwork(*CreateNetworkRequest) error
-	DeleteNetwork(*DeleteNetworkRequest) error
-	CreateEndpoint(*CreateEndpointRequest) error
-	DeleteEndpoint(*DeleteEndpointRequest) error
-	EndpointInfo(*InfoRequest) (*InfoResponse, error)
-	Join(*JoinRequest) (*JoinResponse, error)
-	Leave(*LeaveRequest) error
+	CreateNetwork(context.Context, *CreateNetworkRequest) error
+	DeleteNetwork(context.Context, *DeleteNetworkRequest) error
+	CreateEndpoint(context.Context, *CreateEndpointRequest) error
+	DeleteEndpoint(context.Context, *DeleteEndpointRequest) error
+	EndpointInfo(context.Context, *InfoRequest) (*InfoResponse, error)
+	Join(context.Context, *JoinRequest) (*JoinResponse, error)
+	Leave(context.Context, *LeaveRequest) error
 }
 
 type CreateNetworkRequest struct {
@@ -116,6 +121,35 @@ type LeaveRequest struct {
 	Options    map[string]interface{}
 }
 
+// Discoverer is implemented by drivers that want docker's discovery
+// notifications, the others accept and ignore them.
+type Discoverer interface {
+	DiscoverNew(*DiscoveryNotification) error
+	DiscoverDelete(*DiscoveryNotification) error
+}
+
+type DiscoveryNotification struct {
+	DiscoveryType int
+	DiscoveryData interface{}
+}
+
+// NodeDiscoveryData is the DiscoveryData of a NodeDiscovery notification.
+type NodeDiscoveryData struct {
+	Address     string
+	BindAddress string
+	Self        bool
+}
+
+// Scoper is implemented by drivers whose networks are not local to the
+// host, Scope returns "global" for them.
+type Scoper interface {
+	Scope() string
+}
+
+type CapabilitiesResponse struct {
+	Scope string
+}
+
 // Handler forwards requests and responses between the docker daemon and the plugin.
 type Handler struct {
 	driver Driver
@@ -136,7 +170,43 @@ func (h *Handler) initMux() {
 	})
 
 	h.mux.HandleFunc(capabilitiesPath, func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, defaultScope)
+		scope := defaultScope
+		if s, ok := h.driver.(Scoper); ok {
+			scope = s.Scope()
+		}
+		objectResponse(w, &CapabilitiesResponse{Scope: scope})
+	})
+
+	h.mux.HandleFunc(discoverNewPath, func(w http.ResponseWriter, r *http.Request) {
+		req := &DiscoveryNotification{}
+		err := decodeRequest(r, req)
+		if err != nil {
+			badRequestResponse(w)
+			return
+		}
+		if d, ok := h.driver.(Discoverer); ok {
+			if err := d.DiscoverNew(req); err != nil {
+				errorResponse(w, err)
+				return
+			}
+		}
+		successResponse(w)
+	})
+
+	h.mux.HandleFunc(discoverDeletePath, func(w http.ResponseWriter, r *http.Request) {
+		req := &DiscoveryNotification{}
+		err := decodeRequest(r, req)
+		if err != nil {
+			badRequestResponse(w)
+			return
+		}
+		if d, ok := h.driver.(Discoverer); ok {
+			if err := d.DiscoverDelete(req); err != nil {
+				errorResponse(w, err)
+				return
+			}
+		}
+		successResponse(w)
 	})
 
 	h.mux.HandleFunc(createNetworkPath, func(w http.ResponseWriter, r *http.Request) {
@@ -146,7 +216,7 @@ func (h *Handler) initMux() {
 			badRequestResponse(w)
 			return
 		}
-		err = h.driver.CreateNetwork(req)
+		err = h.driver.CreateNetwork(r.Context(), req)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -160,7 +230,7 @@ func (h *Handler) initMux() {
 			badRequestResponse(w)
 			return
 		}
-		err = h.driver.DeleteNetwork(req)
+		err = h.driver.DeleteNetwork(r.Context(), req)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -174,7 +244,7 @@ func (h *Handler) initMux() {
 			badRequestResponse(w)
 			return
 		}
-		err = h.driver.CreateEndpoint(req)
+		err = h.driver.CreateEndpoint(r.Context(), req)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -188,7 +258,7 @@ func (h *Handler) initMux() {
 			badRequestResponse(w)
 			return
 		}
-		err = h.driver.DeleteEndpoint(req)
+		err = h.driver.DeleteEndpoint(r.Context(), req)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -202,7 +272,7 @@ func (h *Handler) initMux() {
 			badRequestResponse(w)
 			return
 		}
-		res, err := h.driver.EndpointInfo(req)
+		res, err := h.driver.EndpointInfo(r.Context(), req)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -216,7 +286,7 @@ func (h *Handler) initMux() {
 			badRequestResponse(w)
 			return
 		}
-		res, err := h.driver.Join(req)
+		res, err := h.driver.Join(r.Context(), req)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -230,7 +300,7 @@ func (h *Handler) initMux() {
 			badRequestResponse(w)
 			return
 		}
-		err = h.driver.Leave(req)
+		err = h.driver.Leave(r.Context(), req)
 		if err != nil {
 			errorResponse(w, err)
 			return
@@ -0,0 +1,8 @@
+// Package dknet is the plugin's fork of github.com/gopher-net/dknet at
+// 72c72f2, the docker network plugin protocol handler. The fork answers
+// GetCapabilities with the scope of drivers that implement Scoper and
+// passes DiscoverNew and DiscoverDelete to drivers that implement
+// Discoverer, which upstream does not. Driver methods also take the
+// context of docker's request, so drivers can trace and cancel the work
+// of a call.
+package dknet
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	journaldSocket = "/run/systemd/journal/socket"
+	// syslogIdentifier is what journalctl -t selects the plugin's entries by
+	syslogIdentifier = "docker-ovs-plugin"
+)
+
+// journaldHook sends log entries to journald over its native protocol, each
+// logrus field as a journal field, e.g. network_id as NETWORK_ID, so
+// journalctl NETWORK_ID=<id> lists everything done for a network.
+type journaldHook struct {
+	conn *net.UnixConn
+}
+
+func newJournaldHook() (*journaldHook, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to journald: %v", err)
+	}
+	return &journaldHook{conn: conn}, nil
+}
+
+// journalPriorities maps logrus levels to syslog priorities.
+var journalPriorities = map[log.Level]int{
+	log.PanicLevel: 2,
+	log.FatalLevel: 2,
+	log.ErrorLevel: 3,
+	log.WarnLevel:  4,
+	log.InfoLevel:  6,
+	log.DebugLevel: 7,
+}
+
+func (hook *journaldHook) Fire(entry *log.Entry) error {
+	var msg bytes.Buffer
+	writeJournalField(&msg, "MESSAGE", entry.Message)
+	writeJournalField(&msg, "PRIORITY", fmt.Sprint(journalPriorities[entry.Level]))
+	writeJournalField(&msg, "SYSLOG_IDENTIFIER", syslogIdentifier)
+	for key, value := range entry.Data {
+		writeJournalField(&msg, journalFieldName(key), fmt.Sprint(value))
+	}
+	_, err := hook.conn.Write(msg.Bytes())
+	return err
+}
+
+func (hook *journaldHook) Levels() []log.Level {
+	return []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel, log.WarnLevel, log.InfoLevel, log.DebugLevel}
+}
+
+// journalFieldName turns a logrus field name into a journal one, which is
+// upper case letters, digits and underscores not starting with one.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "FIELD_" + name
+	}
+	return name
+}
+
+// writeJournalField writes a field in the native protocol, values with a
+// newline are sent with their length instead of as KEY=value lines.
+func writeJournalField(msg *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(msg, "%s=%s\n", name, value)
+		return
+	}
+	msg.WriteString(name + "\n")
+	binary.Write(msg, binary.LittleEndian, uint64(len(value)))
+	msg.WriteString(value + "\n")
+}
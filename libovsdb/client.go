@@ -3,9 +3,9 @@ package libovsdb
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"log"
 	"net"
+	"strconv"
 
 	"github.com/cenkalti/rpc2"
 	"github.com/cenkalti/rpc2/jsonrpc"
@@ -42,13 +42,23 @@ func Connect(ipAddr string, port int) (*OvsdbClient, error) {
 		port = DEFAULT_PORT
 	}
 
-	target := fmt.Sprintf("%s:%d", ipAddr, port)
+	target := net.JoinHostPort(ipAddr, strconv.Itoa(port))
 	conn, err := net.Dial("tcp", target)
 
 	if err != nil {
 		return nil, err
 	}
 
+	return newRPC2Client(conn)
+}
+
+// ConnectWithConn speaks OVSDB over a connection the caller dialed, to
+// ovsdb-server on its unix socket for example
+func ConnectWithConn(conn net.Conn) (*OvsdbClient, error) {
+	return newRPC2Client(conn)
+}
+
+func newRPC2Client(conn net.Conn) (*OvsdbClient, error) {
 	c := rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
 	c.Handle("echo", echo)
 	c.Handle("update", update)
@@ -0,0 +1,4 @@
+// Package libovsdb is the plugin's fork of github.com/socketplane/libovsdb
+// at 58cf012, the OVSDB client. The fork adds ConnectWithConn, so the
+// plugin dials ovsdb-server on the transports upstream doesn't support.
+package libovsdb
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/Sirupsen/logrus/hooks/syslog"
+)
+
+// logConfig is how and where the plugin logs.
+type logConfig struct {
+	Level  string
+	Format string
+	File   string
+	// MaxSize is the size in bytes the log file is rotated at, MaxFiles how
+	// many rotated files are kept
+	MaxSize  int64
+	MaxFiles int
+	// Journald sends entries to journald with their fields as journal
+	// fields instead of writing them to stderr
+	Journald bool
+	// Syslog is the syslog server entries are also sent to, local for the
+	// host's syslog or udp://<host>:<port> and tcp://<host>:<port>
+	Syslog string
+}
+
+// setupLogging applies the log level, format and destination.
+func setupLogging(config logConfig) error {
+	level, err := log.ParseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(level)
+
+	switch config.Format {
+	case "text":
+		log.SetFormatter(&log.TextFormatter{})
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return fmt.Errorf("unknown log format %s, want text or json", config.Format)
+	}
+
+	if config.Journald {
+		hook, err := newJournaldHook()
+		if err != nil {
+			return err
+		}
+		log.AddHook(hook)
+		// stderr of a systemd service goes to the journal too
+		log.SetOutput(ioutil.Discard)
+	}
+	if config.Syslog != "" {
+		network, raddr := "", ""
+		if config.Syslog != "local" {
+			parts := strings.SplitN(config.Syslog, "://", 2)
+			if len(parts) != 2 || (parts[0] != "udp" && parts[0] != "tcp") {
+				return fmt.Errorf("invalid syslog address %s, want local, udp://<host>:<port> or tcp://<host>:<port>", config.Syslog)
+			}
+			network, raddr = parts[0], parts[1]
+		}
+		hook, err := logrus_syslog.NewSyslogHook(network, raddr, syslog.LOG_DAEMON, syslogIdentifier)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %v", err)
+		}
+		log.AddHook(hook)
+	}
+
+	if config.File == "" {
+		return nil
+	}
+	file, err := openRotatingFile(config.File, config.MaxSize, config.MaxFiles)
+	if err != nil {
+		return err
+	}
+	log.SetOutput(file)
+	return nil
+}
+
+// rotatingFile is a log file that is renamed to <path>.1 once it reaches
+// maxSize, the older files shift to <path>.2 and so on up to maxFiles.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the log file for appending, with mu held.
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open log file %s: %v", r.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the rotated files and starts a new log file, with mu held.
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxFiles))
+	for i := r.maxFiles - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.maxFiles > 0 {
+		os.Rename(r.path, r.path+".1")
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			// keep logging to stderr rather than losing the entry
+			fmt.Fprintf(os.Stderr, "failed to rotate %s: %v\n", r.path, err)
+			return os.Stderr.Write(p)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/codegangsta/cli"
-	"github.com/gopher-net/dknet"
 	"github.com/gopher-net/docker-ovs-plugin/ovs"
 )
 
@@ -15,32 +19,780 @@ const (
 
 func main() {
 
+	var flagConfig = cli.StringFlag{
+		Name:   "config",
+		Value:  defaultConfigPath,
+		Usage:  "TOML file with defaults for the other flags, keyed by flag name",
+		EnvVar: configEnv,
+	}
 	var flagDebug = cli.BoolFlag{
 		Name:  "debug, d",
-		Usage: "enable debugging",
+		Usage: "enable debugging, same as --log-level debug",
+	}
+	var flagLogLevel = cli.StringFlag{
+		Name:   "log-level",
+		Value:  "info",
+		Usage:  "log level: debug, info, warn, error, fatal or panic",
+		EnvVar: "OVS_PLUGIN_LOG_LEVEL",
+	}
+	var flagLogFormat = cli.StringFlag{
+		Name:   "log-format",
+		Value:  "text",
+		Usage:  "log format: text or json",
+		EnvVar: "OVS_PLUGIN_LOG_FORMAT",
+	}
+	var flagLogFile = cli.StringFlag{
+		Name:   "log-file",
+		Usage:  "file to log to instead of stderr, rotated at --log-max-size",
+		EnvVar: "OVS_PLUGIN_LOG_FILE",
+	}
+	var flagLogMaxSize = cli.IntFlag{
+		Name:  "log-max-size",
+		Value: 100,
+		Usage: "size in MB the log file is rotated at, 0 never",
+	}
+	var flagLogMaxFiles = cli.IntFlag{
+		Name:  "log-max-files",
+		Value: 5,
+		Usage: "how many rotated log files are kept",
+	}
+	var flagLogJournald = cli.BoolFlag{
+		Name:  "log-journald",
+		Usage: "log to journald with the network, endpoint and bridge as journal fields",
+	}
+	var flagLogSyslog = cli.StringFlag{
+		Name:  "log-syslog",
+		Usage: "also log to syslog: local, udp://<host>:<port> or tcp://<host>:<port>",
+	}
+	var flagAuditLog = cli.StringFlag{
+		Name:  "audit-log",
+		Usage: "file every CreateNetwork, DeleteNetwork, CreateEndpoint, DeleteEndpoint, Join and Leave is appended to as JSON",
+	}
+	var flagOTLPEndpoint = cli.StringFlag{
+		Name:   "otlp-endpoint",
+		Usage:  "OpenTelemetry collector to send traces of driver calls to over OTLP/HTTP, e.g. http://localhost:4318, off when empty",
+		EnvVar: "OTEL_EXPORTER_OTLP_ENDPOINT",
+	}
+	var flagLogOvsdbUpdates = cli.BoolFlag{
+		Name:  "log-ovsdb-updates",
+		Usage: "log every OVSDB update in full at debug level",
+	}
+	var flagAdminSocket = cli.StringFlag{
+		Name:  "admin-socket",
+		Value: "/run/ovs-plugin/admin.sock",
+		Usage: "unix socket of the admin API, empty to disable it",
+	}
+	var flagFirewall = cli.StringFlag{
+		Name:  "firewall",
+		Value: "iptables",
+		Usage: "firewall backend used for NAT and filter rules, iptables or nftables",
+	}
+	var flagName = cli.StringFlag{
+		Name:  "name",
+		Value: "ovs",
+		Usage: "driver name docker knows the plugin by, lets several instances run side by side",
+	}
+	var flagListen = cli.StringFlag{
+		Name:  "listen",
+		Usage: "unix:///path, tcp://host:port or a socket name in /run/docker/plugins to serve docker on, defaults to /run/docker/plugins/<name>.sock",
+	}
+	var flagManaged = cli.BoolFlag{
+		Name:  "managed",
+		Usage: "run as a docker managed (v2) plugin, confined to its rootfs and serving the socket of its config.json",
+	}
+	var flagHostRoot = cli.StringFlag{
+		Name:  "host-root",
+		Usage: "where the host's root is mounted in the plugin's rootfs, e.g. /host, for installing the gateway unit on the host",
+	}
+	var flagSkipModprobe = cli.BoolFlag{
+		Name:  "skip-modprobe",
+		Usage: "don't load the openvswitch kernel module when it is missing, fail creating kernel datapath bridges instead",
+	}
+	var flagActivate = cli.BoolFlag{
+		Name:  "activate",
+		Usage: "check the plugin activation handshake before registering with docker",
+	}
+	var flagProfile = cli.StringSliceFlag{
+		Name:  "profile",
+		Value: &cli.StringSlice{},
+		Usage: "serve an additional driver name with default network options, name=key=value[,key=value]",
+	}
+	var flagForceOwnership = cli.BoolFlag{
+		Name:  "force-ownership",
+		Usage: "modify and delete bridges, ports and links the plugin did not create",
+	}
+	var flagStandbyPeer = cli.StringFlag{
+		Name:  "standby-peer",
+		Usage: "host:port of the replication API of the warm standby gateway to replicate state to",
+	}
+	var flagReplicationListen = cli.StringFlag{
+		Name:  "replication-listen",
+		Usage: "host:port to accept replicated state from the active gateway on, as a warm standby",
+	}
+	var flagReplicationSecret = cli.StringFlag{
+		Name:   "replication-secret",
+		Usage:  "secret shared by the hosts of a gateway pair",
+		EnvVar: "OVS_PLUGIN_REPLICATION_SECRET",
+	}
+	var flagReplicationCert = cli.StringFlag{
+		Name:  "replication-cert",
+		Usage: "certificate this host presents to the other host of its gateway pair",
+	}
+	var flagReplicationKey = cli.StringFlag{
+		Name:  "replication-key",
+		Usage: "private key of --replication-cert",
+	}
+	var flagReplicationCA = cli.StringFlag{
+		Name:  "replication-ca",
+		Usage: "CA certificate the certificates of both hosts of a gateway pair are signed by",
+	}
+	var flagControllerURL = cli.StringFlag{
+		Name:  "controller-url",
+		Usage: "REST API of the Linker controller to register networks with and report health to, e.g. https://controller:8080/api/v1",
+	}
+	var flagControllerToken = cli.StringFlag{
+		Name:   "controller-token",
+		Usage:  "token the plugin and the Linker controller authenticate each other with",
+		EnvVar: "OVS_PLUGIN_CONTROLLER_TOKEN",
+	}
+	var flagControllerInterval = cli.StringFlag{
+		Name:  "controller-interval",
+		Value: "1m",
+		Usage: "how often to report health to the Linker controller",
+	}
+	var flagControllerListen = cli.StringFlag{
+		Name:  "controller-listen",
+		Usage: "host:port to accept configuration pushed by the Linker controller on, over TLS",
+	}
+	var flagControllerCert = cli.StringFlag{
+		Name:  "controller-cert",
+		Usage: "certificate to serve --controller-listen with",
+	}
+	var flagControllerKey = cli.StringFlag{
+		Name:  "controller-key",
+		Usage: "private key of --controller-cert",
+	}
+	var flagControllerCA = cli.StringFlag{
+		Name:  "controller-ca",
+		Usage: "CA certificate of the Linker controller, trusted for --controller-url and required of its client certificate on --controller-listen",
+	}
+	var flagVisibilityObject = cli.StringFlag{
+		Name:  "visibility-bpf",
+		Usage: "eBPF object to attach to endpoint veths for per-endpoint flow metrics",
+	}
+	var flagAuditInterval = cli.StringFlag{
+		Name:  "audit-interval",
+		Usage: "how often to audit the host for configuration drift, e.g. 24h",
+	}
+	var flagAuditEndpoint = cli.StringFlag{
+		Name:  "audit-endpoint",
+		Usage: "URL signed audit reports are POSTed to",
+	}
+	var flagAuditKey = cli.StringFlag{
+		Name:   "audit-key",
+		Usage:  "key audit reports are signed with (HMAC-SHA256)",
+		EnvVar: "OVS_PLUGIN_AUDIT_KEY",
+	}
+	var flagHWOffload = cli.BoolFlag{
+		Name:  "hw-offload",
+		Usage: "set other_config:hw-offload so flows are offloaded to switchdev NICs with tc flower",
+	}
+	var flagMetadataGCInterval = cli.StringFlag{
+		Name:  "metadata-gc-interval",
+		Value: "10m",
+		Usage: "how often to remove network records of bridges or networks that are gone, 0 to never",
+	}
+	var flagOvsdb = cli.StringFlag{
+		Name:   "ovsdb",
+		Usage:  "ovsdb-server address, unix:<path>, tcp:<ip>:<port> or ssl:<ip>:<port>, defaults to the unix socket if it exists, else tcp:127.0.0.1:6640",
+		EnvVar: "OVS_PLUGIN_OVSDB",
+	}
+	var flagOvsdbNode = cli.StringSliceFlag{
+		Name:  "ovsdb-node",
+		Value: &cli.StringSlice{},
+		Usage: "remote OVS a network can put its bridge on with linker.net.ovs.bridge.node, name=<ovsdb endpoint>",
+	}
+	var flagOvsdbMonitorAll = cli.BoolFlag{
+		Name:  "ovsdb-monitor-all",
+		Usage: "monitor every OVSDB table and column instead of only those the plugin reads",
+	}
+	var flagOvsdbCert = cli.StringFlag{
+		Name:  "ovsdb-cert",
+		Usage: "PEM client certificate for ssl: ovsdb endpoints",
+	}
+	var flagOvsdbKey = cli.StringFlag{
+		Name:  "ovsdb-key",
+		Usage: "PEM private key of the client certificate",
+	}
+	var flagOvsdbCA = cli.StringFlag{
+		Name:  "ovsdb-ca",
+		Usage: "PEM CA certificate the ovsdb-server certificate must chain to",
+	}
+	var flagOvsdbRetries = cli.IntFlag{
+		Name:  "ovsdb-retries",
+		Value: 2,
+		Usage: "how many times connecting to ovsdb-server on start is retried",
+	}
+	var flagOvsdbWait = cli.BoolFlag{
+		Name:  "ovsdb-wait",
+		Usage: "retry connecting to ovsdb-server until it is up, for starting before openvswitch on boot",
+	}
+	var flagOvsdbBackoff = cli.StringFlag{
+		Name:  "ovsdb-backoff",
+		Value: "5s",
+		Usage: "wait before the first retry, doubled on each retry up to --ovsdb-max-backoff",
+	}
+	var flagOvsdbMaxBackoff = cli.StringFlag{
+		Name:  "ovsdb-max-backoff",
+		Value: "5s",
+		Usage: "longest wait between retries",
+	}
+	var flagOvsdbTimeout = cli.StringFlag{
+		Name:  "ovsdb-timeout",
+		Value: "10s",
+		Usage: "how long an OVSDB transaction may take before it fails",
+	}
+	var flagOvsdbBatchWindow = cli.StringFlag{
+		Name:  "ovsdb-batch-window",
+		Value: "10ms",
+		Usage: "how long port changes wait to share an OVSDB transaction, 0 to give each its own",
+	}
+	var flagMetricsListen = cli.StringFlag{
+		Name:  "metrics-listen",
+		Usage: "address to serve Prometheus metrics on, e.g. :9462",
+	}
+	var flagPprofListen = cli.StringFlag{
+		Name:  "pprof-listen",
+		Usage: "address to serve pprof profiles and runtime stats on, e.g. 127.0.0.1:6060, off when empty",
+	}
+	var flagDocker = cli.StringFlag{
+		Name:   "docker",
+		Value:  "unix:///var/run/docker.sock",
+		Usage:  "docker daemon API, unix:///path or tcp://host:port",
+		EnvVar: "OVS_PLUGIN_DOCKER",
+	}
+	var flagDockerCert = cli.StringFlag{
+		Name:  "docker-cert",
+		Usage: "PEM client certificate for a tcp docker endpoint with TLS",
+	}
+	var flagDockerKey = cli.StringFlag{
+		Name:  "docker-key",
+		Usage: "PEM private key of the docker client certificate",
+	}
+	var flagDockerCA = cli.StringFlag{
+		Name:  "docker-ca",
+		Usage: "PEM CA certificate the docker daemon certificate must chain to, the system roots if empty",
+	}
+	var flagDockerAPIVersion = cli.StringFlag{
+		Name:  "docker-api-version",
+		Value: "1.24",
+		Usage: "docker API version to speak, 1.24 is docker 1.12",
+	}
+	var flagPortPrefix = cli.StringFlag{
+		Name:  "port-prefix",
+		Value: "ovs-veth0-",
+		Usage: "name prefix of the OVS ports and veths of endpoints, at most 10 characters",
+	}
+	var flagBridgePrefix = cli.StringFlag{
+		Name:  "bridge-prefix",
+		Value: "ovsbr-",
+		Usage: "name prefix of the bridges of networks without a network name, at most 10 characters",
+	}
+	var flagContainerIfPrefix = cli.StringFlag{
+		Name:  "container-if-prefix",
+		Value: "eth",
+		Usage: "name prefix of the interfaces in containers, at most 12 characters",
+	}
+	var flagDefaultMTU = cli.IntFlag{
+		Name:  "default-mtu",
+		Value: 1500,
+		Usage: "MTU of networks that don't set linker.net.ovs.bridge.mtu",
+	}
+	var flagDefaultMode = cli.StringFlag{
+		Name:  "default-mode",
+		Value: "nat",
+		Usage: "mode of networks that don't set linker.net.ovs.bridge.mode, nat or flat",
+	}
+	var flagGatewayScript = cli.StringFlag{
+		Name:  "gateway-script",
+		Value: "/usr/sbin/ovsopt.sh",
+		Usage: "script the gateway service runs to set up sgw and pgw networks",
+	}
+	var flagGatewayService = cli.StringFlag{
+		Name:  "gateway-service",
+		Value: "/etc/systemd/system/linkerGateway.service",
+		Usage: "systemd unit file of the gateway service",
+	}
+	var flagScope = cli.StringFlag{
+		Name:  "scope",
+		Value: "local",
+		Usage: "scope of the driver's networks, local or global for networks docker creates on every swarm node running a task on them",
+	}
+	var flagClusterStore = cli.StringFlag{
+		Name:  "cluster-store",
+		Usage: "etcd://<host>:<port> or consul://<host>:<port> store global networks are defined in, docker's own store when empty",
+	}
+	var flagClusterStoreCert = cli.StringFlag{
+		Name:  "cluster-store-cert",
+		Usage: "client certificate to present to the cluster store, over TLS",
+	}
+	var flagClusterStoreKey = cli.StringFlag{
+		Name:  "cluster-store-key",
+		Usage: "private key of --cluster-store-cert",
+	}
+	var flagClusterStoreCA = cli.StringFlag{
+		Name:  "cluster-store-ca",
+		Usage: "CA certificate to trust the cluster store with, over TLS",
+	}
+	var flagClusterStoreAuth = cli.StringFlag{
+		Name:  "cluster-store-auth",
+		Usage: "<user>:<password> of etcd or ACL token of consul to authenticate to the cluster store with",
+	}
+	var flagAdvertiseAddress = cli.StringFlag{
+		Name:  "advertise-address",
+		Usage: "address of this host the other hosts tunnel overlay networks to, published with its endpoints in the cluster store",
+	}
+	var flagGossipListen = cli.StringFlag{
+		Name:  "gossip-listen",
+		Usage: "[<ip>]:<port> to gossip the endpoints of overlay networks with other hosts on, e.g. :7946, no gossip when empty",
+	}
+	var flagGossipJoin = cli.StringSliceFlag{
+		Name:  "gossip-join",
+		Value: &cli.StringSlice{},
+		Usage: "<host>[:<port>] of a host already gossiping, repeated for more",
+	}
+	var flagGossipKey = cli.StringFlag{
+		Name:  "gossip-key",
+		Usage: "base64 AES key of 16, 24 or 32 bytes gossip is encrypted with, the same on every host, required with --gossip-listen",
+	}
+	var flagOvsdbHost = cli.StringFlag{
+		Name:  "ovsdb-host",
+		Usage: "host of an ovsdb-server listening on tcp, instead of --ovsdb",
+	}
+	var flagOvsdbPort = cli.IntFlag{
+		Name:  "ovsdb-port",
+		Value: 6640,
+		Usage: "port of the ovsdb-server at --ovsdb-host",
+	}
+	var flagOvsdbJitter = cli.Float64Flag{
+		Name:  "ovsdb-jitter",
+		Usage: "randomize each wait by up to this fraction of it, e.g. 0.2",
 	}
 	app := cli.NewApp()
 	app.Name = "don"
 	app.Usage = "Docker Open vSwitch Networking"
 	app.Version = version
 	app.Flags = []cli.Flag{
+		flagConfig,
 		flagDebug,
+		flagLogLevel,
+		flagLogFormat,
+		flagLogFile,
+		flagLogMaxSize,
+		flagLogMaxFiles,
+		flagLogJournald,
+		flagLogSyslog,
+		flagLogOvsdbUpdates,
+		flagAuditLog,
+		flagOTLPEndpoint,
+		flagFirewall,
+		flagAdminSocket,
+		flagName,
+		flagListen,
+		flagManaged,
+		flagHostRoot,
+		flagSkipModprobe,
+		flagActivate,
+		flagProfile,
+		flagForceOwnership,
+		flagStandbyPeer,
+		flagReplicationListen,
+		flagReplicationSecret,
+		flagReplicationCert,
+		flagReplicationKey,
+		flagReplicationCA,
+		flagControllerURL,
+		flagControllerToken,
+		flagControllerInterval,
+		flagControllerListen,
+		flagControllerCert,
+		flagControllerKey,
+		flagControllerCA,
+		flagVisibilityObject,
+		flagAuditInterval,
+		flagAuditEndpoint,
+		flagAuditKey,
+		flagHWOffload,
+		flagMetadataGCInterval,
+		flagOvsdb,
+		flagOvsdbHost,
+		flagOvsdbPort,
+		flagOvsdbNode,
+		flagOvsdbMonitorAll,
+		flagOvsdbCert,
+		flagOvsdbKey,
+		flagOvsdbCA,
+		flagOvsdbRetries,
+		flagOvsdbWait,
+		flagOvsdbBackoff,
+		flagOvsdbMaxBackoff,
+		flagOvsdbJitter,
+		flagOvsdbTimeout,
+		flagOvsdbBatchWindow,
+		flagMetricsListen,
+		flagPprofListen,
+		flagDocker,
+		flagDockerCert,
+		flagDockerKey,
+		flagDockerCA,
+		flagDockerAPIVersion,
+		flagPortPrefix,
+		flagBridgePrefix,
+		flagContainerIfPrefix,
+		flagDefaultMTU,
+		flagDefaultMode,
+		flagGatewayScript,
+		flagGatewayService,
+		flagScope,
+		flagClusterStore,
+		flagClusterStoreCert,
+		flagClusterStoreKey,
+		flagClusterStoreCA,
+		flagClusterStoreAuth,
+		flagAdvertiseAddress,
+		flagGossipListen,
+		flagGossipJoin,
+		flagGossipKey,
+	}
+	app.Flags = withEnvVars(app.Flags)
+	// the config file sets the defaults of the flags, the environment and
+	// the command line override it
+	if path, asked := configPath(os.Args[1:]); asked || fileExists(path) {
+		flags, err := loadConfig(path, app.Flags)
+		if err != nil {
+			log.Fatalf("failed to load config: %v", err)
+		}
+		app.Flags = flags
 	}
 	app.Action = Run
+	app.Commands = []cli.Command{
+		{
+			Name:   "evacuate",
+			Usage:  "stop gateway services, tear down tunnels and mark the host in maintenance",
+			Action: Evacuate,
+		},
+		{
+			Name:   "resume",
+			Usage:  "take the host out of maintenance, the plugin then brings its gateway services and tunnels back",
+			Action: Resume,
+		},
+		{
+			Name:   "check",
+			Usage:  "check the host for what the plugin needs and print a JSON report, exits 1 if a check fails",
+			Action: Check,
+		},
+	}
 	app.Run(os.Args)
 }
 
 // Run initializes the driver
 func Run(ctx *cli.Context) {
+	logging := logConfig{
+		Level:    ctx.String("log-level"),
+		Format:   ctx.String("log-format"),
+		File:     ctx.String("log-file"),
+		MaxSize:  int64(ctx.Int("log-max-size")) << 20,
+		MaxFiles: ctx.Int("log-max-files"),
+		Journald: ctx.Bool("log-journald"),
+		Syslog:   ctx.String("log-syslog"),
+	}
 	if ctx.Bool("debug") {
-		log.SetLevel(log.DebugLevel)
+		logging.Level = "debug"
+	}
+	if err := setupLogging(logging); err != nil {
+		log.Fatal(err)
 	}
 
-	d, err := ovs.NewDriver()
+	if ctx.String("replication-listen") != "" && ctx.String("replication-secret") == "" {
+		log.Fatal("--replication-listen requires --replication-secret")
+	}
+	if (ctx.String("replication-listen") != "" || ctx.String("standby-peer") != "") &&
+		(ctx.String("replication-cert") == "" || ctx.String("replication-key") == "" || ctx.String("replication-ca") == "") {
+		log.Fatal("replication requires --replication-cert, --replication-key and --replication-ca")
+	}
+	if ctx.String("controller-listen") != "" && ctx.String("controller-token") == "" {
+		log.Fatal("--controller-listen requires --controller-token")
+	}
+	if ctx.String("controller-listen") != "" && (ctx.String("controller-cert") == "" || ctx.String("controller-key") == "") {
+		log.Fatal("--controller-listen requires --controller-cert and --controller-key")
+	}
+	controllerInterval, err := time.ParseDuration(ctx.String("controller-interval"))
+	if err != nil {
+		log.Fatalf("invalid --controller-interval: %v", err)
+	}
+
+	var auditInterval time.Duration
+	if value := ctx.String("audit-interval"); value != "" {
+		var err error
+		if auditInterval, err = time.ParseDuration(value); err != nil {
+			log.Fatalf("invalid --audit-interval: %v", err)
+		}
+		if ctx.String("audit-endpoint") == "" || ctx.String("audit-key") == "" {
+			log.Fatal("--audit-interval requires --audit-endpoint and --audit-key")
+		}
+	}
+
+	metadataGCInterval, err := time.ParseDuration(ctx.String("metadata-gc-interval"))
+	if err != nil {
+		log.Fatalf("invalid --metadata-gc-interval: %v", err)
+	}
+
+	ovsdbBackoff, err := time.ParseDuration(ctx.String("ovsdb-backoff"))
+	if err != nil {
+		log.Fatalf("invalid --ovsdb-backoff: %v", err)
+	}
+	ovsdbMaxBackoff, err := time.ParseDuration(ctx.String("ovsdb-max-backoff"))
+	if err != nil {
+		log.Fatalf("invalid --ovsdb-max-backoff: %v", err)
+	}
+	if jitter := ctx.Float64("ovsdb-jitter"); jitter < 0 || jitter > 1 {
+		log.Fatal("--ovsdb-jitter must be between 0 and 1")
+	}
+	ovsdbTimeout, err := time.ParseDuration(ctx.String("ovsdb-timeout"))
+	if err != nil {
+		log.Fatalf("invalid --ovsdb-timeout: %v", err)
+	}
+	batchWindow, err := time.ParseDuration(ctx.String("ovsdb-batch-window"))
+	if err != nil {
+		log.Fatalf("invalid --ovsdb-batch-window: %v", err)
+	}
+	if batchWindow == 0 {
+		batchWindow = -1
+	}
+
+	nodes := make(map[string]string)
+	for _, node := range ctx.StringSlice("ovsdb-node") {
+		parts := strings.SplitN(node, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			log.Fatalf("invalid --ovsdb-node %s, want name=<ovsdb endpoint>", node)
+		}
+		nodes[parts[0]] = parts[1]
+	}
+
+	d, err := ovs.NewDriver(ovs.Config{
+		FirewallBackend:   ctx.String("firewall"),
+		DriverName:        ctx.String("name"),
+		ForceOwnership:    ctx.Bool("force-ownership"),
+		StandbyPeer:       ctx.String("standby-peer"),
+		ReplicationSecret: ctx.String("replication-secret"),
+		ReplicationCert:   ctx.String("replication-cert"),
+		ReplicationKey:    ctx.String("replication-key"),
+		ReplicationCA:     ctx.String("replication-ca"),
+		VisibilityObject:  ctx.String("visibility-bpf"),
+		AuditInterval:     auditInterval,
+		AuditEndpoint:     ctx.String("audit-endpoint"),
+		AuditKey:          ctx.String("audit-key"),
+		HWOffload:         ctx.Bool("hw-offload"),
+		GCInterval:        metadataGCInterval,
+		OvsdbEndpoint:     ovsdbEndpoint(ctx.String("ovsdb"), ctx.String("ovsdb-host"), ctx.Int("ovsdb-port")),
+		OvsdbCert:         ctx.String("ovsdb-cert"),
+		OvsdbKey:          ctx.String("ovsdb-key"),
+		OvsdbCA:           ctx.String("ovsdb-ca"),
+		Nodes:             nodes,
+		MonitorAll:        ctx.Bool("ovsdb-monitor-all"),
+		OvsdbTimeout:      ovsdbTimeout,
+		BatchWindow:       batchWindow,
+		LogOvsdbUpdates:   ctx.Bool("log-ovsdb-updates"),
+		AuditLog:          ctx.String("audit-log"),
+		OTLPEndpoint:      ctx.String("otlp-endpoint"),
+		DockerEndpoint:    ctx.String("docker"),
+		DockerCert:        ctx.String("docker-cert"),
+		DockerKey:         ctx.String("docker-key"),
+		DockerCA:          ctx.String("docker-ca"),
+		DockerAPIVersion:  ctx.String("docker-api-version"),
+		PortPrefix:        ctx.String("port-prefix"),
+		BridgePrefix:      ctx.String("bridge-prefix"),
+		ContainerIfPrefix: ctx.String("container-if-prefix"),
+		DefaultMTU:        ctx.Int("default-mtu"),
+		DefaultMode:       ctx.String("default-mode"),
+		GatewayScript:     ctx.String("gateway-script"),
+		GatewayService:    ctx.String("gateway-service"),
+		Scope:             ctx.String("scope"),
+		ClusterStore:      ctx.String("cluster-store"),
+		ClusterStoreCert:  ctx.String("cluster-store-cert"),
+		ClusterStoreKey:   ctx.String("cluster-store-key"),
+		ClusterStoreCA:    ctx.String("cluster-store-ca"),
+		ClusterStoreAuth:  ctx.String("cluster-store-auth"),
+		AdvertiseAddress:  ctx.String("advertise-address"),
+		GossipListen:      ctx.String("gossip-listen"),
+		GossipJoin:        ctx.StringSlice("gossip-join"),
+		GossipKey:         ctx.String("gossip-key"),
+		OvsdbRetry: ovs.RetryPolicy{
+			Retries:    ctx.Int("ovsdb-retries"),
+			Forever:    ctx.Bool("ovsdb-wait"),
+			Backoff:    ovsdbBackoff,
+			MaxBackoff: ovsdbMaxBackoff,
+			Jitter:     ctx.Float64("ovsdb-jitter"),
+		},
+		ControllerURL:      ctx.String("controller-url"),
+		ControllerToken:    ctx.String("controller-token"),
+		ControllerInterval: controllerInterval,
+		ControllerCert:     ctx.String("controller-cert"),
+		ControllerKey:      ctx.String("controller-key"),
+		ControllerCA:       ctx.String("controller-ca"),
+		Managed:            ctx.Bool("managed"),
+		HostRoot:           ctx.String("host-root"),
+		SkipModprobe:       ctx.Bool("skip-modprobe"),
+	})
 	if err != nil {
 		panic(err)
 	}
-	h := dknet.NewHandler(d)
-	errs:=h.ServeUnix("root", "ovs")
-        log.Debugln(errs)
+	if addr := ctx.String("replication-listen"); addr != "" {
+		go func() {
+			if err := d.ServeReplication(addr); err != nil {
+				log.Errorf("replication API stopped: %v", err)
+			}
+		}()
+	}
+	if addr := ctx.String("controller-listen"); addr != "" {
+		go func() {
+			if err := d.ServeController(addr); err != nil {
+				log.Errorf("controller API stopped: %v", err)
+			}
+		}()
+	}
+	if addr := ctx.String("metrics-listen"); addr != "" {
+		go func() {
+			if err := d.ServeMetrics(addr); err != nil {
+				log.Errorf("metrics listener stopped: %v", err)
+			}
+		}()
+	}
+	if addr := ctx.String("pprof-listen"); addr != "" {
+		go func() {
+			if err := d.ServeDebug(addr); err != nil {
+				log.Errorf("pprof listener stopped: %v", err)
+			}
+		}()
+	}
+	if socket := ctx.String("admin-socket"); socket != "" {
+		go func() {
+			if err := d.ServeAdmin(socket); err != nil {
+				log.Errorf("admin API stopped: %v", err)
+			}
+		}()
+	}
+	var profiles []ovs.Profile
+	for _, value := range ctx.StringSlice("profile") {
+		p, err := ovs.ParseProfile(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+		profiles = append(profiles, p)
+	}
+	if err := d.Serve(ctx.String("listen"), ctx.Bool("activate"), profiles); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Evacuate drains the host before maintenance
+func Evacuate(ctx *cli.Context) {
+	if err := ovs.Evacuate(maintenanceConfig(ctx)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Resume takes the host out of maintenance
+func Resume(ctx *cli.Context) {
+	if err := ovs.Resume(maintenanceConfig(ctx)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// maintenanceConfig sets up logging for evacuate and resume and returns
+// the part of the plugin's configuration they need to find its bridges,
+// ports and firewall rules
+func maintenanceConfig(ctx *cli.Context) ovs.Config {
+	logging := logConfig{
+		Level:    ctx.GlobalString("log-level"),
+		Format:   ctx.GlobalString("log-format"),
+		File:     ctx.GlobalString("log-file"),
+		MaxSize:  int64(ctx.GlobalInt("log-max-size")) << 20,
+		MaxFiles: ctx.GlobalInt("log-max-files"),
+		Journald: ctx.GlobalBool("log-journald"),
+		Syslog:   ctx.GlobalString("log-syslog"),
+	}
+	if ctx.GlobalBool("debug") {
+		logging.Level = "debug"
+	}
+	if err := setupLogging(logging); err != nil {
+		log.Fatal(err)
+	}
+
+	ovsdbTimeout, err := time.ParseDuration(ctx.GlobalString("ovsdb-timeout"))
+	if err != nil {
+		log.Fatalf("invalid --ovsdb-timeout: %v", err)
+	}
+	return ovs.Config{
+		FirewallBackend:   ctx.GlobalString("firewall"),
+		DriverName:        ctx.GlobalString("name"),
+		ForceOwnership:    ctx.GlobalBool("force-ownership"),
+		OvsdbEndpoint:     ovsdbEndpoint(ctx.GlobalString("ovsdb"), ctx.GlobalString("ovsdb-host"), ctx.GlobalInt("ovsdb-port")),
+		OvsdbCert:         ctx.GlobalString("ovsdb-cert"),
+		OvsdbKey:          ctx.GlobalString("ovsdb-key"),
+		OvsdbCA:           ctx.GlobalString("ovsdb-ca"),
+		OvsdbTimeout:      ovsdbTimeout,
+		PortPrefix:        ctx.GlobalString("port-prefix"),
+		BridgePrefix:      ctx.GlobalString("bridge-prefix"),
+		ContainerIfPrefix: ctx.GlobalString("container-if-prefix"),
+		GatewayService:    ctx.GlobalString("gateway-service"),
+		Managed:           ctx.GlobalBool("managed"),
+		HostRoot:          ctx.GlobalString("host-root"),
+	}
+}
+
+// Check runs the preflight checks of the host with the configuration the
+// plugin would start with, before it registers with docker
+func Check(ctx *cli.Context) {
+	ovsdbTimeout, err := time.ParseDuration(ctx.GlobalString("ovsdb-timeout"))
+	if err != nil {
+		log.Fatalf("invalid --ovsdb-timeout: %v", err)
+	}
+	// the report tells what startup would log
+	log.SetLevel(log.WarnLevel)
+	report := ovs.Preflight(ovs.Config{
+		FirewallBackend:   ctx.GlobalString("firewall"),
+		OvsdbEndpoint:     ovsdbEndpoint(ctx.GlobalString("ovsdb"), ctx.GlobalString("ovsdb-host"), ctx.GlobalInt("ovsdb-port")),
+		OvsdbCert:         ctx.GlobalString("ovsdb-cert"),
+		OvsdbKey:          ctx.GlobalString("ovsdb-key"),
+		OvsdbCA:           ctx.GlobalString("ovsdb-ca"),
+		OvsdbTimeout:      ovsdbTimeout,
+		PortPrefix:        ctx.GlobalString("port-prefix"),
+		BridgePrefix:      ctx.GlobalString("bridge-prefix"),
+		ContainerIfPrefix: ctx.GlobalString("container-if-prefix"),
+		DefaultMTU:        ctx.GlobalInt("default-mtu"),
+		DefaultMode:       ctx.GlobalString("default-mode"),
+		GatewayScript:     ctx.GlobalString("gateway-script"),
+		GatewayService:    ctx.GlobalString("gateway-service"),
+		Managed:           ctx.GlobalBool("managed"),
+		HostRoot:          ctx.GlobalString("host-root"),
+		SkipModprobe:      ctx.GlobalBool("skip-modprobe"),
+	})
+	out, _ := json.MarshalIndent(report, "", "  ")
+	os.Stdout.Write(append(out, '\n'))
+	if !report.OK {
+		os.Exit(1)
+	}
+}
+
+// ovsdbEndpoint returns the ovsdb-server endpoint, given whole with
+// --ovsdb or as --ovsdb-host and --ovsdb-port.
+func ovsdbEndpoint(endpoint, host string, port int) string {
+	if host == "" {
+		return endpoint
+	}
+	if endpoint != "" {
+		log.Fatal("--ovsdb and --ovsdb-host can't both be set")
+	}
+	return "tcp:" + net.JoinHostPort(host, strconv.Itoa(port))
 }
@@ -0,0 +1,76 @@
+package ovs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// ServeAdmin serves the admin API of the driver as JSON over HTTP on a
+// unix socket. It is meant for operators and tooling, not for docker.
+func (d *Driver) ServeAdmin(socketPath string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/trace", d.handleTrace)
+	mux.HandleFunc("/neighbors", d.handleNeighbors)
+	mux.HandleFunc("/apply", d.handleApply)
+	mux.HandleFunc("/pools", d.handlePools)
+	mux.HandleFunc("/topology", d.handleTopology)
+	mux.HandleFunc("/mirrors", d.handleMirrors)
+	mux.HandleFunc("/replica", d.handleReplica)
+	mux.HandleFunc("/replica/activate", d.handleActivate)
+	mux.HandleFunc("/metrics", d.handleMetrics)
+	mux.HandleFunc("/capabilities", d.handleCapabilities)
+	mux.HandleFunc("/audit", d.handleAudit)
+	mux.HandleFunc("/offload", d.handleOffload)
+	mux.HandleFunc("/arp-responder", d.handleARPResponder)
+	mux.HandleFunc("/nodes", d.handleNodes)
+	mux.HandleFunc("/tunnels", d.handleTunnels)
+	mux.HandleFunc("/update-queue", d.handleUpdateQueue)
+	mux.HandleFunc("/healthz", d.handleHealth)
+	mux.HandleFunc("/controller", d.handleController)
+	mux.HandleFunc("/cni", d.handleCNI)
+	mux.HandleFunc("/state", d.handleState)
+	mux.HandleFunc("/ovsdb", d.handleOVSDB)
+	mux.HandleFunc("/reconcile", d.handleReconcile)
+	mux.HandleFunc("/endpoints/clean", d.handleCleanEndpoint)
+	mux.HandleFunc("/networks", d.handleNetworks)
+	mux.HandleFunc("/ports", d.handlePorts)
+	mux.HandleFunc("/gateway", d.handleGateway)
+	mux.HandleFunc("/gc", d.handleGC)
+	mux.HandleFunc("/flows", d.handleFlows)
+	mux.HandleFunc("/captures", d.handleCaptures)
+	mux.HandleFunc("/captures/file", d.handleCaptureFile)
+	mux.HandleFunc("/fdb", d.handleFDB)
+
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return err
+	}
+	os.Remove(socketPath)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	log.Infof("Serving admin API on [ %s ]", socketPath)
+	return http.Serve(listener, mux)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warnf("failed to write admin response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"Err": err.Error()})
+}
+
+func errMethodNotAllowed(method string) error {
+	return fmt.Errorf("method %s not allowed", method)
+}
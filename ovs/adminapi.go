@@ -0,0 +1,206 @@
+package ovs
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// AdminNetwork is a network as GET /networks lists it.
+type AdminNetwork struct {
+	NetworkID   string
+	NetworkName string `json:",omitempty"`
+	NetworkType string `json:",omitempty"`
+	Mode        string
+	BridgeName  string
+	MTU         int
+	Gateway     string `json:",omitempty"`
+	Node        string `json:",omitempty"`
+	Runtime     string `json:",omitempty"`
+	Endpoints   int
+}
+
+// AdminPort is a port of a bridge of the plugin with the counters of its
+// interface, as GET /ports lists it. The endpoint and container are empty
+// for uplinks, patch and tunnel ports.
+type AdminPort struct {
+	Name        string
+	BridgeName  string
+	NetworkID   string
+	EndpointID  string `json:",omitempty"`
+	ContainerID string `json:",omitempty"`
+	OFPort      int    `json:",omitempty"`
+	Statistics  map[string]int64
+}
+
+// GatewayStatus is the state of the gateway service of sgw and pgw
+// networks, what GET /gateway returns. Runner is "systemd" when it runs as
+// Unit, or "process" when it runs as a child process of the plugin.
+type GatewayStatus struct {
+	Runner      string
+	Unit        string
+	Networks    []string
+	Hosted      bool
+	GatewayHost string `json:",omitempty"`
+	Installed   bool
+	Active      bool
+}
+
+// GCResult is what POST /gc returns.
+type GCResult struct {
+	Time time.Time
+}
+
+// handleNetworks serves /networks: GET lists the networks of the driver.
+func (d *Driver) handleNetworks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	reconcileMu.Lock()
+	counts := make(map[string]int)
+	for _, es := range d.endpointStates() {
+		counts[es.NetworkID]++
+	}
+	networks := []AdminNetwork{}
+	for id, ns := range d.networkStates() {
+		networks = append(networks, AdminNetwork{
+			NetworkID:   id,
+			NetworkName: ns.NetworkName,
+			NetworkType: ns.NetworkType,
+			Mode:        ns.Mode,
+			BridgeName:  ns.BridgeName,
+			MTU:         ns.MTU,
+			Gateway:     ns.Gateway,
+			Node:        ns.Node,
+			Runtime:     ns.Runtime,
+			Endpoints:   counts[id],
+		})
+	}
+	reconcileMu.Unlock()
+	sort.Sort(adminNetworksByID(networks))
+	writeJSON(w, http.StatusOK, networks)
+}
+
+type adminNetworksByID []AdminNetwork
+
+func (s adminNetworksByID) Len() int           { return len(s) }
+func (s adminNetworksByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s adminNetworksByID) Less(i, j int) bool { return s[i].NetworkID < s[j].NetworkID }
+
+// handlePorts serves /ports: GET lists the ports of the plugin's bridges,
+// those of one network with ?network=<id>.
+func (d *Driver) handlePorts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	ports, err := d.ovsdber.listPorts(r.URL.Query().Get("network"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ports)
+}
+
+// listPorts returns the ports of the plugin's bridges, or of the bridge of
+// one network. Statistics aren't monitored, so the interfaces are read
+// from ovsdb-server in one select rather than from the cache.
+func (ovsdber *ovsdber) listPorts(networkID string) ([]AdminPort, error) {
+	selectOp := libovsdb.Operation{
+		Op:      "select",
+		Table:   "Interface",
+		Columns: []string{"name", "statistics", "ofport", "external_ids"},
+		Where:   []interface{}{libovsdb.NewCondition("name", "!=", "")},
+	}
+	reply, err := ovsdber.transactReply(selectOp)
+	if err != nil {
+		return nil, err
+	}
+	interfaces := make(map[string]libovsdb.Row)
+	for _, raw := range reply[0].Rows {
+		row, err := decodeRow(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Interface row: %v", err)
+		}
+		if name, ok := row.Fields["name"].(string); ok {
+			interfaces[name] = row
+		}
+	}
+	ports := []AdminPort{}
+	for bridgeName, id := range pluginBridges() {
+		if networkID != "" && id != networkID {
+			continue
+		}
+		names := bridgePortNames(bridgeName)
+		sort.Strings(names)
+		for _, name := range names {
+			if name == bridgeName {
+				// the bridge's own internal port
+				continue
+			}
+			port := AdminPort{Name: name, BridgeName: bridgeName, NetworkID: id, Statistics: make(map[string]int64)}
+			if row, ok := interfaces[name]; ok {
+				port.EndpointID = ovsMapValue(row.Fields["external_ids"], endpointIDKey)
+				port.ContainerID = ovsMapValue(row.Fields["external_ids"], containerIDKey)
+				if ofport, ok := row.Fields["ofport"].(float64); ok && ofport > 0 {
+					port.OFPort = int(ofport)
+				}
+				if counters, ok := row.Fields["statistics"].(libovsdb.OvsMap); ok {
+					for key, value := range counters.GoMap {
+						if name, ok := key.(string); ok {
+							if n, ok := value.(float64); ok {
+								port.Statistics[name] = int64(n)
+							}
+						}
+					}
+				}
+			}
+			ports = append(ports, port)
+		}
+	}
+	return ports, nil
+}
+
+// handleGateway serves /gateway: GET returns the state of the gateway
+// service of sgw and pgw networks.
+func (d *Driver) handleGateway(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	status := GatewayStatus{Runner: "systemd", Unit: serviceName, Networks: []string{}}
+	if !gatewayUnits {
+		status.Runner = "process"
+	}
+	reconcileMu.Lock()
+	for id, ns := range d.networkStates() {
+		if ns.Node == "" && gatewayNetwork(ns) {
+			status.Networks = append(status.Networks, id)
+		}
+	}
+	status.Hosted = len(status.Networks) > 0 && d.hostsGateway()
+	status.GatewayHost = d.controllerConfig.GatewayHost
+	reconcileMu.Unlock()
+	sort.Strings(status.Networks)
+	status.Installed = gatewayServiceInstalled()
+	status.Active = gatewayServiceActive()
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleGC serves /gc: POST removes orphaned ports and veths and stale
+// network records right away rather than at the next interval.
+func (d *Driver) handleGC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	log.Infof("Collecting garbage on request of the admin API")
+	d.collectOrphans()
+	d.collectStaleMetadata()
+	writeJSON(w, http.StatusOK, GCResult{Time: time.Now()})
+}
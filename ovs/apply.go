@@ -0,0 +1,309 @@
+package ovs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	dockertypes "github.com/docker/docker/api/types"
+	dockernetwork "github.com/docker/docker/api/types/network"
+)
+
+const (
+	tenantOption     = "linker.net.ovs.tenant"
+	tenantSpecOption = "linker.net.ovs.tenant.spec"
+)
+
+// applyMu serializes apply calls, a plan is only valid until the next one runs
+var applyMu sync.Mutex
+
+// NetworkSpec declares one network of a tenant. Options are handed to the
+// driver like `docker network create -o`, e.g. the mode, VLAN or QoS settings.
+type NetworkSpec struct {
+	Name    string
+	Subnet  string            `json:",omitempty"`
+	Gateway string            `json:",omitempty"`
+	IPRange string            `json:",omitempty"`
+	Options map[string]string `json:",omitempty"`
+}
+
+// TenantSpec is the desired set of networks of a tenant on this host.
+// Networks of the tenant missing from the spec are removed.
+type TenantSpec struct {
+	Tenant   string
+	Networks []NetworkSpec
+}
+
+// ApplyResult lists what an apply call did, by network name.
+type ApplyResult struct {
+	Created   []string `json:",omitempty"`
+	Replaced  []string `json:",omitempty"`
+	Removed   []string `json:",omitempty"`
+	Unchanged []string `json:",omitempty"`
+}
+
+// tenantNetwork is a network previously created by an apply call
+type tenantNetwork struct {
+	ID   string
+	Spec NetworkSpec
+}
+
+// Apply brings the networks of a tenant in line with the spec. The networks
+// are created through docker so that docker knows about them. Networks whose
+// spec changed are replaced since docker can't update them in place. A
+// replacement is created next to the old network before that is removed,
+// unless both need the same subnet, bridge or uplink. All steps are undone
+// if one of them fails, and nothing is changed when a network to replace or
+// remove still has containers attached.
+func (d *Driver) Apply(spec TenantSpec) (*ApplyResult, error) {
+	applyMu.Lock()
+	defer applyMu.Unlock()
+
+	if err := validateTenantSpec(spec); err != nil {
+		return nil, err
+	}
+
+	current := d.tenantNetworks(spec.Tenant)
+	result := &ApplyResult{}
+	var create, replace []NetworkSpec
+	previous := make(map[string]tenantNetwork)
+	for _, ns := range spec.Networks {
+		old, ok := current[ns.Name]
+		delete(current, ns.Name)
+		switch {
+		case !ok:
+			create = append(create, ns)
+		case specEqual(old.Spec, ns):
+			result.Unchanged = append(result.Unchanged, ns.Name)
+		default:
+			replace = append(replace, ns)
+			previous[ns.Name] = old
+		}
+	}
+	// whatever is left over is no longer wanted
+	remove := current
+
+	for _, ns := range replace {
+		if err := d.checkUnused(ns.Name); err != nil {
+			return nil, err
+		}
+	}
+	for name := range remove {
+		if err := d.checkUnused(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var undo []func() error
+	rollback := func(cause error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if err := undo[i](); err != nil {
+				log.Errorf("failed to roll back tenant %s: %v", spec.Tenant, err)
+			}
+		}
+		return cause
+	}
+
+	for _, ns := range create {
+		id, err := d.createTenantNetwork(spec.Tenant, ns, true)
+		if err != nil {
+			return nil, rollback(err)
+		}
+		undo = append(undo, func() error { return d.removeNetwork(id) })
+		result.Created = append(result.Created, ns.Name)
+	}
+
+	for _, ns := range replace {
+		old := previous[ns.Name]
+		id, err := d.createTenantNetwork(spec.Tenant, ns, false)
+		if err == nil {
+			if err := d.removeNetwork(old.ID); err != nil {
+				if err := d.removeNetwork(id); err != nil {
+					log.Errorf("failed to remove replacement of network %s: %v", ns.Name, err)
+				}
+				return nil, rollback(err)
+			}
+			// the replacement goes before the old network comes back
+			undo = append(undo, func() error {
+				if err := d.removeNetwork(id); err != nil {
+					return err
+				}
+				return d.recreateTenantNetwork(spec.Tenant, old.Spec)()
+			})
+			result.Replaced = append(result.Replaced, ns.Name)
+			continue
+		}
+		log.Infof("network %s can't be created next to the one it replaces, removing that first: %v", ns.Name, err)
+		if err := d.removeNetwork(old.ID); err != nil {
+			return nil, rollback(err)
+		}
+		undo = append(undo, d.recreateTenantNetwork(spec.Tenant, old.Spec))
+		id, err = d.createTenantNetwork(spec.Tenant, ns, true)
+		if err != nil {
+			return nil, rollback(err)
+		}
+		undo = append(undo, func() error { return d.removeNetwork(id) })
+		result.Replaced = append(result.Replaced, ns.Name)
+	}
+
+	for name, old := range remove {
+		if err := d.removeNetwork(old.ID); err != nil {
+			return nil, rollback(err)
+		}
+		undo = append(undo, d.recreateTenantNetwork(spec.Tenant, old.Spec))
+		result.Removed = append(result.Removed, name)
+	}
+
+	sort.Strings(result.Removed)
+	log.Infof("Applied tenant [ %s ]: %d created, %d replaced, %d removed", spec.Tenant,
+		len(result.Created), len(result.Replaced), len(result.Removed))
+	return result, nil
+}
+
+// TenantSpec returns the networks currently applied for a tenant.
+func (d *Driver) TenantSpec(tenant string) TenantSpec {
+	spec := TenantSpec{Tenant: tenant, Networks: []NetworkSpec{}}
+	for _, tn := range d.tenantNetworks(tenant) {
+		spec.Networks = append(spec.Networks, tn.Spec)
+	}
+	sort.Sort(byName(spec.Networks))
+	return spec
+}
+
+type byName []NetworkSpec
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+func validateTenantSpec(spec TenantSpec) error {
+	if spec.Tenant == "" {
+		return fmt.Errorf("tenant is required")
+	}
+	names := make(map[string]bool)
+	for _, ns := range spec.Networks {
+		if ns.Name == "" {
+			return fmt.Errorf("network name is required")
+		}
+		if names[ns.Name] {
+			return fmt.Errorf("network %s is declared twice", ns.Name)
+		}
+		names[ns.Name] = true
+		for key := range ns.Options {
+			if strings.HasPrefix(key, tenantOption) {
+				return fmt.Errorf("option %s is reserved", key)
+			}
+		}
+	}
+	return nil
+}
+
+// tenantNetworks returns the networks of a tenant keyed by name.
+func (d *Driver) tenantNetworks(tenant string) map[string]tenantNetwork {
+	networks := make(map[string]tenantNetwork)
+	for id, ns := range d.networkStates() {
+		if ns.Tenant != tenant || ns.TenantSpec == "" {
+			continue
+		}
+		var spec NetworkSpec
+		if err := json.Unmarshal([]byte(ns.TenantSpec), &spec); err != nil {
+			log.Warnf("ignoring network %s with unreadable tenant spec: %v", truncateID(id), err)
+			continue
+		}
+		networks[spec.Name] = tenantNetwork{ID: id, Spec: spec}
+	}
+	return networks
+}
+
+// checkUnused fails if containers are attached to the named network.
+func (d *Driver) checkUnused(name string) error {
+	network, err := d.inspectNetwork(name)
+	if err != nil {
+		return err
+	}
+	if len(network.Containers) > 0 {
+		return fmt.Errorf("network %s still has %d containers attached", name, len(network.Containers))
+	}
+	return nil
+}
+
+// createTenantNetwork creates the network through docker and records the
+// spec in the network options so a later apply can diff against it. Without
+// checkDuplicate docker lets the network take the name of the one it
+// replaces.
+func (d *Driver) createTenantNetwork(tenant string, ns NetworkSpec, checkDuplicate bool) (string, error) {
+	raw, err := json.Marshal(ns)
+	if err != nil {
+		return "", err
+	}
+	options := map[string]string{
+		tenantOption:     tenant,
+		tenantSpecOption: string(raw),
+	}
+	for key, value := range ns.Options {
+		options[key] = value
+	}
+	config := dockertypes.NetworkCreate{
+		CheckDuplicate: checkDuplicate,
+		Driver:         d.name,
+		Options:        options,
+	}
+	if ns.Subnet != "" {
+		config.IPAM = &dockernetwork.IPAM{Config: []dockernetwork.IPAMConfig{{
+			Subnet:  ns.Subnet,
+			Gateway: ns.Gateway,
+			IPRange: ns.IPRange,
+		}}}
+	}
+	id, err := d.createNetwork(ns.Name, config)
+	if err != nil {
+		return "", fmt.Errorf("failed to create network %s: %v", ns.Name, err)
+	}
+	log.Infof("Created network [ %s ] for tenant [ %s ]", ns.Name, tenant)
+	return id, nil
+}
+
+func (d *Driver) recreateTenantNetwork(tenant string, ns NetworkSpec) func() error {
+	return func() error {
+		_, err := d.createTenantNetwork(tenant, ns, true)
+		return err
+	}
+}
+
+func specEqual(a, b NetworkSpec) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
+
+// handleApply serves GET /apply?tenant=<name> with the applied spec and
+// POST /apply with a TenantSpec body.
+func (d *Driver) handleApply(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		tenant := r.URL.Query().Get("tenant")
+		if tenant == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("tenant is required"))
+			return
+		}
+		writeJSON(w, http.StatusOK, d.TenantSpec(tenant))
+	case "POST":
+		var spec TenantSpec
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		result, err := d.Apply(spec)
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+	}
+}
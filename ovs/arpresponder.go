@@ -0,0 +1,211 @@
+package ovs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// above the ICC flows, so endpoints of isolated networks still resolve
+// the remote endpoints
+const arpResponderPriority = 180
+
+// arpMu serializes changes of the ARP responder entries
+var arpMu sync.Mutex
+
+// ARPEntry is a remote endpoint the bridge answers ARP requests for.
+type ARPEntry struct {
+	IP  string
+	MAC string
+}
+
+// ARPResponder lists the ARP responder entries of a network.
+type ARPResponder struct {
+	NetworkID string
+	Bridge    string `json:",omitempty"`
+	Entries   []ARPEntry
+}
+
+// arpCookie tags the responder flow of an address, so that it can be
+// removed on its own.
+func arpCookie(networkID, ip string) string {
+	h := fnv.New64a()
+	h.Write([]byte("arp" + networkID + ip))
+	return fmt.Sprintf("0x%016x", h.Sum64())
+}
+
+// parseARPEntry checks an entry and returns it in canonical form.
+func parseARPEntry(entry ARPEntry) (ARPEntry, error) {
+	ip := net.ParseIP(entry.IP)
+	if ip == nil || ip.To4() == nil {
+		return entry, fmt.Errorf("%q is not an IPv4 address", entry.IP)
+	}
+	mac, err := net.ParseMAC(entry.MAC)
+	if err != nil || len(mac) != 6 {
+		return entry, fmt.Errorf("%q is not a MAC address", entry.MAC)
+	}
+	return ARPEntry{IP: ip.To4().String(), MAC: mac.String()}, nil
+}
+
+// arpResponderFlow turns an ARP request for the entry's address into the
+// reply and sends it back out of the port it came in on, so the request
+// never floods across tunnels.
+func arpResponderFlow(networkID string, entry ARPEntry) string {
+	mac, _ := net.ParseMAC(entry.MAC)
+	ip := net.ParseIP(entry.IP).To4()
+	actions := []string{
+		"move:NXM_OF_ETH_SRC[]->NXM_OF_ETH_DST[]",
+		"mod_dl_src:" + entry.MAC,
+		"load:0x2->NXM_OF_ARP_OP[]",
+		"move:NXM_NX_ARP_SHA[]->NXM_NX_ARP_THA[]",
+		"move:NXM_OF_ARP_SPA[]->NXM_OF_ARP_TPA[]",
+		fmt.Sprintf("load:0x%x->NXM_NX_ARP_SHA[]", []byte(mac)),
+		fmt.Sprintf("load:0x%x->NXM_OF_ARP_SPA[]", []byte(ip)),
+		"in_port",
+	}
+	return fmt.Sprintf("cookie=%s,priority=%d,arp,arp_op=1,arp_tpa=%s,actions=%s",
+		arpCookie(networkID, entry.IP), arpResponderPriority, entry.IP, strings.Join(actions, ","))
+}
+
+// setupARPResponder installs the responder flows of a network's entries on
+// a new bridge.
+func setupARPResponder(networkID string, ns *NetworkState) error {
+	for _, entry := range ns.ARPEntries {
+		if err := addFlow(ns.BridgeName, policyTable, arpResponderFlow(networkID, entry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateARPEntries adds, or replaces, and removes ARP responder entries of
+// a network along with their flows.
+func (d *Driver) updateARPEntries(networkID string, add, remove []ARPEntry) (*ARPResponder, error) {
+	arpMu.Lock()
+	defer arpMu.Unlock()
+	ns, ok := d.network(networkID)
+	if !ok {
+		return nil, fmt.Errorf("no network with id %s", networkID)
+	}
+	if ns.Node != "" {
+		return nil, fmt.Errorf("network %s is on node %s, its flows are not programmed from here", truncateID(networkID), ns.Node)
+	}
+	current := make(map[string]string)
+	for _, entry := range ns.ARPEntries {
+		current[entry.IP] = entry.MAC
+	}
+	// the entries reflect the flows even if a change fails half way
+	defer d.updateNetwork(ns, func(ns *NetworkState) {
+		ns.ARPEntries = sortedARPEntries(current)
+	})
+	for _, entry := range remove {
+		if _, ok := current[entry.IP]; !ok {
+			continue
+		}
+		if err := delFlows(ns.BridgeName, arpCookie(networkID, entry.IP)); err != nil {
+			return nil, err
+		}
+		delete(current, entry.IP)
+	}
+	for _, entry := range add {
+		if current[entry.IP] == entry.MAC {
+			continue
+		}
+		// add-flow replaces the flow of an address that moved
+		if err := addFlow(ns.BridgeName, policyTable, arpResponderFlow(networkID, entry)); err != nil {
+			return nil, err
+		}
+		current[entry.IP] = entry.MAC
+		log.Debugf("Answering ARP for [ %s ] with [ %s ] on bridge [ %s ]", entry.IP, entry.MAC, ns.BridgeName)
+	}
+	return &ARPResponder{NetworkID: networkID, Bridge: ns.BridgeName, Entries: sortedARPEntries(current)}, nil
+}
+
+func sortedARPEntries(set map[string]string) []ARPEntry {
+	entries := make([]ARPEntry, 0, len(set))
+	for ip, mac := range set {
+		entries = append(entries, ARPEntry{IP: ip, MAC: mac})
+	}
+	sort.Sort(arpEntriesByIP(entries))
+	return entries
+}
+
+type arpEntriesByIP []ARPEntry
+
+func (e arpEntriesByIP) Len() int      { return len(e) }
+func (e arpEntriesByIP) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e arpEntriesByIP) Less(i, j int) bool {
+	return bytes.Compare(net.ParseIP(e[i].IP).To4(), net.ParseIP(e[j].IP).To4()) < 0
+}
+
+// arpResponders returns the entries of all networks that have any, or of
+// the given network.
+func (d *Driver) arpResponders(networkID string) []ARPResponder {
+	arpMu.Lock()
+	defer arpMu.Unlock()
+	var responders []ARPResponder
+	for id, ns := range d.networkStates() {
+		if len(ns.ARPEntries) == 0 || (networkID != "" && id != networkID) {
+			continue
+		}
+		responders = append(responders, ARPResponder{NetworkID: id, Bridge: ns.BridgeName, Entries: ns.ARPEntries})
+	}
+	return responders
+}
+
+// handleARPResponder serves /arp-responder: GET lists the entries,
+// optionally filtered by ?network=, POST adds the entries of an
+// ARPResponder and DELETE removes them by address.
+func (d *Driver) handleARPResponder(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		writeJSON(w, http.StatusOK, d.arpResponders(r.URL.Query().Get("network")))
+		return
+	}
+	if r.Method != "POST" && r.Method != "DELETE" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	var req ARPResponder
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.NetworkID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("NetworkID is required"))
+		return
+	}
+	var res *ARPResponder
+	var err error
+	if r.Method == "POST" {
+		entries := make([]ARPEntry, len(req.Entries))
+		for i, entry := range req.Entries {
+			if entries[i], err = parseARPEntry(entry); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		res, err = d.updateARPEntries(req.NetworkID, entries, nil)
+	} else {
+		for i, entry := range req.Entries {
+			if ip := net.ParseIP(entry.IP); ip != nil && ip.To4() != nil {
+				req.Entries[i].IP = ip.To4().String()
+			}
+		}
+		res, err = d.updateARPEntries(req.NetworkID, nil, req.Entries)
+	}
+	d.replicate()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
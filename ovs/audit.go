@@ -0,0 +1,246 @@
+package ovs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	auditSignatureHeader = "X-Audit-Signature"
+	auditTimeout         = 30 * time.Second
+
+	driftMissingBridge = "missing-bridge"
+	driftOrphanBridge  = "orphan-bridge"
+	driftForeignPort   = "foreign-port"
+	driftMTU           = "mtu-mismatch"
+	driftMissingRules  = "missing-rules"
+	driftStaleUnit     = "stale-unit"
+	driftMissingUnit   = "missing-unit"
+)
+
+// Drift is one difference between the state the driver wants and what is
+// configured on the host.
+type Drift struct {
+	Kind      string
+	NetworkID string `json:",omitempty"`
+	Object    string `json:",omitempty"`
+	Expected  string `json:",omitempty"`
+	Actual    string `json:",omitempty"`
+}
+
+// AuditReport is the drift found on a host at a point in time, an empty
+// Drift list attests that the host matches the desired state.
+type AuditReport struct {
+	Host     string
+	Time     time.Time
+	Networks int
+	Drift    []Drift
+}
+
+var errNoAuditKey = errors.New("no audit key is configured, refusing to sign the report")
+
+// SignedAuditReport carries the HMAC-SHA256 of the JSON encoded report,
+// keyed with the audit key, so the collector can verify where it came from.
+type SignedAuditReport struct {
+	Report    json.RawMessage
+	Signature string
+}
+
+// desiredNetworks returns the networks the host should have: those of the
+// driver, and those recorded on a bridge of the plugin that docker still
+// knows, which a restarted driver has no state of. The bridges map the
+// bridges of the plugin to their network.
+func (d *Driver) desiredNetworks(bridges map[string]string) map[string]*NetworkState {
+	networks := d.networkStates()
+	for name, id := range bridges {
+		if _, ok := networks[id]; ok {
+			continue
+		}
+		if _, err := d.dockerer.inspectNetwork(id); err != nil {
+			continue
+		}
+		networks[id] = &NetworkState{
+			BridgeName:  name,
+			Mode:        bridgeExternalID(name, networkModeKey),
+			NetworkName: bridgeExternalID(name, networkNameKey),
+			NetworkType: bridgeExternalID(name, networkTypeKey),
+		}
+	}
+	return networks
+}
+
+// audit compares the desired networks with the bridges, ports, links,
+// firewall rules and gateway unit on the host. It holds reconcileMu, so it
+// sees no network half created or deleted.
+func (d *Driver) audit() *AuditReport {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	bridges := pluginBridges()
+	networks := d.desiredNetworks(bridges)
+	host, _ := os.Hostname()
+	report := &AuditReport{Host: host, Time: time.Now().UTC(), Networks: len(networks)}
+	bridgeOf := make(map[string]string)
+	for name, id := range bridges {
+		bridgeOf[id] = name
+	}
+
+	gateways := false
+	for id, ns := range networks {
+		if ns.Node != "" {
+			continue
+		}
+		if strings.EqualFold(ns.NetworkType, type_sgw) || strings.EqualFold(ns.NetworkType, type_pgw) {
+			gateways = true
+		}
+		if _, ok := bridgeOf[id]; !ok {
+			report.Drift = append(report.Drift, Drift{Kind: driftMissingBridge, NetworkID: id, Object: ns.BridgeName})
+			continue
+		}
+		if link, err := netlink.LinkByName(ns.BridgeName); err == nil && ns.MTU > 0 && link.Attrs().MTU != ns.MTU {
+			report.Drift = append(report.Drift, Drift{Kind: driftMTU, NetworkID: id, Object: ns.BridgeName,
+				Expected: fmt.Sprint(ns.MTU), Actual: fmt.Sprint(link.Attrs().MTU)})
+		}
+		if ns.Mode == modeNAT {
+			if rules, err := d.firewall.listRules(id); err != nil || len(rules) == 0 {
+				report.Drift = append(report.Drift, Drift{Kind: driftMissingRules, NetworkID: id, Object: ns.BridgeName,
+					Expected: "NAT and filter rules", Actual: "none"})
+			}
+		}
+	}
+
+	for name, id := range bridges {
+		if _, ok := networks[id]; !ok {
+			report.Drift = append(report.Drift, Drift{Kind: driftOrphanBridge, NetworkID: id, Object: name})
+		}
+		for _, port := range bridgePortNames(name) {
+			if port == name {
+				continue
+			}
+			if !d.ovsdber.rowOwned(portRow(port)) {
+				report.Drift = append(report.Drift, Drift{Kind: driftForeignPort, NetworkID: id, Object: port})
+			}
+		}
+	}
+
+	// the controller may have moved the gateway to another host
+	gateways = gateways && d.hostsGateway()
+	switch {
+	case gatewayServiceInstalled() && !gateways:
+		report.Drift = append(report.Drift, Drift{Kind: driftStaleUnit, Object: serviceName})
+	case gateways && !gatewayServiceActive():
+		report.Drift = append(report.Drift, Drift{Kind: driftMissingUnit, Object: serviceName, Expected: "active"})
+	}
+	sort.Sort(driftByKind(report.Drift))
+	return report
+}
+
+type driftByKind []Drift
+
+func (s driftByKind) Len() int      { return len(s) }
+func (s driftByKind) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s driftByKind) Less(i, j int) bool {
+	if s[i].Kind != s[j].Kind {
+		return s[i].Kind < s[j].Kind
+	}
+	return s[i].NetworkID+s[i].Object < s[j].NetworkID+s[j].Object
+}
+
+// signAudit encodes and signs a report, which needs the audit key: an
+// unsigned report attests nothing.
+func (d *Driver) signAudit(report *AuditReport) (*SignedAuditReport, error) {
+	if d.auditKey == "" {
+		return nil, errNoAuditKey
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, []byte(d.auditKey))
+	mac.Write(body)
+	return &SignedAuditReport{Report: body, Signature: hex.EncodeToString(mac.Sum(nil))}, nil
+}
+
+// runAudits audits the host every interval and ships the signed report.
+func (d *Driver) runAudits() {
+	ticker := time.NewTicker(d.auditInterval)
+	for range ticker.C {
+		report := d.audit()
+		if len(report.Drift) > 0 {
+			log.Warnf("audit found %d differences from the desired state", len(report.Drift))
+		}
+		if err := d.shipAudit(report); err != nil {
+			log.Errorf("failed to ship audit report to %s: %v", d.auditEndpoint, err)
+		}
+	}
+}
+
+func (d *Driver) shipAudit(report *AuditReport) error {
+	signed, err := d.signAudit(report)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(signed)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", d.auditEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auditSignatureHeader, "sha256="+signed.Signature)
+	client := &http.Client{Timeout: auditTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+	log.Infof("Shipped audit report with %d differences to [ %s ]", len(report.Drift), d.auditEndpoint)
+	return nil
+}
+
+// handleAudit serves /audit: GET audits the host now and returns the signed
+// report, POST also ships it to the audit endpoint.
+func (d *Driver) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	if d.auditKey == "" {
+		writeError(w, http.StatusBadRequest, errNoAuditKey)
+		return
+	}
+	report := d.audit()
+	if r.Method == "POST" {
+		if d.auditEndpoint == "" {
+			writeError(w, http.StatusBadRequest, errors.New("no audit endpoint is configured"))
+			return
+		}
+		if err := d.shipAudit(report); err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+	}
+	signed, err := d.signAudit(report)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, signed)
+}
@@ -0,0 +1,103 @@
+package ovs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+// AuditRecord is one line of the audit log, a network lifecycle call docker
+// made. Docker doesn't tell plugins which user asked for a network, so the
+// requester is the docker daemon of Host, talking to the driver of the
+// given name, and the user is found in the daemon's own logs at Time.
+type AuditRecord struct {
+	Time       time.Time
+	Host       string
+	Driver     string
+	Operation  string
+	NetworkID  string
+	EndpointID string `json:",omitempty"`
+	// SandboxKey is the network namespace of the container a Join
+	// attaches
+	SandboxKey string                 `json:",omitempty"`
+	Options    map[string]interface{} `json:",omitempty"`
+	// Pools are the IPAM pools of a new network, Address the address of a
+	// new endpoint
+	Pools    []string `json:",omitempty"`
+	Address  string   `json:",omitempty"`
+	Result   string
+	Error    string `json:",omitempty"`
+	Duration string
+}
+
+// auditLogger appends audit records to a file as JSON lines. The file is
+// only ever opened for appending and each record is synced before the call
+// returns to docker.
+type auditLogger struct {
+	mu   sync.Mutex
+	file *os.File
+	host string
+}
+
+// auditLog is the audit log of all drivers, nil when it is off
+var auditLog *auditLogger
+
+func openAuditLog(path string) (*auditLogger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %v", path, err)
+	}
+	host, _ := os.Hostname()
+	return &auditLogger{file: file, host: host}, nil
+}
+
+// record appends a record, filling in the host and the result.
+func (a *auditLogger) record(rec AuditRecord, err error) {
+	rec.Host = a.host
+	rec.Result = "ok"
+	if err != nil {
+		rec.Result = "error"
+		rec.Error = err.Error()
+	}
+	line, jsonErr := json.Marshal(rec)
+	if jsonErr != nil {
+		// options docker sent that don't encode are left out
+		rec.Options = nil
+		line, _ = json.Marshal(rec)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		log.Errorf("failed to write the audit log: %v", err)
+		return
+	}
+	if err := a.file.Sync(); err != nil {
+		log.Errorf("failed to sync the audit log: %v", err)
+	}
+}
+
+// auditCall records a lifecycle call if the audit log is on.
+func auditCall(rec AuditRecord, start time.Time, err error) {
+	if auditLog == nil {
+		return
+	}
+	rec.Time = start.UTC()
+	rec.Duration = time.Since(start).String()
+	auditLog.record(rec, err)
+}
+
+// ipamPools returns the pools of IPAM data.
+func ipamPools(data []*dknet.IPAMData) []string {
+	var pools []string
+	for _, d := range data {
+		if d != nil && d.Pool != "" {
+			pools = append(pools, d.Pool)
+		}
+	}
+	return pools
+}
@@ -0,0 +1,144 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultBatchWindow is how long port changes wait for others to share
+	// their transaction unless Config.BatchWindow says otherwise
+	defaultBatchWindow = 10 * time.Millisecond
+	// maxBatch is the most port changes in one transaction, a full batch
+	// goes out without waiting for the window to end
+	maxBatch = 64
+)
+
+// namedUUIDs numbers the named uuids of inserts, the names must be unique
+// within a transaction and port changes share theirs
+var namedUUIDs uint64
+
+// namedUUID returns a named uuid no other insert uses.
+func namedUUID(prefix string) string {
+	return fmt.Sprintf("%s%d", prefix, atomic.AddUint64(&namedUUIDs, 1))
+}
+
+// txnBatcher merges the port changes made within a short window into one
+// OVSDB transaction, so a service scaling to dozens of containers doesn't
+// wait on a transaction per Join and Leave.
+type txnBatcher struct {
+	db      *ovsdber
+	window  time.Duration
+	mu      sync.Mutex
+	pending *txnBatch
+}
+
+// txnBatch is the port changes of one transaction and the contexts of the
+// driver calls that made them.
+type txnBatch struct {
+	changes  [][]libovsdb.Operation
+	contexts []context.Context
+	done     []chan error
+}
+
+func newTxnBatcher(db *ovsdber, window time.Duration) *txnBatcher {
+	return &txnBatcher{db: db, window: window}
+}
+
+// transact runs the operations of a port change in the next batch and
+// returns its outcome.
+func (b *txnBatcher) transact(ctx context.Context, operations ...libovsdb.Operation) error {
+	done := make(chan error, 1)
+	b.mu.Lock()
+	batch := b.pending
+	if batch == nil {
+		batch = &txnBatch{}
+		b.pending = batch
+		time.AfterFunc(b.window, func() { b.flush(batch) })
+	}
+	batch.changes = append(batch.changes, operations)
+	batch.contexts = append(batch.contexts, ctx)
+	batch.done = append(batch.done, done)
+	if len(batch.changes) >= maxBatch {
+		b.pending = nil
+		go b.run(batch)
+	}
+	b.mu.Unlock()
+	return <-done
+}
+
+// flush runs the batch when its window ends, unless it ran when it filled.
+func (b *txnBatcher) flush(batch *txnBatch) {
+	b.mu.Lock()
+	if b.pending != batch {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+	b.run(batch)
+}
+
+// run commits the changes of a batch in one transaction. OVSDB transactions
+// are atomic, so if one change fails none is applied and each change is
+// retried in a transaction of its own to find out which one failed. When
+// the outcome is unknown every change gets the error, converge checks
+// which were applied.
+func (b *txnBatcher) run(batch *txnBatch) {
+	if len(batch.changes) == 1 {
+		batch.done[0] <- b.db.transactContext(batch.contexts[0], batch.changes[0]...)
+		return
+	}
+	var operations []libovsdb.Operation
+	for _, change := range batch.changes {
+		operations = append(operations, change...)
+	}
+	ctx, s := startBatchSpan(batch)
+	err := b.db.transactContext(ctx, operations...)
+	finishSpan(s, err)
+	if err == nil || outcomeUnknown(err) {
+		for _, done := range batch.done {
+			done <- err
+		}
+		return
+	}
+	log.Debugf("batch of %d port changes failed, applying them one by one: %v", len(batch.changes), err)
+	for i, change := range batch.changes {
+		batch.done[i] <- b.db.transactContext(batch.contexts[i], change...)
+	}
+}
+
+// startBatchSpan starts the span of a transaction shared by several driver
+// calls. It belongs to none of their traces, so it links to the span of
+// each call instead, and records nothing when no call is traced.
+func startBatchSpan(batch *txnBatch) (context.Context, trace.Span) {
+	var links []trace.Link
+	for _, ctx := range batch.contexts {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	if len(links) == 0 {
+		return context.Background(), trace.SpanFromContext(context.Background())
+	}
+	return tracer.Start(context.Background(), "ovsdb.batch",
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("ovsdb.batch.changes", len(batch.changes))))
+}
+
+// transactPort runs the operations of a port change, batched with other
+// port changes unless batching is off.
+func (ovsdber *ovsdber) transactPort(ctx context.Context, operations ...libovsdb.Operation) error {
+	if ovsdber.batcher == nil {
+		return ovsdber.transactContext(ctx, operations...)
+	}
+	return ovsdber.batcher.transact(ctx, operations...)
+}
@@ -0,0 +1,150 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	bfdMinInterval = "500" // milliseconds, for both min_rx and min_tx
+	bfdStateUp     = "up"
+	bfdStateDown   = "down"
+	// tunnelRepairInterval keeps a tunnel to a node that is really gone
+	// from being torn down and added again on every reconciler run
+	tunnelRepairInterval = 2 * time.Minute
+)
+
+// tunnelRepaired is when each tunnel was last re-established, guarded by
+// reconcileMu.
+var tunnelRepaired = make(map[string]time.Time)
+
+// bfdConfig is written to the bfd column of tunnel interfaces so that a
+// dead peer is detected within a few seconds.
+func bfdConfig() (*libovsdb.OvsMap, error) {
+	return libovsdb.NewOvsMap(map[string]string{
+		"enable": "true",
+		"min_rx": bfdMinInterval,
+		"min_tx": bfdMinInterval,
+	})
+}
+
+// TunnelStatus returns the BFD session state of the tunnels keyed by the
+// remote peer address. A peer with a tunnel that is not up reports the state
+// of that tunnel, peers without a BFD session report an empty state.
+func (d *Driver) TunnelStatus() map[string]string {
+	status := make(map[string]string)
+	for _, row := range getTableCache("Interface") {
+		ifaceType, _ := row.Fields["type"].(string)
+		if !tunnelTypes[ifaceType] {
+			continue
+		}
+		peer := ovsMapValue(row.Fields["options"], "remote_ip")
+		if peer == "" {
+			continue
+		}
+		state := ovsMapValue(row.Fields["bfd_status"], "state")
+		if current, ok := status[peer]; !ok || current == bfdStateUp {
+			status[peer] = state
+		}
+	}
+	return status
+}
+
+// handleTunnels returns the BFD state of the tunnels to every peer.
+func (d *Driver) handleTunnels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, d.TunnelStatus())
+}
+
+// tunnelDown reports whether the BFD session of a tunnel interface is down.
+func tunnelDown(portName string) bool {
+	for _, row := range getTableCache("Interface") {
+		if name, _ := row.Fields["name"].(string); name == portName {
+			return ovsMapValue(row.Fields["bfd_status"], "state") == bfdStateDown
+		}
+	}
+	return false
+}
+
+// repairTunnels tunnels an overlay network to the peers it lost its tunnel
+// to, and re-establishes the tunnels whose BFD session is down, at most once
+// every tunnelRepairInterval, with reconcileMu held. The tunnels of an
+// evacuated host stay down until it is resumed.
+func (d *Driver) repairTunnels(networkID string, ns *NetworkState) error {
+	if !d.overlayNetwork(networkID, ns) || hostInMaintenance() {
+		return nil
+	}
+	if !d.ovsdber.hasColumn("Port", "protected") {
+		// connectPeers already reported a switch without protected ports
+		return nil
+	}
+	var failed []string
+	for peer := range d.peers {
+		portName := tunnelPortName(networkID, peer)
+		if !tunnelDown(portName) {
+			if err := d.addTunnel(context.Background(), networkID, ns, peer); err != nil {
+				log.Errorf("failed to tunnel network %s to node %s: %v", truncateID(networkID), peer, err)
+				failed = append(failed, peer)
+			}
+			continue
+		}
+		if time.Since(tunnelRepaired[portName]) < tunnelRepairInterval {
+			continue
+		}
+		tunnelRepaired[portName] = time.Now()
+		log.Warnf("tunnel [ %s ] to node [ %s ] is down, re-establishing it", portName, peer)
+		if err := d.ovsdber.deletePort(context.Background(), ns.BridgeName, portName); err != nil {
+			log.Errorf("failed to remove tunnel %s to node %s: %v", portName, peer, err)
+			failed = append(failed, peer)
+			continue
+		}
+		if err := d.ovsdber.addVxlanPort(context.Background(), ns.BridgeName, portName, peer, d.localAddress, ns.VNI); err != nil {
+			log.Errorf("failed to tunnel network %s to node %s: %v", truncateID(networkID), peer, err)
+			failed = append(failed, peer)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("tunnels to nodes %v could not be repaired", failed)
+	}
+	return nil
+}
+
+// watchTunnelState alerts when the BFD state of a tunnel interface changes,
+// and has the reconciler re-establish tunnels that went down.
+func watchTunnelState(tableUpdate libovsdb.TableUpdate) {
+	for _, row := range tableUpdate.Rows {
+		if _, changed := row.Old.Fields["bfd_status"]; !changed {
+			continue
+		}
+		name, _ := row.New.Fields["name"].(string)
+		peer := ovsMapValue(row.New.Fields["options"], "remote_ip")
+		state := ovsMapValue(row.New.Fields["bfd_status"], "state")
+		if state == bfdStateUp {
+			log.Infof("tunnel [ %s ] to peer [ %s ] is up", name, peer)
+			continue
+		}
+		diag := ovsMapValue(row.New.Fields["bfd_status"], "diagnostic")
+		log.Warnf("tunnel [ %s ] to peer [ %s ] is %s: %s", name, peer, state, diag)
+		if state == bfdStateDown {
+			signalReconcile()
+		}
+	}
+}
+
+// ovsMapValue returns the string stored under key in a map column.
+func ovsMapValue(field interface{}, key string) string {
+	m, ok := field.(libovsdb.OvsMap)
+	if !ok {
+		return ""
+	}
+	value, _ := m.GoMap[key].(string)
+	return value
+}
@@ -0,0 +1,53 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+func tunnelFields(name, peer, state string) map[string]interface{} {
+	fields := map[string]interface{}{
+		"name":    name,
+		"type":    "vxlan",
+		"options": libovsdb.OvsMap{GoMap: map[interface{}]interface{}{"remote_ip": peer}},
+	}
+	if state != "" {
+		fields["bfd_status"] = libovsdb.OvsMap{GoMap: map[interface{}]interface{}{"state": state}}
+	}
+	return fields
+}
+
+// TestTunnelStatus checks that a peer reports the state of a tunnel that is
+// not up, and that only a tunnel whose BFD session is down needs repair.
+func TestTunnelStatus(t *testing.T) {
+	saved := ovsdbCache
+	defer func() { ovsdbCache = saved }()
+	ovsdbCache = newTableCache()
+	ovsdbCache.update(rowUpdate("Interface", "i1", nil, tunnelFields("vxneta00000001", "10.0.0.2", bfdStateUp)))
+	ovsdbCache.update(rowUpdate("Interface", "i2", nil, tunnelFields("vxnetb00000001", "10.0.0.2", bfdStateDown)))
+	ovsdbCache.update(rowUpdate("Interface", "i3", nil, tunnelFields("vxneta00000002", "10.0.0.3", "")))
+	ovsdbCache.update(rowUpdate("Interface", "i4", nil, map[string]interface{}{"name": "ovs-veth-a", "type": ""}))
+
+	want := map[string]string{"10.0.0.2": bfdStateDown, "10.0.0.3": ""}
+	got := (&Driver{}).TunnelStatus()
+	if len(got) != len(want) {
+		t.Fatalf("tunnel status is %v, want %v", got, want)
+	}
+	for peer, state := range want {
+		if got[peer] != state {
+			t.Errorf("peer %s is %q, want %q", peer, got[peer], state)
+		}
+	}
+
+	for name, down := range map[string]bool{
+		"vxneta00000001": false,
+		"vxnetb00000001": true,
+		"vxneta00000002": false,
+		"vxgone00000001": false,
+	} {
+		if tunnelDown(name) != down {
+			t.Errorf("tunnelDown(%s) is %v, want %v", name, !down, down)
+		}
+	}
+}
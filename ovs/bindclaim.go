@@ -0,0 +1,149 @@
+package ovs
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// Flat mode networks claim their bind interface in the external_ids of
+	// the root Open_vSwitch row, as linker-bind:<interface>/<network id> =
+	// <usage>, so that every plugin instance on the host sees the claims.
+	bindClaimPrefix = "linker-bind:"
+
+	// bindFlat is an interface enslaved to the bridge of one network,
+	// bindTrunk one carrying the VLAN sub-interfaces of several networks.
+	bindFlat  = "flat"
+	bindTrunk = "trunk"
+)
+
+// bindClaim is a network's use of a bind interface.
+type bindClaim struct {
+	Interface string
+	NetworkID string
+	Usage     string
+}
+
+func (c bindClaim) key() string {
+	return bindClaimPrefix + c.Interface + "/" + c.NetworkID
+}
+
+// bindUsage returns how a network uses its bind interface, or an empty
+// string if it does not claim one.
+func bindUsage(ns *NetworkState) string {
+	if ns.Mode != modeFlat || ns.FlatBindInterface == "" {
+		return ""
+	}
+	if ns.VLAN != 0 {
+		return bindTrunk
+	}
+	return bindFlat
+}
+
+// bindClaims returns the claims on the named interface. Claims are soft,
+// those of networks whose bridge is gone are stale and ignored.
+func bindClaims(iface string) []bindClaim {
+	var claims []bindClaim
+	live := make(map[string]bool)
+	for _, networkID := range pluginBridges() {
+		live[networkID] = true
+	}
+	for _, row := range getTableCache("Open_vSwitch") {
+		ids, _ := row.Fields["external_ids"].(libovsdb.OvsMap)
+		for k, v := range ids.GoMap {
+			key, _ := k.(string)
+			if !strings.HasPrefix(key, bindClaimPrefix+iface+"/") {
+				continue
+			}
+			c := bindClaim{Interface: iface, NetworkID: strings.TrimPrefix(key, bindClaimPrefix+iface+"/")}
+			c.Usage, _ = v.(string)
+			if !live[c.NetworkID] {
+				log.Debugf("ignoring stale claim of network %s on %s", truncateID(c.NetworkID), iface)
+				continue
+			}
+			claims = append(claims, c)
+		}
+	}
+	return claims
+}
+
+// checkBindClaim returns an error telling how to resolve a conflict between
+// the claim and the ones other networks hold on the interface, or on the
+// members of a bond.
+func checkBindClaim(claim bindClaim) error {
+	link, err := netlink.LinkByName(claim.Interface)
+	if err != nil {
+		return fmt.Errorf("bind interface %s: %v", claim.Interface, err)
+	}
+	if master := link.Attrs().MasterIndex; master != 0 {
+		if bond, err := netlink.LinkByIndex(master); err == nil && bond.Type() == "bond" {
+			return fmt.Errorf("bind interface %s is a member of bond %s, set %s=%s instead",
+				claim.Interface, bond.Attrs().Name, bindInterfaceOption, bond.Attrs().Name)
+		}
+	}
+	if link.Type() == "bond" {
+		if links, err := netlink.LinkList(); err == nil {
+			for _, member := range links {
+				if member.Attrs().MasterIndex != link.Attrs().Index {
+					continue
+				}
+				for _, other := range bindClaims(member.Attrs().Name) {
+					if other.NetworkID == claim.NetworkID {
+						continue
+					}
+					return fmt.Errorf("bond %s has member %s bound by network %s, delete that network or move it to the bond",
+						claim.Interface, member.Attrs().Name, truncateID(other.NetworkID))
+				}
+			}
+		}
+	}
+	for _, other := range bindClaims(claim.Interface) {
+		if other.NetworkID == claim.NetworkID {
+			continue
+		}
+		switch {
+		case other.Usage == bindFlat:
+			return fmt.Errorf("bind interface %s is enslaved to the bridge of network %s, give both networks a %s to share it as a trunk",
+				claim.Interface, truncateID(other.NetworkID), vlanOption)
+		case claim.Usage == bindFlat:
+			return fmt.Errorf("bind interface %s is a VLAN trunk of network %s, set %s to add this network to the trunk",
+				claim.Interface, truncateID(other.NetworkID), vlanOption)
+		}
+	}
+	return nil
+}
+
+// claimBindInterface registers the network's use of its bind interface
+// after checking it against the claims of other networks on the host.
+func (ovsdber *ovsdber) claimBindInterface(networkID string, ns *NetworkState) error {
+	usage := bindUsage(ns)
+	if usage == "" {
+		return nil
+	}
+	claim := bindClaim{Interface: ns.FlatBindInterface, NetworkID: networkID, Usage: usage}
+	if err := checkBindClaim(claim); err != nil {
+		return err
+	}
+	extIDs, _ := libovsdb.NewOvsMap(map[string]string{claim.key(): claim.Usage})
+	if err := ovsdber.mutateRootExternalIDs(claim.key(), libovsdb.NewMutation("external_ids", "insert", extIDs)); err != nil {
+		return err
+	}
+	log.Infof("Network [ %s ] claimed bind interface [ %s ] as %s", truncateID(networkID), claim.Interface, usage)
+	return nil
+}
+
+// releaseBindInterface drops the claim of the network on its bind
+// interface.
+func (ovsdber *ovsdber) releaseBindInterface(networkID string, ns *NetworkState) {
+	if bindUsage(ns) == "" {
+		return
+	}
+	claim := bindClaim{Interface: ns.FlatBindInterface, NetworkID: networkID}
+	if err := ovsdber.mutateRootExternalIDs(claim.key()); err != nil {
+		log.Warnf("failed to release bind interface %s of network %s: %v", claim.Interface, truncateID(networkID), err)
+	}
+}
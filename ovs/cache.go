@@ -0,0 +1,277 @@
+package ovs
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// indexedTables are the tables whose rows have unique names, looked up by
+// name through an index rather than a scan
+var indexedTables = map[string]bool{"Bridge": true, "Port": true, "Interface": true}
+
+// tableCache holds the monitored OVSDB rows keyed by table and uuid. The
+// notifier updates it while driver callbacks and admin handlers read it,
+// so rows are only handed out through the accessors. Rows are replaced,
+// never modified, so a row read from the cache stays valid.
+type tableCache struct {
+	mu     sync.RWMutex
+	tables map[string]map[string]libovsdb.Row
+	// names maps the names of the rows of indexedTables to their uuids
+	names map[string]map[string]string
+	// networksByBridge maps the bridges of the plugin to the ids of their
+	// networks, bridgesByNetwork the other way. Both are rebuilt when a
+	// Bridge or BridgeOpt row changes, there are few of them.
+	networksByBridge map[string]string
+	bridgesByNetwork map[string]string
+	// rooted is closed once the Open_vSwitch row is cached, it stays
+	// closed across reconnects since the row is never removed
+	rooted   chan struct{}
+	rootOnce sync.Once
+}
+
+var ovsdbCache = newTableCache()
+
+func newTableCache() *tableCache {
+	c := &tableCache{rooted: make(chan struct{})}
+	c.clear()
+	return c
+}
+
+// reset drops all rows, before the cache is rebuilt from a new monitor.
+func (c *tableCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clear()
+}
+
+// clear drops all rows and indexes, with mu held.
+func (c *tableCache) clear() {
+	c.tables = make(map[string]map[string]libovsdb.Row)
+	c.names = make(map[string]map[string]string)
+	c.networksByBridge = make(map[string]string)
+	c.bridgesByNetwork = make(map[string]string)
+}
+
+// replace rebuilds the cache from the reply of a new monitor in one step,
+// readers see either the old rows or the new ones.
+func (c *tableCache) replace(updates libovsdb.TableUpdates) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clear()
+	c.apply(updates)
+}
+
+// update applies the rows of an update notification.
+func (c *tableCache) update(updates libovsdb.TableUpdates) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apply(updates)
+}
+
+// apply applies the rows of updates, with mu held.
+func (c *tableCache) apply(updates libovsdb.TableUpdates) {
+	empty := libovsdb.Row{}
+	networksChanged := false
+	for table, tableUpdate := range updates.Updates {
+		if _, ok := c.tables[table]; !ok {
+			c.tables[table] = make(map[string]libovsdb.Row)
+		}
+		for uuid, row := range tableUpdate.Rows {
+			if old, ok := c.tables[table][uuid]; ok {
+				c.unindex(table, uuid, old)
+			}
+			if !reflect.DeepEqual(row.New, empty) {
+				c.tables[table][uuid] = row.New
+				c.index(table, uuid, row.New)
+			} else {
+				delete(c.tables[table], uuid)
+			}
+		}
+		if table == "Bridge" || table == "BridgeOpt" {
+			networksChanged = true
+		}
+	}
+	if networksChanged {
+		c.indexNetworks()
+	}
+	if len(c.tables["Open_vSwitch"]) > 0 {
+		c.rootOnce.Do(func() { close(c.rooted) })
+	}
+}
+
+// waitRoot waits until the Open_vSwitch row is cached, the driver can't
+// change the switch without its uuid.
+func (c *tableCache) waitRoot(timeout time.Duration) error {
+	select {
+	case <-c.rooted:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("the Open_vSwitch table is still empty after %s, is ovs-vswitchd initialized?", timeout)
+	}
+}
+
+// index adds a row to the name index, with mu held.
+func (c *tableCache) index(table, uuid string, row libovsdb.Row) {
+	name, _ := row.Fields["name"].(string)
+	if !indexedTables[table] || name == "" {
+		return
+	}
+	if _, ok := c.names[table]; !ok {
+		c.names[table] = make(map[string]string)
+	}
+	c.names[table][name] = uuid
+}
+
+// unindex removes a row from the name index, with mu held.
+func (c *tableCache) unindex(table, uuid string, row libovsdb.Row) {
+	name, _ := row.Fields["name"].(string)
+	if c.names[table][name] == uuid {
+		delete(c.names[table], name)
+	}
+}
+
+// indexNetworks rebuilds the bridge and network indexes, with mu held. The
+// network recorded in a bridge's external_ids wins over the BridgeOpt row
+// of older releases.
+func (c *tableCache) indexNetworks() {
+	c.networksByBridge = make(map[string]string)
+	c.bridgesByNetwork = make(map[string]string)
+	for _, row := range c.tables["Bridge"] {
+		name, _ := row.Fields["name"].(string)
+		if networkID := ovsMapValue(row.Fields["external_ids"], networkIDKey); name != "" && networkID != "" {
+			c.networksByBridge[name] = networkID
+		}
+	}
+	for _, row := range c.tables["BridgeOpt"] {
+		name, _ := row.Fields["name"].(string)
+		networkID, _ := row.Fields["network_id"].(string)
+		if _, ok := c.networksByBridge[name]; name != "" && !ok {
+			c.networksByBridge[name] = networkID
+		}
+	}
+	for name, networkID := range c.networksByBridge {
+		if networkID != "" {
+			c.bridgesByNetwork[networkID] = name
+		}
+	}
+}
+
+// table returns a copy of the rows of a table.
+func (c *tableCache) table(name string) map[string]libovsdb.Row {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rows := make(map[string]libovsdb.Row, len(c.tables[name]))
+	for uuid, row := range c.tables[name] {
+		rows[uuid] = row
+	}
+	return rows
+}
+
+// row returns a row by uuid.
+func (c *tableCache) row(table, uuid string) (libovsdb.Row, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	row, ok := c.tables[table][uuid]
+	return row, ok
+}
+
+// cachedRow returns a row by uuid, empty if it isn't cached.
+func cachedRow(table, uuid string) libovsdb.Row {
+	row, _ := ovsdbCache.row(table, uuid)
+	return row
+}
+
+// byName returns the uuid and row whose name column matches, for the
+// tables whose names are unique.
+func (c *tableCache) byName(table, name string) (string, libovsdb.Row, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if indexedTables[table] {
+		uuid, ok := c.names[table][name]
+		if !ok {
+			return "", libovsdb.Row{}, false
+		}
+		return uuid, c.tables[table][uuid], true
+	}
+	for uuid, row := range c.tables[table] {
+		if row.Fields["name"] == name {
+			return uuid, row, true
+		}
+	}
+	return "", libovsdb.Row{}, false
+}
+
+// bridge returns the named bridge.
+func (c *tableCache) bridge(name string) (string, libovsdb.Row, bool) {
+	return c.byName("Bridge", name)
+}
+
+// port returns the named port.
+func (c *tableCache) port(name string) (string, libovsdb.Row, bool) {
+	return c.byName("Port", name)
+}
+
+// iface returns the named interface.
+func (c *tableCache) iface(name string) (string, libovsdb.Row, bool) {
+	return c.byName("Interface", name)
+}
+
+// pluginBridges returns a copy of the bridge to network index.
+func (c *tableCache) pluginBridges() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	bridges := make(map[string]string, len(c.networksByBridge))
+	for name, networkID := range c.networksByBridge {
+		bridges[name] = networkID
+	}
+	return bridges
+}
+
+// networkBridge returns the bridge of a network.
+func (c *tableCache) networkBridge(networkID string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.bridgesByNetwork[networkID]
+	return name, ok
+}
+
+// sizes returns the number of rows of each table.
+func (c *tableCache) sizes() map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	sizes := make(map[string]int, len(c.tables))
+	for table, rows := range c.tables {
+		sizes[table] = len(rows)
+	}
+	return sizes
+}
+
+// rootUUID returns the uuid of the Open_vSwitch row.
+func (c *tableCache) rootUUID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for uuid := range c.tables["Open_vSwitch"] {
+		return uuid
+	}
+	return ""
+}
+
+// logUpdates logs OVSDB updates in full, on hosts with many ports they are
+// too large to log by default
+var logUpdates bool
+
+func populateCache(updates libovsdb.TableUpdates) {
+	if logUpdates {
+		log.Debugf("udpates is %v", updates)
+	} else if log.GetLevel() >= log.DebugLevel {
+		for table, tableUpdate := range updates.Updates {
+			log.Debugf("OVSDB update of %d %s rows", len(tableUpdate.Rows), table)
+		}
+	}
+	ovsdbCache.update(updates)
+}
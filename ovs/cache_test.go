@@ -0,0 +1,151 @@
+package ovs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// rowUpdate returns the update of one row, a nil new row deletes it.
+func rowUpdate(table, uuid string, oldFields, newFields map[string]interface{}) libovsdb.TableUpdates {
+	update := libovsdb.RowUpdate{Uuid: libovsdb.UUID{GoUuid: uuid}}
+	if oldFields != nil {
+		update.Old = libovsdb.Row{Fields: oldFields}
+	}
+	if newFields != nil {
+		update.New = libovsdb.Row{Fields: newFields}
+	}
+	return libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		table: {Rows: map[string]libovsdb.RowUpdate{uuid: update}},
+	}}
+}
+
+func bridgeFields(name, networkID string) map[string]interface{} {
+	fields := map[string]interface{}{"name": name}
+	if networkID != "" {
+		fields["external_ids"] = libovsdb.OvsMap{GoMap: map[interface{}]interface{}{networkIDKey: networkID}}
+	}
+	return fields
+}
+
+func TestTableCacheUpdate(t *testing.T) {
+	c := newTableCache()
+	c.update(rowUpdate("Port", "p1", nil, map[string]interface{}{"name": "ovs-veth-a"}))
+	c.update(rowUpdate("Bridge", "b1", nil, bridgeFields("ovsbr-a", "net-a")))
+
+	if uuid, _, ok := c.port("ovs-veth-a"); !ok || uuid != "p1" {
+		t.Errorf("port ovs-veth-a is %q, %v, want p1", uuid, ok)
+	}
+	if bridge, ok := c.networkBridge("net-a"); !ok || bridge != "ovsbr-a" {
+		t.Errorf("bridge of net-a is %q, %v, want ovsbr-a", bridge, ok)
+	}
+
+	// a rename moves the row in the name index
+	c.update(rowUpdate("Port", "p1", map[string]interface{}{"name": "ovs-veth-a"}, map[string]interface{}{"name": "ovs-veth-b"}))
+	if _, _, ok := c.port("ovs-veth-a"); ok {
+		t.Error("renamed port is still found by its old name")
+	}
+	if uuid, _, ok := c.port("ovs-veth-b"); !ok || uuid != "p1" {
+		t.Errorf("port ovs-veth-b is %q, %v, want p1", uuid, ok)
+	}
+
+	// a bridge that no longer records a network leaves the network index
+	c.update(rowUpdate("Bridge", "b1", bridgeFields("ovsbr-a", "net-a"), bridgeFields("ovsbr-a", "")))
+	if _, ok := c.networkBridge("net-a"); ok {
+		t.Error("network net-a still has a bridge after its id was removed")
+	}
+}
+
+func TestTableCacheDelete(t *testing.T) {
+	c := newTableCache()
+	c.update(rowUpdate("Bridge", "b1", nil, bridgeFields("ovsbr-a", "net-a")))
+	c.update(rowUpdate("Port", "p1", nil, map[string]interface{}{"name": "ovs-veth-a"}))
+
+	c.update(rowUpdate("Port", "p1", map[string]interface{}{"name": "ovs-veth-a"}, nil))
+	if _, _, ok := c.port("ovs-veth-a"); ok {
+		t.Error("deleted port is still found by name")
+	}
+	if _, ok := c.row("Port", "p1"); ok {
+		t.Error("deleted port is still found by uuid")
+	}
+
+	c.update(rowUpdate("Bridge", "b1", bridgeFields("ovsbr-a", "net-a"), nil))
+	if bridges := c.pluginBridges(); len(bridges) != 0 {
+		t.Errorf("plugin bridges are %v after the bridge was deleted, want none", bridges)
+	}
+	if sizes := c.sizes(); sizes["Bridge"] != 0 || sizes["Port"] != 0 {
+		t.Errorf("table sizes are %v after all rows were deleted", sizes)
+	}
+}
+
+// TestTableCacheReplace checks that the initial dump of a new monitor
+// replaces the rows of the old connection, and that the updates that
+// follow it merge with the dumped rows.
+func TestTableCacheReplace(t *testing.T) {
+	c := newTableCache()
+	c.update(rowUpdate("Port", "stale", nil, map[string]interface{}{"name": "ovs-veth-gone"}))
+	c.update(rowUpdate("Bridge", "b0", nil, bridgeFields("ovsbr-gone", "net-gone")))
+
+	dump := libovsdb.TableUpdates{Updates: map[string]libovsdb.TableUpdate{
+		"Open_vSwitch": {Rows: map[string]libovsdb.RowUpdate{
+			"root": {New: libovsdb.Row{Fields: map[string]interface{}{"ovs_version": "2.17.0"}}},
+		}},
+		"Bridge": {Rows: map[string]libovsdb.RowUpdate{
+			"b1": {New: libovsdb.Row{Fields: bridgeFields("ovsbr-a", "net-a")}},
+		}},
+		"Port": {Rows: map[string]libovsdb.RowUpdate{
+			"p1": {New: libovsdb.Row{Fields: map[string]interface{}{"name": "ovs-veth-a"}}},
+			"p2": {New: libovsdb.Row{Fields: map[string]interface{}{"name": "ovs-veth-b"}}},
+		}},
+	}}
+	c.replace(dump)
+
+	if _, _, ok := c.port("ovs-veth-gone"); ok {
+		t.Error("port of the old connection survived the initial dump")
+	}
+	if _, ok := c.networkBridge("net-gone"); ok {
+		t.Error("bridge of the old connection survived the initial dump")
+	}
+	if c.rootUUID() != "root" {
+		t.Errorf("root uuid is %q, want root", c.rootUUID())
+	}
+	if err := c.waitRoot(time.Second); err != nil {
+		t.Errorf("waitRoot after the dump: %v", err)
+	}
+
+	c.update(rowUpdate("Port", "p3", nil, map[string]interface{}{"name": "ovs-veth-c"}))
+	c.update(rowUpdate("Port", "p1", map[string]interface{}{"name": "ovs-veth-a"}, nil))
+	ports := c.table("Port")
+	if len(ports) != 2 {
+		t.Fatalf("ports after the dump and updates are %v, want p2 and p3", ports)
+	}
+	for _, uuid := range []string{"p2", "p3"} {
+		if _, ok := ports[uuid]; !ok {
+			t.Errorf("port %s is missing after the dump and updates", uuid)
+		}
+	}
+	if bridge, ok := c.networkBridge("net-a"); !ok || bridge != "ovsbr-a" {
+		t.Errorf("bridge of net-a is %q, %v, want ovsbr-a", bridge, ok)
+	}
+}
+
+// TestTableCacheBridgeOpt checks that the network a bridge records in its
+// external_ids wins over the BridgeOpt row of older releases.
+func TestTableCacheBridgeOpt(t *testing.T) {
+	c := newTableCache()
+	c.update(rowUpdate("BridgeOpt", "o1", nil, map[string]interface{}{"name": "ovsbr-a", "network_id": "net-old"}))
+	c.update(rowUpdate("BridgeOpt", "o2", nil, map[string]interface{}{"name": "ovsbr-b", "network_id": "net-b"}))
+	c.update(rowUpdate("Bridge", "b1", nil, bridgeFields("ovsbr-a", "net-a")))
+
+	want := map[string]string{"ovsbr-a": "net-a", "ovsbr-b": "net-b"}
+	got := c.pluginBridges()
+	if len(got) != len(want) {
+		t.Fatalf("plugin bridges are %v, want %v", got, want)
+	}
+	for bridge, networkID := range want {
+		if got[bridge] != networkID {
+			t.Errorf("bridge %s belongs to %q, want %q", bridge, got[bridge], networkID)
+		}
+	}
+}
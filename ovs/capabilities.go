@@ -0,0 +1,216 @@
+package ovs
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	datapathKernel    = "system"
+	datapathUserspace = "netdev"
+
+	featureConntrack       = "conntrack"
+	featureMcastSnooping   = "multicast snooping"
+	featureLinuxQoS        = "linux-htb QoS"
+	featureIngressPolice   = "ingress policing"
+	featureERSPAN          = "ERSPAN"
+	featureUserspacePaths  = "userspace datapath"
+	featureDPDK            = "DPDK ports"
+	featureVhostUser       = "vhost-user ports"
+	featureVhostUserClient = "vhost-user client ports"
+	featureInternalPorts   = "internal port endpoints"
+	featureSwitchdev       = "switchdev offload"
+	featureRSTP            = "RSTP"
+	featureQinQ            = "dot1q-tunnel ports"
+)
+
+// featureVersions is the first OVS release supporting a feature on each
+// datapath, a datapath missing from the map can't provide it at all.
+var featureVersions = map[string]map[string]string{
+	featureConntrack:       {datapathKernel: "2.5.0", datapathUserspace: "2.6.0"},
+	featureMcastSnooping:   {datapathKernel: "2.4.0", datapathUserspace: "2.4.0"},
+	featureLinuxQoS:        {datapathKernel: "1.0.0"},
+	featureIngressPolice:   {datapathKernel: "1.0.0"},
+	featureERSPAN:          {datapathKernel: "2.10.0", datapathUserspace: "2.10.0"},
+	featureUserspacePaths:  {datapathUserspace: "1.0.0"},
+	featureDPDK:            {datapathUserspace: "2.7.0"},
+	featureVhostUser:       {datapathUserspace: "2.7.0"},
+	featureVhostUserClient: {datapathUserspace: "2.7.0"},
+	featureInternalPorts:   {datapathKernel: "2.5.0"},
+	featureSwitchdev:       {datapathKernel: "2.8.0"},
+	featureRSTP:            {datapathKernel: "2.3.0", datapathUserspace: "2.3.0"},
+	featureQinQ:            {datapathKernel: "2.8.0", datapathUserspace: "2.8.0"},
+}
+
+// featureIfaceTypes are the interface types a feature needs the switch to
+// list in iface_types, besides its version.
+var featureIfaceTypes = map[string]string{
+	featureERSPAN:          "erspan",
+	featureDPDK:            "dpdk",
+	featureVhostUser:       "dpdkvhostuser",
+	featureVhostUserClient: "dpdkvhostuserclient",
+}
+
+// Capabilities is what the local switch supports, read from the root
+// Open_vSwitch row. Features maps each datapath to the features usable on
+// it.
+type Capabilities struct {
+	OVSVersion     string
+	DatapathTypes  []string `json:",omitempty"`
+	InterfaceTypes []string `json:",omitempty"`
+	Features       map[string][]string
+}
+
+// switchCapabilities reads the capabilities of the switch from the cache.
+func switchCapabilities() *Capabilities {
+	caps := &Capabilities{Features: make(map[string][]string)}
+	for _, row := range getTableCache("Open_vSwitch") {
+		caps.OVSVersion, _ = row.Fields["ovs_version"].(string)
+		caps.DatapathTypes = ovsStringSet(row.Fields["datapath_types"])
+		caps.InterfaceTypes = ovsStringSet(row.Fields["iface_types"])
+	}
+	for _, datapath := range []string{datapathKernel, datapathUserspace} {
+		for feature := range featureVersions {
+			if caps.require(feature, datapath) == nil {
+				caps.Features[datapath] = append(caps.Features[datapath], feature)
+			}
+		}
+		sort.Strings(caps.Features[datapath])
+	}
+	return caps
+}
+
+// require returns an error telling what a feature needs when the switch
+// can't provide it on the datapath. Checks that need data the switch does
+// not report, like iface_types before OVS 2.7, are skipped.
+func (c *Capabilities) require(feature, datapath string) error {
+	minVersion, ok := featureVersions[feature][datapath]
+	if !ok {
+		return fmt.Errorf("%s requires the %s datapath, not the %s datapath",
+			feature, datapathDescription(otherDatapath(datapath)), datapathDescription(datapath))
+	}
+	if datapath == datapathUserspace && len(c.DatapathTypes) > 0 && !containsString(c.DatapathTypes, datapathUserspace) {
+		return fmt.Errorf("%s requires the userspace datapath, which this switch does not provide", feature)
+	}
+	if c.OVSVersion != "" && compareVersions(c.OVSVersion, minVersion) < 0 {
+		return fmt.Errorf("%s requires OVS >= %s on the %s datapath, this host runs %s",
+			feature, minVersion, datapathDescription(datapath), c.OVSVersion)
+	}
+	if ifaceType, ok := featureIfaceTypes[feature]; ok && len(c.InterfaceTypes) > 0 && !containsString(c.InterfaceTypes, ifaceType) {
+		return fmt.Errorf("%s requires the %s interface type, which this switch does not provide", feature, ifaceType)
+	}
+	return nil
+}
+
+// checkNetworkCapabilities fails a network whose options the switch can't
+// honor on its datapath, instead of creating it without them.
+func checkNetworkCapabilities(ns *NetworkState) error {
+	caps := switchCapabilities()
+	datapath := ns.Datapath
+	var features []string
+	if datapath == datapathUserspace {
+		features = append(features, featureUserspacePaths)
+	}
+	if ns.DPDKUplink != "" {
+		features = append(features, featureDPDK)
+	}
+	if ns.InternalPorts {
+		features = append(features, featureInternalPorts)
+	}
+	if ns.Switchdev {
+		features = append(features, featureSwitchdev)
+	}
+	if !ns.ICC {
+		features = append(features, featureConntrack)
+	}
+	if ns.McastSnooping {
+		features = append(features, featureMcastSnooping)
+	}
+	if ns.SpanningTree == spanningTreeRSTP {
+		features = append(features, featureRSTP)
+	}
+	if ns.QoSMinRate > 0 || ns.QoSMaxRate > 0 || len(ns.QoSClasses) > 0 {
+		features = append(features, featureLinuxQoS)
+	}
+	for _, feature := range features {
+		if err := caps.require(feature, datapath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func datapathDescription(datapath string) string {
+	if datapath == datapathUserspace {
+		return "userspace"
+	}
+	return "kernel"
+}
+
+func otherDatapath(datapath string) string {
+	if datapath == datapathUserspace {
+		return datapathKernel
+	}
+	return datapathUserspace
+}
+
+// compareVersions compares dotted version numbers, missing or non numeric
+// parts count as 0.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func ovsStringSet(field interface{}) []string {
+	var values []string
+	switch v := field.(type) {
+	case string:
+		values = append(values, v)
+	case libovsdb.OvsSet:
+		for _, elem := range v.GoSet {
+			if s, ok := elem.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// handleCapabilities serves /capabilities
+func (d *Driver) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, switchCapabilities())
+}
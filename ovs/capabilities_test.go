@@ -0,0 +1,52 @@
+package ovs
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.17.0", "2.17.0", 0},
+		{"2.17", "2.17.0", 0},
+		{"2.9.0", "2.10.0", -1},
+		{"2.10.0", "2.9.0", 1},
+		{"3.0", "2.17.9", 1},
+		{"2.17.1", "2.17", 1},
+		{"2.5.90", "2.6", -1},
+		// non numeric parts count as 0
+		{"2.x", "2.0", 0},
+		{"", "0", 0},
+		{"", "2.5", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		caps     Capabilities
+		feature  string
+		datapath string
+		wantErr  bool
+	}{
+		{Capabilities{OVSVersion: "2.17.0"}, featureConntrack, datapathKernel, false},
+		{Capabilities{OVSVersion: "2.4.0"}, featureConntrack, datapathKernel, true},
+		{Capabilities{OVSVersion: "2.5.0"}, featureConntrack, datapathUserspace, true},
+		{Capabilities{OVSVersion: "2.17.0", DatapathTypes: []string{datapathKernel}}, featureConntrack, datapathUserspace, true},
+		{Capabilities{OVSVersion: "2.17.0"}, featureLinuxQoS, datapathUserspace, true},
+		{Capabilities{OVSVersion: "2.17.0", InterfaceTypes: []string{"internal"}}, featureERSPAN, datapathKernel, true},
+		{Capabilities{OVSVersion: "2.17.0", InterfaceTypes: []string{"erspan"}}, featureERSPAN, datapathKernel, false},
+		// an unknown version is not held against the switch
+		{Capabilities{}, featureConntrack, datapathKernel, false},
+	}
+	for _, tt := range tests {
+		err := tt.caps.require(tt.feature, tt.datapath)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("require(%q, %q) on %+v = %v, want error %v", tt.feature, tt.datapath, tt.caps, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package ovs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// capturePrefix names the mirror of a capture and the internal port it
+	// outputs to, within IFNAMSIZ
+	capturePrefix = "pcap"
+	// captureDir holds the pcap files of captures until they are deleted
+	captureDir = "/var/tmp/ovs-plugin-captures"
+
+	defaultCaptureExpiry     = 30 * time.Second
+	maxCaptureExpiry         = 600 * time.Second
+	defaultCaptureMaxPackets = 100000
+	// maxCaptures bounds the captures running at once, each mirrors a port
+	// to userspace
+	maxCaptures = 4
+)
+
+var (
+	captureMu sync.Mutex
+	captures  = make(map[string]*packetCapture)
+	// captureSeq numbers captures from a start that differs per process, so
+	// the ids of a new run rarely meet the ports and files an earlier run
+	// left, and nextCaptureID skips those it does meet
+	captureSeq = uint32(time.Now().UnixNano())
+)
+
+// CaptureRequest starts a capture of the traffic of an endpoint, given by
+// its full id or a prefix of at least five characters. Filter is a tcpdump
+// filter expression, Snaplen and MaxPackets bound the size of the file.
+type CaptureRequest struct {
+	EndpointID string
+	Seconds    int    `json:",omitempty"`
+	Snaplen    int    `json:",omitempty"`
+	MaxPackets int    `json:",omitempty"`
+	Filter     string `json:",omitempty"`
+}
+
+// CaptureInfo describes a capture. Size is that of the pcap file so far,
+// Error is set when tcpdump failed.
+type CaptureInfo struct {
+	ID         string
+	EndpointID string
+	NetworkID  string
+	Bridge     string
+	Port       string
+	Filter     string `json:",omitempty"`
+	Started    time.Time
+	Expires    time.Time
+	Running    bool
+	Size       int64
+	Error      string `json:",omitempty"`
+}
+
+type packetCapture struct {
+	info CaptureInfo
+	file string
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// startCapture mirrors both directions of an endpoint's port to a new
+// internal port of its bridge and runs tcpdump on it until the capture
+// expires, MaxPackets are written or it is stopped. OVS neither switches
+// to nor forwards from the output port of a mirror, so the endpoint's
+// traffic is not changed.
+func (d *Driver) startCapture(req CaptureRequest) (*CaptureInfo, error) {
+	if _, err := exec.LookPath("tcpdump"); err != nil {
+		return nil, errors.New("tcpdump is not installed on this host")
+	}
+	if req.Snaplen < 0 || req.MaxPackets < 0 {
+		return nil, errors.New("Snaplen and MaxPackets can not be negative")
+	}
+	reconcileMu.Lock()
+	endpointID, networkID, err := d.resolveEndpoint(req.EndpointID)
+	reconcileMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if networkID == "" {
+		return nil, fmt.Errorf("no endpoint with id %s", req.EndpointID)
+	}
+	portName := ovsPortPrefix + truncateID(endpointID)
+	bridgeName := bridgeNameForPort(portName)
+	if bridgeName == "" {
+		return nil, fmt.Errorf("endpoint %s has no port on this host", truncateID(endpointID))
+	}
+	expiry := defaultCaptureExpiry
+	if req.Seconds > 0 {
+		expiry = time.Duration(req.Seconds) * time.Second
+	}
+	if expiry > maxCaptureExpiry {
+		expiry = maxCaptureExpiry
+	}
+	maxPackets := req.MaxPackets
+	if maxPackets == 0 {
+		maxPackets = defaultCaptureMaxPackets
+	}
+	if err := os.MkdirAll(captureDir, 0700); err != nil {
+		return nil, err
+	}
+
+	captureMu.Lock()
+	running := 0
+	for _, c := range captures {
+		if c.info.Running {
+			running++
+		}
+	}
+	if running >= maxCaptures {
+		captureMu.Unlock()
+		return nil, fmt.Errorf("%d captures are running already", running)
+	}
+	now := time.Now()
+	c := &packetCapture{
+		info: CaptureInfo{
+			ID:         nextCaptureID(),
+			EndpointID: endpointID,
+			NetworkID:  networkID,
+			Bridge:     bridgeName,
+			Port:       portName,
+			Filter:     req.Filter,
+			Started:    now,
+			Expires:    now.Add(expiry),
+			Running:    true,
+		},
+		done: make(chan struct{}),
+	}
+	c.file = filepath.Join(captureDir, c.info.ID+".pcap")
+	captures[c.info.ID] = c
+	captureMu.Unlock()
+
+	if err := d.ovsdber.addCaptureMirror(bridgeName, c.info.ID, portName); err != nil {
+		captureMu.Lock()
+		delete(captures, c.info.ID)
+		captureMu.Unlock()
+		return nil, err
+	}
+	fail := func(err error) (*CaptureInfo, error) {
+		captureMu.Lock()
+		delete(captures, c.info.ID)
+		captureMu.Unlock()
+		d.removeCaptureMirror(c)
+		return nil, err
+	}
+	link, err := waitForLink(c.info.ID)
+	if err != nil {
+		return fail(err)
+	}
+	if err := d.ovsdber.markLink(c.info.ID); err != nil {
+		return fail(err)
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fail(fmt.Errorf("failed to bring up %s: %v", c.info.ID, err))
+	}
+
+	args := []string{"-i", c.info.ID, "-n", "-U", "-w", c.file, "-c", strconv.Itoa(maxPackets)}
+	if req.Snaplen > 0 {
+		args = append(args, "-s", strconv.Itoa(req.Snaplen))
+	}
+	if req.Filter != "" {
+		// the filter is never taken for an option, e.g. another -w
+		args = append(args, "--", req.Filter)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("tcpdump", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fail(fmt.Errorf("failed to start tcpdump: %v", err))
+	}
+	timer := time.AfterFunc(expiry, func() { cmd.Process.Signal(syscall.SIGTERM) })
+	captureMu.Lock()
+	c.cmd = cmd
+	captureMu.Unlock()
+	go func() {
+		err := cmd.Wait()
+		timer.Stop()
+		d.removeCaptureMirror(c)
+		captureMu.Lock()
+		c.info.Running = false
+		// being killed by the signal that stops it is no failure
+		if err != nil && !cmd.ProcessState.Exited() {
+			err = nil
+		}
+		if err != nil {
+			c.info.Error = strings.TrimSpace(lastLine(stderr.String()))
+			if c.info.Error == "" {
+				c.info.Error = err.Error()
+			}
+		}
+		captureMu.Unlock()
+		close(c.done)
+		log.Infof("Finished capture [ %s ] of endpoint [ %s ]", c.info.ID, truncateID(endpointID))
+	}()
+	log.Infof("Started capture [ %s ] of port [ %s ] on bridge [ %s ] for %s", c.info.ID, portName, bridgeName, expiry)
+	info := c.info
+	return &info, nil
+}
+
+// nextCaptureID returns the id of a new capture, with captureMu held. Ids
+// of captures, ports and pcap files that exist already are skipped.
+func nextCaptureID() string {
+	for {
+		captureSeq++
+		id := fmt.Sprintf("%s%08x", capturePrefix, captureSeq)
+		if _, ok := captures[id]; ok || portUUIDForName(id) != "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(captureDir, id+".pcap")); err == nil {
+			continue
+		}
+		return id
+	}
+}
+
+// addCaptureMirror adds the internal port a capture reads from and the
+// mirror of the endpoint's port to it, both named after the capture. The
+// port is marked with mirrorKey so deleteMirror removes it too.
+func (ovsdber *ovsdber) addCaptureMirror(bridgeName, id, portName string) error {
+	source := portUUIDForName(portName)
+	if source == "" {
+		return fmt.Errorf("port %s not found", portName)
+	}
+	portIDs, _ := libovsdb.NewOvsMap(map[string]string{
+		ownerKey:         ownerValue,
+		ownerInstanceKey: ovsdber.instance,
+		mirrorKey:        id,
+	})
+	sources, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: source}})
+	portSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: "output"}})
+	mirrorSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: "mirror"}})
+	return ovsdber.transact(
+		libovsdb.Operation{
+			Op:       "insert",
+			Table:    "Interface",
+			Row:      map[string]interface{}{"name": id, "type": "internal"},
+			UUIDName: "iface",
+		},
+		libovsdb.Operation{
+			Op:       "insert",
+			Table:    "Port",
+			Row:      map[string]interface{}{"name": id, "interfaces": libovsdb.UUID{GoUuid: "iface"}, "external_ids": portIDs},
+			UUIDName: "output",
+		},
+		libovsdb.Operation{
+			Op:    "insert",
+			Table: "Mirror",
+			Row: map[string]interface{}{
+				"name":            id,
+				"select_src_port": sources,
+				"select_dst_port": sources,
+				"output_port":     libovsdb.UUID{GoUuid: "output"},
+				"external_ids":    ovsdber.ownerExternalIDs(),
+			},
+			UUIDName: "mirror",
+		},
+		libovsdb.Operation{
+			Op:    "mutate",
+			Table: "Bridge",
+			Mutations: []interface{}{
+				libovsdb.NewMutation("ports", "insert", portSet),
+				libovsdb.NewMutation("mirrors", "insert", mirrorSet),
+			},
+			Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+		},
+	)
+}
+
+// removeCaptureMirror removes the mirror and port of a capture. They are
+// gone already when the network was deleted meanwhile.
+func (d *Driver) removeCaptureMirror(c *packetCapture) {
+	if err := d.deleteMirror(c.info.NetworkID, c.info.ID); err != nil {
+		log.Debugf("failed to remove the mirror of capture %s: %v", c.info.ID, err)
+	}
+}
+
+// waitForLink waits for the link of a new internal port.
+func waitForLink(name string) (netlink.Link, error) {
+	for i := 0; i < 10; i++ {
+		if link, err := netlink.LinkByName(name); err == nil {
+			return link, nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return nil, fmt.Errorf("could not find a link for the internal port %s", name)
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
+
+// captureInfo returns the state of a capture with the size of its file.
+func captureInfo(id string) (*packetCapture, CaptureInfo, error) {
+	captureMu.Lock()
+	c, ok := captures[id]
+	var info CaptureInfo
+	if ok {
+		info = c.info
+	}
+	captureMu.Unlock()
+	if !ok {
+		return nil, info, fmt.Errorf("no capture with id %s", id)
+	}
+	if fi, err := os.Stat(c.file); err == nil {
+		info.Size = fi.Size()
+	}
+	return c, info, nil
+}
+
+// listCaptures returns the captures, running or not, in the order they
+// were started.
+func listCaptures() []CaptureInfo {
+	captureMu.Lock()
+	var ids []string
+	for id := range captures {
+		ids = append(ids, id)
+	}
+	captureMu.Unlock()
+	sort.Strings(ids)
+	infos := []CaptureInfo{}
+	for _, id := range ids {
+		if _, info, err := captureInfo(id); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// deleteCapture stops a capture if it is running and removes its file.
+func deleteCapture(id string) error {
+	captureMu.Lock()
+	c, ok := captures[id]
+	var cmd *exec.Cmd
+	if ok {
+		cmd = c.cmd
+	}
+	captureMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no capture with id %s", id)
+	}
+	if cmd == nil {
+		return fmt.Errorf("capture %s is still starting", id)
+	}
+	cmd.Process.Signal(syscall.SIGTERM)
+	<-c.done
+	captureMu.Lock()
+	delete(captures, id)
+	captureMu.Unlock()
+	if err := os.Remove(c.file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	log.Infof("Deleted capture [ %s ]", id)
+	return nil
+}
+
+// collectCaptures removes the mirrors and ports of captures the plugin no
+// longer runs, left behind by a restart.
+func (d *Driver) collectCaptures() {
+	for bridgeName, networkID := range pluginBridges() {
+		bridge := cachedRow("Bridge", getBridgeUUIDForName(bridgeName))
+		for _, uuid := range rowUUIDs(bridge.Fields["mirrors"]) {
+			name, _ := cachedRow("Mirror", uuid).Fields["name"].(string)
+			if !strings.HasPrefix(name, capturePrefix) {
+				continue
+			}
+			captureMu.Lock()
+			_, ok := captures[name]
+			captureMu.Unlock()
+			if ok {
+				continue
+			}
+			if err := d.deleteMirror(networkID, name); err != nil {
+				log.Warnf("failed to remove the mirror of stale capture %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// handleCaptures serves /captures: POST starts a capture from a
+// CaptureRequest, GET lists the captures or returns ?id= and DELETE stops
+// ?id= and removes its file.
+func (d *Driver) handleCaptures(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		var req CaptureRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(req.EndpointID) < truncatedIDLen {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("endpoint id %q is shorter than %d characters", req.EndpointID, truncatedIDLen))
+			return
+		}
+		info, err := d.startCapture(req)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	case "GET":
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			writeJSON(w, http.StatusOK, listCaptures())
+			return
+		}
+		_, info, err := captureInfo(id)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	case "DELETE":
+		if err := deleteCapture(r.URL.Query().Get("id")); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+	}
+}
+
+// handleCaptureFile serves /captures/file: GET ?id= returns the pcap file
+// of a capture, what it holds so far while it is running.
+func (d *Driver) handleCaptureFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	c, info, err := captureInfo(r.URL.Query().Get("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	f, err := os.Open(c.file)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("capture %s has no file yet", info.ID))
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/vnd.tcpdump.pcap")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.ID+".pcap"))
+	http.ServeContent(w, r, info.ID+".pcap", info.Started, f)
+}
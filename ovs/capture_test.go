@@ -0,0 +1,23 @@
+package ovs
+
+import "testing"
+
+// TestNextCaptureID checks that a new capture never takes the id of a
+// running capture or of a mirror port an earlier run left.
+func TestNextCaptureID(t *testing.T) {
+	saved, savedSeq, savedCaptures := ovsdbCache, captureSeq, captures
+	defer func() { ovsdbCache, captureSeq, captures = saved, savedSeq, savedCaptures }()
+	ovsdbCache = newTableCache()
+	captures = map[string]*packetCapture{"pcap00000001": {}}
+	ovsdbCache.update(rowUpdate("Port", "p1", nil, map[string]interface{}{"name": "pcap00000002"}))
+	captureSeq = 0
+
+	if id := nextCaptureID(); id != "pcap00000003" {
+		t.Errorf("next capture id is %s, want pcap00000003", id)
+	}
+	// the sequence wraps within IFNAMSIZ
+	captureSeq = 0xffffffff
+	if id := nextCaptureID(); id != "pcap00000000" {
+		t.Errorf("capture id after the last is %s, want pcap00000000", id)
+	}
+}
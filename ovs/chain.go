@@ -0,0 +1,187 @@
+package ovs
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+const (
+	// chainOption lists, in order, the containers that off-subnet traffic of
+	// a network passes through before it leaves via the uplink, e.g. dpi,fw
+	chainOption = "linker.net.ovs.chain"
+
+	chainIngressPriority = 240
+	chainHopPriority     = 250
+	chainCheckInterval   = 5 * time.Second
+
+	// chainMarkPriority marks, in the port security table, IP packets from
+	// the hops and the bridge's local port with their stage in the chain,
+	// in reg1, then sends them through the table again. Stage 0 is traffic
+	// that is not in the chain yet, stage i+1 comes out of hop i and
+	// the last stage comes from the local port.
+	chainMarkPriority = 60000
+)
+
+// serviceChain steers traffic of a network through a list of containers.
+// Hops that are not attached or whose link is down are bypassed.
+type serviceChain struct {
+	networkID  string
+	bridgeName string
+	hops       []string
+	path       []int
+	quit       chan bool
+}
+
+// getChain returns the container names of the service chain of a network.
+func getChain(r *dknet.CreateNetworkRequest) []string {
+	if r.Options == nil {
+		return nil
+	}
+	option, ok := r.Options[optionKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	value, _ := option[chainOption].(string)
+	var hops []string
+	for _, hop := range strings.Split(value, ",") {
+		if hop = strings.TrimSpace(hop); hop != "" {
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// startChain begins maintaining the service chain of a network.
+func (d *Driver) startChain(networkID, bridgeName string, hops []string) {
+	chain := &serviceChain{
+		networkID:  networkID,
+		bridgeName: bridgeName,
+		hops:       hops,
+		quit:       make(chan bool),
+	}
+	d.setChain(networkID, chain)
+	go d.runChain(chain)
+}
+
+// stopChain stops maintaining the chain and removes its flows.
+func (d *Driver) stopChain(networkID string) {
+	chain, ok := d.takeChain(networkID)
+	if !ok {
+		return
+	}
+	close(chain.quit)
+	if err := delFlows(chain.bridgeName, flowCookie(networkID)); err != nil {
+		log.Warnf("failed to remove service chain flows of network %s: %v", truncateID(networkID), err)
+	}
+}
+
+func (d *Driver) runChain(chain *serviceChain) {
+	ticker := time.NewTicker(chainCheckInterval)
+	defer ticker.Stop()
+	for {
+		path := d.healthyPath(chain)
+		if !reflect.DeepEqual(path, chain.path) {
+			if err := installChain(chain, path); err != nil {
+				log.Errorf("failed to program service chain of network %s: %v", truncateID(chain.networkID), err)
+			} else {
+				log.Infof("Service chain of network [ %s ] now runs through ofports %v", truncateID(chain.networkID), path)
+				chain.path = path
+			}
+		}
+		select {
+		case <-chain.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthyPath returns the ofports of the hops that can currently forward.
+func (d *Driver) healthyPath(chain *serviceChain) []int {
+	var path []int
+	for _, hop := range chain.hops {
+		ep, err := d.dockerer.containerEndpoint(chain.networkID, hop)
+		if err != nil {
+			log.Debugf("bypassing service chain hop %s: %v", hop, err)
+			continue
+		}
+		port := ovsPortPrefix + truncateID(ep.EndpointID)
+		ofport := ofportForName(port)
+		if ofport < 0 || !linkUp(port) {
+			log.Debugf("bypassing service chain hop %s: port %s is down", hop, port)
+			continue
+		}
+		path = append(path, ofport)
+	}
+	return path
+}
+
+// installChain replaces the flows of the chain with ones for the path. IP
+// traffic sent to the gateway enters the first hop, every hop outputs to the
+// next one and the last hop outputs to the bridge's local port. Traffic from
+// the local port to the containers takes the path in reverse, and leaves the
+// first hop as if it came from the local port. Hops are recognized by the
+// stage the packet was marked with, so what a hop sends on its own is
+// switched like any other container's traffic.
+func installChain(chain *serviceChain, path []int) error {
+	cookie := flowCookie(chain.networkID)
+	if err := delFlows(chain.bridgeName, cookie); err != nil {
+		return err
+	}
+	if len(path) == 0 {
+		return nil
+	}
+	iface, err := net.InterfaceByName(chain.bridgeName)
+	if err != nil {
+		return err
+	}
+	gateway := iface.HardwareAddr
+	localStage := len(path) + 1
+
+	mark := func(inPort string, stage int) string {
+		return fmt.Sprintf("cookie=%s,priority=%d,ip,in_port=%s,reg1=0,actions=load:%d->NXM_NX_REG1[],resubmit(,%d)",
+			cookie, chainMarkPriority, inPort, stage, portSecurityTable)
+	}
+	marks := []string{mark("LOCAL", localStage)}
+	flows := []string{
+		fmt.Sprintf("cookie=%s,priority=%d,ip,reg1=0,dl_dst=%s,actions=output:%d",
+			cookie, chainIngressPriority, gateway, path[0]),
+		fmt.Sprintf("cookie=%s,priority=%d,ip,reg1=%d,dl_src=%s,actions=output:%d",
+			cookie, chainHopPriority, localStage, gateway, path[len(path)-1]),
+	}
+	for i, ofport := range path {
+		marks = append(marks, mark(fmt.Sprintf("%d", ofport), i+1))
+		next := "output:LOCAL"
+		if i+1 < len(path) {
+			next = fmt.Sprintf("output:%d", path[i+1])
+		}
+		// the reply leaves the chain through normal switching, learning
+		// the gateway on the local port rather than on the hop
+		previous := "load:0xfffe->NXM_OF_IN_PORT[],NORMAL"
+		if i > 0 {
+			previous = fmt.Sprintf("output:%d", path[i-1])
+		}
+		flows = append(flows,
+			fmt.Sprintf("cookie=%s,priority=%d,ip,reg1=%d,dl_dst=%s,actions=%s",
+				cookie, chainHopPriority, i+1, gateway, next),
+			fmt.Sprintf("cookie=%s,priority=%d,ip,reg1=%d,dl_src=%s,actions=%s",
+				cookie, chainHopPriority, i+1, gateway, previous))
+	}
+	for _, flow := range marks {
+		if err := addFlow(chain.bridgeName, portSecurityTable, flow); err != nil {
+			return err
+		}
+	}
+	for _, flow := range flows {
+		if err := addFlow(chain.bridgeName, policyTable, flow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
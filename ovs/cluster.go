@@ -0,0 +1,598 @@
+package ovs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+const (
+	scopeLocal  = "local"
+	scopeGlobal = "global"
+
+	// clusterNetworksKey is the directory of the network definitions in
+	// the cluster store
+	clusterNetworksKey = "docker-ovs-plugin/networks/"
+	// clusterEndpointsKey is the directory of the endpoints of overlay
+	// networks, by endpoint id
+	clusterEndpointsKey = "docker-ovs-plugin/endpoints/"
+	// clusterVNIsKey is the directory of the VNIs networks took, each the
+	// id of its network
+	clusterVNIsKey = "docker-ovs-plugin/vnis/"
+	clusterTimeout = 10 * time.Second
+	// a watch waits clusterWatchWait for a change, the store may take up
+	// to clusterWatchTimeout to reply
+	clusterWatchWait    = 60 * time.Second
+	clusterWatchTimeout = 90 * time.Second
+)
+
+// ClusterNetwork is the definition of a global network as the first node
+// to create it saw it. The other nodes build their bridge from it, so the
+// bridge is the same on every node whatever options their docker passed.
+type ClusterNetwork struct {
+	NetworkID string
+	Options   map[string]interface{}
+	IPv4Data  []*dknet.IPAMData
+	// VNI is the VXLAN id of the network on every node, unique in the
+	// store
+	VNI     int
+	Creator string
+	Created time.Time
+}
+
+// clusterStore is the key/value store global networks are shared through.
+type clusterStore interface {
+	// get returns the value of key, nil if it is not set
+	get(key string) ([]byte, error)
+	// create sets key unless it is set already, and reports whether it did
+	create(key string, value []byte) (bool, error)
+	put(key string, value []byte) error
+	delete(key string) error
+	// list returns the values of the keys below prefix by key, and the
+	// index of the store they were read at
+	list(prefix string) (map[string][]byte, uint64, error)
+	// watch waits until a key below prefix changes after index, or for a
+	// while. The caller lists the keys again either way.
+	watch(prefix string, index uint64) error
+}
+
+// clusterBackends builds the store of each scheme from its base URL,
+// http://<host>:<port> or https://<host>:<port>, and the credentials it
+// authenticates with. The watch client has a longer timeout, for the
+// requests that wait.
+var clusterBackends = map[string]func(base, auth string, client, watchClient *http.Client) (clusterStore, error){
+	"etcd": func(base, auth string, client, watchClient *http.Client) (clusterStore, error) {
+		store := &etcdStore{url: base + "/v2/keys/", client: client, watchClient: watchClient}
+		if auth != "" {
+			i := strings.Index(auth, ":")
+			if i <= 0 {
+				return nil, fmt.Errorf("invalid etcd credentials, want <user>:<password>")
+			}
+			store.user, store.password = auth[:i], auth[i+1:]
+		}
+		return store, nil
+	},
+	"consul": func(base, auth string, client, watchClient *http.Client) (clusterStore, error) {
+		return &consulStore{url: base + "/v1/kv/", token: auth, client: client, watchClient: watchClient}, nil
+	},
+}
+
+// ClusterStoreConfig is how the plugin reaches its cluster store. Address
+// is etcd://<host>:<port> for the etcd v2 keys API or
+// consul://<host>:<port> for the consul KV API. The store is reached over
+// TLS when any of Cert, Key or CA is set: the store's certificate must
+// chain to CA, the system roots without one, and Cert and Key are the
+// plugin's client certificate. Auth is <user>:<password> for etcd and an
+// ACL token for consul.
+type ClusterStoreConfig struct {
+	Address string
+	Cert    string
+	Key     string
+	CA      string
+	Auth    string
+}
+
+// newClusterStore returns the store config points at.
+func newClusterStore(config ClusterStoreConfig) (clusterStore, error) {
+	u, err := url.Parse(config.Address)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid cluster store %s, want etcd://<host>:<port> or consul://<host>:<port>", config.Address)
+	}
+	backend, ok := clusterBackends[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown cluster store %s, want etcd or consul", u.Scheme)
+	}
+	base := "http://" + u.Host
+	var transport http.RoundTripper
+	if config.Cert != "" || config.Key != "" || config.CA != "" {
+		tlsConfig, err := clientTLSConfig(config.Cert, config.Key, config.CA)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration of the cluster store: %v", err)
+		}
+		base = "https://" + u.Host
+		transport = &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment}
+	}
+	client := &http.Client{Timeout: clusterTimeout, Transport: transport}
+	watchClient := &http.Client{Timeout: clusterWatchTimeout, Transport: transport}
+	return backend(base, config.Auth, client, watchClient)
+}
+
+// clusterDefinition returns the request a global network is built from,
+// the definition in the cluster store, and the network's VNI. The request
+// becomes the definition if the network has none yet, published is then
+// true and withdrawClusterNetwork undoes it. Without a cluster store
+// docker's own store is trusted, the request is used as is and the VNI is
+// 0.
+func (d *Driver) clusterDefinition(r *dknet.CreateNetworkRequest) (_ *dknet.CreateNetworkRequest, vni int, published bool, err error) {
+	if d.cluster == nil {
+		return r, 0, false, nil
+	}
+	key := clusterNetworksKey + r.NetworkID
+	explicit, err := getVNI(r)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	vni, err = d.clusterVNI(r.NetworkID, explicit)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to take a VNI in the cluster store: %v", err)
+	}
+	host, _ := os.Hostname()
+	value, err := json.Marshal(ClusterNetwork{NetworkID: r.NetworkID, Options: r.Options, IPv4Data: r.IPv4Data, VNI: vni, Creator: host, Created: time.Now().UTC()})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	created, err := d.cluster.create(key, value)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to publish network %s to the cluster store: %v", truncateID(r.NetworkID), err)
+	}
+	if created {
+		log.Infof("Published network [ %s ] to the cluster store with VNI %d", truncateID(r.NetworkID), vni)
+		return r, vni, true, nil
+	}
+	if value, err = d.cluster.get(key); err != nil || value == nil {
+		return nil, 0, false, fmt.Errorf("failed to read network %s from the cluster store: %v", truncateID(r.NetworkID), err)
+	}
+	var network ClusterNetwork
+	if err := json.Unmarshal(value, &network); err != nil {
+		return nil, 0, false, fmt.Errorf("invalid definition of network %s in the cluster store: %v", truncateID(r.NetworkID), err)
+	}
+	stored := &dknet.CreateNetworkRequest{NetworkID: r.NetworkID, Options: network.Options, IPv4Data: network.IPv4Data, IPv6Data: r.IPv6Data}
+	if !sameDefinition(r, stored) {
+		log.Warnf("docker passed other options for network %s than %s created it with, using those of %s", truncateID(r.NetworkID), network.Creator, network.Creator)
+	}
+	if network.VNI != vni {
+		if err := d.cluster.delete(clusterVNIsKey + strconv.Itoa(vni)); err != nil {
+			log.Warnf("failed to release VNI %d in the cluster store: %v", vni, err)
+		}
+	}
+	return stored, network.VNI, false, nil
+}
+
+// withdrawClusterNetwork removes the definition a network that failed to
+// be created published, and releases its VNI, so neither is left taken
+// until collectClusterNetworks runs on a manager.
+func (d *Driver) withdrawClusterNetwork(networkID string, vni int) {
+	if err := d.cluster.delete(clusterNetworksKey + networkID); err != nil {
+		log.Warnf("failed to remove network %s from the cluster store: %v", truncateID(networkID), err)
+	}
+	if err := d.cluster.delete(clusterVNIsKey + strconv.Itoa(vni)); err != nil {
+		log.Warnf("failed to release VNI %d in the cluster store: %v", vni, err)
+	}
+}
+
+// clusterVNI takes the VNI of a new network in the store: explicit if the
+// network sets one, else the one derived from its id or the next free one.
+// A VNI is taken by creating its key, which fails if another network has
+// it, so two nodes creating networks at once never share one. Nodes
+// creating the same network take the same VNI.
+func (d *Driver) clusterVNI(networkID string, explicit int) (int, error) {
+	networks, _, err := d.cluster.list(clusterNetworksKey)
+	if err != nil {
+		return 0, err
+	}
+	// definitions stored before VNIs had keys of their own
+	used := make(map[int]bool)
+	for key, value := range networks {
+		var network ClusterNetwork
+		if json.Unmarshal(value, &network) == nil && strings.TrimPrefix(key, clusterNetworksKey) != networkID {
+			used[network.VNI] = true
+		}
+	}
+	vni := explicit
+	if vni == 0 {
+		vni = networkVNI(networkID)
+	}
+	for tries := 0; tries <= vniMask; tries++ {
+		if vni != 0 && !used[vni] {
+			key := clusterVNIsKey + strconv.Itoa(vni)
+			created, err := d.cluster.create(key, []byte(networkID))
+			if err != nil {
+				return 0, err
+			}
+			if created {
+				return vni, nil
+			}
+			owner, err := d.cluster.get(key)
+			if err != nil {
+				return 0, err
+			}
+			if owner == nil {
+				// released since, take it again
+				continue
+			}
+			if string(owner) == networkID {
+				return vni, nil
+			}
+			used[vni] = true
+		}
+		if explicit != 0 {
+			return 0, fmt.Errorf("VNI %d is taken by another network", explicit)
+		}
+		vni = (vni + 1) & vniMask
+	}
+	return 0, fmt.Errorf("no VNI left")
+}
+
+// sameDefinition compares the options and pools of two requests as JSON,
+// the form the stored one went through.
+func sameDefinition(a, b *dknet.CreateNetworkRequest) bool {
+	var defs [2]interface{}
+	for i, r := range []*dknet.CreateNetworkRequest{a, b} {
+		raw, _ := json.Marshal(ClusterNetwork{Options: r.Options, IPv4Data: r.IPv4Data})
+		json.Unmarshal(raw, &defs[i])
+	}
+	return reflect.DeepEqual(defs[0], defs[1])
+}
+
+// collectClusterNetworks removes the definitions of the networks docker no
+// longer knows, and their endpoints, from the cluster store. Only swarm
+// managers know every network, so other nodes leave the store alone.
+func (d *Driver) collectClusterNetworks(known map[string]bool) {
+	if d.cluster == nil {
+		return
+	}
+	manager, err := d.dockerer.swarmManager()
+	if err != nil || !manager {
+		return
+	}
+	networks, _, err := d.cluster.list(clusterNetworksKey)
+	if err != nil {
+		log.Warnf("skipping cleanup of the cluster store: %v", err)
+		return
+	}
+	for key := range networks {
+		id := strings.TrimPrefix(key, clusterNetworksKey)
+		if known[id] {
+			continue
+		}
+		if err := d.cluster.delete(key); err != nil {
+			log.Warnf("failed to remove network %s from the cluster store: %v", truncateID(id), err)
+			continue
+		}
+		log.Infof("Removed network [ %s ] from the cluster store", truncateID(id))
+	}
+	vnis, _, err := d.cluster.list(clusterVNIsKey)
+	if err != nil {
+		log.Warnf("skipping cleanup of the VNIs in the cluster store: %v", err)
+		return
+	}
+	for key, value := range vnis {
+		if known[string(value)] {
+			continue
+		}
+		if err := d.cluster.delete(key); err != nil {
+			log.Warnf("failed to release VNI %s in the cluster store: %v", strings.TrimPrefix(key, clusterVNIsKey), err)
+		}
+	}
+	endpoints, _, err := d.cluster.list(clusterEndpointsKey)
+	if err != nil {
+		log.Warnf("skipping cleanup of the endpoints in the cluster store: %v", err)
+		return
+	}
+	for key, value := range endpoints {
+		var ep OverlayEndpoint
+		if err := json.Unmarshal(value, &ep); err == nil && known[ep.Network] {
+			continue
+		}
+		if err := d.cluster.delete(key); err != nil {
+			log.Warnf("failed to remove endpoint %s from the cluster store: %v", strings.TrimPrefix(key, clusterEndpointsKey), err)
+		}
+	}
+}
+
+// clusterResponse fails a store reply with any status but the expected.
+func clusterResponse(resp *http.Response, expected ...int) error {
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("cluster store replied %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// clusterIndex reads the store index a reply carries in header.
+func clusterIndex(resp *http.Response, header string) uint64 {
+	index, _ := strconv.ParseUint(resp.Header.Get(header), 10, 64)
+	return index
+}
+
+// watchTimedOut tells a watch the store had nothing to report in time
+// from a failure.
+func watchTimedOut(err error) bool {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	nerr, ok := err.(net.Error)
+	return ok && nerr.Timeout()
+}
+
+// etcdStore talks to the etcd v2 keys API.
+type etcdStore struct {
+	url string
+	// user and password authenticate with etcd, none when empty
+	user        string
+	password    string
+	client      *http.Client
+	watchClient *http.Client
+}
+
+type etcdNode struct {
+	Key   string
+	Value string
+	Dir   bool
+	Nodes []etcdNode
+}
+
+func (c *etcdStore) do(method, key string, query, form url.Values) (*http.Response, error) {
+	return c.doWith(c.client, method, key, query, form)
+}
+
+func (c *etcdStore) doWith(client *http.Client, method, key string, query, form url.Values) (*http.Response, error) {
+	u := c.url + key
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	return client.Do(req)
+}
+
+func (c *etcdStore) get(key string) ([]byte, error) {
+	resp, err := c.do("GET", key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := clusterResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	var res struct{ Node etcdNode }
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return []byte(res.Node.Value), nil
+}
+
+func (c *etcdStore) create(key string, value []byte) (bool, error) {
+	resp, err := c.do("PUT", key, url.Values{"prevExist": {"false"}}, url.Values{"value": {string(value)}})
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return false, nil
+	}
+	if err := clusterResponse(resp, http.StatusOK, http.StatusCreated); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *etcdStore) put(key string, value []byte) error {
+	resp, err := c.do("PUT", key, nil, url.Values{"value": {string(value)}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return clusterResponse(resp, http.StatusOK, http.StatusCreated)
+}
+
+func (c *etcdStore) delete(key string) error {
+	resp, err := c.do("DELETE", key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return clusterResponse(resp, http.StatusOK, http.StatusNotFound)
+}
+
+func (c *etcdStore) list(prefix string) (map[string][]byte, uint64, error) {
+	resp, err := c.do("GET", prefix, url.Values{"recursive": {"true"}}, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	values := make(map[string][]byte)
+	index := clusterIndex(resp, "X-Etcd-Index")
+	if resp.StatusCode == http.StatusNotFound {
+		return values, index, nil
+	}
+	if err := clusterResponse(resp, http.StatusOK); err != nil {
+		return nil, 0, err
+	}
+	var res struct{ Node etcdNode }
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, 0, err
+	}
+	for _, node := range res.Node.Nodes {
+		if !node.Dir {
+			// etcd keys start with a slash
+			values[strings.TrimPrefix(node.Key, "/")] = []byte(node.Value)
+		}
+	}
+	return values, index, nil
+}
+
+func (c *etcdStore) watch(prefix string, index uint64) error {
+	query := url.Values{"wait": {"true"}, "recursive": {"true"}, "waitIndex": {strconv.FormatUint(index+1, 10)}}
+	resp, err := c.doWith(c.watchClient, "GET", prefix, query, nil)
+	if err != nil {
+		if watchTimedOut(err) {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	// etcd only keeps the last 1000 events, an older index is cleared with
+	// a 400 and the keys are listed again
+	return clusterResponse(resp, http.StatusOK, http.StatusBadRequest)
+}
+
+// consulStore talks to the consul KV API.
+type consulStore struct {
+	url string
+	// token is the ACL token of the plugin, none when empty
+	token       string
+	client      *http.Client
+	watchClient *http.Client
+}
+
+func (c *consulStore) do(method, key, query string, body []byte) (*http.Response, error) {
+	return c.doWith(c.client, method, key, query, body)
+}
+
+func (c *consulStore) doWith(client *http.Client, method, key, query string, body []byte) (*http.Response, error) {
+	u := c.url + key
+	if query != "" {
+		u += "?" + query
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	return client.Do(req)
+}
+
+func (c *consulStore) get(key string) ([]byte, error) {
+	resp, err := c.do("GET", key, "raw", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := clusterResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (c *consulStore) create(key string, value []byte) (bool, error) {
+	// a check-and-set index of 0 only sets keys that don't exist
+	resp, err := c.do("PUT", key, "cas=0", value)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if err := clusterResponse(resp, http.StatusOK); err != nil {
+		return false, err
+	}
+	var created bool
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+func (c *consulStore) put(key string, value []byte) error {
+	resp, err := c.do("PUT", key, "", value)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return clusterResponse(resp, http.StatusOK)
+}
+
+func (c *consulStore) delete(key string) error {
+	resp, err := c.do("DELETE", key, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return clusterResponse(resp, http.StatusOK)
+}
+
+func (c *consulStore) list(prefix string) (map[string][]byte, uint64, error) {
+	resp, err := c.do("GET", prefix, "recurse", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	values := make(map[string][]byte)
+	index := clusterIndex(resp, "X-Consul-Index")
+	if resp.StatusCode == http.StatusNotFound {
+		return values, index, nil
+	}
+	if err := clusterResponse(resp, http.StatusOK); err != nil {
+		return nil, 0, err
+	}
+	var pairs []struct {
+		Key   string
+		Value string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, err
+	}
+	for _, pair := range pairs {
+		value, err := base64.StdEncoding.DecodeString(pair.Value)
+		if err != nil {
+			return nil, 0, err
+		}
+		values[pair.Key] = value
+	}
+	return values, index, nil
+}
+
+func (c *consulStore) watch(prefix string, index uint64) error {
+	// a blocking query returns once the index moves past the given one or
+	// the wait is over
+	query := fmt.Sprintf("recurse&index=%d&wait=%s", index, clusterWatchWait)
+	resp, err := c.doWith(c.watchClient, "GET", prefix, query, nil)
+	if err != nil {
+		if watchTimedOut(err) {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	return clusterResponse(resp, http.StatusOK, http.StatusNotFound)
+}
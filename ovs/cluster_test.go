@@ -0,0 +1,59 @@
+package ovs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+// memStore is a cluster store in memory.
+type memStore map[string][]byte
+
+func (m memStore) get(key string) ([]byte, error) { return m[key], nil }
+
+func (m memStore) create(key string, value []byte) (bool, error) {
+	if _, ok := m[key]; ok {
+		return false, nil
+	}
+	m[key] = value
+	return true, nil
+}
+
+func (m memStore) put(key string, value []byte) error { m[key] = value; return nil }
+
+func (m memStore) delete(key string) error { delete(m, key); return nil }
+
+func (m memStore) list(prefix string) (map[string][]byte, uint64, error) {
+	values := make(map[string][]byte)
+	for key, value := range m {
+		if strings.HasPrefix(key, prefix) {
+			values[key] = value
+		}
+	}
+	return values, 0, nil
+}
+
+func (m memStore) watch(prefix string, index uint64) error { return nil }
+
+// TestWithdrawClusterNetwork checks that a node only withdraws the
+// definition and VNI of a network it published itself.
+func TestWithdrawClusterNetwork(t *testing.T) {
+	store := memStore{}
+	d := &Driver{cluster: store}
+	r := &dknet.CreateNetworkRequest{NetworkID: "net-a00000000", Options: map[string]interface{}{}}
+
+	_, vni, published, err := d.clusterDefinition(r)
+	if err != nil || !published || vni == 0 {
+		t.Fatalf("first definition of the network: vni %d, published %v, %v", vni, published, err)
+	}
+	// another node creating the same network finds the definition
+	if _, again, published, err := d.clusterDefinition(r); err != nil || published || again != vni {
+		t.Fatalf("second definition of the network: vni %d, published %v, %v, want vni %d unpublished", again, published, err, vni)
+	}
+
+	d.withdrawClusterNetwork(r.NetworkID, vni)
+	if len(store) != 0 {
+		t.Errorf("store after the network was withdrawn is %v, want it empty", store)
+	}
+}
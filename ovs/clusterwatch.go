@@ -0,0 +1,125 @@
+package ovs
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// clusterRetry is how long watching the cluster store pauses after it
+// failed
+const clusterRetry = 5 * time.Second
+
+// publishEndpoint records an endpoint of an overlay network in the cluster
+// store, for the other hosts to program.
+func (d *Driver) publishEndpoint(ep OverlayEndpoint) {
+	if ep.VTEP == "" {
+		log.Warnf("not publishing endpoint %s, the address of this host is unknown, set --advertise-address", truncateID(ep.EndpointID))
+		return
+	}
+	value, err := json.Marshal(ep)
+	if err != nil {
+		return
+	}
+	if err := d.cluster.put(clusterEndpointsKey+ep.EndpointID, value); err != nil {
+		log.Errorf("failed to publish endpoint %s to the cluster store: %v", truncateID(ep.EndpointID), err)
+		return
+	}
+	log.Debugf("Published endpoint [ %s ] to the cluster store", truncateID(ep.EndpointID))
+}
+
+// unpublishEndpoint removes an endpoint from the cluster store.
+func (d *Driver) unpublishEndpoint(endpointID string) {
+	if err := d.cluster.delete(clusterEndpointsKey + endpointID); err != nil {
+		log.Warnf("failed to remove endpoint %s from the cluster store: %v", truncateID(endpointID), err)
+	}
+}
+
+// watchClusterEndpoints programs the endpoints other hosts publish in the
+// cluster store, and removes them as they go. The endpoints are listed
+// again after every change the watch reports and at least once a minute.
+func (d *Driver) watchClusterEndpoints() {
+	for {
+		values, index, err := d.cluster.list(clusterEndpointsKey)
+		if err != nil {
+			log.Warnf("failed to list the endpoints of the cluster store: %v", err)
+			time.Sleep(clusterRetry)
+			continue
+		}
+		d.syncClusterEndpoints(values)
+		if err := d.cluster.watch(clusterEndpointsKey, index); err != nil {
+			log.Warnf("failed to watch the endpoints of the cluster store: %v", err)
+			time.Sleep(clusterRetry)
+		}
+	}
+}
+
+// syncClusterEndpoints programs the endpoints of other hosts that are new
+// or changed and unprograms those that are gone. Tunnels to hosts left
+// without endpoints are removed, unless node discovery knows the host.
+func (d *Driver) syncClusterEndpoints(values map[string][]byte) {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	self := d.nodeName()
+	current := make(map[string]OverlayEndpoint)
+	for key, value := range values {
+		var ep OverlayEndpoint
+		if err := json.Unmarshal(value, &ep); err != nil {
+			log.Warnf("invalid endpoint %s in the cluster store: %v", strings.TrimPrefix(key, clusterEndpointsKey), err)
+			continue
+		}
+		if ep.Node == self || ep.VTEP == "" {
+			continue
+		}
+		current[key] = ep
+	}
+	for key, old := range d.clusterEndpoints {
+		if ep, ok := current[key]; !ok || ep != old {
+			d.unprogramRemote(old)
+			delete(d.clusterEndpoints, key)
+		}
+	}
+	vteps := make(map[string]bool)
+	for key, ep := range current {
+		vteps[ep.VTEP] = true
+		if _, ok := d.clusterEndpoints[key]; ok {
+			continue
+		}
+		d.programRemote(ep)
+		d.clusterEndpoints[key] = ep
+	}
+	for _, row := range getTableCache("Port") {
+		peer := ovsMapValue(row.Fields["external_ids"], peerKey)
+		if peer == "" || vteps[peer] || d.peers[peer] || !d.ovsdber.rowOwned(row) {
+			continue
+		}
+		vteps[peer] = true
+		if err := d.removePeer(peer); err != nil {
+			log.Warnf("%v", err)
+		}
+	}
+}
+
+// collectClusterEndpoints removes the endpoints this host published that
+// docker no longer knows, those of crashed containers and plugin restarts.
+// live are the truncated ids of docker's endpoints.
+func (d *Driver) collectClusterEndpoints(live map[string]bool) {
+	if d.cluster == nil {
+		return
+	}
+	values, _, err := d.cluster.list(clusterEndpointsKey)
+	if err != nil {
+		log.Warnf("skipping cleanup of the endpoints in the cluster store: %v", err)
+		return
+	}
+	self := d.nodeName()
+	for _, value := range values {
+		var ep OverlayEndpoint
+		if json.Unmarshal(value, &ep) != nil || ep.Node != self || live[truncateID(ep.EndpointID)] {
+			continue
+		}
+		d.unpublishEndpoint(ep.EndpointID)
+	}
+}
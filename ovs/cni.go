@@ -0,0 +1,68 @@
+package ovs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// CNIRequest is what the CNI plugin asks of the daemon on the admin socket
+// for a pod's interface. Address is the CIDR the IPAM plugin assigned, ADD
+// only.
+type CNIRequest struct {
+	Command     string
+	ContainerID string
+	IfName      string
+	Network     NetworkSpec
+	Address     string `json:",omitempty"`
+}
+
+// handleCNI serves /cni: POST wires (ADD), checks (CHECK) or removes (DEL)
+// the interface of a pod through the same core as docker's endpoints.
+func (d *Driver) handleCNI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	var req CNIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.ContainerID == "" || req.IfName == "" || req.Network.Name == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("container id, interface name and network name are required"))
+		return
+	}
+	networkID := RuntimeID(runtimeCNI, req.Network.Name)
+	endpointID := RuntimeID(runtimeCNI, req.ContainerID, req.IfName)
+	switch req.Command {
+	case "ADD":
+		if _, err := d.EnsureNetwork(runtimeCNI, req.Network); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		a, err := d.AttachEndpoint(runtimeCNI, networkID, req.ContainerID, endpointID, req.Address, "")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		log.Infof("Attached pod [ %s ] interface [ %s ] to network [ %s ]", truncateID(req.ContainerID), req.IfName, req.Network.Name)
+		writeJSON(w, http.StatusOK, a)
+	case "CHECK":
+		if portUUIDForName(ovsPortPrefix+truncateID(endpointID)) == "" {
+			writeError(w, http.StatusNotFound, fmt.Errorf("interface %s of pod %s has no port", req.IfName, truncateID(req.ContainerID)))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{})
+	case "DEL":
+		if err := d.DetachEndpoint(networkID, endpointID); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{})
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown CNI command %s", req.Command))
+	}
+}
@@ -0,0 +1,97 @@
+package ovs
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+)
+
+const (
+	// Connection tracking zones are 16 bit. Zone 0 is left to the host's
+	// own iptables conntrack.
+	maxCTZone = 65535
+
+	// ctZoneKey records the conntrack zone of a network on its bridge, so
+	// that flows installed after a restart keep using it.
+	ctZoneKey = "linker-ct-zone"
+)
+
+// allocateCTZone picks the conntrack zone of a new network so that its
+// connections are tracked apart from those of other networks, which may
+// use overlapping subnets. A zone recorded on the network's bridge is kept.
+// Otherwise the zone is derived from the network id, probing for the next
+// free zone on collisions with the zones of the other networks, including
+// those recorded on bridges the driver has no state of.
+func (d *Driver) allocateCTZone(networkID string) int {
+	used := make(map[int]bool)
+	for id, ns := range d.networkStates() {
+		if id != networkID {
+			used[ns.CTZone] = true
+		}
+	}
+	recorded := 0
+	for bridge, id := range ovsdbCache.pluginBridges() {
+		zone, err := bridgeCTZone(bridge)
+		if err != nil {
+			continue
+		}
+		if id == networkID {
+			recorded = zone
+		} else {
+			used[zone] = true
+		}
+	}
+	if recorded != 0 && !used[recorded] {
+		return recorded
+	}
+	h := fnv.New32a()
+	h.Write([]byte(networkID))
+	zone := int(h.Sum32()%maxCTZone) + 1
+	for used[zone] {
+		zone = zone%maxCTZone + 1
+	}
+	return zone
+}
+
+// bridgeCTZone returns the conntrack zone recorded on a bridge.
+func bridgeCTZone(bridgeName string) (int, error) {
+	value := bridgeExternalID(bridgeName, ctZoneKey)
+	if value == "" {
+		return 0, fmt.Errorf("bridge %s records no conntrack zone", bridgeName)
+	}
+	zone, err := strconv.Atoi(value)
+	if err != nil || zone < 1 || zone > maxCTZone {
+		return 0, fmt.Errorf("bridge %s records an invalid conntrack zone %q", bridgeName, value)
+	}
+	return zone, nil
+}
+
+// ctZone returns the conntrack zone of a network for its ct() actions, from
+// its bridge if the driver lost it. It fails if the switch can't track
+// connections on the network's datapath, or if there is no zone, since the
+// connections of the network would be tracked with those of the host.
+func (d *Driver) ctZone(networkID string) (int, error) {
+	bridgeName, err := d.networkBridge(networkID)
+	if err != nil {
+		return 0, err
+	}
+	ns, ok := d.network(networkID)
+	datapath := bridgeDatapath(bridgeName)
+	if ok && ns.Datapath != "" {
+		datapath = ns.Datapath
+	}
+	if err := switchCapabilities().require(featureConntrack, datapath); err != nil {
+		return 0, err
+	}
+	if ok && ns.CTZone != 0 {
+		return ns.CTZone, nil
+	}
+	zone, err := bridgeCTZone(bridgeName)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		d.updateNetwork(ns, func(ns *NetworkState) { ns.CTZone = zone })
+	}
+	return zone, nil
+}
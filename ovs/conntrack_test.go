@@ -0,0 +1,37 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+func TestBridgeCTZone(t *testing.T) {
+	saved := ovsdbCache
+	defer func() { ovsdbCache = saved }()
+	ovsdbCache = newTableCache()
+
+	tests := []struct {
+		zone    string
+		want    int
+		wantErr bool
+	}{
+		{zone: "42", want: 42},
+		{zone: "65535", want: 65535},
+		{zone: "", wantErr: true},
+		{zone: "0", wantErr: true},
+		{zone: "65536", wantErr: true},
+		{zone: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		fields := bridgeFields("ovsbr-a", "net-a")
+		if tt.zone != "" {
+			fields["external_ids"] = libovsdb.OvsMap{GoMap: map[interface{}]interface{}{networkIDKey: "net-a", ctZoneKey: tt.zone}}
+		}
+		ovsdbCache.update(rowUpdate("Bridge", "b1", nil, fields))
+		got, err := bridgeCTZone("ovsbr-a")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("bridgeCTZone with zone %q = %d, %v, want %d, error %v", tt.zone, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
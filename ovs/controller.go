@@ -0,0 +1,391 @@
+package ovs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	controllerTokenHeader     = "X-Controller-Token"
+	controllerTimeout         = 30 * time.Second
+	defaultControllerInterval = time.Minute
+
+	// controllerGatewayKey records the gateway host the controller picked
+	// in the external_ids of the root Open_vSwitch row, anyGatewayHost
+	// stands for every host
+	controllerGatewayKey = "linker-gateway-host"
+	anyGatewayHost       = "*"
+)
+
+// controllerStatus is the outcome of the last exchange with the controller,
+// for /controller
+var controllerStatus struct {
+	sync.Mutex
+	registered time.Time
+	reported   time.Time
+	err        string
+}
+
+// ControllerNetwork is a network of the host as the Linker controller
+// tracks it.
+type ControllerNetwork struct {
+	NetworkID   string
+	NetworkName string `json:",omitempty"`
+	NetworkType string `json:",omitempty"`
+	Mode        string
+	BridgeName  string
+	Node        string `json:",omitempty"`
+}
+
+// ControllerRegistration is what a host registers with the controller,
+// again whenever its networks change.
+type ControllerRegistration struct {
+	Host     string
+	Driver   string
+	Scope    string
+	Address  string `json:",omitempty"`
+	Networks []ControllerNetwork
+}
+
+// BridgeHealth is the state of the bridge of a network.
+type BridgeHealth struct {
+	NetworkID  string
+	BridgeName string
+	Present    bool
+	Up         bool
+}
+
+// GatewayHealth is the state of the gateway service of sgw and pgw
+// networks. Hosted is set on the host that should run it.
+type GatewayHealth struct {
+	Hosted bool
+	Active bool
+}
+
+// ControllerReport is the health a host reports to the controller at every
+// interval.
+type ControllerReport struct {
+	Host    string
+	Time    time.Time
+	Health  Health
+	Bridges []BridgeHealth
+	Gateway GatewayHealth
+}
+
+// ControllerConfig is the configuration the controller pushes to a host,
+// or returns when the host registers.
+type ControllerConfig struct {
+	// GatewayHost is the host that runs the gateway service of sgw and pgw
+	// networks, every host with such a network when empty
+	GatewayHost string
+}
+
+// ControllerState is what /controller returns.
+type ControllerState struct {
+	URL        string
+	Config     ControllerConfig
+	Registered time.Time
+	Reported   time.Time
+	Error      string `json:",omitempty"`
+}
+
+// notifyController registers the host again soon, it never blocks.
+func (d *Driver) notifyController() {
+	if d.controllerURL == "" {
+		return
+	}
+	select {
+	case d.controllerSignal <- struct{}{}:
+	default:
+	}
+}
+
+// runController registers the host with the controller and reports its
+// health at every interval, registering again after network changes.
+func (d *Driver) runController() {
+	ticker := time.NewTicker(d.controllerInterval)
+	for {
+		err := d.registerWithController()
+		if err == nil {
+			err = d.reportToController()
+		}
+		controllerStatus.Lock()
+		controllerStatus.err = ""
+		if err != nil {
+			controllerStatus.err = err.Error()
+		}
+		controllerStatus.Unlock()
+		if err != nil {
+			log.Warnf("failed to update the controller at %s: %v", d.controllerURL, err)
+		}
+		select {
+		case <-d.controllerSignal:
+		case <-ticker.C:
+		}
+	}
+}
+
+// registerWithController registers the host and its networks, and applies
+// the configuration the controller replies with, if any.
+func (d *Driver) registerWithController() error {
+	host, _ := os.Hostname()
+	registration := ControllerRegistration{
+		Host:    host,
+		Driver:  d.name,
+		Scope:   d.scope,
+		Address: d.localAddress,
+	}
+	reconcileMu.Lock()
+	for id, ns := range d.networkStates() {
+		registration.Networks = append(registration.Networks, ControllerNetwork{
+			NetworkID:   id,
+			NetworkName: ns.NetworkName,
+			NetworkType: ns.NetworkType,
+			Mode:        ns.Mode,
+			BridgeName:  ns.BridgeName,
+			Node:        ns.Node,
+		})
+	}
+	reconcileMu.Unlock()
+	var config *ControllerConfig
+	if err := d.controllerRequest("PUT", "/hosts/"+host, registration, &config); err != nil {
+		return err
+	}
+	controllerStatus.Lock()
+	controllerStatus.registered = time.Now()
+	controllerStatus.Unlock()
+	if config != nil {
+		d.applyControllerConfig(*config)
+		return nil
+	}
+	// the controller has no config for the host, the default stands
+	reconcileMu.Lock()
+	synced := d.controllerSynced
+	reconcileMu.Unlock()
+	if !synced {
+		d.applyControllerConfig(ControllerConfig{})
+	}
+	return nil
+}
+
+// reportToController reports the health of the host, its bridges and the
+// gateway service.
+func (d *Driver) reportToController() error {
+	host, _ := os.Hostname()
+	report := ControllerReport{Host: host, Time: time.Now().UTC(), Health: d.health()}
+	reconcileMu.Lock()
+	gateways := false
+	for id, ns := range d.networkStates() {
+		if ns.Node != "" {
+			continue
+		}
+		gateways = gateways || gatewayNetwork(ns)
+		bridge := BridgeHealth{NetworkID: id, BridgeName: ns.BridgeName, Present: getBridgeUUIDForName(ns.BridgeName) != ""}
+		if link, err := netlink.LinkByName(ns.BridgeName); err == nil {
+			bridge.Up = link.Attrs().Flags&net.FlagUp != 0
+		}
+		report.Bridges = append(report.Bridges, bridge)
+	}
+	report.Gateway.Hosted = gateways && d.hostsGateway()
+	reconcileMu.Unlock()
+	report.Gateway.Active = gatewayServiceActive()
+	if err := d.controllerRequest("POST", "/hosts/"+host+"/health", report, nil); err != nil {
+		return err
+	}
+	controllerStatus.Lock()
+	controllerStatus.reported = time.Now()
+	controllerStatus.Unlock()
+	return nil
+}
+
+// controllerRequest sends body as JSON to path below the controller URL
+// and decodes the reply into out, unless the reply is empty.
+func (d *Driver) controllerRequest(method, path string, body, out interface{}) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(d.controllerURL, "/")+path, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(controllerTokenHeader, d.controllerToken)
+	client := &http.Client{
+		Timeout:   controllerTimeout,
+		Transport: &http.Transport{TLSClientConfig: d.controllerTLS},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("controller answered %s", resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("invalid controller reply: %v", err)
+	}
+	return nil
+}
+
+// applyControllerConfig takes the configuration pushed by the controller
+// and records it in OVSDB for the next start. The gateway service is
+// started on the host that now hosts it and stopped on the others.
+func (d *Driver) applyControllerConfig(config ControllerConfig) {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	if d.controllerSynced && config == d.controllerConfig {
+		return
+	}
+	log.Infof("Controller configuration changed from %+v to %+v", d.controllerConfig, config)
+	if err := d.ovsdber.saveGatewayHost(config.GatewayHost); err != nil {
+		log.Warnf("failed to record the gateway host: %v", err)
+	}
+	d.controllerConfig = config
+	d.controllerSynced = true
+	var gateway *NetworkState
+	for _, ns := range d.networkStates() {
+		if ns.Node == "" && gatewayNetwork(ns) {
+			gateway = ns
+		}
+	}
+	if gateway == nil {
+		return
+	}
+	switch {
+	case d.hostsGateway() && !gatewayServiceActive():
+		log.Infof("This host now hosts the gateway, starting %s for bridge [ %s ]", serviceName, gateway.BridgeName)
+		runOvsScript(context.Background(), gateway.BridgeName, gateway.NetworkName, gateway.NetworkType, gateway.FlatBindInterface)
+	case !d.hostsGateway():
+		if gatewayServiceInstalled() {
+			log.Infof("The gateway moved to %s, stopping %s", config.GatewayHost, serviceName)
+			stopOvsService(context.Background())
+		}
+	}
+}
+
+// hostsGateway reports whether this host runs the gateway service of its
+// sgw and pgw networks, with reconcileMu held. A host managed by the
+// controller runs none until it knows the controller's choice, every host
+// would run it otherwise.
+func (d *Driver) hostsGateway() bool {
+	if d.controllerURL != "" && !d.controllerSynced {
+		return false
+	}
+	if d.controllerConfig.GatewayHost == "" {
+		return true
+	}
+	host, _ := os.Hostname()
+	return d.controllerConfig.GatewayHost == host
+}
+
+// loadControllerConfig restores the gateway host the controller picked
+// before the restart, if any.
+func (d *Driver) loadControllerConfig() {
+	for _, row := range getTableCache("Open_vSwitch") {
+		extIDs, ok := row.Fields["external_ids"].(libovsdb.OvsMap)
+		if !ok {
+			continue
+		}
+		host, ok := extIDs.GoMap[controllerGatewayKey].(string)
+		if !ok {
+			continue
+		}
+		if host == anyGatewayHost {
+			host = ""
+		}
+		reconcileMu.Lock()
+		d.controllerConfig = ControllerConfig{GatewayHost: host}
+		d.controllerSynced = true
+		reconcileMu.Unlock()
+		log.Infof("Restored the controller configuration %+v", d.controllerConfig)
+		return
+	}
+	log.Infof("Not running gateway services until the controller at %s is reached", d.controllerURL)
+}
+
+// saveGatewayHost records the gateway host in the root row.
+func (ovsdber *ovsdber) saveGatewayHost(host string) error {
+	if host == "" {
+		host = anyGatewayHost
+	}
+	extIDs, _ := libovsdb.NewOvsMap(map[string]string{controllerGatewayKey: host})
+	return ovsdber.mutateRootExternalIDs(controllerGatewayKey, libovsdb.NewMutation("external_ids", "insert", extIDs))
+}
+
+func gatewayNetwork(ns *NetworkState) bool {
+	return strings.EqualFold(ns.NetworkType, type_sgw) || strings.EqualFold(ns.NetworkType, type_pgw)
+}
+
+// ServeController serves the configuration push API to the controller on
+// a TCP address, over TLS. Requests must carry the controller token, and
+// with a CA the controller must present a certificate it signed.
+func (d *Driver) ServeController(addr string) error {
+	tlsConfig, err := serverTLSConfig(d.controllerCert, d.controllerKey, d.controllerCA)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/controller", d.handleController)
+	server := &http.Server{Addr: addr, Handler: d.checkControllerToken(mux), TLSConfig: tlsConfig}
+	log.Infof("Serving controller API on [ %s ]", addr)
+	return server.ListenAndServeTLS("", "")
+}
+
+func (d *Driver) checkControllerToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hmac.Equal([]byte(r.Header.Get(controllerTokenHeader)), []byte(d.controllerToken)) {
+			writeError(w, http.StatusForbidden, errors.New("invalid controller token"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// handleController serves /controller: GET returns the state of the
+// controller integration, POST applies a ControllerConfig.
+func (d *Driver) handleController(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		state := ControllerState{URL: d.controllerURL}
+		reconcileMu.Lock()
+		state.Config = d.controllerConfig
+		reconcileMu.Unlock()
+		controllerStatus.Lock()
+		state.Registered = controllerStatus.registered
+		state.Reported = controllerStatus.reported
+		state.Error = controllerStatus.err
+		controllerStatus.Unlock()
+		writeJSON(w, http.StatusOK, state)
+	case "POST":
+		var config ControllerConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		d.applyControllerConfig(config)
+		d.notifyController()
+		writeJSON(w, http.StatusOK, config)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+	}
+}
@@ -0,0 +1,101 @@
+package ovs
+
+import (
+	"encoding/json"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// convergeRetry is how bridge and port changes are retried when the
+// connection to ovsdb-server fails under them, long enough to span a
+// restart of ovsdb-server and the reconnect that follows
+var convergeRetry = RetryPolicy{Retries: 5, Backoff: 500 * time.Millisecond, MaxBackoff: 5 * time.Second, Jitter: 0.1}
+
+// converge applies a change until applied reports it is in place. A change
+// already in place, because an earlier attempt committed before its reply
+// was lost or someone else made it, counts as done. Transactions whose
+// outcome is unknown are retried, other failures are returned unless the
+// change turns out to be in place after all, e.g. a conflicting insert.
+func converge(what string, applied func() (bool, error), apply func() error) error {
+	policy := convergeRetry.withDefaults()
+	for attempt := 0; ; attempt++ {
+		done, err := applied()
+		if err == nil {
+			if done {
+				return nil
+			}
+			if err = apply(); err == nil {
+				return nil
+			}
+			if !outcomeUnknown(err) {
+				if done, checkErr := applied(); checkErr == nil && done {
+					return nil
+				}
+				return err
+			}
+		} else if !outcomeUnknown(err) {
+			return err
+		}
+		if !policy.retry(attempt) {
+			return err
+		}
+		log.Warnf("failed to %s, retrying: %v", what, err)
+		time.Sleep(policy.delay(attempt))
+	}
+}
+
+// outcomeUnknown reports whether a transaction failed without an answer
+// from ovsdb-server, so it may or may not have been applied.
+func outcomeUnknown(err error) bool {
+	if err == errNotConnected {
+		return true
+	}
+	txnErr, ok := err.(*TransactionError)
+	return ok && txnErr.Lost
+}
+
+// rowExists asks ovsdb-server whether the table has a row of the name, the
+// cache may not have caught up with a change or may be gone with the
+// connection.
+func (ovsdber *ovsdber) rowExists(table, name string) (bool, error) {
+	selectOp := libovsdb.Operation{
+		Op:      "select",
+		Table:   table,
+		Columns: []string{"_uuid"},
+		Where:   []interface{}{libovsdb.NewCondition("name", "==", name)},
+	}
+	reply, err := ovsdber.transactReply(selectOp)
+	if err != nil {
+		return false, err
+	}
+	return len(reply[0].Rows) > 0, nil
+}
+
+// decodeRow decodes a row a select returned, which comes in wire notation,
+// like the rows of updates.
+func decodeRow(raw map[string]interface{}) (libovsdb.Row, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return libovsdb.Row{}, err
+	}
+	var row libovsdb.Row
+	err = json.Unmarshal(data, &row)
+	return row, err
+}
+
+// created is the applied check of a change creating a named row.
+func (ovsdber *ovsdber) created(table, name string) func() (bool, error) {
+	return func() (bool, error) {
+		return ovsdber.rowExists(table, name)
+	}
+}
+
+// deleted is the applied check of a change deleting a named row.
+func (ovsdber *ovsdber) deleted(table, name string) func() (bool, error) {
+	return func() (bool, error) {
+		exists, err := ovsdber.rowExists(table, name)
+		return !exists, err
+	}
+}
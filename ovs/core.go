@@ -0,0 +1,195 @@
+package ovs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+const (
+	// runtimeKey marks the bridges and ports wired for a runtime other
+	// than docker, docker's cleanups leave them to that runtime
+	runtimeKey = "linker-runtime"
+	runtimeCNI = "cni"
+)
+
+// ensureMu keeps concurrent requests for a new network from both creating
+// it
+var ensureMu sync.Mutex
+
+// Attachment is an endpoint wired to the bridge of its network. PeerName
+// is the interface to move into the sandbox, the runtime configures it
+// there with Address, MAC and MTU and routes through Gateway.
+type Attachment struct {
+	NetworkID  string
+	EndpointID string
+	BridgeName string
+	PortName   string
+	PeerName   string
+	Address    string
+	MAC        string
+	Gateway    string `json:",omitempty"`
+	MTU        int
+}
+
+// RuntimeID derives a docker-like id, 64 hex characters, for the networks
+// and endpoints of another runtime from what names them there.
+func RuntimeID(runtime string, names ...string) string {
+	sum := sha256.Sum256([]byte(runtime + "/" + strings.Join(names, "/")))
+	return hex.EncodeToString(sum[:])
+}
+
+// EnsureNetwork creates the network of another runtime unless the driver
+// has it, with the same bridge, uplink, NAT and gateway wiring as a docker
+// network with those options, and returns its id. The network's id derives
+// from its name, the same on every host.
+func (d *Driver) EnsureNetwork(runtime string, spec NetworkSpec) (string, error) {
+	if spec.Name == "" {
+		return "", fmt.Errorf("network name is required")
+	}
+	id := RuntimeID(runtime, spec.Name)
+	ensureMu.Lock()
+	defer ensureMu.Unlock()
+	if _, ok := d.network(id); ok {
+		return id, nil
+	}
+	_, subnet, err := net.ParseCIDR(spec.Subnet)
+	if err != nil {
+		return "", fmt.Errorf("invalid subnet %s of network %s: %v", spec.Subnet, spec.Name, err)
+	}
+	gateway := net.ParseIP(spec.Gateway)
+	if gateway == nil || !subnet.Contains(gateway) {
+		return "", fmt.Errorf("invalid gateway %s of network %s, want an address of %s", spec.Gateway, spec.Name, subnet)
+	}
+	// docker passes the gateway with the prefix length of the pool
+	ones, _ := subnet.Mask.Size()
+	options := map[string]interface{}{networkNameOption: spec.Name}
+	for key, value := range spec.Options {
+		options[key] = value
+	}
+	r := &dknet.CreateNetworkRequest{
+		NetworkID: id,
+		Options:   map[string]interface{}{optionKey: options},
+		IPv4Data:  []*dknet.IPAMData{{Pool: subnet.String(), Gateway: fmt.Sprintf("%s/%d", gateway, ones)}},
+	}
+	if err := d.CreateNetwork(context.Background(), r); err != nil {
+		return "", err
+	}
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	ns, ok := d.network(id)
+	if !ok {
+		return "", fmt.Errorf("network %s was not created", spec.Name)
+	}
+	d.updateNetwork(ns, func(ns *NetworkState) { ns.Runtime = runtime })
+	if ns.Node == "" {
+		if err := d.ovsdber.setRowMap("Bridge", ns.BridgeName, "external_ids", map[string]string{runtimeKey: runtime}); err != nil {
+			log.Warnf("failed to mark bridge %s as %s's: %v", ns.BridgeName, runtime, err)
+		}
+	}
+	log.Infof("Created %s network [ %s ] on bridge [ %s ]", runtime, spec.Name, ns.BridgeName)
+	return id, nil
+}
+
+// AttachEndpoint creates an endpoint of a container of another runtime and
+// wires its veth to the bridge, as CreateEndpoint and Join do for docker.
+// address is the endpoint's CIDR, the MAC derives from it when mac is
+// empty.
+func (d *Driver) AttachEndpoint(runtime, networkID, containerID, endpointID, address, mac string) (*Attachment, error) {
+	ip, _, err := net.ParseCIDR(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint address %s: %v", address, err)
+	}
+	if mac == "" {
+		mac = makeMac(ip)
+	}
+	if err := d.createEndpoint(context.Background(), networkID, endpointID, address, "", mac, nil); err != nil {
+		return nil, err
+	}
+	peerName, gateway, err := d.joinEndpoint(context.Background(), networkID, endpointID)
+	if err != nil {
+		d.deleteEndpoint(context.Background(), endpointID)
+		return nil, err
+	}
+	a := &Attachment{
+		NetworkID:  networkID,
+		EndpointID: endpointID,
+		PortName:   ovsPortPrefix + truncateID(endpointID),
+		PeerName:   peerName,
+		Address:    address,
+		MAC:        mac,
+		Gateway:    gateway,
+	}
+	if ns, ok := d.network(networkID); ok {
+		a.BridgeName = ns.BridgeName
+		a.MTU = ns.MTU
+	}
+	if err := d.ovsdber.setRowMap("Interface", a.PortName, "external_ids", map[string]string{runtimeKey: runtime}); err != nil {
+		log.Warnf("failed to mark port %s as %s's: %v", a.PortName, runtime, err)
+	}
+	if err := d.ovsdber.recordContainer(a.PortName, containerID, endpointID); err != nil {
+		log.Warnf("failed to record container of endpoint %s on its port: %v", truncateID(endpointID), err)
+	}
+	return a, nil
+}
+
+// DetachEndpoint removes an endpoint of another runtime, its port and
+// veth. The port is found in OVSDB by the endpoint id recorded on it, so
+// an endpoint the driver forgot in a restart is still removed. Endpoints
+// that are already gone are not an error, runtimes retry.
+func (d *Driver) DetachEndpoint(networkID, endpointID string) error {
+	row, ok, err := d.ovsdber.endpointInterface(endpointID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		_, known := d.endpoint(endpointID)
+		if _, err := d.networkBridge(networkID); err == nil && known {
+			err = d.leaveEndpoint(context.Background(), networkID, endpointID)
+		} else {
+			portName, _ := row.Fields["name"].(string)
+			err = d.forceCleanEndpoint(endpointID, portName)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	d.deleteEndpoint(context.Background(), endpointID)
+	return nil
+}
+
+// runtimeEndpoints returns the truncated ids of the endpoints wired for
+// other runtimes, live as far as docker's cleanups are concerned.
+func runtimeEndpoints() map[string]bool {
+	live := make(map[string]bool)
+	for _, row := range getTableCache("Interface") {
+		if ovsMapValue(row.Fields["external_ids"], runtimeKey) == "" {
+			continue
+		}
+		if id := ovsMapValue(row.Fields["external_ids"], endpointIDKey); len(id) >= 5 {
+			live[truncateID(id)] = true
+		}
+	}
+	return live
+}
+
+// runtimeNetworks returns the ids of the networks of other runtimes.
+func runtimeNetworks() map[string]bool {
+	known := make(map[string]bool)
+	for _, row := range getTableCache("Bridge") {
+		if ovsMapValue(row.Fields["external_ids"], runtimeKey) == "" {
+			continue
+		}
+		if id := ovsMapValue(row.Fields["external_ids"], networkIDKey); id != "" {
+			known[id] = true
+		}
+	}
+	return known
+}
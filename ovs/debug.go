@@ -0,0 +1,61 @@
+package ovs
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// started is when the plugin started, for the uptime of /debug/runtime
+var started = time.Now()
+
+// RuntimeStats is the state of the Go runtime, served at /debug/runtime.
+type RuntimeStats struct {
+	Uptime       string
+	Goroutines   int
+	GOMAXPROCS   int
+	HeapAlloc    uint64
+	HeapObjects  uint64
+	Sys          uint64
+	NumGC        uint32
+	PauseTotalNs uint64
+}
+
+// ServeDebug serves the pprof profiles at /debug/pprof/ and the runtime
+// state at /debug/runtime on addr until it fails. Profiles show where a
+// slow docker network create spends its time, e.g. with
+// go tool pprof http://<addr>/debug/pprof/profile?seconds=30.
+func (d *Driver) ServeDebug(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/runtime", d.handleRuntime)
+	log.Infof("Serving pprof on %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// handleRuntime serves /debug/runtime.
+func (d *Driver) handleRuntime(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	writeJSON(w, http.StatusOK, RuntimeStats{
+		Uptime:       time.Since(started).String(),
+		Goroutines:   runtime.NumGoroutine(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		PauseTotalNs: mem.PauseTotalNs,
+	})
+}
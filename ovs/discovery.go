@@ -0,0 +1,226 @@
+package ovs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+const (
+	defaultDriverName = "ovs"
+
+	// docker finds sockets in pluginSockDir on its own, plugins listening
+	// anywhere else need a spec file in pluginSpecDir
+	pluginSockDir = "/run/docker/plugins"
+	pluginSpecDir = "/etc/docker/plugins"
+
+	activateRetries = 10
+)
+
+// Serve serves the driver API to docker until it fails or the process is
+// told to stop. listen is empty for a socket named after the driver in
+// docker's plugin directory, a socket name in that directory, or
+// unix:///path or tcp://host:port. The discovery file needed for the
+// address is written once the plugin answers, and removed again on exit.
+// With activate the plugin first runs the activation handshake docker will
+// use against itself and fails if the answer would not get it registered
+// as a network driver. Each profile is served under its own name in
+// docker's plugin directory. A managed plugin serves the socket of its
+// config.json only.
+func (d *Driver) Serve(listen string, activate bool, profiles []Profile) error {
+	if d.managed {
+		// docker only looks for the socket of its config.json
+		if listen == "" {
+			listen = managedSocket
+		}
+		if strings.Contains(listen, "/") {
+			return fmt.Errorf("a managed plugin must listen on a socket in %s, not %s", pluginSockDir, listen)
+		}
+		if len(profiles) > 0 {
+			return fmt.Errorf("a managed plugin serves a single driver, profiles need a socket each")
+		}
+	}
+	names := map[string]bool{d.name: true}
+	for _, p := range profiles {
+		if names[p.Name] {
+			return fmt.Errorf("driver name %s is used twice", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	errc := make(chan error, 1+len(profiles))
+	cleanup, err := serveDriver(d, d.name, d.scope, listen, activate, errc)
+	if err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		files, err := serveDriver(&profileDriver{Driver: d, profile: p}, p.Name, d.scope, "", activate, errc)
+		cleanup = append(cleanup, files...)
+		if err != nil {
+			removeFiles(cleanup)
+			return err
+		}
+	}
+
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		removeFiles(cleanup)
+		log.Infof("Plugin [ %s ] stopped", d.name)
+		os.Exit(0)
+	}()
+
+	err = <-errc
+	removeFiles(cleanup)
+	return err
+}
+
+// serveDriver starts serving one driver name in the background and returns
+// the files to remove on exit. Docker is told the networks of the driver
+// span scope.
+func serveDriver(driver dknet.Driver, name, scope, listen string, activate bool, errc chan<- error) ([]string, error) {
+	proto, addr, err := parseListenAddress(name, listen)
+	if err != nil {
+		return nil, err
+	}
+
+	h := dknet.NewHandler(instrumentedDriver{Driver: driver, name: name, scope: scope})
+	served := make(chan error, 1)
+	go func() {
+		var err error
+		if proto == "tcp" {
+			// dknet writes the spec file of tcp plugins itself
+			err = h.ServeTCP(name, addr)
+		} else {
+			err = h.ServeUnix("root", addr)
+		}
+		served <- err
+		errc <- fmt.Errorf("driver %s: %v", name, err)
+	}()
+
+	var cleanup []string
+	if proto == "tcp" {
+		cleanup = append(cleanup, filepath.Join(pluginSpecDir, name+".spec"))
+	} else {
+		cleanup = append(cleanup, addr)
+	}
+
+	if activate {
+		if err := activateSelf(proto, addr, served); err != nil {
+			return cleanup, err
+		}
+		log.Infof("Plugin [ %s ] answered the activation handshake", name)
+	}
+
+	if proto == "unix" && filepath.Dir(addr) != pluginSockDir {
+		spec, err := writeSpec(name, "unix://"+addr)
+		if err != nil {
+			return cleanup, err
+		}
+		cleanup = append(cleanup, spec)
+	}
+	log.Infof("Serving driver [ %s ] on %s://%s", name, proto, addr)
+	return cleanup, nil
+}
+
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		os.Remove(path)
+	}
+}
+
+// parseListenAddress splits the listen flag into a protocol and an address
+// dknet accepts. A bare file name is a socket in docker's plugin directory.
+func parseListenAddress(name, listen string) (string, string, error) {
+	switch {
+	case listen == "":
+		return "unix", filepath.Join(pluginSockDir, name+".sock"), nil
+	case !strings.Contains(listen, "/"):
+		return "unix", filepath.Join(pluginSockDir, listen), nil
+	case strings.HasPrefix(listen, "unix://"):
+		path := strings.TrimPrefix(listen, "unix://")
+		if !filepath.IsAbs(path) {
+			return "", "", fmt.Errorf("unix socket path %s must be absolute", path)
+		}
+		return "unix", path, nil
+	case strings.HasPrefix(listen, "tcp://"):
+		return "tcp", strings.TrimPrefix(listen, "tcp://"), nil
+	}
+	return "", "", fmt.Errorf("invalid listen address %s, expected unix:///path or tcp://host:port", listen)
+}
+
+// writeSpec writes the discovery file pointing docker at the plugin.
+func writeSpec(name, url string) (string, error) {
+	if err := os.MkdirAll(pluginSpecDir, 0755); err != nil {
+		return "", err
+	}
+	spec := filepath.Join(pluginSpecDir, name+".spec")
+	if err := ioutil.WriteFile(spec, []byte(url), 0644); err != nil {
+		return "", err
+	}
+	log.Infof("Wrote plugin discovery file [ %s ]", spec)
+	return spec, nil
+}
+
+// activateSelf performs the handshake docker runs when it first loads the
+// plugin, retrying while the listener comes up.
+func activateSelf(proto, addr string, errc <-chan error) error {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			Dial: func(_, _ string) (net.Conn, error) {
+				return net.Dial(proto, addr)
+			},
+		},
+	}
+	var err error
+	for i := 0; i < activateRetries; i++ {
+		select {
+		case err := <-errc:
+			return err
+		default:
+		}
+		if err = activate(client); err == nil {
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("activation handshake failed: %v", err)
+}
+
+func activate(client *http.Client) error {
+	req, err := http.NewRequest("POST", "http://plugin/Plugin.Activate", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.docker.plugins.v1.2+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var manifest struct {
+		Implements []string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return err
+	}
+	for _, implements := range manifest.Implements {
+		if implements == "NetworkDriver" {
+			return nil
+		}
+	}
+	return fmt.Errorf("plugin implements %v, not NetworkDriver", manifest.Implements)
+}
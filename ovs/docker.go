@@ -1,7 +1,168 @@
 package ovs
 
-import "github.com/samalba/dockerclient"
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	dockerapi "github.com/docker/docker/client"
+	"github.com/docker/go-connections/sockets"
+)
+
+const (
+	// dockerTimeout bounds a call to the docker API, so a hung daemon fails
+	// the caller instead of blocking it
+	dockerTimeout = 30 * time.Second
+	// defaultDockerAPIVersion is the API of docker 1.12, the oldest daemon
+	// with the network APIs the plugin uses
+	defaultDockerAPIVersion = "1.24"
+)
 
 type dockerer struct {
-	client *dockerclient.DockerClient
+	client *dockerapi.Client
+}
+
+// newDockerClient returns a client of the docker API at endpoint, with TLS
+// if tlsConfig is set.
+func newDockerClient(endpoint, version string, tlsConfig *tls.Config) (*dockerapi.Client, error) {
+	if version == "" {
+		version = defaultDockerAPIVersion
+	}
+	var httpClient *http.Client
+	if tlsConfig != nil {
+		proto, addr, _, err := dockerapi.ParseHost(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		transport := &http.Transport{TLSClientConfig: tlsConfig}
+		if err := sockets.ConfigureTransport(transport, proto, addr); err != nil {
+			return nil, err
+		}
+		httpClient = &http.Client{Transport: transport}
+	}
+	return dockerapi.NewClient(endpoint, version, httpClient, nil)
+}
+
+// dockerContext is the context of a docker API call.
+func dockerContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), dockerTimeout)
+}
+
+func (d dockerer) inspectNetwork(networkID string) (dockertypes.NetworkResource, error) {
+	ctx, cancel := dockerContext()
+	defer cancel()
+	return d.client.NetworkInspect(ctx, networkID)
+}
+
+func (d dockerer) inspectContainer(containerID string) (dockertypes.ContainerJSON, error) {
+	ctx, cancel := dockerContext()
+	defer cancel()
+	return d.client.ContainerInspect(ctx, containerID)
+}
+
+func (d dockerer) listNetworks() ([]dockertypes.NetworkResource, error) {
+	ctx, cancel := dockerContext()
+	defer cancel()
+	return d.client.NetworkList(ctx, dockertypes.NetworkListOptions{})
+}
+
+// createNetwork creates a network and returns its id.
+func (d dockerer) createNetwork(name string, config dockertypes.NetworkCreate) (string, error) {
+	ctx, cancel := dockerContext()
+	defer cancel()
+	resp, err := d.client.NetworkCreate(ctx, name, config)
+	return resp.ID, err
+}
+
+func (d dockerer) removeNetwork(networkID string) error {
+	ctx, cancel := dockerContext()
+	defer cancel()
+	return d.client.NetworkRemove(ctx, networkID)
+}
+
+// swarmManager reports whether the daemon is a manager of a swarm, and so
+// lists every network of the swarm.
+func (d dockerer) swarmManager() (bool, error) {
+	ctx, cancel := dockerContext()
+	defer cancel()
+	info, err := d.client.Info(ctx)
+	return info.Swarm.ControlAvailable, err
+}
+
+// dockerTLSConfig loads the client certificate and key and the CA
+// certificate for a tcp docker endpoint, nil if none is given. Unlike
+// ovsdb-server, the daemon's certificate must name the endpoint's host, as
+// docker's own client requires. Without a CA the system roots are trusted.
+func dockerTLSConfig(endpoint, certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	if u, err := url.Parse(endpoint); err != nil || u.Scheme != "tcp" {
+		return nil, fmt.Errorf("TLS needs a tcp://<host>:<port> docker endpoint, got %s", endpoint)
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("a docker client certificate needs its key and the other way round")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the docker client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the docker CA certificate: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+	}
+	return config, nil
+}
+
+// endpointContainer returns the container attached to a network through the
+// endpoint. It must not be called from a driver callback since docker holds
+// the container lock while it waits for the driver.
+func (d dockerer) endpointContainer(networkID, endpointID string) (dockertypes.ContainerJSON, error) {
+	network, err := d.inspectNetwork(networkID)
+	if err != nil {
+		return dockertypes.ContainerJSON{}, err
+	}
+	for containerID, ep := range network.Containers {
+		if ep.EndpointID == endpointID {
+			return d.inspectContainer(containerID)
+		}
+	}
+	return dockertypes.ContainerJSON{}, fmt.Errorf("no container found for endpoint %s", truncateID(endpointID))
+}
+
+// containerEndpoint returns the endpoint of a container, given by name or id,
+// on a network.
+func (d dockerer) containerEndpoint(networkID, container string) (*dockertypes.EndpointResource, error) {
+	info, err := d.inspectContainer(container)
+	if err != nil {
+		return nil, err
+	}
+	network, err := d.inspectNetwork(networkID)
+	if err != nil {
+		return nil, err
+	}
+	for containerID, ep := range network.Containers {
+		if strings.HasPrefix(info.ID, containerID) || strings.HasPrefix(containerID, info.ID) {
+			return &ep, nil
+		}
+	}
+	return nil, fmt.Errorf("container %s is not attached to network %s", container, truncateID(networkID))
 }
@@ -0,0 +1,229 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// datapathOption selects the datapath of the network's bridge, system
+	// (the kernel) or netdev (userspace, DPDK on OVS-DPDK hosts). sgw and
+	// pgw networks default to netdev, all others to system.
+	datapathOption = "linker.net.ovs.bridge.datapath"
+	// dpdkUplinkOption attaches a NIC bound to DPDK, given by its devargs,
+	// e.g. a PCI address, to a flat mode netdev bridge as its uplink
+	dpdkUplinkOption = "linker.net.ovs.bridge.dpdk_uplink"
+	// vhostUserOption adds a dpdkvhostuser port for the endpoint next to
+	// its veth, for containers running a DPDK application with virtio-user
+	vhostUserOption = "linker.net.ovs.endpoint.vhostuser"
+	// vhostUserClientOption replaces the endpoint's veth by a
+	// dpdkvhostuserclient port. Its value is the socket path, or 1 for
+	// <vhostSocketDir>/<endpoint>.sock, which the container mounts and
+	// creates as the virtio-user server.
+	vhostUserClientOption = "linker.net.ovs.endpoint.vhostuser_client"
+
+	dpdkUplinkPrefix = "dpdk-"
+	vhostUserPrefix  = "vhu"
+	// ovsRunDir holds the vhost-user sockets OVS creates, containers mount
+	// it to reach their socket
+	ovsRunDir = "/var/run/openvswitch"
+	// vhostSocketDir holds the sockets of vhost-user client endpoints
+	vhostSocketDir = "/var/run/linker-vhost"
+)
+
+// getDatapath returns the datapath a network's bridge runs on.
+func getDatapath(r *dknet.CreateNetworkRequest, networkType string) (string, error) {
+	datapath := strings.ToLower(getStringOption(r, datapathOption))
+	switch datapath {
+	case "":
+		return defaultDatapath(networkType), nil
+	case datapathKernel, datapathUserspace:
+		return datapath, nil
+	}
+	return "", fmt.Errorf("%s must be %s or %s, got %s", datapathOption, datapathKernel, datapathUserspace, datapath)
+}
+
+// defaultDatapath is the datapath of networks that don't ask for one, the
+// userspace one for the sgw and pgw dataplane.
+func defaultDatapath(networkType string) string {
+	if strings.EqualFold(networkType, type_pgw) || strings.EqualFold(networkType, type_sgw) {
+		return datapathUserspace
+	}
+	return datapathKernel
+}
+
+// bridgeDatapath returns the datapath of an existing bridge.
+func bridgeDatapath(bridgeName string) string {
+	_, row, _ := ovsdbCache.bridge(bridgeName)
+	if datapath, _ := row.Fields["datapath_type"].(string); datapath != "" {
+		return datapath
+	}
+	return datapathKernel
+}
+
+// getDPDKUplink returns the devargs of the network's DPDK uplink.
+func getDPDKUplink(r *dknet.CreateNetworkRequest, mode, datapath, bindInterface string) (string, error) {
+	devargs := getStringOption(r, dpdkUplinkOption)
+	if devargs == "" {
+		return "", nil
+	}
+	switch {
+	case datapath != datapathUserspace:
+		return "", fmt.Errorf("%s requires %s=%s", dpdkUplinkOption, datapathOption, datapathUserspace)
+	case mode != modeFlat:
+		return "", fmt.Errorf("%s is only supported in %s mode", dpdkUplinkOption, modeFlat)
+	case bindInterface != "":
+		return "", fmt.Errorf("%s and %s are mutually exclusive", dpdkUplinkOption, bindInterfaceOption)
+	}
+	return devargs, nil
+}
+
+// dpdkUplinkName is the port name of a DPDK uplink, derived from the bridge
+// since a NIC can only be attached once.
+func dpdkUplinkName(bridgeName string) string {
+	name := dpdkUplinkPrefix + strings.TrimPrefix(bridgeName, bridgePrefix)
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	return name
+}
+
+// vhostUserPortName is the port, and socket, name of an endpoint's
+// vhost-user port.
+func vhostUserPortName(endpointID string) string {
+	return vhostUserPrefix + truncateID(endpointID)
+}
+
+// getVhostUser reports whether an endpoint asks for a vhost-user port.
+func getVhostUser(options map[string]interface{}) (bool, error) {
+	n, err := endpointIntOption(options, vhostUserOption)
+	if err != nil {
+		return false, fmt.Errorf("%s must be 0 or 1", vhostUserOption)
+	}
+	return n > 0, nil
+}
+
+// getVhostUserClient returns the socket path of an endpoint that asks for a
+// vhost-user client port instead of a veth, an empty string otherwise.
+func getVhostUserClient(options map[string]interface{}, endpointID string) (string, error) {
+	value := endpointStringOption(options, vhostUserClientOption)
+	switch value {
+	case "", "0", "false":
+		return "", nil
+	case "1", "true":
+		return filepath.Join(vhostSocketDir, truncateID(endpointID)+".sock"), nil
+	}
+	if !filepath.IsAbs(value) {
+		return "", fmt.Errorf("%s must be 1 or an absolute socket path, got %s", vhostUserClientOption, value)
+	}
+	return filepath.Clean(value), nil
+}
+
+// checkVhostUserClient fails endpoint settings that act on the veth, which a
+// vhost-user client endpoint does not have.
+func checkVhostUserClient(ns *NetworkState, es *EndpointState) error {
+	switch {
+	case ns.PortSecurity:
+		return fmt.Errorf("%s is not supported with %s", vhostUserClientOption, portSecurityOption)
+	case ns.ReadinessTimeout > 0:
+		return fmt.Errorf("%s is not supported with %s", vhostUserClientOption, readinessTimeoutOption)
+	case es.IngressRate > 0:
+		return fmt.Errorf("%s is not supported with %s", vhostUserClientOption, ingressRateOption)
+	case es.DSCP > 0:
+		return fmt.Errorf("%s is not supported with DSCP marking", vhostUserClientOption)
+	case es.VhostUser:
+		return fmt.Errorf("%s and %s are mutually exclusive", vhostUserClientOption, vhostUserOption)
+	}
+	return switchCapabilities().require(featureVhostUserClient, ns.Datapath)
+}
+
+// joinVhostUser attaches a vhost-user client endpoint. OVS connects to the
+// socket once the container's application creates it, docker gets no
+// interface to move into the container.
+func (d *Driver) joinVhostUser(ctx context.Context, r *dknet.JoinRequest, es *EndpointState) (*dknet.JoinResponse, error) {
+	if err := os.MkdirAll(filepath.Dir(es.VhostSocket), 0755); err != nil {
+		return nil, err
+	}
+	name := vhostUserPortName(r.EndpointID)
+	options := map[string]string{"vhost-server-path": es.VhostSocket}
+	if err := d.ovsdber.addDPDKPort(es.BridgeName, name, "dpdkvhostuserclient", options); err != nil {
+		log.Errorf("failed to add vhost-user client port of endpoint %s: %v", truncateID(r.EndpointID), err)
+		return nil, err
+	}
+	if err := d.recordEndpoint(name, r.NetworkID, r.EndpointID, ""); err != nil {
+		log.Warnf("failed to record endpoint %s on its port: %v", truncateID(r.EndpointID), err)
+	}
+	gatewayIP, err := getIPByInterface(es.BridgeName)
+	if err != nil {
+		d.ovsdber.deletePort(ctx, es.BridgeName, name)
+		return nil, err
+	}
+	if es.FloatingIP != "" {
+		if err := d.bindFloatingIP(ctx, r.EndpointID, es); err != nil {
+			d.ovsdber.deletePort(ctx, es.BridgeName, name)
+			return nil, err
+		}
+	}
+	log.Infof("Endpoint [ %s ] uses vhost-user socket [ %s ]", truncateID(r.EndpointID), es.VhostSocket)
+	return &dknet.JoinResponse{Gateway: gatewayIP}, nil
+}
+
+// addDPDKPort adds a port of a DPDK interface type to a bridge, with the
+// interface options given.
+func (ovsdber *ovsdber) addDPDKPort(bridgeName, portName, ifaceType string, options map[string]string) error {
+	intf := map[string]interface{}{
+		"name": portName,
+		"type": ifaceType,
+	}
+	if len(options) > 0 {
+		ifaceOptions, _ := libovsdb.NewOvsMap(options)
+		intf["options"] = ifaceOptions
+	}
+	port := map[string]interface{}{
+		"name":         portName,
+		"interfaces":   libovsdb.UUID{GoUuid: "intf"},
+		"external_ids": ovsdber.ownerExternalIDs(),
+	}
+	portSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: "port"}})
+	operations := []libovsdb.Operation{
+		{Op: "insert", Table: "Interface", Row: intf, UUIDName: "intf"},
+		{Op: "insert", Table: "Port", Row: port, UUIDName: "port"},
+		{
+			Op:        "mutate",
+			Table:     "Bridge",
+			Mutations: []interface{}{libovsdb.NewMutation("ports", "insert", portSet)},
+			Where:     []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+		},
+	}
+	if err := ovsdber.transact(operations...); err != nil {
+		return err
+	}
+	log.Infof("Added %s port [ %s ] to bridge [ %s ]", ifaceType, portName, bridgeName)
+	return nil
+}
+
+// addDPDKUplink attaches the DPDK NIC of a network to its bridge.
+func (ovsdber *ovsdber) addDPDKUplink(bridgeName, devargs string) (string, error) {
+	name := dpdkUplinkName(bridgeName)
+	if portUUIDForName(name) != "" {
+		return name, nil
+	}
+	return name, ovsdber.addDPDKPort(bridgeName, name, "dpdk", map[string]string{"dpdk-devargs": devargs})
+}
+
+// addVhostUserPort adds the vhost-user port of an endpoint, OVS creates its
+// socket in ovsRunDir.
+func (ovsdber *ovsdber) addVhostUserPort(bridgeName, endpointID string) (string, error) {
+	name := vhostUserPortName(endpointID)
+	if err := ovsdber.addDPDKPort(bridgeName, name, "dpdkvhostuser", nil); err != nil {
+		return "", err
+	}
+	return ovsRunDir + "/" + name, nil
+}
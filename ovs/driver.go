@@ -1,25 +1,24 @@
 package ovs
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
 	// "github.com/docker/libnetwork/iptables"
-	"github.com/gopher-net/dknet"
-	"github.com/samalba/dockerclient"
-	"github.com/socketplane/libovsdb"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
 	"github.com/vishvananda/netlink"
 )
 
 const (
-	defaultRoute     = "0.0.0.0/0"
-	ovsPortPrefix    = "ovs-veth0-"
-	bridgePrefix     = "ovsbr-"
-	containerEthName = "eth"
+	defaultRoute = "0.0.0.0/0"
 
 	optionKey = "com.docker.network.generic"
 
@@ -29,16 +28,36 @@ const (
 	bindInterfaceOption = "linker.net.ovs.bridge.bind_interface"
 	typeOption          = "linker.net.ovs.bridge.type" //"sgw" or "pgw"
 	networkNameOption   = "linker.net.ovs.network.name"
-
-	// portMappingKey = "com.docker.network.portmap"
+	vlanOption          = "linker.net.ovs.bridge.vlan"
+	svlanOption         = "linker.net.ovs.bridge.svlan"
+	pcpOption           = "linker.net.ovs.bridge.vlan_pcp"
+	floatingIPOption    = "linker.net.ovs.endpoint.floating_ip"
+	portSecurityOption  = "linker.net.ovs.bridge.port_security"
+	iccOption           = "linker.net.ovs.bridge.enable_icc"
+	mcastSnoopingOption = "linker.net.ovs.bridge.mcast_snooping"
+	failModeOption      = "linker.net.ovs.bridge.fail_mode"
+	internalPortsOption = "linker.net.ovs.bridge.internal_ports"
+	proxyARPOption      = "linker.net.ovs.bridge.proxy_arp"
+	// Seconds a learned MAC is kept and how many MACs the bridge learns,
+	// the switch defaults to 300 and 2048
+	macAgingTimeOption = "linker.net.ovs.bridge.mac_aging_time"
+	macTableSizeOption = "linker.net.ovs.bridge.mac_table_size"
+	// MAC of the bridge interface, kept across bridge re-creation so that
+	// upstream ARP entries of the gateway stay valid. "gateway" derives
+	// it from the gateway address.
+	hwaddrOption = "linker.net.ovs.bridge.hwaddr"
+
+	portMappingKey = "com.docker.network.portmap"
 
 	modeNAT  = "nat"
 	modeFlat = "flat"
 	type_sgw = "sgw"
 	type_pgw = "pgw"
 
-	defaultMTU  = 1500
-	defaultMode = modeNAT
+	failModeSecure     = "secure"
+	failModeStandalone = "standalone"
+
+	defaultDockerEndpoint = "unix:///var/run/docker.sock"
 )
 
 var (
@@ -48,14 +67,219 @@ var (
 	}
 )
 
+// Names and defaults NewDriver may change from the config, they are fixed
+// once the driver serves docker.
+var (
+	ovsPortPrefix    = "ovs-veth0-"
+	bridgePrefix     = "ovsbr-"
+	containerEthName = "eth"
+	defaultMTU       = 1500
+	defaultMode      = modeNAT
+	// gatewayScript sets up sgw and pgw networks, run by the gateway
+	// service
+	gatewayScript = "/usr/sbin/ovsopt.sh"
+)
+
 type Driver struct {
 	dknet.Driver
 	dockerer
 	ovsdber
-	networks map[string]*NetworkState
+	// stateMu guards the networks, endpoints and chains maps, which docker
+	// calls, the reconciler and the admin API use concurrently. Read and
+	// write them through the accessors of state.go only.
+	stateMu   sync.RWMutex
+	networks  map[string]*NetworkState
+	endpoints map[string]*EndpointState
+	chains    map[string]*serviceChain
+	nodes     map[string]*ovsNode
+	firewall  firewaller
+	name      string
+	// managed is set when docker runs the plugin as a managed (v2) plugin
+	managed bool
+	// standbyPeer is the replication address of the warm standby
+	standbyPeer       string
+	replicationSecret string
+	replicationCert   string
+	replicationKey    string
+	replicationCA     string
+	// replicationTLS is the client config pushes to the standby use
+	replicationTLS   *tls.Config
+	replicaSignal    chan struct{}
+	replicaNetworks  map[string]*NetworkState
+	replicaEndpoints map[string]*EndpointState
+	// visibilityObject is the eBPF object attached to endpoint veths
+	visibilityObject string
+	auditInterval    time.Duration
+	auditEndpoint    string
+	auditKey         string
+	// gcInterval is how often stale network records are removed
+	gcInterval time.Duration
+	// scope is what docker is told the driver's networks span, local or
+	// global, cluster where the definitions of global networks are shared
+	scope   string
+	cluster clusterStore
+	// peers are the addresses of the other nodes of the cluster, learned
+	// from docker's node discovery, localAddress is the one of this node
+	peers        map[string]bool
+	localAddress string
+	// clusterEndpoints are the endpoints of other hosts in the cluster
+	// store by key, guarded by reconcileMu
+	clusterEndpoints map[string]OverlayEndpoint
+	// gossip spreads the endpoints of overlay networks between hosts
+	gossip *gossiper
+	// controllerURL is the Linker controller the host registers with,
+	// controllerConfig what it pushed, guarded by reconcileMu
+	controllerURL      string
+	controllerToken    string
+	controllerInterval time.Duration
+	controllerSignal   chan struct{}
+	controllerConfig   ControllerConfig
+	// controllerSynced is set once the config is known, from the
+	// controller or from OVSDB, guarded by reconcileMu
+	controllerSynced bool
+	controllerCert   string
+	controllerKey    string
+	controllerCA     string
+	controllerTLS    *tls.Config
 	OvsdbNotifier
 }
 
+// Config holds the settings a driver is created with
+type Config struct {
+	// FirewallBackend selects how NAT and filter rules are programmed,
+	// either "iptables" (the default) or "nftables"
+	FirewallBackend string
+	// DriverName is the name docker knows the driver by, "ovs" by default
+	DriverName string
+	// ForceOwnership disables the owner marker checks, so bridges, ports
+	// and links the plugin did not create may be modified and deleted
+	ForceOwnership bool
+	// StandbyPeer is the host:port of the replication API of the warm
+	// standby of a gateway pair, state is replicated to it when set
+	StandbyPeer string
+	// ReplicationSecret must be presented on replication requests
+	ReplicationSecret string
+	// ReplicationCert and ReplicationKey are the certificate the hosts of
+	// the pair present to each other, both signed by ReplicationCA
+	ReplicationCert string
+	ReplicationKey  string
+	ReplicationCA   string
+	// VisibilityObject is a compiled eBPF object attached to the veth of
+	// every endpoint to count its L4 flows, none is attached when empty
+	VisibilityObject string
+	// AuditInterval is how often the host is audited for drift from the
+	// desired state, the report is POSTed to AuditEndpoint and signed
+	// with AuditKey. No audits are scheduled when it is 0.
+	AuditInterval time.Duration
+	AuditEndpoint string
+	AuditKey      string
+	// HWOffload sets other_config:hw-offload so the kernel datapath
+	// offloads flows to NICs that support tc flower
+	HWOffload bool
+	// GCInterval is how often BridgeOpt rows and bridge external_ids of
+	// networks that are gone are removed, 0 never
+	GCInterval time.Duration
+	// OvsdbRetry is how connecting to ovsdb-server on start is retried,
+	// three attempts five seconds apart when empty
+	OvsdbRetry RetryPolicy
+	// OvsdbEndpoint is the address of ovsdb-server, unix:<path>,
+	// tcp:<ip>:<port> or ssl:<ip>:<port>. The unix socket of the distro packages is used
+	// when it is empty and exists, tcp:127.0.0.1:6640 otherwise.
+	OvsdbEndpoint string
+	// OvsdbCert, OvsdbKey and OvsdbCA are the PEM files of the client
+	// certificate, its key and the CA certificate of ssl: endpoints
+	OvsdbCert string
+	OvsdbKey  string
+	OvsdbCA   string
+	// Nodes maps the names networks pick with linker.net.ovs.bridge.node
+	// to the OVSDB endpoints of remote nodes, e.g. gateway appliances
+	Nodes map[string]string
+	// MonitorAll monitors every OVSDB table and column like older
+	// releases, rather than only the columns the plugin reads
+	MonitorAll bool
+	// OvsdbTimeout is how long an OVSDB transaction may take, 10 seconds
+	// when 0
+	OvsdbTimeout time.Duration
+	// BatchWindow is how long port changes wait to share a transaction
+	// with others, 10 milliseconds when 0, negative turns batching off
+	BatchWindow time.Duration
+	// LogOvsdbUpdates logs every OVSDB update in full at debug level,
+	// otherwise only the number of changed rows per table
+	LogOvsdbUpdates bool
+	// AuditLog is the file network lifecycle calls are appended to, off
+	// when empty
+	AuditLog string
+	// OTLPEndpoint is the OpenTelemetry collector driver calls are traced
+	// to, off when empty
+	OTLPEndpoint string
+	// DockerEndpoint is the docker daemon's API, the unix socket if empty
+	DockerEndpoint string
+	// DockerCert, DockerKey and DockerCA are the PEM files of the client
+	// certificate and the CA for a tcp docker endpoint with TLS
+	DockerCert string
+	DockerKey  string
+	DockerCA   string
+	// DockerAPIVersion is the docker API version the plugin speaks, 1.24
+	// if empty
+	DockerAPIVersion string
+	// PortPrefix, BridgePrefix and ContainerIfPrefix name the OVS ports of
+	// endpoints, the bridges of networks without a name and the
+	// interfaces in containers
+	PortPrefix        string
+	BridgePrefix      string
+	ContainerIfPrefix string
+	// DefaultMTU and DefaultMode apply to networks that don't set them
+	DefaultMTU  int
+	DefaultMode string
+	// GatewayScript and GatewayService are the script that sets up sgw and
+	// pgw networks and the systemd unit file it runs from
+	GatewayScript  string
+	GatewayService string
+	// Scope is "local" (the default) for networks of the host only, or
+	// "global" for networks docker creates on every node of a swarm or
+	// cluster store that runs a task on them
+	Scope string
+	// ClusterStore is the etcd://<host>:<port> or consul://<host>:<port>
+	// store global networks are defined in, docker's own store when empty.
+	// Hosts also publish the endpoints of their overlay networks there,
+	// reached at AdvertiseAddress. ClusterStoreCert, ClusterStoreKey,
+	// ClusterStoreCA and ClusterStoreAuth secure the store, see
+	// ClusterStoreConfig.
+	ClusterStore     string
+	ClusterStoreCert string
+	ClusterStoreKey  string
+	ClusterStoreCA   string
+	ClusterStoreAuth string
+	AdvertiseAddress string
+	// GossipListen is the [<ip>]:<port> the hosts gossip endpoints of
+	// overlay networks on, no gossip when empty. GossipJoin are hosts
+	// already gossiping and GossipKey the base64 key gossip is encrypted
+	// with.
+	GossipListen string
+	GossipJoin   []string
+	GossipKey    string
+	// ControllerURL is the REST API of the Linker controller the host
+	// registers its networks with and reports health to every
+	// ControllerInterval, a minute when 0. ControllerToken authenticates
+	// the plugin to the controller and the other way round.
+	ControllerURL      string
+	ControllerToken    string
+	ControllerInterval time.Duration
+	// ControllerCert and ControllerKey are the certificate the controller
+	// API is served with. The controller must present a certificate of
+	// ControllerCA, which the plugin trusts the controller's URL with too.
+	ControllerCert string
+	ControllerKey  string
+	ControllerCA   string
+	// Managed is set when docker runs the plugin as a managed (v2) plugin,
+	// confined to its own rootfs. HostRoot is where the host's root is
+	// mounted in that rootfs, the gateway unit file is written below it.
+	Managed  bool
+	HostRoot string
+	// SkipModprobe leaves loading the openvswitch kernel module to the host
+	SkipModprobe bool
+}
+
 // NetworkState is filled in at network creation time
 // it contains state that we wish to keep for each network
 type NetworkState struct {
@@ -67,6 +291,71 @@ type NetworkState struct {
 	FlatBindInterface string
 	NetworkType       string
 	NetworkName       string
+	VLAN              int
+	SVLAN             int
+	PCP               int
+	PortSecurity      bool
+	ICC               bool
+	McastSnooping     bool
+	FailMode          string
+	SpanningTree      string
+	SpanningTreePrio  int
+	MACAgingTime      int
+	MACTableSize      int
+	Hwaddr            string
+	Datapath          string
+	Node              string
+	DPDKUplink        string
+	InternalPorts     bool
+	Switchdev         bool
+	ProxyARP          bool
+	Tenant            string
+	TenantSpec        string
+	Reserved          []ipRange
+	CTZone            int
+	QoSMinRate        int
+	QoSMaxRate        int
+	QoSClasses        []QoSClass
+	QoSPort           string
+	PgwGateway        string
+	Pools             []string
+	ARPEntries        []ARPEntry
+	DSCP              int
+	ReadinessTimeout  time.Duration
+	NetFlowTargets    []string
+	NetFlowTimeout    int
+	SFlow             *SFlowConfig
+	IPFIX             *IPFIXConfig
+	// VNI is the VXLAN id of an overlay network agreed in the cluster
+	// store, derived from its name when 0
+	VNI int
+	// Runtime is the runtime other than docker the network was created
+	// for, e.g. cni
+	Runtime string
+}
+
+// EndpointState is filled in at endpoint creation time
+// it holds what is needed to undo the endpoint's host side configuration
+type EndpointState struct {
+	NetworkID    string
+	BridgeName   string
+	Address      string
+	MacAddress   string
+	PortMappings []portBinding
+	FloatingIP   string
+	Uplink       string
+	IngressRate  int
+	IngressBurst int
+	DSCP         int
+	QoSQueue     int
+	VhostUser    bool
+	VhostSocket  string
+	Representor  string
+	AddressIPv6  string
+	// Allow and AllowEgress are the security group and egress rules of
+	// the endpoint, see allowOption
+	Allow       string
+	AllowEgress string
 }
 
 //CreateNetworkRequest value is :
@@ -82,8 +371,24 @@ type NetworkState struct {
 // IPv4Data:[0xc42011e000]
 // IPv6Data:[]
 //}
-func (d *Driver) CreateNetwork(r *dknet.CreateNetworkRequest) error {
+func (d *Driver) CreateNetwork(ctx context.Context, r *dknet.CreateNetworkRequest) (err error) {
 	log.Debugf("Create network request: %+v", r)
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+
+	r, vni, published, err := d.clusterDefinition(r)
+	if err != nil {
+		return err
+	}
+	// a network this node published but could not create, e.g. one whose
+	// options are refused below, leaves its VNI to other networks
+	if published {
+		defer func() {
+			if err != nil {
+				d.withdrawClusterNetwork(r.NetworkID, vni)
+			}
+		}()
+	}
 
 	mtu, err := getBridgeMTU(r)
 	if err != nil {
@@ -117,6 +422,120 @@ func (d *Driver) CreateNetwork(r *dknet.CreateNetworkRequest) error {
 
 	networktype := getNetworkType(r)
 
+	vlan, err := getVLAN(r, vlanOption)
+	if err != nil {
+		return err
+	}
+
+	svlan, err := getVLAN(r, svlanOption)
+	if err != nil {
+		return err
+	}
+	if svlan != 0 && vlan == 0 {
+		return fmt.Errorf("%s requires %s to be set", svlanOption, vlanOption)
+	}
+
+	pcp, err := getPCP(r)
+	if err != nil {
+		return err
+	}
+	if pcp != 0 && vlan == 0 {
+		return fmt.Errorf("%s requires %s to be set", pcpOption, vlanOption)
+	}
+
+	failMode, err := getFailMode(r)
+	if err != nil {
+		return err
+	}
+
+	spanningTree, spanningTreePrio, err := getSpanningTree(r)
+	if err != nil {
+		return err
+	}
+
+	macAgingTime, err := getPositiveIntOption(r, macAgingTimeOption)
+	if err != nil {
+		return err
+	}
+	macTableSize, err := getPositiveIntOption(r, macTableSizeOption)
+	if err != nil {
+		return err
+	}
+
+	hwaddr, err := getHwaddr(r, gateway)
+	if err != nil {
+		return err
+	}
+
+	datapath, err := getDatapath(r, networktype)
+	if err != nil {
+		return err
+	}
+
+	dpdkUplink, err := getDPDKUplink(r, mode, datapath, bindInterface)
+	if err != nil {
+		return err
+	}
+
+	icc := getICC(r)
+	if !icc && mode == modeFlat && bindInterface == "" {
+		return fmt.Errorf("%s=false requires %s in %s mode", iccOption, bindInterfaceOption, modeFlat)
+	}
+
+	qosMinRate, qosMaxRate, err := getQoS(r)
+	if err != nil {
+		return err
+	}
+	qosClasses, err := getQoSClasses(r)
+	if err != nil {
+		return err
+	}
+
+	reserved, err := getReservedRanges(r)
+	if err != nil {
+		return err
+	}
+
+	dscp, err := getDSCP(r)
+	if err != nil {
+		return err
+	}
+
+	pgwGateway, pools, err := getPgwRoutes(r, networktype, mode)
+	if err != nil {
+		return err
+	}
+
+	readinessTimeout, err := getReadinessTimeout(r)
+	if err != nil {
+		return err
+	}
+
+	netflowTargets, netflowTimeout, err := getNetFlow(r)
+	if err != nil {
+		return err
+	}
+
+	sflow, err := getSFlow(r)
+	if err != nil {
+		return err
+	}
+
+	ipfix, err := getIPFIX(r)
+	if err != nil {
+		return err
+	}
+
+	node, err := d.getNode(r)
+	if err != nil {
+		return err
+	}
+
+	chain := getChain(r)
+	if len(chain) > 0 && mode != modeNAT {
+		return fmt.Errorf("%s is only supported in %s mode", chainOption, modeNAT)
+	}
+
 	errc := checkExecutable(networktype, networkName)
 	if errc != nil {
 		log.Errorf("validate failed, error is %v", errc)
@@ -132,18 +551,126 @@ func (d *Driver) CreateNetwork(r *dknet.CreateNetworkRequest) error {
 		FlatBindInterface: bindInterface,
 		NetworkType:       networktype,
 		NetworkName:       networkName,
+		VLAN:              vlan,
+		SVLAN:             svlan,
+		PCP:               pcp,
+		PortSecurity:      getPortSecurity(r),
+		ICC:               icc,
+		McastSnooping:     getBoolOption(r, mcastSnoopingOption, false),
+		FailMode:          failMode,
+		SpanningTree:      spanningTree,
+		SpanningTreePrio:  spanningTreePrio,
+		MACAgingTime:      macAgingTime,
+		MACTableSize:      macTableSize,
+		Hwaddr:            hwaddr,
+		Datapath:          datapath,
+		Node:              node,
+		DPDKUplink:        dpdkUplink,
+		InternalPorts:     getBoolOption(r, internalPortsOption, false),
+		Switchdev:         getBoolOption(r, switchdevOption, false),
+		ProxyARP:          getBoolOption(r, proxyARPOption, false),
+		Tenant:            getStringOption(r, tenantOption),
+		TenantSpec:        getStringOption(r, tenantSpecOption),
+		Reserved:          reserved,
+		QoSMinRate:        qosMinRate,
+		QoSMaxRate:        qosMaxRate,
+		QoSClasses:        qosClasses,
+		DSCP:              dscp,
+		PgwGateway:        pgwGateway,
+		Pools:             pools,
+		ReadinessTimeout:  readinessTimeout,
+		NetFlowTargets:    netflowTargets,
+		NetFlowTimeout:    netflowTimeout,
+		SFlow:             sflow,
+		IPFIX:             ipfix,
+		VNI:               vni,
 	}
-	d.networks[r.NetworkID] = ns
+	if ns.ProxyARP && mode != modeNAT {
+		return fmt.Errorf("%s is only supported in %s mode", proxyARPOption, modeNAT)
+	}
+	if ns.Switchdev {
+		if mode != modeFlat || ns.InternalPorts {
+			return fmt.Errorf("%s is only supported in %s mode without %s", switchdevOption, modeFlat, internalPortsOption)
+		}
+		if err := checkSwitchdev(bindInterface); err != nil {
+			return err
+		}
+	}
+	if d.overlayNetwork(r.NetworkID, ns) {
+		explicit, err := getVNI(r)
+		if err != nil {
+			return err
+		}
+		if ns.VNI == 0 {
+			ns.VNI = explicit
+		}
+		if ns.VNI, err = d.overlayVNI(r.NetworkID, ns.VNI); err != nil {
+			return err
+		}
+	}
+	if ns.Node != "" {
+		// the node's switch and interfaces are its own
+		if err := checkNodeNetwork(ns, chain); err != nil {
+			return err
+		}
+		return d.createNodeNetwork(r.NetworkID, ns)
+	}
+	if err := checkNetworkCapabilities(ns); err != nil {
+		log.Errorf("network %s is not supported by the switch: %v", r.NetworkID, err)
+		return err
+	}
+	if err := d.checkUplinkIsolation(r.NetworkID, ns); err != nil {
+		log.Errorf("network %s is not isolated: %v", r.NetworkID, err)
+		return err
+	}
+	if err := d.ovsdber.claimBindInterface(r.NetworkID, ns); err != nil {
+		log.Errorf("network %s can't use its bind interface: %v", r.NetworkID, err)
+		return err
+	}
+	ns.CTZone = d.allocateCTZone(r.NetworkID)
+	d.setNetwork(r.NetworkID, ns)
 
 	log.Debugf("Initializing bridge for network %s", r.NetworkID)
 	log.Debugf("Network status is %v", *ns)
-	if err := d.initBridge(r.NetworkID); err != nil {
-		delete(d.networks, r.NetworkID)
+	if err := d.initBridge(ctx, r.NetworkID); err != nil {
+		// initBridge stops at the first failure, the bridge with its vlan
+		// uplinks and the firewall chains may already be set up
+		if err := d.deleteBridge(ctx, bridgeName); err != nil {
+			log.Warnf("failed to remove bridge %s of network %s: %v", bridgeName, truncateID(r.NetworkID), err)
+		}
+		d.firewall.teardownNetwork(ctx, r.NetworkID, bridgeName)
+		d.ovsdber.releaseBindInterface(r.NetworkID, ns)
+		d.forgetNetwork(r.NetworkID)
 		return err
 	}
 
+	if err := d.setupPoolRoutes(ns); err != nil {
+		log.Errorf("failed to route the pools of network %s: %v", r.NetworkID, err)
+		teardownPoolRoutes(ns)
+		if err := d.deleteBridge(ctx, bridgeName); err != nil {
+			log.Warnf("failed to remove bridge %s of network %s: %v", bridgeName, truncateID(r.NetworkID), err)
+		}
+		d.firewall.teardownNetwork(ctx, r.NetworkID, bridgeName)
+		d.ovsdber.releaseBindInterface(r.NetworkID, ns)
+		d.forgetNetwork(r.NetworkID)
+		return err
+	}
+	if ns.VNI != 0 {
+		if err := d.ovsdber.setRowMap("Bridge", bridgeName, "external_ids", map[string]string{vniKey: strconv.Itoa(ns.VNI)}); err != nil {
+			log.Warnf("failed to record the VNI of network %s on its bridge: %v", truncateID(r.NetworkID), err)
+		}
+	}
+	d.connectPeers(ctx, r.NetworkID, ns)
+	d.programOverlay(r.NetworkID, ns)
+
+	if len(chain) > 0 {
+		d.startChain(r.NetworkID, bridgeName, chain)
+	}
+
 	// d.addBridgeToInterface(bridgeName, bindInterface)
 
+	d.replicate()
+	d.notifyController()
 	return nil
 }
 
@@ -158,7 +685,7 @@ func checkExecutable(networkType, networkName string) error {
 		return errors.New("options must specify network name for sgw or pgw type")
 	}
 
-	command := "ps -ef | grep /usr/sbin/ovsopt.sh | grep -v grep | wc -l"
+	command := "ps -ef | grep " + gatewayScript + " | grep -v grep | wc -l"
 	ExecCommandWithComplete(command)
 	return nil
 }
@@ -173,140 +700,562 @@ func checkExecutable(networkType, networkName string) error {
 // 	return
 // }
 
-func (d *Driver) DeleteNetwork(r *dknet.DeleteNetworkRequest) error {
+func (d *Driver) DeleteNetwork(ctx context.Context, r *dknet.DeleteNetworkRequest) error {
 	log.Debugf("Delete network request: %+v", r)
-	// bridgeName := bridgePrefix + truncateID(r.NetworkID)
-	bridgeName, errg := d.ovsdber.getBridgeNameByNetworkId(r.NetworkID)
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	if ns, ok := d.network(r.NetworkID); ok && ns.Node != "" {
+		return d.deleteNodeNetwork(r.NetworkID, ns)
+	}
+	bridgeName, errg := d.networkBridge(r.NetworkID)
 	if errg != nil {
 		log.Errorf("failed to get bridgeName by networkid %v", errg)
 		return errg
 	}
+	if ns, ok := d.network(r.NetworkID); ok && ns.QoSPort != "" {
+		if err := d.ovsdber.clearPortQoS(ns.QoSPort); err != nil {
+			log.Warnf("failed to remove QoS of network %s: %v", truncateID(r.NetworkID), err)
+		}
+	}
+	if ns, ok := d.network(r.NetworkID); ok {
+		teardownPoolRoutes(ns)
+	} else if gateway, pools := bridgePools(bridgeName); gateway != "" {
+		// the routes of a network created before a restart
+		teardownPoolRoutes(&NetworkState{BridgeName: bridgeName, PgwGateway: gateway, Pools: pools})
+	}
 	log.Debugf("Deleting Bridge %s", bridgeName)
-	err := d.deleteBridge(bridgeName)
+	err := d.deleteBridge(ctx, bridgeName)
 	if err != nil {
 		log.Errorf("Deleting bridge %s failed: %s", bridgeName, err)
 		return err
 	}
-	delete(d.networks, r.NetworkID)
+	d.stopChain(r.NetworkID)
+	d.firewall.teardownNetwork(ctx, r.NetworkID, bridgeName)
+	if ns, ok := d.network(r.NetworkID); ok {
+		d.ovsdber.releaseBindInterface(r.NetworkID, ns)
+	}
+	d.forgetNetwork(r.NetworkID)
+	d.replicate()
+	d.notifyController()
 	return nil
 }
 
-func (d *Driver) CreateEndpoint(r *dknet.CreateEndpointRequest) error {
-	// log.Debugf("Create endpoint request: %+v", r)
-	// //add filter and nat rule for container here
-	// interfaceobj := *(r.Interface)
-	// containerIP := parseContainerIP(interfaceobj.Address)
-	// hostPort, containerPort := parsePort(ainterface.Options)
-	// log.Infof("hostPort is %s, containerPort is %s", hostPort, containerPort)
-	// if hostPort == "" || containerPort == "" {
-	// 	return nil
-	// } else {
+func (d *Driver) CreateEndpoint(ctx context.Context, r *dknet.CreateEndpointRequest) error {
+	log.Debugf("Create endpoint request: %+v", r)
+	var address, addressIPv6, mac string
+	if r.Interface != nil {
+		address, addressIPv6, mac = r.Interface.Address, r.Interface.AddressIPv6, r.Interface.MacAddress
+	}
+	return d.createEndpoint(ctx, r.NetworkID, r.EndpointID, address, addressIPv6, mac, r.Options)
+}
+
+// createEndpoint records an endpoint of a network and publishes its ports.
+// address and addressIPv6 are CIDRs, endpoints without an address are not
+// the plugin's to wire.
+func (d *Driver) createEndpoint(ctx context.Context, networkID, endpointID, address, addressIPv6, mac string, options map[string]interface{}) error {
+	if ns, ok := d.network(networkID); ok && ns.Node != "" {
+		return fmt.Errorf("network %s is on node %s, containers can't attach to it", truncateID(networkID), ns.Node)
+	}
+	if address == "" {
+		return nil
+	}
+	bindings, err := getPortMappings(options)
+	if err != nil {
+		return err
+	}
+	floatingIP, err := getFloatingIP(options)
+	if err != nil {
+		return err
+	}
+	if floatingIP != "" {
+		if err := d.checkFloatingIP(endpointID, floatingIP); err != nil {
+			return err
+		}
+	}
+
+	ingressRate, ingressBurst, err := getIngressPolicing(options)
+	if err != nil {
+		return err
+	}
+	if ns, ok := d.network(networkID); ok && ingressRate > 0 {
+		if err := switchCapabilities().require(featureIngressPolice, ns.Datapath); err != nil {
+			return err
+		}
+	}
+
+	vhostUser, err := getVhostUser(options)
+	if err != nil {
+		return err
+	}
+
+	vhostSocket, err := getVhostUserClient(options, endpointID)
+	if err != nil {
+		return err
+	}
+	if ns, ok := d.network(networkID); ok && vhostUser {
+		if err := switchCapabilities().require(featureVhostUser, ns.Datapath); err != nil {
+			return err
+		}
+	}
+
+	containerIP, _, err := net.ParseCIDR(address)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint address %s: %v", address, err)
+	}
+	var networkDSCP int
+	var qosClasses []QoSClass
+	if ns, ok := d.network(networkID); ok {
+		if reserved, ok := ns.reservedRange(containerIP); ok {
+			return fmt.Errorf("address %s is reserved (%s) on network %s", containerIP, reserved, truncateID(networkID))
+		}
+		networkDSCP = ns.DSCP
+		qosClasses = ns.QoSClasses
+	}
+	dscp, err := getEndpointDSCP(options, networkDSCP)
+	if err != nil {
+		return err
+	}
+	queue, err := getEndpointQueue(options, qosClasses)
+	if err != nil {
+		return err
+	}
+	allow, allowEgress, err := getSecGroupOptions(options)
+	if err != nil {
+		return err
+	}
+	if ns, ok := d.network(networkID); ok && (allow != "" || allowEgress != "") {
+		if err := switchCapabilities().require(featureConntrack, ns.Datapath); err != nil {
+			return err
+		}
+	}
+	var containerIPv6 string
+	if addressIPv6 != "" {
+		ip, _, err := net.ParseCIDR(addressIPv6)
+		if err != nil {
+			return fmt.Errorf("invalid endpoint address %s: %v", addressIPv6, err)
+		}
+		containerIPv6 = ip.String()
+	}
+	bridgeName, err := d.networkBridge(networkID)
+	if err != nil {
+		log.Errorf("failed to get bridge for network %s, error %v", networkID, err)
+		return err
+	}
+
+	es := &EndpointState{
+		NetworkID:    networkID,
+		BridgeName:   bridgeName,
+		Address:      containerIP.String(),
+		MacAddress:   mac,
+		FloatingIP:   floatingIP,
+		IngressRate:  ingressRate,
+		IngressBurst: ingressBurst,
+		DSCP:         dscp,
+		QoSQueue:     queue,
+		VhostUser:    vhostUser,
+		VhostSocket:  vhostSocket,
+		AddressIPv6:  containerIPv6,
+		Allow:        allow,
+		AllowEgress:  allowEgress,
+	}
+	if ns, ok := d.network(networkID); ok && vhostSocket != "" {
+		if err := checkVhostUserClient(ns, es); err != nil {
+			return err
+		}
+	}
+	d.setEndpoint(endpointID, es)
+	defer d.replicate()
 
-	// }
+	if ns, ok := d.network(networkID); ok && ns.Mode == modeFlat {
+		if len(bindings) > 0 || floatingIP != "" {
+			log.Warnf("ignoring published ports and floating ip for endpoint %s, network %s is in flat mode", truncateID(endpointID), truncateID(networkID))
+		}
+		d.updateEndpoint(es, func(es *EndpointState) { es.FloatingIP = "" })
+		d.announceEndpoint(endpointID, es)
+		return nil
+	}
+
+	for _, b := range bindings {
+		if err := d.firewall.programPortMapping(ctx, true, networkID, bridgeName, es.Address, b); err != nil {
+			log.Errorf("failed to publish port %v for endpoint %s: %v", b, endpointID, err)
+			d.removePortMappings(ctx, es)
+			d.forgetEndpoint(endpointID)
+			return err
+		}
+		d.updateEndpoint(es, func(es *EndpointState) { es.PortMappings = append(es.PortMappings, b) })
+		log.Infof("Published %s port %d on host port %d for endpoint %s", b.Proto, b.Port, b.HostPort, truncateID(endpointID))
+	}
+	if len(bindings) > 0 {
+		if err := d.ovsdber.recordPortMappings(endpointID, es); err != nil {
+			log.Errorf("failed to record the published ports of endpoint %s: %v", truncateID(endpointID), err)
+			d.removePortMappings(ctx, es)
+			d.forgetEndpoint(endpointID)
+			return err
+		}
+	}
 	return nil
 }
 
-func (d *Driver) DeleteEndpoint(r *dknet.DeleteEndpointRequest) error {
+func (d *Driver) DeleteEndpoint(ctx context.Context, r *dknet.DeleteEndpointRequest) error {
 	log.Debugf("Delete endpoint request: %+v", r)
+	d.deleteEndpoint(ctx, r.EndpointID)
 	return nil
 }
 
-func (d *Driver) EndpointInfo(r *dknet.InfoRequest) (*dknet.InfoResponse, error) {
+// deleteEndpoint unpublishes the ports of an endpoint and forgets it. The
+// ports of an endpoint created before a restart are those recorded on its
+// bridge.
+func (d *Driver) deleteEndpoint(ctx context.Context, endpointID string) {
+	es, ok := d.endpoint(endpointID)
+	if !ok {
+		es, ok = recordedEndpoint(endpointID)
+	}
+	if ok {
+		d.removePortMappings(ctx, es)
+		if len(es.PortMappings) > 0 {
+			if err := d.ovsdber.deleteRowMapKeys("Bridge", es.BridgeName, "external_ids", portMappingsKey(endpointID)); err != nil {
+				log.Warnf("failed to clear the published ports of endpoint %s: %v", truncateID(endpointID), err)
+			}
+		}
+		d.forgetEndpoint(endpointID)
+		d.replicate()
+	}
+	d.withdrawEndpoint(endpointID)
+}
+
+// EndpointInfo returns the OVS port of the endpoint and its traffic
+// counters, empty before the endpoint joined a container.
+func (d *Driver) EndpointInfo(ctx context.Context, r *dknet.InfoRequest) (*dknet.InfoResponse, error) {
 	res := &dknet.InfoResponse{
 		Value: make(map[string]string),
 	}
+	row, ok, err := d.ovsdber.endpointInterface(r.EnpointID)
+	if err != nil {
+		log.Warnf("failed to read the port of endpoint %s: %v", truncateID(r.EnpointID), err)
+		return res, nil
+	}
+	if ok {
+		res.Value = interfaceStatistics(row)
+		var mac string
+		if es, ok := d.endpoint(r.EnpointID); ok {
+			mac = es.MacAddress
+		}
+		for key, value := range interfacePort(row, mac) {
+			res.Value[key] = value
+		}
+	}
 	return res, nil
 }
 
-func (d *Driver) Join(r *dknet.JoinRequest) (*dknet.JoinResponse, error) {
-	// create and attach local name to the bridge
+func (d *Driver) Join(ctx context.Context, r *dknet.JoinRequest) (*dknet.JoinResponse, error) {
 	log.Debugf("join request is %v", r)
-	localVethPair := vethPair(truncateID(r.EndpointID))
-	if err := netlink.LinkAdd(localVethPair); err != nil {
-		log.Errorf("failed to create the veth pair named: [ %v ] error: [ %s ] ", localVethPair, err)
-		return nil, err
+	if es, ok := d.endpoint(r.EndpointID); ok && es.VhostSocket != "" {
+		return d.joinVhostUser(ctx, r, es)
 	}
-	// Bring the veth pair up
-	err := netlink.LinkSetUp(localVethPair)
+	peerName, gatewayIP, err := d.joinEndpoint(ctx, r.NetworkID, r.EndpointID)
 	if err != nil {
-		log.Warnf("Error enabling  Veth local iface: [ %v ]", localVethPair)
 		return nil, err
 	}
+	res := &dknet.JoinResponse{
+		InterfaceName: dknet.InterfaceName{
+			SrcName:   peerName,
+			DstPrefix: containerEthName,
+		},
+		Gateway: gatewayIP,
+	}
+	log.Debugf("Join endpoint %s:%s to %s", r.NetworkID, r.EndpointID, r.SandboxKey)
+	return res, nil
+}
+
+// joinEndpoint creates the veth of an endpoint, attaches it to the bridge
+// and programs its flows. It returns the interface to move into the
+// sandbox and the gateway to route through. If a step fails, what the
+// earlier ones did is undone, so docker can retry the join.
+func (d *Driver) joinEndpoint(ctx context.Context, networkID, endpointID string) (ifname string, gateway string, err error) {
+	// create and attach local name to the bridge
+	localVethPair := vethPair(truncateID(endpointID))
+	ns, ok := d.network(networkID)
+	internalPort := ok && ns.InternalPorts
+	switchdev := ok && ns.Switchdev
+	if internalPort {
+		// the internal port itself is moved into the container
+		localVethPair.PeerName = localVethPair.Name
+	} else if !switchdev {
+		_, s := startSpan(ctx, "netlink.LinkAdd")
+		err := netlink.LinkAdd(localVethPair)
+		finishSpan(s, err)
+		if err != nil {
+			log.Errorf("failed to create the veth pair named: [ %v ] error: [ %s ] ", localVethPair, err)
+			return "", "", err
+		}
+		if err := d.ovsdber.markLink(localVethPair.Name); err != nil {
+			log.Errorf("%v", err)
+			netlink.LinkDel(localVethPair)
+			return "", "", err
+		}
+		// Bring the veth pair up
+		_, s = startSpan(ctx, "netlink.LinkSetUp")
+		err = netlink.LinkSetUp(localVethPair)
+		finishSpan(s, err)
+		if err != nil {
+			log.Warnf("Error enabling  Veth local iface: [ %v ]", localVethPair)
+			netlink.LinkDel(localVethPair)
+			return "", "", err
+		}
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if lerr := d.leaveEndpoint(ctx, networkID, endpointID); lerr != nil {
+			log.Warnf("failed to undo the join of endpoint %s: %v", truncateID(endpointID), lerr)
+		}
+	}()
 
-	// bridgeName := bridgePrefix + truncateID(r.NetworkID)
-	bridgeName, err := d.ovsdber.getBridgeNameByNetworkId(r.NetworkID)
+	bridgeName, err := d.networkBridge(networkID)
 	if err != nil {
-		log.Errorf("failed to get bridge for network %s, error %v", r.NetworkID, err)
-		return nil, err
+		log.Errorf("failed to get bridge for network %s, error %v", networkID, err)
+		return "", "", err
+	}
+	var erra error
+	switch {
+	case internalPort:
+		erra = d.ovsdber.addEndpointInternalPort(ctx, bridgeName, localVethPair.Name)
+	case switchdev:
+		// the VF is moved into the container, its representor takes the
+		// place of the veth on the bridge
+		var vf, rep string
+		vf, rep, erra = d.allocateVF(ctx, ns.FlatBindInterface, bridgeName, localVethPair.Name)
+		localVethPair.PeerName = vf
+		if es, ok := d.endpoint(endpointID); ok {
+			d.updateEndpoint(es, func(es *EndpointState) { es.Representor = rep })
+		}
+	default:
+		erra = d.addOvsVethPort(ctx, bridgeName, localVethPair.Name, 0)
 	}
-	erra := d.addOvsVethPort(bridgeName, localVethPair.Name, 0)
 	if erra != nil {
 		log.Errorf("error attaching veth [ %s ] to bridge [ %s ]", localVethPair.Name, bridgeName)
-		return nil, erra
+		return "", "", erra
 	}
 	log.Infof("Attached veth [ %s ] to bridge [ %s ]", localVethPair.Name, bridgeName)
+	if err := d.recordEndpoint(localVethPair.Name, networkID, endpointID, localVethPair.PeerName); err != nil {
+		log.Warnf("failed to record endpoint %s on its port: %v", truncateID(endpointID), err)
+	}
+	if es, ok := d.endpoint(endpointID); ok && es.VhostUser {
+		socket, err := d.ovsdber.addVhostUserPort(bridgeName, endpointID)
+		if err != nil {
+			log.Errorf("failed to add vhost-user port of endpoint %s: %v", truncateID(endpointID), err)
+			return "", "", err
+		}
+		log.Infof("Endpoint [ %s ] vhost-user socket is [ %s ]", truncateID(endpointID), socket)
+	}
+	if es, ok := d.endpoint(endpointID); ok && es.IngressRate > 0 {
+		if err := d.ovsdber.setIngressPolicing(localVethPair.Name, es.IngressRate, es.IngressBurst); err != nil {
+			log.Errorf("failed to rate limit endpoint %s: %v", truncateID(endpointID), err)
+			return "", "", err
+		}
+		log.Infof("Policing [ %s ] at %d kbps", localVethPair.Name, es.IngressRate)
+	}
+	if ns, ok := d.network(networkID); ok && ns.PortSecurity {
+		if err := d.applyPortSecurity(endpointID, bridgeName, localVethPair); err != nil {
+			log.Errorf("failed to apply port security to endpoint %s: %v", truncateID(endpointID), err)
+			return "", "", err
+		}
+	} else if es, ok := d.endpoint(endpointID); ok && es.DSCP > 0 {
+		if err := applyDSCP(endpointID, bridgeName, localVethPair.Name, es.DSCP); err != nil {
+			log.Errorf("failed to mark traffic of endpoint %s: %v", truncateID(endpointID), err)
+			return "", "", err
+		}
+	}
+	if es, ok := d.endpoint(endpointID); ok && es.QoSQueue > 0 {
+		if err := applyQueue(endpointID, bridgeName, localVethPair.Name, es.QoSQueue); err != nil {
+			log.Errorf("failed to queue traffic of endpoint %s: %v", truncateID(endpointID), err)
+			return "", "", err
+		}
+	}
+	if err := d.applyEndpointSecurity(endpointID, bridgeName); err != nil {
+		log.Errorf("%v", err)
+		return "", "", err
+	}
+	go d.applyEndpointPolicies(networkID, endpointID, bridgeName)
+	if d.visibilityObject != "" {
+		// visibility is a debugging aid, the endpoint works without it
+		if err := d.attachVisibility(localVethPair.Name); err != nil {
+			log.Warnf("failed to attach visibility program to endpoint %s: %v", truncateID(endpointID), err)
+		}
+	}
 
 	// SrcName gets renamed to DstPrefix + ID on the container iface
 	gatewayIP, err := getIPByInterface(bridgeName)
 	if err != nil {
 		log.Errorf("error get gateway ip of bridgeName %s", bridgeName)
-		return nil, err
+		return "", "", err
 	}
-	res := &dknet.JoinResponse{
-		InterfaceName: dknet.InterfaceName{
-			SrcName:   localVethPair.PeerName,
-			DstPrefix: containerEthName,
-		},
-		Gateway: gatewayIP,
+	if es, ok := d.endpoint(endpointID); ok && es.FloatingIP != "" {
+		if err := d.bindFloatingIP(ctx, endpointID, es); err != nil {
+			log.Errorf("failed to bind floating ip %s to endpoint %s: %v", es.FloatingIP, truncateID(endpointID), err)
+			return "", "", err
+		}
 	}
-	log.Debugf("Join endpoint %s:%s to %s", r.NetworkID, r.EndpointID, r.SandboxKey)
-	return res, nil
+	if ns, ok := d.network(networkID); ok && ns.ReadinessTimeout > 0 {
+		if err := d.waitEndpointReady(endpointID, bridgeName, localVethPair, ns.ReadinessTimeout); err != nil {
+			log.Errorf("%v", err)
+			return "", "", err
+		}
+	}
+
+	return localVethPair.PeerName, gatewayIP, nil
 }
 
-func (d *Driver) Leave(r *dknet.LeaveRequest) error {
+func (d *Driver) Leave(ctx context.Context, r *dknet.LeaveRequest) error {
 	log.Debugf("Leave request: %+v", r)
-	localVethPair := vethPair(truncateID(r.EndpointID))
-	if err := netlink.LinkDel(localVethPair); err != nil {
-		log.Errorf("unable to delete veth on leave: %s", err)
+	return d.leaveEndpoint(ctx, r.NetworkID, r.EndpointID)
+}
+
+// leaveEndpoint removes the port, veth and flows of an endpoint.
+func (d *Driver) leaveEndpoint(ctx context.Context, networkID, endpointID string) error {
+	localVethPair := vethPair(truncateID(endpointID))
+	if es, ok := d.endpoint(endpointID); ok && es.VhostSocket != "" {
+		d.leaveFloatingIP(ctx, endpointID, vhostUserPortName(endpointID))
+		return d.ovsdber.deletePort(ctx, es.BridgeName, vhostUserPortName(endpointID))
+	}
+	d.leaveFloatingIP(ctx, endpointID, localVethPair.Name)
+	if es, ok := d.endpoint(endpointID); ok {
+		if es.IngressRate > 0 {
+			if err := d.ovsdber.setIngressPolicing(localVethPair.Name, 0, 0); err != nil {
+				log.Warnf("failed to clear rate limit of endpoint %s: %v", truncateID(endpointID), err)
+			}
+		}
+		if es.VhostUser && portUUIDForName(vhostUserPortName(endpointID)) != "" {
+			if err := d.ovsdber.deletePort(ctx, es.BridgeName, vhostUserPortName(endpointID)); err != nil {
+				log.Warnf("failed to delete vhost-user port of endpoint %s: %v", truncateID(endpointID), err)
+			}
+		}
+	}
+	// an internal port docker moved back is removed by OVS with its port,
+	// a representor is renamed back once its port is gone
+	representor := portRepresentor(localVethPair.Name)
+	if es, ok := d.endpoint(endpointID); ok && es.Representor != "" {
+		representor = es.Representor
 	}
-	portID := fmt.Sprintf(ovsPortPrefix + truncateID(r.EndpointID))
-	// bridgeName := d.networks[r.NetworkID].BridgeName
-	// bridgeName := bridgePrefix + truncateID(r.NetworkID)
-	bridgeName, err := d.ovsdber.getBridgeNameByNetworkId(r.NetworkID)
+	if d.visibilityObject != "" {
+		if err := forgetVisibility(localVethPair.Name); err != nil {
+			log.Warnf("failed to remove visibility entries of endpoint %s: %v", truncateID(endpointID), err)
+		}
+	}
+	if !portIsInternal(localVethPair.Name) && representor == "" {
+		if err := d.ovsdber.checkLinkOwner(localVethPair.Name); err != nil {
+			log.Errorf("unable to delete veth on leave: %s", err)
+		} else {
+			_, s := startSpan(ctx, "netlink.LinkDel")
+			err := netlink.LinkDel(localVethPair)
+			finishSpan(s, err)
+			if err != nil {
+				log.Errorf("unable to delete veth on leave: %s", err)
+			}
+		}
+	}
+	portID := ovsPortPrefix + truncateID(endpointID)
+	bridgeName, err := d.networkBridge(networkID)
 	if err != nil {
-		log.Errorf("failed to get bridge for network %s, error %v", r.NetworkID, err)
+		log.Errorf("failed to get bridge for network %s, error %v", networkID, err)
 		return err
 	}
-	errd := d.ovsdber.deletePort(bridgeName, portID)
+	errd := d.ovsdber.deletePort(ctx, bridgeName, portID)
 	if errd != nil {
 		log.Errorf("OVS port [ %s ] delete transaction failed on bridge [ %s ] due to: %s", portID, bridgeName, errd)
 		return errd
 	}
 	log.Infof("Deleted OVS port [ %s ] from bridge [ %s ]", portID, bridgeName)
-	log.Debugf("Leave %s:%s", r.NetworkID, r.EndpointID)
+	if representor != "" {
+		releaseRepresentor(portID, representor)
+	}
+	removeEndpointPolicies(endpointID, bridgeName)
+	forgetContainer(endpointID)
+	log.Debugf("Leave %s:%s", networkID, endpointID)
 	return nil
 }
 
-func NewDriver() (*Driver, error) {
-	docker, err := dockerclient.NewDockerClient("unix:///var/run/docker.sock", nil)
+func NewDriver(config Config) (*Driver, error) {
+	firewall, err := newFirewaller(config.FirewallBackend)
 	if err != nil {
-		return nil, fmt.Errorf("could not connect to docker: %s", err)
+		return nil, err
+	}
+	if config.FirewallBackend == firewallNftables {
+		if err := checkForwardDrops(); err != nil {
+			return nil, err
+		}
 	}
 
-	// initiate the ovsdb manager port binding
-	var ovsdb *libovsdb.OvsdbClient
-	retries := 3
-	for i := 0; i < retries; i++ {
-		ovsdb, err = libovsdb.Connect(localhost, ovsdbPort)
-		if err == nil {
-			break
+	nodes, err := newNodes(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyNaming(config); err != nil {
+		return nil, err
+	}
+	applyManaged(config.Managed, config.HostRoot)
+	loadModule = !config.SkipModprobe
+	// bridges of the userspace datapath do without the module, creating a
+	// kernel datapath bridge fails until it is loaded
+	if err := ensureKernelModule(); err != nil {
+		log.Warnf("%v", err)
+	}
+	scope := config.Scope
+	if scope == "" {
+		scope = scopeLocal
+	}
+	if scope != scopeLocal && scope != scopeGlobal {
+		return nil, fmt.Errorf("unknown scope %s, want %s or %s", scope, scopeLocal, scopeGlobal)
+	}
+	var cluster clusterStore
+	if config.ClusterStore != "" {
+		if scope != scopeGlobal {
+			return nil, fmt.Errorf("a cluster store needs the %s scope", scopeGlobal)
+		}
+		cluster, err = newClusterStore(ClusterStoreConfig{
+			Address: config.ClusterStore,
+			Cert:    config.ClusterStoreCert,
+			Key:     config.ClusterStoreKey,
+			CA:      config.ClusterStoreCA,
+			Auth:    config.ClusterStoreAuth,
+		})
+		if err != nil {
+			return nil, err
 		}
-		log.Errorf("could not connect to openvswitch on port [ %d ]: %s. Retrying in 5 seconds", ovsdbPort, err)
-		time.Sleep(5 * time.Second)
+	}
+	dockerEndpoint := config.DockerEndpoint
+	if dockerEndpoint == "" {
+		dockerEndpoint = defaultDockerEndpoint
+	}
+	dockerTLS, err := dockerTLSConfig(dockerEndpoint, config.DockerCert, config.DockerKey, config.DockerCA)
+	if err != nil {
+		return nil, err
+	}
+	docker, err := newDockerClient(dockerEndpoint, config.DockerAPIVersion, dockerTLS)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to docker: %s", err)
 	}
 
-	if ovsdb == nil {
-		return nil, fmt.Errorf("could not connect to open vswitch")
+	if config.OvsdbTimeout > 0 {
+		transactTimeout = config.OvsdbTimeout
+	}
+	logUpdates = config.LogOvsdbUpdates
+	if config.OTLPEndpoint != "" {
+		if err := startTracing(config.OTLPEndpoint); err != nil {
+			return nil, err
+		}
+	}
+	if config.AuditLog != "" {
+		if auditLog, err = openAuditLog(config.AuditLog); err != nil {
+			return nil, err
+		}
+	}
+	// initiate the ovsdb manager port binding
+	ovsdb, endpoint, tlsConfig, err := connectOvsdb(config)
+	if err != nil {
+		return nil, err
 	}
 
 	d := &Driver{
@@ -314,12 +1263,114 @@ func NewDriver() (*Driver, error) {
 			client: docker,
 		},
 		ovsdber: ovsdber{
-			ovsdb: ovsdb,
+			ovsdb:          ovsdb,
+			forceOwnership: config.ForceOwnership,
+			endpoint:       endpoint,
+			tlsConfig:      tlsConfig,
+			monitorAll:     config.MonitorAll,
 		},
-		networks: make(map[string]*NetworkState),
+		networks:          make(map[string]*NetworkState),
+		endpoints:         make(map[string]*EndpointState),
+		chains:            make(map[string]*serviceChain),
+		nodes:             nodes,
+		firewall:          tracedFirewall{firewall, firewallBackend(config.FirewallBackend)},
+		name:              config.DriverName,
+		managed:           config.Managed,
+		standbyPeer:       config.StandbyPeer,
+		replicationSecret: config.ReplicationSecret,
+		replicationCert:   config.ReplicationCert,
+		replicationKey:    config.ReplicationKey,
+		replicationCA:     config.ReplicationCA,
+		replicaSignal:     make(chan struct{}, 1),
+		replicaNetworks:   make(map[string]*NetworkState),
+		replicaEndpoints:  make(map[string]*EndpointState),
+		visibilityObject:  config.VisibilityObject,
+		auditInterval:     config.AuditInterval,
+		auditEndpoint:     config.AuditEndpoint,
+		auditKey:          config.AuditKey,
+		gcInterval:        config.GCInterval,
+		scope:             scope,
+		cluster:           cluster,
+		peers:             make(map[string]bool),
+		clusterEndpoints:  make(map[string]OverlayEndpoint),
+		controllerURL:     config.ControllerURL,
+		controllerToken:   config.ControllerToken,
+		controllerSignal:  make(chan struct{}, 1),
+		controllerCert:    config.ControllerCert,
+		controllerKey:     config.ControllerKey,
+		controllerCA:      config.ControllerCA,
+	}
+	d.controllerInterval = config.ControllerInterval
+	if d.controllerInterval <= 0 {
+		d.controllerInterval = defaultControllerInterval
+	}
+	if d.name == "" {
+		d.name = defaultDriverName
+	}
+	d.ovsdber.instance = d.name
+	if config.BatchWindow >= 0 {
+		window := config.BatchWindow
+		if window == 0 {
+			window = defaultBatchWindow
+		}
+		d.ovsdber.batcher = newTxnBatcher(&d.ovsdber, window)
+	}
+	for _, node := range d.nodes {
+		node.instance = d.name
 	}
 	// Initialize ovsdb cache at rpc connection setup
-	d.ovsdber.initDBCache()
+	if err := d.ovsdber.initDBCache(); err != nil {
+		return nil, err
+	}
+	if config.HWOffload {
+		if err := d.ovsdber.setHWOffload(true); err != nil {
+			log.Errorf("failed to enable hardware offload: %v", err)
+		}
+	}
+	if config.AdvertiseAddress != "" {
+		if net.ParseIP(config.AdvertiseAddress) == nil {
+			return nil, fmt.Errorf("invalid advertise address %s", config.AdvertiseAddress)
+		}
+		d.localAddress = config.AdvertiseAddress
+	}
+	if config.GossipListen != "" {
+		if scope != scopeGlobal {
+			return nil, fmt.Errorf("gossip needs the %s scope", scopeGlobal)
+		}
+		if err := d.startGossip(config.GossipListen, config.GossipJoin, config.GossipKey); err != nil {
+			return nil, err
+		}
+	}
+	if d.standbyPeer != "" {
+		if d.replicationTLS, err = d.replicationTLSConfig(false); err != nil {
+			return nil, err
+		}
+	}
+	if d.controllerURL != "" {
+		if d.controllerTLS, err = clientTLSConfig("", "", d.controllerCA); err != nil {
+			return nil, err
+		}
+		d.loadControllerConfig()
+	}
+	d.restoreNodeNetworks()
+	d.collectOrphans()
+	go d.watchContainerEvents()
+	go d.runReconciler()
+	if d.cluster != nil {
+		go d.watchClusterEndpoints()
+	}
+	if d.gcInterval > 0 {
+		go d.runMetadataGC()
+	}
+	if d.standbyPeer != "" {
+		go d.runReplication()
+	}
+	if d.auditInterval > 0 && d.auditEndpoint != "" {
+		go d.runAudits()
+	}
+	if d.controllerURL != "" {
+		go d.runController()
+	}
 	return d, nil
 }
 
@@ -384,40 +1435,18 @@ func getIPByInterface(iname string) (string, error) {
 // }
 // }
 
-// func parseContainerIP(fullip string) string {
-// 	log.Debugf("the full ip is %s", fullip)
-// 	ips := strings.Split(fullip, "/")
-// 	ip := ips[0]
-// 	log.Infof("the requested container ip is %s", ip)
-// 	return ip
-// }
-
-// func parsePort(options map[string]interface{}) (string, string) {
-// 	if len(options) <= 0 {
-// 		log.Infof("no info in options")
-// 		return "", ""
-// 	}
-
-// 	portMappingIn := options[portMappingKey]
-// 	portMapping := portMappingIn.(map[string]string)
-// 	if len(portMapping) <= 0 {
-// 		log.Infof("no port mapping info")
-// 		return "", ""
-// 	}
-
-// 	return portMapping["HostPort"], portMapping["Port"]
-// }
-
 // Create veth pair. Peername is renamed to eth0 in the container
 func vethPair(suffix string) *netlink.Veth {
 	return &netlink.Veth{
 		LinkAttrs: netlink.LinkAttrs{Name: ovsPortPrefix + suffix},
-		PeerName:  "ethc" + suffix,
+		PeerName:  vethPeerPrefix + suffix,
 	}
 }
 
 // Enable a netlink interface
-func interfaceUp(name string) error {
+func interfaceUp(ctx context.Context, name string) (err error) {
+	_, s := startSpan(ctx, "netlink.LinkSetUp")
+	defer func() { finishSpan(s, err) }()
 	iface, err := netlink.LinkByName(name)
 	if err != nil {
 		log.Debugf("Error retrieving a link named [ %s ]", iface.Attrs().Name)
@@ -427,7 +1456,7 @@ func interfaceUp(name string) error {
 }
 
 func truncateID(id string) string {
-	return id[:5]
+	return id[:truncatedIDLen]
 }
 
 func getBridgeMTU(r *dknet.CreateNetworkRequest) (int, error) {
@@ -448,7 +1477,15 @@ func getBridgeName(r *dknet.CreateNetworkRequest, networkname string) (string, e
 	}
 
 	if r.Options != nil {
-		if name, ok := r.Options[bridgeNameOption].(string); ok {
+		name, ok := r.Options[bridgeNameOption].(string)
+		if !ok {
+			// docker network create -o puts driver options in the generic map
+			name = getStringOption(r, bridgeNameOption)
+		}
+		if name != "" {
+			if len(name) > 15 || strings.ContainsAny(name, "/ \t") {
+				return "", fmt.Errorf("%s must be a valid interface name of at most 15 characters, got %q", bridgeNameOption, name)
+			}
 			bridgeName = name
 		}
 	}
@@ -456,10 +1493,26 @@ func getBridgeName(r *dknet.CreateNetworkRequest, networkname string) (string, e
 	return bridgeName, nil
 }
 
+// networkBridge returns the bridge of a network, which may have been given
+// a custom name, from its state or else from OVSDB.
+func (d *Driver) networkBridge(networkID string) (string, error) {
+	if ns, ok := d.network(networkID); ok && ns.BridgeName != "" {
+		return ns.BridgeName, nil
+	}
+	return d.ovsdber.getBridgeNameByNetworkId(networkID)
+}
+
 func getBridgeMode(r *dknet.CreateNetworkRequest) (string, error) {
 	bridgeMode := defaultMode
 	if r.Options != nil {
-		if mode, ok := r.Options[modeOption].(string); ok {
+		mode, ok := r.Options[modeOption].(string)
+		if !ok {
+			// docker network create -o puts driver options in the generic map
+			if option, isMap := r.Options[optionKey].(map[string]interface{}); isMap {
+				mode, ok = option[modeOption].(string)
+			}
+		}
+		if ok {
 			if _, isValid := validModes[mode]; !isValid {
 				return "", fmt.Errorf("%s is not a valid mode", mode)
 			}
@@ -547,3 +1600,255 @@ func getNetworkType(r *dknet.CreateNetworkRequest) string {
 	return ""
 }
 
+
+// getPortMappings decodes the port bindings docker passes for `docker run -p`,
+// e.g. [{"Proto":6,"IP":"","Port":80,"HostIP":"","HostPort":8080}]
+func getPortMappings(options map[string]interface{}) ([]portBinding, error) {
+	raw, ok := options[portMappingKey].([]interface{})
+	if !ok {
+		return nil, nil
+	}
+	var bindings []portBinding
+	for _, entry := range raw {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid port mapping %v", entry)
+		}
+		proto, ok := protocolNames[toInt(m["Proto"])]
+		if !ok {
+			return nil, fmt.Errorf("unsupported protocol in port mapping %v", m)
+		}
+		b := portBinding{
+			Proto:    proto,
+			Port:     toInt(m["Port"]),
+			HostPort: toInt(m["HostPort"]),
+		}
+		if hostIP, ok := m["HostIP"].(string); ok {
+			b.HostIP = hostIP
+		}
+		if b.HostPort == 0 {
+			// docker allocates ephemeral host ports before calling the driver
+			// so an empty host port means the port is only exposed
+			continue
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, nil
+}
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+// getFloatingIP returns the floating ip requested for an endpoint, either as
+// a plain driver option or nested in the generic options.
+func getFloatingIP(options map[string]interface{}) (string, error) {
+	if options == nil {
+		return "", nil
+	}
+	fip, ok := options[floatingIPOption].(string)
+	if !ok {
+		if option, isMap := options[optionKey].(map[string]interface{}); isMap {
+			fip, _ = option[floatingIPOption].(string)
+		}
+	}
+	if fip == "" {
+		return "", nil
+	}
+	ip := net.ParseIP(fip)
+	if ip == nil || ip.To4() == nil {
+		return "", fmt.Errorf("%s is not a valid floating ip", fip)
+	}
+	return ip.String(), nil
+}
+
+// getVLAN returns a tag pushed on traffic leaving through the bind
+// interface of a flat mode network, 0 means untagged. The vlan option is
+// the 802.1Q (customer) tag, the svlan option the 802.1ad (service) tag.
+func getVLAN(r *dknet.CreateNetworkRequest, key string) (int, error) {
+	if r.Options == nil {
+		return 0, nil
+	}
+	option, ok := r.Options[optionKey].(map[string]interface{})
+	if !ok {
+		return 0, nil
+	}
+	var vlan int
+	switch v := option[key].(type) {
+	case nil:
+		return 0, nil
+	case string:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("%s is not a valid vlan", v)
+		}
+		vlan = n
+	default:
+		vlan = toInt(v)
+	}
+	if vlan < 1 || vlan > 4094 {
+		return 0, fmt.Errorf("vlan %d is out of range 1-4094", vlan)
+	}
+	return vlan, nil
+}
+
+// getPortSecurity reports whether endpoints of the network may only send
+// from their assigned addresses.
+func getPortSecurity(r *dknet.CreateNetworkRequest) bool {
+	return getBoolOption(r, portSecurityOption, false)
+}
+
+// getFailMode returns what the bridge does when it loses its controller,
+// an empty string leaves the switch default, standalone.
+func getFailMode(r *dknet.CreateNetworkRequest) (string, error) {
+	mode := strings.ToLower(getStringOption(r, failModeOption))
+	switch mode {
+	case "", failModeSecure, failModeStandalone:
+		return mode, nil
+	}
+	return "", fmt.Errorf("%s must be %s or %s, got %s", failModeOption, failModeSecure, failModeStandalone, mode)
+}
+
+// getHwaddr returns the MAC requested for the network's bridge, an empty
+// string lets the switch pick one.
+func getHwaddr(r *dknet.CreateNetworkRequest, gateway string) (string, error) {
+	value := getStringOption(r, hwaddrOption)
+	switch value {
+	case "":
+		return "", nil
+	case "gateway":
+		ip := net.ParseIP(gateway)
+		if ip == nil || ip.To4() == nil {
+			return "", fmt.Errorf("%s=gateway requires an IPv4 gateway", hwaddrOption)
+		}
+		return makeMac(ip), nil
+	}
+	mac, err := net.ParseMAC(value)
+	if err != nil || len(mac) != 6 || mac[0]&1 != 0 {
+		return "", fmt.Errorf("%s must be a unicast MAC address or gateway, got %s", hwaddrOption, value)
+	}
+	return mac.String(), nil
+}
+
+// getICC reports whether endpoints of the network may talk to each other.
+func getICC(r *dknet.CreateNetworkRequest) bool {
+	return getBoolOption(r, iccOption, true)
+}
+
+func getStringOption(r *dknet.CreateNetworkRequest, key string) string {
+	if r.Options == nil {
+		return ""
+	}
+	option, ok := r.Options[optionKey].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	value, _ := option[key].(string)
+	return value
+}
+
+func getBoolOption(r *dknet.CreateNetworkRequest, key string, defaultValue bool) bool {
+	if r.Options == nil {
+		return defaultValue
+	}
+	option, ok := r.Options[optionKey].(map[string]interface{})
+	if !ok {
+		return defaultValue
+	}
+	switch v := option[key].(type) {
+	case bool:
+		return v
+	case string:
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			return enabled
+		}
+	}
+	return defaultValue
+}
+
+// applyNaming sets the names and defaults the config changes.
+func applyNaming(config Config) error {
+	if config.DefaultMode != "" {
+		if !validModes[config.DefaultMode] {
+			return fmt.Errorf("%s is not a valid default mode", config.DefaultMode)
+		}
+		defaultMode = config.DefaultMode
+	}
+	if config.DefaultMTU < 0 {
+		return fmt.Errorf("invalid default MTU %d", config.DefaultMTU)
+	}
+	if config.DefaultMTU > 0 {
+		defaultMTU = config.DefaultMTU
+	}
+	if config.PortPrefix != "" {
+		if err := checkNamePrefix("port", config.PortPrefix, maxIfNameLen-truncatedIDLen); err != nil {
+			return err
+		}
+		ovsPortPrefix = config.PortPrefix
+	}
+	if config.BridgePrefix != "" {
+		if err := checkNamePrefix("bridge", config.BridgePrefix, maxIfNameLen-truncatedIDLen); err != nil {
+			return err
+		}
+		bridgePrefix = config.BridgePrefix
+	}
+	if config.ContainerIfPrefix != "" {
+		// docker numbers the interfaces of a container
+		if err := checkNamePrefix("container interface", config.ContainerIfPrefix, maxIfNameLen-3); err != nil {
+			return err
+		}
+		containerEthName = config.ContainerIfPrefix
+	}
+	// ports and veths are told apart from bridges and the container side
+	// of veths by their prefix alone, see collectOrphans
+	if overlaps(ovsPortPrefix, bridgePrefix) {
+		return fmt.Errorf("port prefix %s and bridge prefix %s overlap", ovsPortPrefix, bridgePrefix)
+	}
+	if overlaps(ovsPortPrefix, vethPeerPrefix) {
+		return fmt.Errorf("port prefix %s overlaps %s, the prefix of the container side of veths", ovsPortPrefix, vethPeerPrefix)
+	}
+	for _, prefix := range []string{qinqNetworkPrefix, qinqCarrierPrefix} {
+		if overlaps(ovsPortPrefix, prefix) {
+			return fmt.Errorf("port prefix %s overlaps %s, the prefix of the links of QinQ networks", ovsPortPrefix, prefix)
+		}
+	}
+	if config.GatewayScript != "" {
+		gatewayScript = config.GatewayScript
+	}
+	if config.GatewayService != "" {
+		serviceName = config.GatewayService
+	}
+	return nil
+}
+
+const (
+	// maxIfNameLen is the longest interface name the kernel takes,
+	// IFNAMSIZ without the terminating NUL
+	maxIfNameLen = 15
+	// truncatedIDLen is the length of a network or endpoint id in names,
+	// see truncateID
+	truncatedIDLen = 5
+)
+
+// checkNamePrefix checks that a prefix leaves room for what follows it in
+// an interface name and has no characters the kernel or ovs-vsctl reject.
+func checkNamePrefix(kind, prefix string, maxLen int) error {
+	if len(prefix) > maxLen {
+		return fmt.Errorf("%s prefix %s is longer than %d characters", kind, prefix, maxLen)
+	}
+	if strings.ContainsAny(prefix, "/:,= \t\n") {
+		return fmt.Errorf("%s prefix %q is not valid in an interface name", kind, prefix)
+	}
+	return nil
+}
+
+// overlaps reports whether one name prefix starts with the other.
+func overlaps(a, b string) bool {
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
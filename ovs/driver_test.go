@@ -0,0 +1,98 @@
+package ovs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+// networkRequest returns a CreateNetwork request with the generic options
+// docker passes for `docker network create -o`.
+func networkRequest(options map[string]string) *dknet.CreateNetworkRequest {
+	generic := make(map[string]interface{}, len(options))
+	for key, value := range options {
+		generic[key] = value
+	}
+	return &dknet.CreateNetworkRequest{NetworkID: "net-a", Options: map[string]interface{}{optionKey: generic}}
+}
+
+func TestGetPortMappings(t *testing.T) {
+	mapping := func(proto, port, hostPort float64, hostIP string) map[string]interface{} {
+		return map[string]interface{}{"Proto": proto, "IP": "", "Port": port, "HostIP": hostIP, "HostPort": hostPort}
+	}
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		want    []portBinding
+		wantErr bool
+	}{
+		{name: "no options"},
+		{name: "no port mappings", options: map[string]interface{}{optionKey: map[string]interface{}{}}},
+		{
+			name:    "published ports",
+			options: map[string]interface{}{portMappingKey: []interface{}{mapping(6, 80, 8080, ""), mapping(17, 53, 5353, "10.0.0.1")}},
+			want: []portBinding{
+				{Proto: "tcp", Port: 80, HostPort: 8080},
+				{Proto: "udp", Port: 53, HostPort: 5353, HostIP: "10.0.0.1"},
+			},
+		},
+		{
+			// an exposed port has no host port and is not published
+			name:    "exposed port",
+			options: map[string]interface{}{portMappingKey: []interface{}{mapping(6, 80, 0, ""), mapping(132, 9000, 9000, "")}},
+			want:    []portBinding{{Proto: "sctp", Port: 9000, HostPort: 9000}},
+		},
+		{
+			name:    "unsupported protocol",
+			options: map[string]interface{}{portMappingKey: []interface{}{mapping(1, 0, 8080, "")}},
+			wantErr: true,
+		},
+		{
+			name:    "not a mapping",
+			options: map[string]interface{}{portMappingKey: []interface{}{"80:8080"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := getPortMappings(tt.options)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: getPortMappings error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: getPortMappings = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetHwaddr(t *testing.T) {
+	tests := []struct {
+		value   string
+		gateway string
+		want    string
+		wantErr bool
+	}{
+		{value: "", gateway: "10.0.0.1"},
+		{value: "gateway", gateway: "10.0.0.1", want: "7a:42:0a:00:00:01"},
+		{value: "02:42:AC:11:00:01", want: "02:42:ac:11:00:01"},
+		{value: "02-42-ac-11-00-01", want: "02:42:ac:11:00:01"},
+		{value: "gateway", gateway: "fd00::1", wantErr: true},
+		{value: "gateway", wantErr: true},
+		// multicast
+		{value: "01:00:5e:00:00:01", wantErr: true},
+		// EUI-64
+		{value: "02:42:ac:11:00:01:00:01", wantErr: true},
+		{value: "bridge", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := getHwaddr(networkRequest(map[string]string{hwaddrOption: tt.value}), tt.gateway)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getHwaddr(%q, %q) error = %v, want error %v", tt.value, tt.gateway, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("getHwaddr(%q, %q) = %q, want %q", tt.value, tt.gateway, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ovs
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+const (
+	// DSCP code point written into the IP header of traffic an endpoint
+	// sends, e.g. 46 (EF) for EPC traffic of sgw and pgw networks. The
+	// endpoint option overrides the network one, 0 leaves the header alone.
+	dscpOption         = "linker.net.ovs.bridge.dscp"
+	endpointDSCPOption = "linker.net.ovs.endpoint.dscp"
+
+	// dscpPriority marks the traffic of a port without port security like
+	// portSecurityAllowPriority does, ahead of the egress rules
+	dscpPriority = 1080
+	maxDSCP      = 63
+)
+
+// getDSCP returns the code point requested for a network, 0 if unset.
+func getDSCP(r *dknet.CreateNetworkRequest) (int, error) {
+	value := getStringOption(r, dscpOption)
+	if value == "" {
+		return 0, nil
+	}
+	dscp, err := strconv.Atoi(value)
+	if err != nil || dscp < 0 || dscp > maxDSCP {
+		return 0, fmt.Errorf("%s must be a code point between 0 and %d, got %s", dscpOption, maxDSCP, value)
+	}
+	return dscp, nil
+}
+
+// getEndpointDSCP returns the code point requested for an endpoint, or the
+// one of its network if the endpoint does not ask for one.
+func getEndpointDSCP(options map[string]interface{}, networkDSCP int) (int, error) {
+	dscp, err := endpointIntOption(options, endpointDSCPOption)
+	if err != nil {
+		return 0, err
+	}
+	if dscp > maxDSCP {
+		return 0, fmt.Errorf("%s must be a code point between 0 and %d", endpointDSCPOption, maxDSCP)
+	}
+	if dscp == 0 {
+		return networkDSCP, nil
+	}
+	return dscp, nil
+}
+
+// dscpAction is the action marking IP traffic with the code point, the
+// DSCP bits are the upper six of the ToS byte.
+func dscpAction(dscp int) string {
+	return fmt.Sprintf("mod_nw_tos:%d", dscp<<2)
+}
+
+// applyDSCP marks the IP traffic leaving the endpoint's port before it
+// goes through the port security table again, where egress rules may
+// apply. With port security the marking is part of its flows instead,
+// which take precedence over this one.
+func applyDSCP(endpointID, bridgeName, port string, dscp int) error {
+	flow := fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,ip,actions=%s,load:1->NXM_NX_REG3[],resubmit(,%d)",
+		flowCookie(endpointID), dscpPriority, port, dscpAction(dscp), portSecurityTable)
+	if err := addFlow(bridgeName, portSecurityTable, flow); err != nil {
+		return err
+	}
+	log.Infof("Marking traffic of port [ %s ] with DSCP %d", port, dscp)
+	return nil
+}
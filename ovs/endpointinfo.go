@@ -0,0 +1,81 @@
+package ovs
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// interfaceCounters are the keys of the Interface statistics column
+// EndpointInfo reports, absent keys are counters the datapath doesn't keep.
+var interfaceCounters = []string{
+	"rx_bytes", "rx_packets", "rx_dropped", "rx_errors",
+	"tx_bytes", "tx_packets", "tx_dropped", "tx_errors",
+}
+
+// endpointInterface reads the Interface row of an endpoint's port, found by
+// the endpoint id recordEndpoint stamped on it. Statistics aren't
+// monitored, so the row is read from ovsdb-server rather than the cache.
+func (ovsdber *ovsdber) endpointInterface(endpointID string) (libovsdb.Row, bool, error) {
+	ids, _ := libovsdb.NewOvsMap(map[string]string{endpointIDKey: endpointID})
+	selectOp := libovsdb.Operation{
+		Op:      "select",
+		Table:   "Interface",
+		Columns: []string{"name", "statistics", "ofport", "mac_in_use"},
+		Where:   []interface{}{libovsdb.NewCondition("external_ids", "includes", ids)},
+	}
+	reply, err := ovsdber.transactReply(selectOp)
+	if err != nil {
+		return libovsdb.Row{}, false, err
+	}
+	if len(reply[0].Rows) == 0 {
+		return libovsdb.Row{}, false, nil
+	}
+	row, err := decodeRow(reply[0].Rows[0])
+	if err != nil {
+		return libovsdb.Row{}, false, fmt.Errorf("invalid Interface row of endpoint %s: %v", truncateID(endpointID), err)
+	}
+	return row, true, nil
+}
+
+// interfacePort returns the OVS port name, OpenFlow port number, VLAN tag and
+// MAC of an endpoint's Interface row, the values that show up in
+// ovs-ofctl dump-flows. The MAC is the one the endpoint was created with,
+// the container's, or the port's own if docker didn't give one.
+func interfacePort(row libovsdb.Row, mac string) map[string]string {
+	info := make(map[string]string)
+	name, _ := row.Fields["name"].(string)
+	if name != "" {
+		info["port_name"] = name
+	}
+	if ofport, ok := row.Fields["ofport"].(float64); ok && ofport > 0 {
+		info["ofport"] = strconv.Itoa(int(ofport))
+	}
+	if tag, ok := cachedRow("Port", portUUIDForName(name)).Fields["tag"].(float64); ok {
+		info["tag"] = strconv.Itoa(int(tag))
+	}
+	if mac == "" {
+		mac, _ = row.Fields["mac_in_use"].(string)
+	}
+	if mac != "" {
+		info["mac_address"] = mac
+	}
+	return info
+}
+
+// interfaceStatistics returns the counters of an Interface row as the
+// strings InfoResponse carries.
+func interfaceStatistics(row libovsdb.Row) map[string]string {
+	stats := make(map[string]string)
+	counters, ok := row.Fields["statistics"].(libovsdb.OvsMap)
+	if !ok {
+		return stats
+	}
+	for _, key := range interfaceCounters {
+		if value, ok := counters.GoMap[key].(float64); ok {
+			stats[key] = strconv.FormatInt(int64(value), 10)
+		}
+	}
+	return stats
+}
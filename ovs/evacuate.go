@@ -0,0 +1,223 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	maintenanceKey = "linker-maintenance"
+)
+
+var (
+	tunnelTypes = map[string]bool{
+		"vxlan":  true,
+		"gre":    true,
+		"geneve": true,
+	}
+)
+
+// Evacuate drains the host in one pass so it can be taken down for
+// maintenance: the gateway service is stopped, the routes of the pgw pools
+// are withdrawn, the floating ips are released, every tunnel port is
+// removed from the plugin's bridges and the host is marked in maintenance
+// in the Open_vSwitch table. All steps are attempted even if one of them
+// fails. The pools and the gateway unit are left recorded, so the host can
+// take its traffic back after maintenance. It runs next to the plugin, so
+// it only connects to OVSDB and starts none of the driver's background
+// work.
+func Evacuate(config Config) error {
+	ovsdber, firewall, err := openMaintenance(config)
+	if err != nil {
+		return err
+	}
+	var failed []string
+
+	log.Infof("evacuating host: stopping gateway service")
+	if err := stopGatewayService(context.Background()); err != nil {
+		log.Errorf("failed to stop gateway service: %v", err)
+		failed = append(failed, "gateway")
+	}
+
+	for bridgeName := range ovsdbCache.pluginBridges() {
+		gateway, pools := bridgePools(bridgeName)
+		for _, pool := range pools {
+			if err := delPoolRoute(bridgeName, gateway, pool); err != nil {
+				log.Errorf("%v", err)
+				failed = append(failed, pool)
+			}
+		}
+	}
+
+	for _, es := range recordedFloatingIPs() {
+		if err := releaseFloatingIP(context.Background(), ovsdber, firewall, es); err != nil {
+			failed = append(failed, es.FloatingIP)
+		}
+	}
+
+	for tunnel, bridgeName := range tunnelPorts() {
+		if err := ovsdber.deletePort(context.Background(), bridgeName, tunnel); err != nil {
+			log.Errorf("failed to remove tunnel port %s from bridge %s: %v", tunnel, bridgeName, err)
+			failed = append(failed, tunnel)
+			continue
+		}
+		log.Infof("Removed tunnel port [ %s ] from bridge [ %s ]", tunnel, bridgeName)
+	}
+
+	if err := ovsdber.setMaintenance(true); err != nil {
+		log.Errorf("failed to mark host in maintenance: %v", err)
+		failed = append(failed, maintenanceKey)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("evacuation incomplete, failed steps: %v", failed)
+	}
+	log.Infof("host evacuated")
+	return nil
+}
+
+// Resume takes the host out of maintenance: the routes of the pgw pools
+// are added back and the maintenance mark is removed, after which the
+// plugin's reconciler starts the gateway service and tunnels the overlay
+// networks to their peers again.
+func Resume(config Config) error {
+	ovsdber, _, err := openMaintenance(config)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for bridgeName := range ovsdbCache.pluginBridges() {
+		gateway, pools := bridgePools(bridgeName)
+		for _, pool := range pools {
+			if err := addPoolRoute(bridgeName, gateway, pool); err != nil {
+				log.Errorf("%v", err)
+				failed = append(failed, pool)
+			}
+		}
+	}
+	if err := ovsdber.setMaintenance(false); err != nil {
+		log.Errorf("failed to take host out of maintenance: %v", err)
+		failed = append(failed, maintenanceKey)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("resume incomplete, failed steps: %v", failed)
+	}
+	log.Infof("host resumed")
+	return nil
+}
+
+// openMaintenance connects to OVSDB and the firewall backend as the plugin
+// of the config would, for the maintenance commands.
+func openMaintenance(config Config) (*ovsdber, firewaller, error) {
+	firewall, err := newFirewaller(config.FirewallBackend)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := applyNaming(config); err != nil {
+		return nil, nil, err
+	}
+	applyManaged(config.Managed, config.HostRoot)
+	if config.OvsdbTimeout > 0 {
+		transactTimeout = config.OvsdbTimeout
+	}
+	ovsdb, endpoint, tlsConfig, err := connectOvsdb(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	ovsdber := &ovsdber{
+		ovsdb:          ovsdb,
+		forceOwnership: config.ForceOwnership,
+		endpoint:       endpoint,
+		tlsConfig:      tlsConfig,
+		instance:       config.DriverName,
+	}
+	if ovsdber.instance == "" {
+		ovsdber.instance = defaultDriverName
+	}
+	if err := ovsdber.initDBCache(); err != nil {
+		return nil, nil, err
+	}
+	return ovsdber, tracedFirewall{firewall, firewallBackend(config.FirewallBackend)}, nil
+}
+
+// hostInMaintenance reports whether the host was evacuated and not resumed.
+func hostInMaintenance() bool {
+	for _, row := range getTableCache("Open_vSwitch") {
+		if ovsMapValue(row.Fields["external_ids"], maintenanceKey) == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// tunnelPorts returns the tunnel ports on the plugin's bridges with the
+// bridge holding each. Tunnels on other bridges, e.g. br-tun, are left
+// alone.
+func tunnelPorts() map[string]string {
+	tunnels := make(map[string]bool)
+	for _, row := range getTableCache("Interface") {
+		ifaceType, _ := row.Fields["type"].(string)
+		if !tunnelTypes[ifaceType] {
+			continue
+		}
+		if name, ok := row.Fields["name"].(string); ok {
+			tunnels[name] = true
+		}
+	}
+	ports := make(map[string]string)
+	for bridgeName := range ovsdbCache.pluginBridges() {
+		for _, portName := range bridgePortNames(bridgeName) {
+			if tunnels[portName] {
+				ports[portName] = bridgeName
+			}
+		}
+	}
+	return ports
+}
+
+// bridgeNameForPort returns the name of the bridge holding the named port.
+func bridgeNameForPort(portName string) string {
+	portUUID := portUUIDForName(portName)
+	if portUUID == "" {
+		return ""
+	}
+	for _, row := range getTableCache("Bridge") {
+		for _, uuid := range rowUUIDs(row.Fields["ports"]) {
+			if uuid == portUUID {
+				name, _ := row.Fields["name"].(string)
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// rowUUIDs flattens a column holding either a single uuid or a set of them.
+func rowUUIDs(field interface{}) []string {
+	var uuids []string
+	switch v := field.(type) {
+	case libovsdb.UUID:
+		uuids = append(uuids, v.GoUuid)
+	case libovsdb.OvsSet:
+		for _, elem := range v.GoSet {
+			if uuid, ok := elem.(libovsdb.UUID); ok {
+				uuids = append(uuids, uuid.GoUuid)
+			}
+		}
+	}
+	return uuids
+}
+
+// setMaintenance records the maintenance state of the host in the
+// external_ids of the root Open_vSwitch row, replacing the recorded one.
+// Leaving maintenance removes the key.
+func (ovsdber *ovsdber) setMaintenance(enabled bool) error {
+	if !enabled {
+		return ovsdber.mutateRootExternalIDs(maintenanceKey)
+	}
+	extIDs, _ := libovsdb.NewOvsMap(map[string]string{maintenanceKey: "true"})
+	return ovsdber.mutateRootExternalIDs(maintenanceKey, libovsdb.NewMutation("external_ids", "insert", extIDs))
+}
@@ -0,0 +1,48 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// TestTunnelPorts checks that evacuate only removes the tunnels of the
+// plugin's bridges.
+func TestTunnelPorts(t *testing.T) {
+	saved := ovsdbCache
+	defer func() { ovsdbCache = saved }()
+	ovsdbCache = newTableCache()
+	for name, networkID := range map[string]string{"ovsbr-a": "net-a", "br-tun": ""} {
+		fields := bridgeFields(name, networkID)
+		ports, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: "p-" + name}, {GoUuid: "v-" + name}})
+		fields["ports"] = *ports
+		ovsdbCache.update(rowUpdate("Bridge", "b-"+name, nil, fields))
+		ovsdbCache.update(rowUpdate("Port", "p-"+name, nil, map[string]interface{}{"name": "vx-" + name}))
+		ovsdbCache.update(rowUpdate("Port", "v-"+name, nil, map[string]interface{}{"name": "veth-" + name}))
+		ovsdbCache.update(rowUpdate("Interface", "i-"+name, nil, tunnelFields("vx-"+name, "10.0.0.2", bfdStateUp)))
+		ovsdbCache.update(rowUpdate("Interface", "j-"+name, nil, map[string]interface{}{"name": "veth-" + name, "type": ""}))
+	}
+
+	got := tunnelPorts()
+	if len(got) != 1 || got["vx-ovsbr-a"] != "ovsbr-a" {
+		t.Errorf("tunnel ports are %v, want vx-ovsbr-a on ovsbr-a", got)
+	}
+}
+
+func TestHostInMaintenance(t *testing.T) {
+	saved := ovsdbCache
+	defer func() { ovsdbCache = saved }()
+	ovsdbCache = newTableCache()
+	root := func(ids map[interface{}]interface{}) map[string]interface{} {
+		return map[string]interface{}{"external_ids": libovsdb.OvsMap{GoMap: ids}}
+	}
+
+	ovsdbCache.update(rowUpdate("Open_vSwitch", "root", nil, root(map[interface{}]interface{}{})))
+	if hostInMaintenance() {
+		t.Error("host without the mark is in maintenance")
+	}
+	ovsdbCache.update(rowUpdate("Open_vSwitch", "root", root(nil), root(map[interface{}]interface{}{maintenanceKey: "true"})))
+	if !hostInMaintenance() {
+		t.Error("evacuated host is not in maintenance")
+	}
+}
@@ -0,0 +1,115 @@
+package ovs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// docker calls Leave itself when a container stops, endpoints are only
+	// cleaned up if their port is still there after the grace period
+	eventGracePeriod = 30 * time.Second
+	eventRetryDelay  = 5 * time.Second
+)
+
+// watchContainerEvents follows the docker event stream and cleans up the
+// endpoints of containers that die or are removed without a Leave. The
+// stream is resumed from the last event seen if it breaks.
+func (d *Driver) watchContainerEvents() {
+	var since int64
+	wanted := filters.NewArgs()
+	wanted.Add("type", "container")
+	wanted.Add("event", "die")
+	wanted.Add("event", "destroy")
+	for {
+		options := dockertypes.EventsOptions{Filters: wanted}
+		if since > 0 {
+			options.Since = strconv.FormatInt(since, 10)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		events, errs := d.dockerer.client.Events(ctx, options)
+	stream:
+		for {
+			select {
+			case e := <-events:
+				since = e.Time
+				go d.cleanupContainer(e.Actor.ID)
+			case err := <-errs:
+				log.Debugf("docker event stream broke: %v", err)
+				break stream
+			}
+		}
+		cancel()
+		time.Sleep(eventRetryDelay)
+	}
+}
+
+// cleanupContainer removes the ports, and their veths, that Join recorded
+// for the container and that are still there once docker had its chance
+// to call Leave.
+func (d *Driver) cleanupContainer(containerID string) {
+	time.Sleep(eventGracePeriod)
+	ports := make(map[string]string)
+	for _, row := range getTableCache("Interface") {
+		if ovsMapValue(row.Fields["external_ids"], containerIDKey) != containerID {
+			continue
+		}
+		if name, ok := row.Fields["name"].(string); ok {
+			ports[name] = ovsMapValue(row.Fields["external_ids"], endpointIDKey)
+		}
+	}
+	if len(ports) == 0 {
+		return
+	}
+	live, err := d.liveEndpoints()
+	if err != nil {
+		log.Warnf("not cleaning up endpoints of container %s, could not list docker endpoints: %v", truncateID(containerID), err)
+		return
+	}
+	for portName, endpointID := range ports {
+		if len(endpointID) < 5 || live[truncateID(endpointID)] {
+			continue
+		}
+		if err := d.forceCleanEndpoint(endpointID, portName); err != nil {
+			log.Warnf("failed to remove port %s of vanished endpoint %s: %v", portName, truncateID(endpointID), err)
+		}
+	}
+}
+
+// forceCleanEndpoint does what Leave would have done for an endpoint whose
+// container is gone: removes its port, flows and veth.
+func (d *Driver) forceCleanEndpoint(endpointID, portName string) error {
+	bridgeName := bridgeNameForPort(portName)
+	if bridgeName == "" {
+		return nil
+	}
+	representor := portRepresentor(portName)
+	if es, ok := d.endpoint(endpointID); ok && es.Representor != "" {
+		representor = es.Representor
+	}
+	internal := portIsInternal(portName)
+	if err := d.ovsdber.deletePort(context.Background(), bridgeName, portName); err != nil {
+		return err
+	}
+	removeEndpointPolicies(endpointID, bridgeName)
+	forgetContainer(endpointID)
+	switch {
+	case representor != "":
+		releaseRepresentor(portName, representor)
+	case !internal && strings.HasPrefix(portName, ovsPortPrefix):
+		if link, err := netlink.LinkByName(portName); err == nil && d.ovsdber.checkLinkOwner(portName) == nil {
+			if err := netlink.LinkDel(link); err != nil {
+				log.Warnf("failed to remove veth %s of vanished endpoint %s: %v", portName, truncateID(endpointID), err)
+			}
+		}
+	}
+	log.Infof("Cleaned up port [ %s ] of endpoint [ %s ] whose container vanished without a Leave", portName, truncateID(endpointID))
+	return nil
+}
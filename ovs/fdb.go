@@ -0,0 +1,142 @@
+package ovs
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FDBEntry is a MAC address learnt by a bridge of the plugin and the port
+// it was learnt on. OFPort is "LOCAL" for the bridge's own port, Age is in
+// seconds. The endpoint and container are empty for uplinks, patch and
+// tunnel ports.
+type FDBEntry struct {
+	MAC         string
+	VLAN        int
+	Port        string
+	OFPort      string
+	Age         int
+	BridgeName  string
+	NetworkID   string
+	EndpointID  string `json:",omitempty"`
+	ContainerID string `json:",omitempty"`
+}
+
+// handleFDB serves /fdb: GET returns the MAC learning tables of the
+// plugin's bridges, of one network with ?network=<id>, and only the
+// entries of one address with ?mac=<mac>.
+func (d *Driver) handleFDB(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	var mac net.HardwareAddr
+	if s := r.URL.Query().Get("mac"); s != "" {
+		var err error
+		if mac, err = net.ParseMAC(s); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	entries, err := bridgeFDB(r.URL.Query().Get("network"), mac)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// bridgeFDB reads the MAC learning tables of the plugin's bridges through
+// ovs-appctl fdb/show and names the port and endpoint of each entry.
+func bridgeFDB(networkID string, mac net.HardwareAddr) ([]FDBEntry, error) {
+	// ofport numbers are per bridge, port names are not
+	ports := make(map[string]map[string]string)
+	endpoints := make(map[string]portEndpoint)
+	for _, row := range getTableCache("Interface") {
+		name, _ := row.Fields["name"].(string)
+		ofport, ok := row.Fields["ofport"].(float64)
+		if name == "" || !ok || ofport <= 0 {
+			continue
+		}
+		bridgeName := bridgeNameForPort(name)
+		if ports[bridgeName] == nil {
+			ports[bridgeName] = make(map[string]string)
+		}
+		ports[bridgeName][strconv.Itoa(int(ofport))] = name
+		endpoints[name] = portEndpoint{
+			endpointID:  ovsMapValue(row.Fields["external_ids"], endpointIDKey),
+			containerID: ovsMapValue(row.Fields["external_ids"], containerIDKey),
+		}
+	}
+	entries := []FDBEntry{}
+	for bridgeName, id := range pluginBridges() {
+		if networkID != "" && id != networkID {
+			continue
+		}
+		out, err := exec.Command("ovs-appctl", "fdb/show", bridgeName).Output()
+		if err != nil {
+			return nil, fmt.Errorf("ovs-appctl fdb/show %s: %v", bridgeName, err)
+		}
+		for _, entry := range parseFDB(string(out)) {
+			if mac != nil && entry.MAC != mac.String() {
+				continue
+			}
+			entry.BridgeName, entry.NetworkID = bridgeName, id
+			entry.Port = ports[bridgeName][entry.OFPort]
+			if entry.OFPort == "LOCAL" {
+				entry.Port = bridgeName
+			}
+			entry.EndpointID = endpoints[entry.Port].endpointID
+			entry.ContainerID = endpoints[entry.Port].containerID
+			entries = append(entries, entry)
+		}
+	}
+	sort.Sort(fdbByBridge(entries))
+	return entries, nil
+}
+
+// portEndpoint is the endpoint and container the Interface row of a port
+// names.
+type portEndpoint struct {
+	endpointID, containerID string
+}
+
+// parseFDB parses the table ovs-appctl fdb/show prints, e.g.
+//
+//	 port  VLAN  MAC                Age
+//	    1     0  02:42:ac:11:00:02    3
+//	LOCAL     0  5e:1f:8b:0a:9c:41    0
+func parseFDB(out string) []FDBEntry {
+	var entries []FDBEntry
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || fields[0] == "port" {
+			continue
+		}
+		vlan, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		// static entries show "static" for their age
+		age, _ := strconv.Atoi(fields[3])
+		entries = append(entries, FDBEntry{OFPort: fields[0], VLAN: vlan, MAC: fields[2], Age: age})
+	}
+	return entries
+}
+
+type fdbByBridge []FDBEntry
+
+func (s fdbByBridge) Len() int      { return len(s) }
+func (s fdbByBridge) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s fdbByBridge) Less(i, j int) bool {
+	if s[i].BridgeName != s[j].BridgeName {
+		return s[i].BridgeName < s[j].BridgeName
+	}
+	return s[i].MAC < s[j].MAC
+}
@@ -0,0 +1,42 @@
+package ovs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFDB(t *testing.T) {
+	tests := []struct {
+		out  string
+		want []FDBEntry
+	}{
+		{
+			" port  VLAN  MAC                Age\n" +
+				"    1     0  02:42:ac:11:00:02    3\n" +
+				"LOCAL     0  5e:1f:8b:0a:9c:41    0\n",
+			[]FDBEntry{
+				{OFPort: "1", VLAN: 0, MAC: "02:42:ac:11:00:02", Age: 3},
+				{OFPort: "LOCAL", VLAN: 0, MAC: "5e:1f:8b:0a:9c:41", Age: 0},
+			},
+		},
+		{
+			" port  VLAN  MAC                Age\n" +
+				"    2   100  02:42:ac:11:00:03  static\n",
+			[]FDBEntry{{OFPort: "2", VLAN: 100, MAC: "02:42:ac:11:00:03", Age: 0}},
+		},
+		{
+			// lines that are not entries are skipped
+			"ovs-appctl: ovsbr-a: unknown bridge\n" +
+				"    3  none  02:42:ac:11:00:04    1\n" +
+				"    4   200  02:42:ac:11:00:05    9\n",
+			[]FDBEntry{{OFPort: "4", VLAN: 200, MAC: "02:42:ac:11:00:05", Age: 9}},
+		},
+		{" port  VLAN  MAC                Age\n", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := parseFDB(tt.out); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFDB(%q) = %+v, want %+v", tt.out, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package ovs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	firewallIptables = "iptables"
+	firewallNftables = "nftables"
+
+	// portMappingsKeyPrefix records the published ports of an endpoint on
+	// the bridge of its network, followed by the endpoint's short id, so
+	// that they are withdrawn when it is deleted after a restart
+	portMappingsKeyPrefix = "linker-ports-"
+)
+
+var (
+	protocolNames = map[int]string{
+		6:   "tcp",
+		17:  "udp",
+		132: "sctp",
+	}
+)
+
+// portBinding is a single published port of an endpoint
+type portBinding struct {
+	Proto    string
+	HostIP   string
+	HostPort int
+	Port     int
+}
+
+// firewaller programs the NAT and filter rules owned by the networks and
+// endpoints of the driver. Every network gets its own chains so that all of
+// its rules go away with the network.
+type firewaller interface {
+	setupNetwork(ctx context.Context, networkID, bridgeName, cidr string) error
+	teardownNetwork(ctx context.Context, networkID, bridgeName string)
+	programPortMapping(ctx context.Context, enable bool, networkID, bridgeName, containerIP string, b portBinding) error
+	programFloatingIP(ctx context.Context, enable bool, es *EndpointState) error
+	// isolateNetwork drops traffic the host would route from the bridge
+	// back into it, between the endpoints of a network without ICC
+	isolateNetwork(ctx context.Context, networkID, bridgeName string) error
+	// listRules returns the rules of the network's chains as the backend
+	// prints them
+	listRules(networkID string) ([]string, error)
+}
+
+func newFirewaller(backend string) (firewaller, error) {
+	switch backend {
+	case "", firewallIptables:
+		return iptablesFirewall{}, nil
+	case firewallNftables:
+		return nftablesFirewall{}, nil
+	}
+	return nil, fmt.Errorf("%s is not a valid firewall backend", backend)
+}
+
+// removePortMappings withdraws every published port of an endpoint.
+func (d *Driver) removePortMappings(ctx context.Context, es *EndpointState) {
+	for _, b := range es.PortMappings {
+		if err := d.firewall.programPortMapping(ctx, false, es.NetworkID, es.BridgeName, es.Address, b); err != nil {
+			log.Warnf("failed to remove port mapping %v: %v", b, err)
+		}
+	}
+}
+
+// recordedPortMappings is the value of an endpoint's portMappingsKeyPrefix
+// key, the rules of a published port are derived from the address.
+type recordedPortMappings struct {
+	Address      string
+	PortMappings []portBinding
+}
+
+func portMappingsKey(endpointID string) string {
+	return portMappingsKeyPrefix + truncateID(endpointID)
+}
+
+// recordPortMappings records the published ports of an endpoint on its
+// bridge.
+func (ovsdber *ovsdber) recordPortMappings(endpointID string, es *EndpointState) error {
+	value, err := json.Marshal(recordedPortMappings{Address: es.Address, PortMappings: es.PortMappings})
+	if err != nil {
+		return err
+	}
+	return ovsdber.setRowMap("Bridge", es.BridgeName, "external_ids", map[string]string{portMappingsKey(endpointID): string(value)})
+}
+
+// recordedEndpoint returns the published ports recorded for an endpoint
+// the driver has no state of, as the endpoint state removePortMappings
+// needs.
+func recordedEndpoint(endpointID string) (*EndpointState, bool) {
+	for bridgeName, networkID := range ovsdbCache.pluginBridges() {
+		value := bridgeExternalID(bridgeName, portMappingsKey(endpointID))
+		if value == "" {
+			continue
+		}
+		var recorded recordedPortMappings
+		if err := json.Unmarshal([]byte(value), &recorded); err != nil {
+			log.Warnf("bridge %s records invalid port mappings for endpoint %s: %v", bridgeName, truncateID(endpointID), err)
+			continue
+		}
+		return &EndpointState{
+			NetworkID:    networkID,
+			BridgeName:   bridgeName,
+			Address:      recorded.Address,
+			PortMappings: recorded.PortMappings,
+		}, true
+	}
+	return nil, false
+}
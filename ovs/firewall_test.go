@@ -0,0 +1,43 @@
+package ovs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+func TestRecordedEndpoint(t *testing.T) {
+	saved := ovsdbCache
+	defer func() { ovsdbCache = saved }()
+	ovsdbCache = newTableCache()
+
+	ids := map[interface{}]interface{}{
+		networkIDKey:                    "net-a",
+		portMappingsKey("ep-aaaaaaaa"):  `{"Address":"172.18.0.2","PortMappings":[{"Proto":"tcp","HostIP":"","HostPort":8080,"Port":80}]}`,
+		portMappingsKey("ep-invalid00"): `{"Address":`,
+		portMappingsKey("ep-other0000"): `{"Address":"172.18.0.3","PortMappings":[]}`,
+	}
+	fields := bridgeFields("ovsbr-a", "net-a")
+	fields["external_ids"] = libovsdb.OvsMap{GoMap: ids}
+	ovsdbCache.update(rowUpdate("Bridge", "b1", nil, fields))
+
+	es, ok := recordedEndpoint("ep-aaaaaaaa")
+	if !ok {
+		t.Fatal("recordedEndpoint(ep-aaaaaaaa) found nothing")
+	}
+	want := &EndpointState{
+		NetworkID:    "net-a",
+		BridgeName:   "ovsbr-a",
+		Address:      "172.18.0.2",
+		PortMappings: []portBinding{{Proto: "tcp", HostPort: 8080, Port: 80}},
+	}
+	if !reflect.DeepEqual(es, want) {
+		t.Errorf("recordedEndpoint(ep-aaaaaaaa) = %+v, want %+v", es, want)
+	}
+	for _, endpointID := range []string{"ep-invalid00", "ep-missing00"} {
+		if es, ok := recordedEndpoint(endpointID); ok {
+			t.Errorf("recordedEndpoint(%q) = %+v, want nothing", endpointID, es)
+		}
+	}
+}
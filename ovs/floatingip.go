@@ -0,0 +1,219 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
+)
+
+// Keys of the external_ids of an endpoint's Interface row recording its
+// bound floating ip, so that evacuate can release it without the state of
+// the running plugin
+const (
+	floatingIPKey     = "linker-floating-ip"
+	floatingUplinkKey = "linker-floating-uplink"
+)
+
+// checkFloatingIP fails if the floating ip of a new endpoint is already in
+// use, by another endpoint or as an address of the host.
+func (d *Driver) checkFloatingIP(endpointID, floatingIP string) error {
+	for id, es := range d.endpointStates() {
+		if id != endpointID && es.FloatingIP == floatingIP {
+			return fmt.Errorf("floating ip %s is already bound to endpoint %s", floatingIP, truncateID(id))
+		}
+	}
+	if name, ok := hostAddressLink(floatingIP); ok {
+		return fmt.Errorf("floating ip %s is already an address of %s", floatingIP, name)
+	}
+	return nil
+}
+
+// hostAddressLink returns the interface holding the IPv4 address, if any.
+func hostAddressLink(ip string) (string, bool) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return "", false
+	}
+	for _, link := range links {
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if addr.IPNet != nil && addr.IP.String() == ip {
+				return link.Attrs().Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// bindFloatingIP claims the floating ip on the uplink, announces it and
+// installs the 1:1 NAT rules towards the endpoint. An address the host
+// already has is not claimed, and a failure releases what was claimed.
+func (d *Driver) bindFloatingIP(ctx context.Context, endpointID string, es *EndpointState) (err error) {
+	uplink := ""
+	if ns, ok := d.network(es.NetworkID); ok {
+		uplink = ns.FlatBindInterface
+	}
+	if uplink == "" {
+		var err error
+		if uplink, err = defaultRouteInterface(); err != nil {
+			return err
+		}
+	}
+
+	link, err := netlink.LinkByName(uplink)
+	if err != nil {
+		return err
+	}
+	addr, err := netlink.ParseAddr(es.FloatingIP + "/32")
+	if err != nil {
+		return err
+	}
+	if name, ok := hostAddressLink(es.FloatingIP); ok {
+		return fmt.Errorf("floating ip %s is already an address of %s", es.FloatingIP, name)
+	}
+	if err := netlink.AddrAdd(link, addr); err != nil {
+		return fmt.Errorf("failed to add %s to %s: %v", es.FloatingIP, uplink, err)
+	}
+	d.updateEndpoint(es, func(es *EndpointState) { es.Uplink = uplink })
+	defer func() {
+		if err != nil {
+			d.unbindFloatingIP(ctx, es)
+		}
+	}()
+
+	if err := d.firewall.programFloatingIP(ctx, true, es); err != nil {
+		return err
+	}
+
+	// Refresh the neighbours' ARP caches, the address may have moved hosts
+	if out, err := exec.Command("arping", "-U", "-c", "3", "-I", uplink, es.FloatingIP).CombinedOutput(); err != nil {
+		log.Warnf("gratuitous arp for %s on %s failed: %v %s", es.FloatingIP, uplink, err, out)
+	}
+	portName := ovsPortPrefix + truncateID(endpointID)
+	if es.VhostSocket != "" {
+		portName = vhostUserPortName(endpointID)
+	}
+	if err := d.ovsdber.setRowMap("Interface", portName, "external_ids", map[string]string{floatingIPKey: es.FloatingIP, floatingUplinkKey: uplink}); err != nil {
+		log.Warnf("failed to record floating ip %s on port %s: %v", es.FloatingIP, portName, err)
+	}
+	log.Infof("Bound floating ip [ %s ] on [ %s ] to [ %s ]", es.FloatingIP, uplink, es.Address)
+	return nil
+}
+
+// recordedFloatingIPs returns the floating ips recorded on the ports of
+// the plugin's bridges, as the endpoint state unbindFloatingIP needs.
+func recordedFloatingIPs() []*EndpointState {
+	var bound []*EndpointState
+	for _, row := range getTableCache("Interface") {
+		if es, ok := recordedFloatingIP(row); ok {
+			bound = append(bound, es)
+		}
+	}
+	return bound
+}
+
+// portFloatingIP returns the floating ip recorded on the named port.
+func portFloatingIP(portName string) (*EndpointState, bool) {
+	for _, row := range getTableCache("Interface") {
+		if name, _ := row.Fields["name"].(string); name == portName {
+			return recordedFloatingIP(row)
+		}
+	}
+	return nil, false
+}
+
+// recordedFloatingIP returns the floating ip an Interface row records.
+func recordedFloatingIP(row libovsdb.Row) (*EndpointState, bool) {
+	ids := row.Fields["external_ids"]
+	fip := ovsMapValue(ids, floatingIPKey)
+	if fip == "" {
+		return nil, false
+	}
+	name, _ := row.Fields["name"].(string)
+	return &EndpointState{
+		NetworkID:  ovsMapValue(ids, networkIDKey),
+		BridgeName: bridgeNameForPort(name),
+		Address:    ovsMapValue(ids, ipAddressKey),
+		FloatingIP: fip,
+		Uplink:     ovsMapValue(ids, floatingUplinkKey),
+	}, true
+}
+
+// leaveFloatingIP releases the floating ip of an endpoint before its port
+// is deleted. An endpoint whose state lost the binding, e.g. across a
+// restart of the plugin, releases the one its port records, which goes
+// away with the port.
+func (d *Driver) leaveFloatingIP(ctx context.Context, endpointID, portName string) {
+	if es, ok := d.endpoint(endpointID); ok && es.Uplink != "" {
+		d.unbindFloatingIP(ctx, es)
+		return
+	}
+	if es, ok := portFloatingIP(portName); ok {
+		d.unbindFloatingIP(ctx, es)
+	}
+}
+
+// unbindFloatingIP removes the NAT rules and releases the address. It
+// fails if the address could not be removed from the uplink.
+func (d *Driver) unbindFloatingIP(ctx context.Context, es *EndpointState) error {
+	if err := releaseFloatingIP(ctx, &d.ovsdber, d.firewall, es); err != nil {
+		return err
+	}
+	d.updateEndpoint(es, func(es *EndpointState) { es.Uplink = "" })
+	return nil
+}
+
+// releaseFloatingIP is unbindFloatingIP without the driver's endpoint
+// state, for evacuate.
+func releaseFloatingIP(ctx context.Context, ovsdber *ovsdber, firewall firewaller, es *EndpointState) error {
+	if err := firewall.programFloatingIP(ctx, false, es); err != nil {
+		log.Warnf("failed to remove floating ip rules for %s: %v", es.FloatingIP, err)
+	}
+	link, err := netlink.LinkByName(es.Uplink)
+	if err == nil {
+		addr, _ := netlink.ParseAddr(es.FloatingIP + "/32")
+		err = netlink.AddrDel(link, addr)
+	}
+	if err != nil {
+		log.Warnf("failed to remove floating ip %s from %s: %v", es.FloatingIP, es.Uplink, err)
+		return err
+	}
+	for _, row := range getTableCache("Interface") {
+		if ovsMapValue(row.Fields["external_ids"], floatingIPKey) != es.FloatingIP {
+			continue
+		}
+		name, _ := row.Fields["name"].(string)
+		if err := ovsdber.deleteRowMapKeys("Interface", name, "external_ids", floatingIPKey, floatingUplinkKey); err != nil {
+			log.Warnf("failed to clear floating ip %s from port %s: %v", es.FloatingIP, name, err)
+		}
+	}
+	log.Infof("Released floating ip [ %s ] from [ %s ]", es.FloatingIP, es.Uplink)
+	return nil
+}
+
+// defaultRouteInterface returns the name of the interface holding the
+// IPv4 default route.
+func defaultRouteInterface() (string, error) {
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return "", err
+	}
+	for _, route := range routes {
+		if route.Dst != nil {
+			continue
+		}
+		link, err := netlink.LinkByIndex(route.LinkIndex)
+		if err != nil {
+			return "", err
+		}
+		return link.Attrs().Name, nil
+	}
+	return "", fmt.Errorf("no default route found")
+}
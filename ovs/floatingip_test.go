@@ -0,0 +1,38 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// TestPortFloatingIP checks that the floating ip recorded on a port is
+// found without the endpoint state, as Leave needs after a restart.
+func TestPortFloatingIP(t *testing.T) {
+	saved := ovsdbCache
+	defer func() { ovsdbCache = saved }()
+	ovsdbCache = newTableCache()
+	ovsdbCache.update(rowUpdate("Interface", "i1", nil, map[string]interface{}{
+		"name": "ovs-veth-a",
+		"external_ids": libovsdb.OvsMap{GoMap: map[interface{}]interface{}{
+			networkIDKey:      "net-a",
+			ipAddressKey:      "10.1.0.2",
+			floatingIPKey:     "192.0.2.10",
+			floatingUplinkKey: "eth0",
+		}},
+	}))
+	ovsdbCache.update(rowUpdate("Interface", "i2", nil, map[string]interface{}{"name": "ovs-veth-b"}))
+
+	es, ok := portFloatingIP("ovs-veth-a")
+	if !ok {
+		t.Fatal("floating ip recorded on ovs-veth-a not found")
+	}
+	if es.FloatingIP != "192.0.2.10" || es.Uplink != "eth0" || es.Address != "10.1.0.2" || es.NetworkID != "net-a" {
+		t.Errorf("floating ip of ovs-veth-a is %+v", es)
+	}
+	for _, name := range []string{"ovs-veth-b", "ovs-veth-gone"} {
+		if es, ok := portFloatingIP(name); ok {
+			t.Errorf("port %s has floating ip %+v, want none", name, es)
+		}
+	}
+}
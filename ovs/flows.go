@@ -0,0 +1,191 @@
+package ovs
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// defaultFlowPriority is the priority ovs-ofctl leaves out of a dump
+const defaultFlowPriority = 32768
+
+// flowDumpFields are the fields of an ovs-ofctl flow dump that describe the
+// flow rather than match packets
+var flowDumpFields = map[string]bool{
+	"duration": true, "idle_age": true, "hard_age": true,
+	"idle_timeout": true, "hard_timeout": true, "importance": true,
+	"send_flow_rem": true, "reset_counts": true, "check_overlap": true,
+	"no_packet_counts": true, "no_byte_counts": true,
+}
+
+// Flow is an OpenFlow flow of a network's bridge. Owner is what the plugin
+// installed it for: "pipeline" for the default flows of the tables,
+// "network" and "icc" for the network's service chain and ICC flows,
+// "endpoint:<id>", "remote:<id>", "arp:<ip>" or "trace:<id>". It is empty
+// for flows the plugin did not install.
+type Flow struct {
+	Cookie   string
+	Owner    string `json:",omitempty"`
+	Table    int
+	Priority int
+	Match    string `json:",omitempty"`
+	Actions  string
+	Packets  int64
+	Bytes    int64
+}
+
+// FlowDump is what GET /flows returns.
+type FlowDump struct {
+	NetworkID  string
+	BridgeName string
+	Flows      []Flow
+}
+
+// handleFlows serves /flows: GET ?network=<id> returns the flows the
+// plugin installed on the network's bridge, with their counters. With
+// &all=true the flows of other cookies are included too.
+func (d *Driver) handleFlows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	networkID := r.URL.Query().Get("network")
+	if networkID == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("network is required"))
+		return
+	}
+	all, _ := strconv.ParseBool(r.URL.Query().Get("all"))
+	dump, err := d.dumpFlows(networkID, all)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dump)
+}
+
+// dumpFlows reads the flows of a network's bridge through ovs-ofctl and
+// keeps those whose cookie the plugin uses for the network, unless all.
+func (d *Driver) dumpFlows(networkID string, all bool) (*FlowDump, error) {
+	reconcileMu.Lock()
+	ns, ok := d.network(networkID)
+	if ok && ns.Node != "" {
+		reconcileMu.Unlock()
+		return nil, fmt.Errorf("the bridge of network %s is on node %s, not this host", truncateID(networkID), ns.Node)
+	}
+	bridgeName, err := d.networkBridge(networkID)
+	owners := d.flowOwners(networkID, ns)
+	reconcileMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out, err := exec.Command("ovs-ofctl", "dump-flows", bridgeName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ovs-ofctl dump-flows %s: %v", bridgeName, err)
+	}
+	dump := &FlowDump{NetworkID: networkID, BridgeName: bridgeName, Flows: []Flow{}}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		flow, ok := parseFlow(scanner.Text())
+		if !ok {
+			continue
+		}
+		cookie, _ := strconv.ParseUint(strings.TrimPrefix(flow.Cookie, "0x"), 16, 64)
+		flow.Owner = owners[cookie]
+		if flow.Owner != "" || all {
+			dump.Flows = append(dump.Flows, flow)
+		}
+	}
+	return dump, nil
+}
+
+// flowOwners maps the cookies of the flows the plugin installs for a
+// network to what they are for, with reconcileMu held.
+func (d *Driver) flowOwners(networkID string, ns *NetworkState) map[uint64]string {
+	owners := make(map[uint64]string)
+	add := func(cookie, owner string) {
+		if value, err := strconv.ParseUint(strings.TrimPrefix(cookie, "0x"), 16, 64); err == nil {
+			owners[value] = owner
+		}
+	}
+	add(pipelineCookie, "pipeline")
+	add(flowCookie(networkID), "network")
+	add(flowCookie("1cc"+networkID), "icc")
+	for id, es := range d.endpointStates() {
+		if es.NetworkID == networkID {
+			add(flowCookie(id), "endpoint:"+truncateID(id))
+		}
+	}
+	if ns != nil {
+		for _, entry := range ns.ARPEntries {
+			add(arpCookie(networkID, entry.IP), "arp:"+entry.IP)
+		}
+		var remote []OverlayEndpoint
+		if d.gossip != nil {
+			remote = d.gossip.remoteEndpoints()
+		}
+		for _, ep := range d.clusterEndpoints {
+			remote = append(remote, ep)
+		}
+		for _, ep := range remote {
+			if ep.Network == networkID {
+				add(remoteCookie(networkID, ep.MAC), "remote:"+truncateID(ep.EndpointID))
+			}
+		}
+	}
+	traceMu.Lock()
+	for id, t := range traces {
+		if t.request.NetworkID == networkID {
+			add(flowCookie(id), "trace:"+id)
+		}
+	}
+	traceMu.Unlock()
+	return owners
+}
+
+// parseFlow parses a line of an ovs-ofctl flow dump, e.g.
+// " cookie=0x0, duration=5.1s, table=0, n_packets=3, n_bytes=180, priority=1 actions=resubmit(,1)".
+func parseFlow(line string) (Flow, bool) {
+	line = strings.TrimSpace(line)
+	i := strings.Index(line, " actions=")
+	if i < 0 {
+		return Flow{}, false
+	}
+	flow := Flow{Cookie: "0x0", Priority: defaultFlowPriority, Actions: line[i+len(" actions="):]}
+	// a flow of the default priority that matches everything has no match
+	// before its actions, so the last field keeps the comma
+	for _, field := range strings.Split(strings.TrimSuffix(line[:i], ","), ", ") {
+		kv := strings.SplitN(field, "=", 2)
+		switch {
+		case kv[0] == "cookie" && len(kv) == 2:
+			flow.Cookie = kv[1]
+		case kv[0] == "table" && len(kv) == 2:
+			flow.Table, _ = strconv.Atoi(kv[1])
+		case kv[0] == "n_packets" && len(kv) == 2:
+			flow.Packets, _ = strconv.ParseInt(kv[1], 10, 64)
+		case kv[0] == "n_bytes" && len(kv) == 2:
+			flow.Bytes, _ = strconv.ParseInt(kv[1], 10, 64)
+		case flowDumpFields[kv[0]]:
+		default:
+			// the match, led by flags and the priority unless it is the
+			// default
+			words := strings.Fields(field)
+			for len(words) > 1 && flowDumpFields[words[0]] {
+				words = words[1:]
+			}
+			match := strings.Join(words, " ")
+			if strings.HasPrefix(match, "priority=") {
+				parts := strings.SplitN(strings.TrimPrefix(match, "priority="), ",", 2)
+				flow.Priority, _ = strconv.Atoi(parts[0])
+				match = ""
+				if len(parts) == 2 {
+					match = parts[1]
+				}
+			}
+			flow.Match = match
+		}
+	}
+	return flow, true
+}
@@ -0,0 +1,47 @@
+package ovs
+
+import "testing"
+
+func TestParseFlow(t *testing.T) {
+	tests := []struct {
+		line string
+		want Flow
+		ok   bool
+	}{
+		{
+			" cookie=0x0, duration=5.1s, table=0, n_packets=3, n_bytes=180, priority=1 actions=resubmit(,1)",
+			Flow{Cookie: "0x0", Table: 0, Priority: 1, Actions: "resubmit(,1)", Packets: 3, Bytes: 180},
+			true,
+		},
+		{
+			" cookie=0x1f, duration=2.0s, table=2, n_packets=0, n_bytes=0, idle_age=2, priority=100,ip,nw_src=10.0.0.2 actions=NORMAL",
+			Flow{Cookie: "0x1f", Table: 2, Priority: 100, Match: "ip,nw_src=10.0.0.2", Actions: "NORMAL"},
+			true,
+		},
+		{
+			" cookie=0x2, duration=1.0s, table=1, n_packets=7, n_bytes=420, send_flow_rem priority=50,arp actions=drop",
+			Flow{Cookie: "0x2", Table: 1, Priority: 50, Match: "arp", Actions: "drop", Packets: 7, Bytes: 420},
+			true,
+		},
+		{
+			// the default priority is not printed
+			" duration=1.0s, table=0, n_packets=1, n_bytes=60, in_port=3 actions=output:1",
+			Flow{Cookie: "0x0", Priority: defaultFlowPriority, Match: "in_port=3", Actions: "output:1", Packets: 1, Bytes: 60},
+			true,
+		},
+		{
+			// nor is an empty match
+			" cookie=0x0, duration=9.2s, table=0, n_packets=3, n_bytes=180, actions=drop",
+			Flow{Cookie: "0x0", Priority: defaultFlowPriority, Actions: "drop", Packets: 3, Bytes: 180},
+			true,
+		},
+		{"NXST_FLOW reply (xid=0x4):", Flow{}, false},
+		{"", Flow{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseFlow(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseFlow(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	dockerapi "github.com/docker/docker/client"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
+)
+
+// vethPeerPrefix names the container side of an endpoint's veth pair
+const vethPeerPrefix = "ethc"
+
+// liveEndpoints returns the truncated ids of the endpoints docker has on
+// the plugin's networks, the suffix of their port and veth names, and those
+// of other runtimes. Only NetworkInspect lists the endpoints of a network,
+// NetworkList leaves them out since API 1.28, and the networks are taken
+// from the bridges in OVSDB as the driver knows none after a restart.
+// Endpoints the driver holds count too, docker lists them only once Join
+// returned. It fails if docker reports no endpoint at all, which is more
+// likely a daemon still starting than a host without containers.
+func (d *Driver) liveEndpoints() (map[string]bool, error) {
+	networks := make(map[string]bool)
+	for _, networkID := range pluginBridges() {
+		if networkID != "" {
+			networks[networkID] = true
+		}
+	}
+	for id := range d.networkStates() {
+		networks[id] = true
+	}
+	for id := range runtimeNetworks() {
+		delete(networks, id)
+	}
+	live := runtimeEndpoints()
+	for id := range d.endpointStates() {
+		live[truncateID(id)] = true
+	}
+	endpoints := 0
+	for networkID := range networks {
+		network, err := d.dockerer.inspectNetwork(networkID)
+		if dockerapi.IsErrNetworkNotFound(err) {
+			// its endpoints are orphans along with it
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range network.Containers {
+			if len(ep.EndpointID) >= 5 {
+				live[truncateID(ep.EndpointID)] = true
+				endpoints++
+			}
+		}
+	}
+	if endpoints == 0 {
+		return nil, fmt.Errorf("docker reports no endpoint on the %d networks of the plugin", len(networks))
+	}
+	return live, nil
+}
+
+// collectOrphans removes the endpoint ports and veths left behind by
+// crashed containers and plugin restarts, those whose endpoint docker no
+// longer knows, and their records in the cluster store. Nothing is
+// removed if docker can't be asked, except the mirrors of captures lost in
+// a restart.
+func (d *Driver) collectOrphans() {
+	d.collectCaptures()
+	live, err := d.liveEndpoints()
+	if err != nil {
+		log.Warnf("skipping cleanup of orphaned ports, could not list docker endpoints: %v", err)
+		return
+	}
+	d.collectClusterEndpoints(live)
+	ports := 0
+	for bridgeName := range pluginBridges() {
+		for _, portName := range bridgePortNames(bridgeName) {
+			if !strings.HasPrefix(portName, ovsPortPrefix) || live[strings.TrimPrefix(portName, ovsPortPrefix)] {
+				continue
+			}
+			if err := d.ovsdber.deletePort(context.Background(), bridgeName, portName); err != nil {
+				log.Warnf("failed to remove orphaned port %s from bridge %s: %v", portName, bridgeName, err)
+				continue
+			}
+			ports++
+		}
+	}
+	links, err := netlink.LinkList()
+	if err != nil {
+		log.Warnf("skipping cleanup of orphaned veths: %v", err)
+		return
+	}
+	veths := 0
+	for _, link := range links {
+		// representors of switchdev endpoints carry the port name too, but
+		// are not ours to delete
+		if link.Type() != "veth" {
+			continue
+		}
+		name := link.Attrs().Name
+		var suffix string
+		switch {
+		case strings.HasPrefix(name, ovsPortPrefix):
+			suffix = strings.TrimPrefix(name, ovsPortPrefix)
+		case strings.HasPrefix(name, vethPeerPrefix):
+			suffix = strings.TrimPrefix(name, vethPeerPrefix)
+		default:
+			continue
+		}
+		if live[suffix] {
+			continue
+		}
+		if err := d.ovsdber.checkLinkOwner(vethPair(suffix).Name); err != nil {
+			log.Warnf("not removing orphaned veth %s: %v", name, err)
+			continue
+		}
+		// deleting either side removes the pair
+		if err := netlink.LinkDel(link); err != nil {
+			log.Debugf("failed to remove orphaned veth %s: %v", name, err)
+			continue
+		}
+		veths++
+	}
+	if ports > 0 || veths > 0 {
+		log.Infof("Removed %d orphaned ports and %d orphaned veths", ports, veths)
+	}
+}
+
+// runMetadataGC collects stale network records right away and then at
+// every interval.
+func (d *Driver) runMetadataGC() {
+	d.collectStaleMetadata()
+	ticker := time.NewTicker(d.gcInterval)
+	for range ticker.C {
+		d.collectStaleMetadata()
+	}
+}
+
+// collectStaleMetadata removes the BridgeOpt rows and bridge external_ids
+// recording a network whose bridge is gone or that docker no longer knows,
+// so lookups by network or bridge never find a dead record. Bridges
+// themselves are left alone, the audit reports them. Definitions of
+// removed global networks are dropped from the cluster store too.
+func (d *Driver) collectStaleMetadata() {
+	networks, err := d.dockerer.listNetworks()
+	if err != nil {
+		log.Warnf("skipping cleanup of stale network records, could not list docker networks: %v", err)
+		return
+	}
+	// docker only lists a network once CreateNetwork returned
+	known := make(map[string]bool)
+	for _, network := range networks {
+		known[network.ID] = true
+	}
+	for id := range d.networkStates() {
+		known[id] = true
+	}
+	for id := range runtimeNetworks() {
+		known[id] = true
+	}
+	bridges := make(map[string]bool)
+	for _, row := range getTableCache("Bridge") {
+		if name, ok := row.Fields["name"].(string); ok {
+			bridges[name] = true
+		}
+	}
+
+	removed := 0
+	if d.ovsdber.hasBridgeOpt() {
+		for _, row := range getTableCache("BridgeOpt") {
+			name, _ := row.Fields["name"].(string)
+			networkID, _ := row.Fields["network_id"].(string)
+			if bridges[name] && (networkID == "" || known[networkID]) {
+				continue
+			}
+			if err := d.ovsdber.deleteBridgeOpt(name, networkID); err != nil {
+				log.Warnf("failed to remove stale BridgeOpt row of bridge %s: %v", name, err)
+				continue
+			}
+			removed++
+		}
+	}
+	for _, row := range getTableCache("Bridge") {
+		name, _ := row.Fields["name"].(string)
+		networkID := ovsMapValue(row.Fields["external_ids"], networkIDKey)
+		if networkID == "" || known[networkID] || !d.ovsdber.rowOwned(row) {
+			continue
+		}
+		if err := d.ovsdber.clearBridgeNetwork(name); err != nil {
+			log.Warnf("failed to remove stale network record of bridge %s: %v", name, err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Infof("Removed %d stale network records", removed)
+	}
+	d.collectClusterNetworks(known)
+}
+
+// deleteBridgeOpt deletes the BridgeOpt row of a bridge and network.
+func (ovsdber *ovsdber) deleteBridgeOpt(bridgeName, networkID string) error {
+	deleteOp := libovsdb.Operation{
+		Op:    "delete",
+		Table: "BridgeOpt",
+		Where: []interface{}{
+			libovsdb.NewCondition("name", "==", bridgeName),
+			libovsdb.NewCondition("network_id", "==", networkID),
+		},
+	}
+	return ovsdber.transact(deleteOp)
+}
+
+// clearBridgeNetwork removes the network keys from a bridge's external_ids.
+func (ovsdber *ovsdber) clearBridgeNetwork(bridgeName string) error {
+	keySet, _ := libovsdb.NewOvsSet([]string{networkIDKey, networkNameKey, networkModeKey, networkTypeKey, pgwGatewayKey, pgwPoolsKey, ctZoneKey})
+	mutateOp := libovsdb.Operation{
+		Op:        "mutate",
+		Table:     "Bridge",
+		Mutations: []interface{}{libovsdb.NewMutation("external_ids", "delete", keySet)},
+		Where:     []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+	}
+	return ovsdber.transact(mutateOp)
+}
@@ -0,0 +1,338 @@
+package ovs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/hashicorp/memberlist"
+)
+
+const (
+	defaultGossipPort = 7946
+	// gossipQueue is how many membership and endpoint changes wait to be
+	// applied. Membership changes block the gossip goroutines beyond it,
+	// endpoint announcements are coalesced in pending.
+	gossipQueue = 256
+)
+
+// gossiper is a memberlist control plane between hosts: members become
+// tunnel peers and the endpoints of overlay networks are spread to every
+// host, which answers ARP for them and forwards to their host's tunnel.
+// No central database is needed.
+type gossiper struct {
+	d *Driver
+	// name is the host's member name
+	name       string
+	list       *memberlist.Memberlist
+	broadcasts *memberlist.TransmitLimitedQueue
+	// changes applies membership and endpoint changes in order, away from
+	// the memberlist goroutines that report them
+	changes chan func()
+
+	mu sync.Mutex
+	// local are the endpoints of this host, remote those of the others,
+	// both by endpoint id
+	local  map[string]OverlayEndpoint
+	remote map[string]OverlayEndpoint
+	// pending are the announcements received but not learned yet, the
+	// latest of each endpoint
+	pending map[string]OverlayEndpoint
+}
+
+// startGossip joins the gossip of the hosts at join, or starts a new one,
+// listening on listen. key is the base64 AES key gossip is encrypted with,
+// 16, 24 or 32 bytes. Gossip programs the flows of every host, so it is
+// never unencrypted.
+func (d *Driver) startGossip(listen string, join []string, key string) error {
+	if key == "" {
+		return fmt.Errorf("gossip needs a key, generate one with: head -c 32 /dev/urandom | base64")
+	}
+	host, portStr, err := net.SplitHostPort(listen)
+	if err != nil {
+		return fmt.Errorf("invalid gossip address %s, want [<ip>]:<port>: %v", listen, err)
+	}
+	port := defaultGossipPort
+	if portStr != "" {
+		if port, err = strconv.Atoi(portStr); err != nil {
+			return fmt.Errorf("invalid gossip port %s", portStr)
+		}
+	}
+	g := &gossiper{
+		d:       d,
+		changes: make(chan func(), gossipQueue),
+		local:   make(map[string]OverlayEndpoint),
+		remote:  make(map[string]OverlayEndpoint),
+		pending: make(map[string]OverlayEndpoint),
+	}
+	config := memberlist.DefaultLANConfig()
+	config.Name = d.nodeName()
+	g.name = config.Name
+	if host != "" {
+		config.BindAddr = host
+		if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+			d.localAddress = host
+		}
+	}
+	config.BindPort = port
+	config.AdvertisePort = port
+	config.Delegate = g
+	config.Events = g
+	config.LogOutput = gossipLog{}
+	if config.SecretKey, err = base64.StdEncoding.DecodeString(key); err != nil {
+		return fmt.Errorf("invalid gossip key: %v", err)
+	}
+	go g.applyChanges()
+	if g.list, err = memberlist.Create(config); err != nil {
+		return fmt.Errorf("failed to start gossip: %v", err)
+	}
+	g.broadcasts = &memberlist.TransmitLimitedQueue{
+		NumNodes:       g.list.NumMembers,
+		RetransmitMult: config.RetransmitMult,
+	}
+	d.gossip = g
+	log.Infof("Gossiping as [ %s ] on %s:%d", config.Name, g.list.LocalNode().Addr, port)
+	if len(join) > 0 {
+		n, err := g.list.Join(join)
+		if err != nil && n == 0 {
+			return fmt.Errorf("failed to join the gossip of %v: %v", join, err)
+		}
+		log.Infof("Joined the gossip through %d of %v", n, join)
+	}
+	return nil
+}
+
+func (g *gossiper) applyChanges() {
+	for change := range g.changes {
+		change()
+	}
+}
+
+// announce spreads an endpoint of an overlay network, or its removal.
+func (g *gossiper) announce(ep OverlayEndpoint) {
+	g.mu.Lock()
+	if ep.Deleted {
+		delete(g.local, ep.EndpointID)
+	} else {
+		g.local[ep.EndpointID] = ep
+	}
+	g.mu.Unlock()
+	msg, err := json.Marshal(ep)
+	if err != nil {
+		return
+	}
+	g.broadcasts.QueueBroadcast(endpointBroadcast{id: ep.EndpointID, msg: msg})
+}
+
+// withdraw announces that an endpoint of this host is gone.
+func (g *gossiper) withdraw(endpointID string) {
+	g.mu.Lock()
+	ep, ok := g.local[endpointID]
+	g.mu.Unlock()
+	if !ok {
+		return
+	}
+	ep.Deleted = true
+	g.announce(ep)
+}
+
+// learn records an endpoint of another host and programs it on the bridge
+// of its overlay network, if the host has that network.
+func (g *gossiper) learn(ep OverlayEndpoint) {
+	if ep.Node == g.name {
+		return
+	}
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	g.mu.Lock()
+	old, known := g.remote[ep.EndpointID]
+	if ep.Deleted {
+		delete(g.remote, ep.EndpointID)
+	} else {
+		g.remote[ep.EndpointID] = ep
+	}
+	g.mu.Unlock()
+	if known {
+		if !ep.Deleted && old == ep {
+			return
+		}
+		g.d.unprogramRemote(old)
+	}
+	if !ep.Deleted {
+		g.d.programRemote(ep)
+	}
+}
+
+// receive queues an announcement to be learned. Announcements of the same
+// endpoint replace each other until they are learned, and none waits for
+// room in the queue: when it is full, the announcement is learned with the
+// next one that finds room.
+func (g *gossiper) receive(ep OverlayEndpoint) {
+	g.mu.Lock()
+	g.pending[ep.EndpointID] = ep
+	g.mu.Unlock()
+	select {
+	case g.changes <- g.learnPending:
+	default:
+		log.Debugf("gossip queue full, deferring endpoint %s", truncateID(ep.EndpointID))
+	}
+}
+
+// learnPending learns the announcements received so far.
+func (g *gossiper) learnPending() {
+	g.mu.Lock()
+	pending := g.pending
+	g.pending = make(map[string]OverlayEndpoint)
+	g.mu.Unlock()
+	for _, ep := range pending {
+		g.learn(ep)
+	}
+}
+
+// forgetNode removes the endpoints and tunnels of a host that left.
+func (g *gossiper) forgetNode(name, vtep string) {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	g.mu.Lock()
+	var gone []OverlayEndpoint
+	for id, ep := range g.remote {
+		if ep.Node == name {
+			gone = append(gone, ep)
+			delete(g.remote, id)
+		}
+	}
+	for id, ep := range g.pending {
+		if ep.Node == name {
+			delete(g.pending, id)
+		}
+	}
+	g.mu.Unlock()
+	for _, ep := range gone {
+		g.d.unprogramRemote(ep)
+	}
+	if err := g.d.removePeer(vtep); err != nil {
+		log.Warnf("%v", err)
+	}
+}
+
+// remoteEndpoints returns the endpoints learned from other hosts.
+func (g *gossiper) remoteEndpoints() []OverlayEndpoint {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	eps := make([]OverlayEndpoint, 0, len(g.remote))
+	for _, ep := range g.remote {
+		eps = append(eps, ep)
+	}
+	return eps
+}
+
+// NodeMeta is part of memberlist.Delegate, hosts carry no metadata.
+func (g *gossiper) NodeMeta(limit int) []byte {
+	return nil
+}
+
+// NotifyMsg receives an endpoint announcement.
+func (g *gossiper) NotifyMsg(msg []byte) {
+	var ep OverlayEndpoint
+	if err := json.Unmarshal(msg, &ep); err != nil {
+		log.Warnf("invalid gossip message: %v", err)
+		return
+	}
+	g.receive(ep)
+}
+
+func (g *gossiper) GetBroadcasts(overhead, limit int) [][]byte {
+	return g.broadcasts.GetBroadcasts(overhead, limit)
+}
+
+// LocalState sends the endpoints of this host to a host syncing with it,
+// so it catches up on announcements it missed.
+func (g *gossiper) LocalState(join bool) []byte {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	eps := make([]OverlayEndpoint, 0, len(g.local))
+	for _, ep := range g.local {
+		eps = append(eps, ep)
+	}
+	state, _ := json.Marshal(eps)
+	return state
+}
+
+func (g *gossiper) MergeRemoteState(state []byte, join bool) {
+	var eps []OverlayEndpoint
+	if err := json.Unmarshal(state, &eps); err != nil {
+		log.Warnf("invalid gossip state: %v", err)
+		return
+	}
+	for _, ep := range eps {
+		g.receive(ep)
+	}
+}
+
+// NotifyJoin is part of memberlist.EventDelegate, a host that joins
+// becomes a tunnel peer.
+func (g *gossiper) NotifyJoin(node *memberlist.Node) {
+	if node.Name == g.name {
+		return
+	}
+	peer := node.Addr.String()
+	g.changes <- func() {
+		reconcileMu.Lock()
+		defer reconcileMu.Unlock()
+		if err := g.d.addPeer(peer); err != nil {
+			log.Warnf("%v", err)
+		}
+	}
+}
+
+func (g *gossiper) NotifyLeave(node *memberlist.Node) {
+	if node.Name == g.name {
+		return
+	}
+	name, peer := node.Name, node.Addr.String()
+	g.changes <- func() { g.forgetNode(name, peer) }
+}
+
+func (g *gossiper) NotifyUpdate(node *memberlist.Node) {}
+
+// endpointBroadcast is the announcement of an endpoint, a newer one of the
+// same endpoint replaces it in the queue.
+type endpointBroadcast struct {
+	id  string
+	msg []byte
+}
+
+func (b endpointBroadcast) Invalidates(other memberlist.Broadcast) bool {
+	o, ok := other.(endpointBroadcast)
+	return ok && o.id == b.id
+}
+
+func (b endpointBroadcast) Message() []byte {
+	return b.msg
+}
+
+func (b endpointBroadcast) Finished() {}
+
+// gossipLog passes memberlist's log lines, "[DEBUG] memberlist: ...", to
+// the plugin's log at their level.
+type gossipLog struct{}
+
+func (gossipLog) Write(p []byte) (int, error) {
+	line := string(bytes.TrimSpace(p))
+	switch {
+	case bytes.HasPrefix(p, []byte("[DEBUG]")):
+		log.Debug(line)
+	case bytes.HasPrefix(p, []byte("[WARN]")):
+		log.Warn(line)
+	case bytes.HasPrefix(p, []byte("[ERR]")):
+		log.Error(line)
+	default:
+		log.Info(line)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,107 @@
+package ovs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// healthTimeout is how long each check of /healthz may take, monitors
+// time out the whole request soon after
+const healthTimeout = 2 * time.Second
+
+// HealthCheck is the outcome of one check of /healthz.
+type HealthCheck struct {
+	OK    bool
+	Error string `json:",omitempty"`
+}
+
+// Health is what /healthz returns. The plugin is healthy when all checks
+// pass, the reconciler may be behind without failing it.
+type Health struct {
+	Healthy bool
+	Checks  map[string]HealthCheck
+	// ReconcilePending is set when bridges or ports changed and the
+	// reconciler hasn't run since
+	ReconcilePending bool
+	LastReconcile    time.Time
+	// FailedNetworks is how many networks the last reconciler run could
+	// not repair
+	FailedNetworks   int
+	UpdateQueueDepth int
+}
+
+// health runs all checks.
+func (d *Driver) health() Health {
+	h := Health{
+		Checks: map[string]HealthCheck{
+			"ovsdb":    healthCheck(d.ovsdber.ping()),
+			"docker":   healthCheck(d.dockerer.ping()),
+			"rootUUID": healthCheck(rootUUIDCheck()),
+		},
+		ReconcilePending: len(bridgesChanged) > 0,
+		UpdateQueueDepth: updates.snapshot().Depth,
+	}
+	reconcileStatus.Lock()
+	h.LastReconcile = reconcileStatus.last
+	h.FailedNetworks = reconcileStatus.failed
+	reconcileStatus.Unlock()
+	h.Healthy = true
+	for _, check := range h.Checks {
+		h.Healthy = h.Healthy && check.OK
+	}
+	return h
+}
+
+func healthCheck(err error) HealthCheck {
+	if err != nil {
+		return HealthCheck{Error: err.Error()}
+	}
+	return HealthCheck{OK: true}
+}
+
+// ping checks that ovsdb-server answers a transaction.
+func (ovsdber *ovsdber) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	selectOp := libovsdb.Operation{
+		Op:      "select",
+		Table:   "Open_vSwitch",
+		Columns: []string{"_uuid"},
+		Where:   []interface{}{rootCondition()},
+	}
+	_, err := transactContext(ctx, ovsdber.client(), selectOp)
+	return err
+}
+
+// ping checks that the docker daemon answers on its socket.
+func (dockerer *dockerer) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	_, err := dockerer.client.ServerVersion(ctx)
+	return err
+}
+
+func rootUUIDCheck() error {
+	if ovsdbCache.rootUUID() == "" {
+		return errors.New("the Open_vSwitch row is not cached")
+	}
+	return nil
+}
+
+// handleHealth serves /healthz, with status 503 when a check fails.
+func (d *Driver) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	h := d.health()
+	status := http.StatusOK
+	if !h.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, h)
+}
@@ -0,0 +1,68 @@
+package ovs
+
+import (
+	"fmt"
+	"net"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	// above the conntrack commit flows of security groups, which would
+	// otherwise switch endpoint traffic normally. The allow flows of
+	// security groups are above these and hand what they let through back
+	// to them.
+	iccAllowPriority = 160
+	iccDropPriority  = 155
+)
+
+// disableICC isolates the endpoints of a bridge from each other, mirroring
+// enable_icc=false of the docker bridge driver. Endpoints may only send to
+// the uplink port, the gateway in nat mode, and traffic coming in from the
+// uplink is switched normally. Outbound connections are committed so
+// that the security groups of endpoints see the replies as established.
+// Flows of higher priority such as service chains take precedence. The
+// security groups of endpoints on such a bridge hand the traffic they let
+// through back to the policy table rather than switching it, so it is
+// checked here too, see secGroupFlows.
+//
+// In nat mode an endpoint could still reach another through the gateway,
+// which routes the packet back into the bridge. Such packets come in from
+// the LOCAL port with the address of an endpoint as source and are
+// dropped, only the gateway's own address may send to the endpoints. The
+// FORWARD rules of the network drop them before that, see isolateNetwork.
+func disableICC(networkID, bridgeName, uplinkPort, gatewayCIDR string, zone int) error {
+	flows, err := iccFlows(networkID, uplinkPort, gatewayCIDR, zone)
+	if err != nil {
+		return err
+	}
+	for _, flow := range flows {
+		if err := addFlow(bridgeName, policyTable, flow); err != nil {
+			return err
+		}
+	}
+	log.Infof("Disabled inter-container communication on bridge [ %s ]", bridgeName)
+	return nil
+}
+
+// iccFlows returns the policy table flows of disableICC.
+func iccFlows(networkID, uplinkPort, gatewayCIDR string, zone int) ([]string, error) {
+	// a distinct cookie keeps these flows apart from the service chain ones
+	cookie := flowCookie("1cc" + networkID)
+	var flows []string
+	if gatewayCIDR != "" {
+		gateway, subnet, err := net.ParseCIDR(gatewayCIDR)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows,
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,ip,nw_src=%s,actions=NORMAL", cookie, iccAllowPriority+30, uplinkPort, gateway),
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,ip,nw_src=%s,actions=drop", cookie, iccAllowPriority+20, uplinkPort, subnet))
+	}
+	flows = append(flows,
+		fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,actions=NORMAL", cookie, iccAllowPriority+10, uplinkPort),
+		fmt.Sprintf("cookie=%s,priority=%d,ip,actions=%s,output:%s", cookie, iccAllowPriority, ctCommit(zone), uplinkPort),
+		fmt.Sprintf("cookie=%s,priority=%d,arp,actions=output:%s", cookie, iccAllowPriority, uplinkPort),
+		fmt.Sprintf("cookie=%s,priority=%d,actions=drop", cookie, iccDropPriority))
+	return flows, nil
+}
@@ -0,0 +1,220 @@
+package ovs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+// DriverState is the internal state of the driver, what /state returns.
+type DriverState struct {
+	Networks  map[string]*NetworkState
+	Endpoints map[string]*EndpointState
+}
+
+// ReconcileResult is what POST /reconcile returns.
+type ReconcileResult struct {
+	Time   time.Time
+	Failed int
+}
+
+// CleanRequest asks /endpoints/clean to remove an endpoint, by its full id
+// or a prefix of at least five characters.
+type CleanRequest struct {
+	EndpointID string
+}
+
+// CleanResult lists what was removed for an endpoint.
+type CleanResult struct {
+	EndpointID string
+	NetworkID  string `json:",omitempty"`
+	Removed    []string
+}
+
+// handleState serves /state: GET returns the networks and endpoints the
+// driver holds, those of one network with ?network=<id>.
+func (d *Driver) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	network := r.URL.Query().Get("network")
+	state := DriverState{
+		Networks:  make(map[string]*NetworkState),
+		Endpoints: make(map[string]*EndpointState),
+	}
+	// encoded with the lock held, the states are changed in place
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	for id, ns := range d.networkStates() {
+		if network == "" || id == network {
+			state.Networks[id] = ns
+		}
+	}
+	for id, es := range d.endpointStates() {
+		if network == "" || es.NetworkID == network {
+			state.Endpoints[id] = es
+		}
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleOVSDB serves /ovsdb: GET returns the OVSDB cache, the rows of each
+// table by uuid, or of one table with ?table=<name>.
+func (d *Driver) handleOVSDB(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	tables := []string{r.URL.Query().Get("table")}
+	if tables[0] == "" {
+		tables = tables[:0]
+		for table := range ovsdbCache.sizes() {
+			tables = append(tables, table)
+		}
+	}
+	dump := make(map[string]map[string]map[string]interface{})
+	for _, table := range tables {
+		rows := make(map[string]map[string]interface{})
+		for uuid, row := range getTableCache(table) {
+			rows[uuid] = row.Fields
+		}
+		dump[table] = rows
+	}
+	writeJSON(w, http.StatusOK, dump)
+}
+
+// handleReconcile serves /reconcile: POST repairs the host right away
+// instead of at the next change or interval.
+func (d *Driver) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	log.Infof("Reconciling on request of the admin API")
+	d.reconcile()
+	reconcileStatus.Lock()
+	result := ReconcileResult{Time: reconcileStatus.last, Failed: reconcileStatus.failed}
+	reconcileStatus.Unlock()
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleCleanEndpoint serves /endpoints/clean: POST removes an endpoint
+// docker lost track of, see cleanEndpoint.
+func (d *Driver) handleCleanEndpoint(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	var req CleanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.EndpointID) < truncatedIDLen {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("endpoint id %q is shorter than %d characters", req.EndpointID, truncatedIDLen))
+		return
+	}
+	result, err := d.cleanEndpoint(req.EndpointID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// cleanEndpoint removes an endpoint whatever docker thinks of it: it leaves
+// and is deleted as docker would, then its port, veth and state are removed
+// if they are still around.
+func (d *Driver) cleanEndpoint(id string) (*CleanResult, error) {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	endpointID, networkID, err := d.resolveEndpoint(id)
+	if err != nil {
+		return nil, err
+	}
+	result := &CleanResult{EndpointID: endpointID, NetworkID: networkID}
+	portName := ovsPortPrefix + truncateID(endpointID)
+	// the cache may still have the port Leave removed
+	left := false
+	if networkID != "" {
+		if portUUIDForName(portName) != "" {
+			if err := d.leaveEndpoint(context.Background(), networkID, endpointID); err != nil {
+				log.Warnf("failed to leave endpoint %s, removing its port directly: %v", truncateID(endpointID), err)
+			} else {
+				left = true
+				result.Removed = append(result.Removed, "port "+portName)
+			}
+		}
+		if _, ok := d.endpoint(endpointID); ok {
+			result.Removed = append(result.Removed, "state")
+		}
+		d.deleteEndpoint(context.Background(), endpointID)
+	}
+	if bridgeName := bridgeNameForPort(portName); bridgeName != "" && !left {
+		if err := d.ovsdber.deletePort(context.Background(), bridgeName, portName); err != nil {
+			return result, err
+		}
+		result.Removed = append(result.Removed, "port "+portName)
+	}
+	veth := vethPair(truncateID(endpointID))
+	if _, err := netlink.LinkByName(veth.Name); err == nil {
+		if err := d.ovsdber.checkLinkOwner(veth.Name); err != nil {
+			return result, err
+		}
+		if err := netlink.LinkDel(veth); err != nil {
+			return result, fmt.Errorf("failed to remove veth %s: %v", veth.Name, err)
+		}
+		result.Removed = append(result.Removed, "veth "+veth.Name)
+	}
+	forgetContainer(endpointID)
+	log.Infof("Cleaned endpoint [ %s ] on request of the admin API, removed %v", truncateID(endpointID), result.Removed)
+	return result, nil
+}
+
+// resolveEndpoint expands an endpoint id prefix to the full id and finds
+// the network of the endpoint, from the driver's state or the external_ids
+// of its port. The network is empty if neither knows the endpoint. A
+// prefix of more than one endpoint is an error, rather than whichever
+// endpoint comes first.
+func (d *Driver) resolveEndpoint(id string) (string, string, error) {
+	matches := make(map[string]string)
+	for endpointID, es := range d.endpointStates() {
+		if strings.HasPrefix(endpointID, id) {
+			matches[endpointID] = es.NetworkID
+		}
+	}
+	for _, row := range getTableCache("Interface") {
+		endpointID := ovsMapValue(row.Fields["external_ids"], endpointIDKey)
+		if endpointID == "" || !strings.HasPrefix(endpointID, id) {
+			continue
+		}
+		if _, ok := matches[endpointID]; !ok {
+			matches[endpointID] = ovsdbCache.pluginBridges()[bridgeNameForPort(ovsPortPrefix+truncateID(endpointID))]
+		}
+	}
+	if networkID, ok := matches[id]; ok {
+		return id, networkID, nil
+	}
+	switch len(matches) {
+	case 0:
+		return id, "", nil
+	case 1:
+		for endpointID, networkID := range matches {
+			return endpointID, networkID, nil
+		}
+	}
+	ids := make([]string, 0, len(matches))
+	for endpointID := range matches {
+		ids = append(ids, endpointID)
+	}
+	sort.Strings(ids)
+	return "", "", fmt.Errorf("endpoint id %s is ambiguous, it matches %s", id, strings.Join(ids, ", "))
+}
@@ -0,0 +1,35 @@
+package ovs
+
+import "testing"
+
+func TestResolveEndpoint(t *testing.T) {
+	d := &Driver{endpoints: map[string]*EndpointState{
+		"abcdef0123": {NetworkID: "net1"},
+		"abcdef4567": {NetworkID: "net2"},
+		"abc":        {NetworkID: "net3"},
+		"123456789a": {NetworkID: "net4"},
+	}}
+	tests := []struct {
+		id         string
+		endpointID string
+		networkID  string
+		wantErr    bool
+	}{
+		{id: "12345", endpointID: "123456789a", networkID: "net4"},
+		{id: "abcdef0", endpointID: "abcdef0123", networkID: "net1"},
+		// an exact id wins over the longer ids it is a prefix of
+		{id: "abc", endpointID: "abc", networkID: "net3"},
+		{id: "abcde", wantErr: true},
+		{id: "fffff", endpointID: "fffff"},
+	}
+	for _, tt := range tests {
+		endpointID, networkID, err := d.resolveEndpoint(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveEndpoint(%s): err = %v, wantErr %v", tt.id, err, tt.wantErr)
+			continue
+		}
+		if endpointID != tt.endpointID || networkID != tt.networkID {
+			t.Errorf("resolveEndpoint(%s) = %s, %s, want %s, %s", tt.id, endpointID, networkID, tt.endpointID, tt.networkID)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ovs
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// IPFIX collectors the bridge exports flow records to, as a comma
+	// separated list of ip:port
+	ipfixOption = "linker.net.ovs.bridge.ipfix"
+	// One in this many packets is sampled, the switch defaults to 400
+	ipfixSamplingOption = "linker.net.ovs.bridge.ipfix_sampling"
+	// Observation domain and point ids of the records
+	ipfixDomainOption = "linker.net.ovs.bridge.ipfix_obs_domain_id"
+	ipfixPointOption  = "linker.net.ovs.bridge.ipfix_obs_point_id"
+	// Flow cache: seconds a flow is aggregated before its record is
+	// exported, and how many flows are cached, 0 disables the cache
+	ipfixCacheTimeoutOption = "linker.net.ovs.bridge.ipfix_cache_active_timeout"
+	ipfixCacheMaxOption     = "linker.net.ovs.bridge.ipfix_cache_max_flows"
+)
+
+// IPFIXConfig is the IPFIX export of a network's bridge.
+type IPFIXConfig struct {
+	Targets            []string
+	Sampling           int `json:",omitempty"`
+	ObsDomainID        int `json:",omitempty"`
+	ObsPointID         int `json:",omitempty"`
+	CacheActiveTimeout int `json:",omitempty"`
+	CacheMaxFlows      int `json:",omitempty"`
+}
+
+// getIPFIX returns the IPFIX settings of a network, nil if it has none.
+func getIPFIX(r *dknet.CreateNetworkRequest) (*IPFIXConfig, error) {
+	targets, err := getCollectors(r, ipfixOption)
+	if err != nil {
+		return nil, err
+	}
+	config := &IPFIXConfig{Targets: targets}
+	for option, value := range map[string]*int{
+		ipfixSamplingOption:     &config.Sampling,
+		ipfixDomainOption:       &config.ObsDomainID,
+		ipfixPointOption:        &config.ObsPointID,
+		ipfixCacheTimeoutOption: &config.CacheActiveTimeout,
+		ipfixCacheMaxOption:     &config.CacheMaxFlows,
+	} {
+		if *value, err = getPositiveIntOption(r, option); err != nil {
+			return nil, err
+		}
+		if *value != 0 && len(targets) == 0 {
+			return nil, fmt.Errorf("%s requires %s to be set", option, ipfixOption)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	return config, nil
+}
+
+// setIPFIX inserts an IPFIX row and references it from the bridge, like
+// setNetFlow.
+func (ovsdber *ovsdber) setIPFIX(bridgeName string, config *IPFIXConfig) error {
+	ipfix := map[string]interface{}{
+		"targets":      collectorSet(config.Targets),
+		"external_ids": ovsdber.ownerExternalIDs(),
+	}
+	for column, value := range map[string]int{
+		"sampling":             config.Sampling,
+		"obs_domain_id":        config.ObsDomainID,
+		"obs_point_id":         config.ObsPointID,
+		"cache_active_timeout": config.CacheActiveTimeout,
+		"cache_max_flows":      config.CacheMaxFlows,
+	} {
+		if value > 0 {
+			ipfix[column] = value
+		}
+	}
+	operations := []libovsdb.Operation{
+		{Op: "insert", Table: "IPFIX", Row: ipfix, UUIDName: "ipfix"},
+		{
+			Op:    "update",
+			Table: "Bridge",
+			Row:   map[string]interface{}{"ipfix": libovsdb.UUID{GoUuid: "ipfix"}},
+			Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+		},
+	}
+	if err := ovsdber.transact(operations...); err != nil {
+		return err
+	}
+	log.Infof("Exporting IPFIX of bridge [ %s ] to %v", bridgeName, config.Targets)
+	return nil
+}
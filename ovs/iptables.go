@@ -0,0 +1,251 @@
+package ovs
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/libnetwork/iptables"
+)
+
+const (
+	chainPrefix     = "LINKER-OVS-"
+	dnatChainPrefix = "LINKER-DNAT-"
+)
+
+// iptablesFirewall programs the rules through the legacy iptables binary
+type iptablesFirewall struct{}
+
+// networkChainName returns the name of the iptables chain owned by a network.
+// The same name is used in both the nat and filter tables.
+func networkChainName(networkID string) string {
+	return chainPrefix + truncateID(networkID)
+}
+
+// setupNetwork creates the per-network chains and hooks them into
+// POSTROUTING and FORWARD. The jump rules only reference the chain and the
+// bridge so that they can be removed without knowing the subnet.
+func (iptablesFirewall) setupNetwork(ctx context.Context, networkID, bridgeName, cidr string) error {
+	chain := networkChainName(networkID)
+
+	natChain, err := iptables.NewChain(chain, iptables.Nat, false)
+	if err != nil {
+		return err
+	}
+	masquerade := []string{"-s", cidr, "!", "-o", bridgeName, "-j", "MASQUERADE"}
+	if err := appendRule(natChain, masquerade...); err != nil {
+		return err
+	}
+	if err := insertRule(iptables.Nat, "POSTROUTING", "-j", chain); err != nil {
+		return err
+	}
+
+	filterChain, err := iptables.NewChain(chain, iptables.Filter, false)
+	if err != nil {
+		return err
+	}
+	outbound := []string{"-i", bridgeName, "-j", "ACCEPT"}
+	if err := appendRule(filterChain, outbound...); err != nil {
+		return err
+	}
+	inbound := []string{"-o", bridgeName, "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"}
+	if err := appendRule(filterChain, inbound...); err != nil {
+		return err
+	}
+	if err := insertRule(iptables.Filter, "FORWARD", "-i", bridgeName, "-j", chain); err != nil {
+		return err
+	}
+	return insertRule(iptables.Filter, "FORWARD", "-o", bridgeName, "-j", chain)
+}
+
+// isolateNetwork puts a rule dropping traffic between the bridge and itself
+// ahead of the accept rules of the network's filter chain.
+func (iptablesFirewall) isolateNetwork(ctx context.Context, networkID, bridgeName string) error {
+	return insertRule(iptables.Filter, networkChainName(networkID), "-i", bridgeName, "-o", bridgeName, "-j", "DROP")
+}
+
+// teardownNetwork removes the jump rules and deletes the per-network
+// chains. Errors are logged rather than returned since the chains may never
+// have been created, e.g. for flat mode networks.
+func (iptablesFirewall) teardownNetwork(ctx context.Context, networkID, bridgeName string) {
+	chain := networkChainName(networkID)
+
+	deleteJump(iptables.Nat, "POSTROUTING", "-j", chain)
+	deleteJump(iptables.Filter, "FORWARD", "-i", bridgeName, "-j", chain)
+	deleteJump(iptables.Filter, "FORWARD", "-o", bridgeName, "-j", chain)
+
+	dnatChain := dnatChainName(networkID)
+	if err := iptables.RemoveExistingChain(dnatChain, iptables.Nat); err != nil {
+		log.Warnf("failed to remove nat chain %s: %v", dnatChain, err)
+	}
+	if err := iptables.RemoveExistingChain(chain, iptables.Nat); err != nil {
+		log.Warnf("failed to remove nat chain %s: %v", chain, err)
+	}
+	if err := iptables.RemoveExistingChain(chain, iptables.Filter); err != nil {
+		log.Warnf("failed to remove filter chain %s: %v", chain, err)
+	}
+	log.Debugf("removed iptables chain %s for bridge %s", chain, bridgeName)
+}
+
+// dnatChainName returns the name of the nat chain holding a network's
+// published ports. It is separate from the masquerade chain since it is
+// reached from PREROUTING and OUTPUT rather than POSTROUTING.
+func dnatChainName(networkID string) string {
+	return dnatChainPrefix + truncateID(networkID)
+}
+
+// programPortMapping adds or removes the DNAT rule and the matching FORWARD
+// accept rule for a published port.
+func (iptablesFirewall) programPortMapping(ctx context.Context, enable bool, networkID, bridgeName, containerIP string, b portBinding) error {
+	dnatChain := &iptables.ChainInfo{Name: dnatChainName(networkID), Table: iptables.Nat}
+	if enable {
+		c, err := iptables.NewChain(dnatChain.Name, iptables.Nat, false)
+		if err != nil {
+			return err
+		}
+		if err := iptables.ProgramChain(c, bridgeName, false, true); err != nil {
+			return err
+		}
+		dnatChain = c
+	}
+
+	dnat := []string{"-p", b.Proto}
+	if b.HostIP != "" && !net.ParseIP(b.HostIP).IsUnspecified() {
+		dnat = append(dnat, "-d", b.HostIP)
+	}
+	dnat = append(dnat,
+		"--dport", strconv.Itoa(b.HostPort),
+		"!", "-i", bridgeName,
+		"-j", "DNAT",
+		"--to-destination", net.JoinHostPort(containerIP, strconv.Itoa(b.Port)))
+
+	accept := []string{
+		"!", "-i", bridgeName,
+		"-o", bridgeName,
+		"-p", b.Proto,
+		"-d", containerIP,
+		"--dport", strconv.Itoa(b.Port),
+		"-j", "ACCEPT",
+	}
+	filterChain := &iptables.ChainInfo{Name: networkChainName(networkID), Table: iptables.Filter}
+
+	if enable {
+		if err := appendRule(dnatChain, dnat...); err != nil {
+			return err
+		}
+		if err := appendRule(filterChain, accept...); err != nil {
+			// a DNAT rule without its accept rule would blackhole the port
+			deleteRule(dnatChain, dnat...)
+			return err
+		}
+		return nil
+	}
+	deleteRule(dnatChain, dnat...)
+	deleteRule(filterChain, accept...)
+	return nil
+}
+
+func appendRule(c *iptables.ChainInfo, rule ...string) error {
+	if iptables.Exists(c.Table, c.Name, rule...) {
+		return nil
+	}
+	args := append([]string{"-t", string(c.Table), string(iptables.Append), c.Name}, rule...)
+	if output, err := iptables.Raw(args...); err != nil {
+		return err
+	} else if len(output) > 0 {
+		return &iptables.ChainError{Chain: c.Name, Output: output}
+	}
+	return nil
+}
+
+func deleteRule(c *iptables.ChainInfo, rule ...string) {
+	deleteJump(c.Table, c.Name, rule...)
+}
+
+func insertRule(table iptables.Table, parent string, rule ...string) error {
+	if iptables.Exists(table, parent, rule...) {
+		return nil
+	}
+	args := append([]string{"-t", string(table), string(iptables.Insert), parent}, rule...)
+	if output, err := iptables.Raw(args...); err != nil {
+		return err
+	} else if len(output) > 0 {
+		return &iptables.ChainError{Chain: parent, Output: output}
+	}
+	return nil
+}
+
+func deleteJump(table iptables.Table, parent string, rule ...string) {
+	if !iptables.Exists(table, parent, rule...) {
+		return
+	}
+	args := append([]string{"-t", string(table), string(iptables.Delete), parent}, rule...)
+	if output, err := iptables.Raw(args...); err != nil || len(output) > 0 {
+		log.Warnf("failed to delete %s rule %v: %v %s", parent, rule, err, output)
+	}
+}
+
+// programFloatingIP adds or removes the DNAT, SNAT and FORWARD rules of a
+// floating ip. The DNAT rule goes to the network's DNAT chain which is
+// reached for all local addresses, the SNAT rule is put ahead of the
+// network's masquerade rule.
+func (iptablesFirewall) programFloatingIP(ctx context.Context, enable bool, es *EndpointState) error {
+	dnat := []string{"-d", es.FloatingIP, "-j", "DNAT", "--to-destination", es.Address}
+	snat := []string{"-s", es.Address, "-j", "SNAT", "--to-source", es.FloatingIP}
+	accept := []string{"-o", es.BridgeName, "-d", es.Address, "-j", "ACCEPT"}
+
+	natChain := &iptables.ChainInfo{Name: networkChainName(es.NetworkID), Table: iptables.Nat}
+	filterChain := &iptables.ChainInfo{Name: networkChainName(es.NetworkID), Table: iptables.Filter}
+	dnatChain := &iptables.ChainInfo{Name: dnatChainName(es.NetworkID), Table: iptables.Nat}
+
+	if !enable {
+		deleteRule(dnatChain, dnat...)
+		deleteRule(natChain, snat...)
+		deleteRule(filterChain, accept...)
+		return nil
+	}
+
+	c, err := iptables.NewChain(dnatChain.Name, iptables.Nat, false)
+	if err != nil {
+		return err
+	}
+	if err := iptables.ProgramChain(c, es.BridgeName, false, true); err != nil {
+		return err
+	}
+	if err := appendRule(c, dnat...); err != nil {
+		return err
+	}
+	if err := insertRule(natChain.Table, natChain.Name, snat...); err != nil {
+		return err
+	}
+	return appendRule(filterChain, accept...)
+}
+
+// listRules returns the rules of the network's chains in iptables -S form.
+// Chains that can't be listed, e.g. the DNAT chain of a network without
+// published ports, are skipped.
+func (iptablesFirewall) listRules(networkID string) ([]string, error) {
+	chains := []struct {
+		table iptables.Table
+		name  string
+	}{
+		{iptables.Nat, networkChainName(networkID)},
+		{iptables.Nat, dnatChainName(networkID)},
+		{iptables.Filter, networkChainName(networkID)},
+	}
+	var rules []string
+	for _, c := range chains {
+		out, err := iptables.Raw("-t", string(c.table), "-S", c.name)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if strings.HasPrefix(line, "-A ") {
+				rules = append(rules, "-t "+string(c.table)+" "+line)
+			}
+		}
+	}
+	return rules, nil
+}
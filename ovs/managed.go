@@ -0,0 +1,167 @@
+package ovs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	// managedSocket is the socket of a managed plugin in docker's plugin
+	// directory, interface.socket of its config.json
+	managedSocket = "ovs.sock"
+	// gatewayStopTimeout is how long the gateway process has to exit
+	// before it is killed
+	gatewayStopTimeout = 10 * time.Second
+)
+
+// gatewayUnits is set while the gateway service runs as a systemd unit, it
+// is cleared when units can't be installed and the gateway runs as a child
+// process of the plugin
+var gatewayUnits = true
+
+// gatewayProcess is the gateway service run as a child process
+var gatewayProcess struct {
+	sync.Mutex
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// applyManaged resolves the paths of the host a plugin confined to its
+// rootfs reaches through hostRoot, and picks how the gateway service runs.
+// A managed (v2) plugin runs in its own rootfs, /etc/systemd/system there
+// is not the host's unit directory.
+func applyManaged(managed bool, hostRoot string) {
+	if hostRoot != "" {
+		serviceName = filepath.Join(hostRoot, serviceName)
+	}
+	reason := gatewayUnitsUnusable(managed, hostRoot)
+	if reason == "" {
+		return
+	}
+	gatewayUnits = false
+	log.Infof("The gateway service runs as a child process of the plugin, %s", reason)
+}
+
+// gatewayUnitsUnusable returns why the gateway service can't run as a
+// systemd unit, empty if it can.
+func gatewayUnitsUnusable(managed bool, hostRoot string) string {
+	if managed && hostRoot == "" {
+		return "the unit directory is confined to the plugin's rootfs without --host-root"
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return "systemctl is not installed"
+	}
+	// how sd_booted(3) tells systemd runs, the directory is also there in
+	// a plugin that mounts the host's /run/systemd
+	if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return "systemd is not running or /run/systemd is not mounted"
+	}
+	if dir := filepath.Dir(serviceName); !writableDir(dir) {
+		return fmt.Sprintf("%s is not writable", dir)
+	}
+	return ""
+}
+
+func writableDir(dir string) bool {
+	f, err := ioutil.TempFile(dir, ".linker-")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// gatewayServiceInstalled reports whether the gateway service was set up on
+// this host, running or not.
+func gatewayServiceInstalled() bool {
+	if !gatewayUnits {
+		return gatewayProcessRunning()
+	}
+	_, err := os.Stat(serviceName)
+	return err == nil
+}
+
+// startGatewayProcess runs the gateway command as a child process of the
+// plugin with its output in the plugin's log. A gateway process that runs
+// is left alone, as systemctl start leaves a running unit.
+func startGatewayProcess(command string) error {
+	gatewayProcess.Lock()
+	defer gatewayProcess.Unlock()
+	if gatewayProcess.cmd != nil {
+		select {
+		case <-gatewayProcess.done:
+		default:
+			return nil
+		}
+	}
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return fmt.Errorf("empty gateway command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	output := log.StandardLogger().Writer()
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// its own process group, so stopping it also stops what it started
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		output.Close()
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		output.Close()
+		close(done)
+		log.Infof("Gateway process [ %d ] exited: %v", cmd.Process.Pid, err)
+	}()
+	gatewayProcess.cmd = cmd
+	gatewayProcess.done = done
+	log.Infof("Started gateway process [ %d ]: %s", cmd.Process.Pid, command)
+	return nil
+}
+
+// stopGatewayProcess stops the gateway process and its children, killing
+// them if they don't exit in time.
+func stopGatewayProcess() error {
+	gatewayProcess.Lock()
+	defer gatewayProcess.Unlock()
+	if gatewayProcess.cmd == nil {
+		return nil
+	}
+	pgid := gatewayProcess.cmd.Process.Pid
+	syscall.Kill(-pgid, syscall.SIGTERM)
+	select {
+	case <-gatewayProcess.done:
+	case <-time.After(gatewayStopTimeout):
+		log.Warnf("gateway process %d did not exit, killing it", pgid)
+		syscall.Kill(-pgid, syscall.SIGKILL)
+		<-gatewayProcess.done
+	}
+	gatewayProcess.cmd = nil
+	return nil
+}
+
+func gatewayProcessRunning() bool {
+	gatewayProcess.Lock()
+	defer gatewayProcess.Unlock()
+	if gatewayProcess.cmd == nil {
+		return false
+	}
+	select {
+	case <-gatewayProcess.done:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,337 @@
+package ovs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// mirrorKey marks the tunnel port created as the output of a remote
+	// mirror with the mirror's name, the port goes away with the mirror
+	mirrorKey = "linker-mirror"
+
+	mirrorTunnelERSPAN = "erspan"
+	mirrorTunnelGRE    = "gre"
+)
+
+// MirrorRequest copies the traffic of containers on a network, or of the
+// whole bridge when Sources is empty, to the port of an analyzer container
+// on the same network, or to a tunnel towards RemoteIP. Tunnel is erspan
+// (the default) or gre, Key is the ERSPAN session id or GRE key.
+// Containers are given by name or id.
+type MirrorRequest struct {
+	NetworkID string
+	Name      string
+	Analyzer  string   `json:",omitempty"`
+	RemoteIP  string   `json:",omitempty"`
+	Tunnel    string   `json:",omitempty"`
+	Key       int      `json:",omitempty"`
+	Sources   []string `json:",omitempty"`
+}
+
+// MirrorInfo describes a mirror of a bridge.
+type MirrorInfo struct {
+	Name     string
+	Bridge   string
+	Output   string
+	RemoteIP string   `json:",omitempty"`
+	Sources  []string `json:",omitempty"`
+	All      bool     `json:",omitempty"`
+	Packets  int64
+}
+
+// endpointPortName returns the OVS port of a container on a network.
+func (d *Driver) endpointPortName(networkID, container string) (string, error) {
+	ep, err := d.dockerer.containerEndpoint(networkID, container)
+	if err != nil {
+		return "", err
+	}
+	name := ovsPortPrefix + truncateID(ep.EndpointID)
+	if portUUIDForName(name) == "" {
+		return "", fmt.Errorf("container %s has no port on the bridge", container)
+	}
+	return name, nil
+}
+
+// addMirror creates a Mirror row on the network's bridge. Source ports are
+// mirrored in both directions.
+func (d *Driver) addMirror(req MirrorRequest) (*MirrorInfo, error) {
+	bridgeName, err := d.networkBridge(req.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		req.Name = "span-" + req.Analyzer
+		if req.RemoteIP != "" {
+			req.Name = "span-" + req.RemoteIP
+		}
+	}
+	if mirrorUUIDForName(bridgeName, req.Name) != "" {
+		return nil, fmt.Errorf("bridge %s already has a mirror named %s", bridgeName, req.Name)
+	}
+
+	var operations []libovsdb.Operation
+	var output string
+	outputUUID := libovsdb.UUID{GoUuid: "output"}
+	if req.RemoteIP != "" {
+		if req.Tunnel == "" || req.Tunnel == mirrorTunnelERSPAN {
+			if err := switchCapabilities().require(featureERSPAN, bridgeDatapath(bridgeName)); err != nil {
+				return nil, err
+			}
+		}
+		output = mirrorTunnelName(bridgeName, req.Name)
+		ops, err := d.ovsdber.mirrorTunnelOps(bridgeName, output, req)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, ops...)
+	} else {
+		if output, err = d.endpointPortName(req.NetworkID, req.Analyzer); err != nil {
+			return nil, err
+		}
+		outputUUID = libovsdb.UUID{GoUuid: portUUIDForName(output)}
+	}
+
+	mirror := map[string]interface{}{
+		"name":         req.Name,
+		"output_port":  outputUUID,
+		"external_ids": d.ovsdber.ownerExternalIDs(),
+	}
+	var sources []string
+	if len(req.Sources) == 0 {
+		mirror["select_all"] = true
+	} else {
+		var uuids []libovsdb.UUID
+		for _, container := range req.Sources {
+			port, err := d.endpointPortName(req.NetworkID, container)
+			if err != nil {
+				return nil, err
+			}
+			if port == output {
+				return nil, fmt.Errorf("analyzer %s can't mirror its own traffic", req.Analyzer)
+			}
+			sources = append(sources, port)
+			uuids = append(uuids, libovsdb.UUID{GoUuid: portUUIDForName(port)})
+		}
+		ports, _ := libovsdb.NewOvsSet(uuids)
+		mirror["select_src_port"] = ports
+		mirror["select_dst_port"] = ports
+	}
+
+	mirrorSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: "mirror"}})
+	operations = append(operations,
+		libovsdb.Operation{Op: "insert", Table: "Mirror", Row: mirror, UUIDName: "mirror"},
+		libovsdb.Operation{
+			Op:        "mutate",
+			Table:     "Bridge",
+			Mutations: []interface{}{libovsdb.NewMutation("mirrors", "insert", mirrorSet)},
+			Where:     []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+		},
+	)
+	if err := d.ovsdber.transact(operations...); err != nil {
+		return nil, err
+	}
+	log.Infof("Mirroring %v on bridge [ %s ] to [ %s ]", req.Sources, bridgeName, output)
+	return &MirrorInfo{Name: req.Name, Bridge: bridgeName, Output: output, RemoteIP: req.RemoteIP,
+		Sources: sources, All: len(sources) == 0}, nil
+}
+
+// mirrorTunnelName derives the name of a remote mirror's tunnel port from
+// the bridge and mirror names, within IFNAMSIZ.
+func mirrorTunnelName(bridgeName, mirrorName string) string {
+	h := fnv.New32a()
+	h.Write([]byte(bridgeName + "/" + mirrorName))
+	return fmt.Sprintf("span-%08x", h.Sum32())
+}
+
+// mirrorTunnelOps returns the operations adding the tunnel port a remote
+// mirror outputs to, named "output" in the transaction. The port only
+// carries mirrored traffic, OVS does not switch to output ports of mirrors.
+func (ovsdber *ovsdber) mirrorTunnelOps(bridgeName, portName string, req MirrorRequest) ([]libovsdb.Operation, error) {
+	if ip := net.ParseIP(req.RemoteIP); ip == nil || ip.To4() == nil {
+		return nil, fmt.Errorf("%s is not a valid remote ip", req.RemoteIP)
+	}
+	options := map[string]string{"remote_ip": req.RemoteIP}
+	switch req.Tunnel {
+	case "", mirrorTunnelERSPAN:
+		req.Tunnel = mirrorTunnelERSPAN
+		if req.Key < 0 || req.Key > 1023 {
+			return nil, fmt.Errorf("ERSPAN session id %d is out of range 0-1023", req.Key)
+		}
+		options["erspan_ver"] = "1"
+		options["erspan_idx"] = "1"
+		options["key"] = strconv.Itoa(req.Key)
+	case mirrorTunnelGRE:
+		if req.Key != 0 {
+			options["key"] = strconv.Itoa(req.Key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported mirror tunnel %s, use %s or %s", req.Tunnel, mirrorTunnelERSPAN, mirrorTunnelGRE)
+	}
+	ifaceOptions, _ := libovsdb.NewOvsMap(options)
+	portIDs, _ := libovsdb.NewOvsMap(map[string]string{
+		ownerKey:         ownerValue,
+		ownerInstanceKey: ovsdber.instance,
+		mirrorKey:        req.Name,
+	})
+	iface := map[string]interface{}{
+		"name":    portName,
+		"type":    req.Tunnel,
+		"options": ifaceOptions,
+	}
+	port := map[string]interface{}{
+		"name":         portName,
+		"interfaces":   libovsdb.UUID{GoUuid: "iface"},
+		"external_ids": portIDs,
+	}
+	portSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: "output"}})
+	return []libovsdb.Operation{
+		{Op: "insert", Table: "Interface", Row: iface, UUIDName: "iface"},
+		{Op: "insert", Table: "Port", Row: port, UUIDName: "output"},
+		{
+			Op:        "mutate",
+			Table:     "Bridge",
+			Mutations: []interface{}{libovsdb.NewMutation("ports", "insert", portSet)},
+			Where:     []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+		},
+	}, nil
+}
+
+// deleteMirror removes the named mirror from the network's bridge, OVSDB
+// garbage collects the unreferenced row.
+func (d *Driver) deleteMirror(networkID, name string) error {
+	bridgeName, err := d.networkBridge(networkID)
+	if err != nil {
+		return err
+	}
+	mirrorUUID := mirrorUUIDForName(bridgeName, name)
+	if mirrorUUID == "" {
+		return fmt.Errorf("bridge %s has no mirror named %s", bridgeName, name)
+	}
+	if !d.ovsdber.forceOwnership && !d.ovsdber.rowOwned(cachedRow("Mirror", mirrorUUID)) {
+		return fmt.Errorf("mirror %s is not owned by %s, refusing to delete it", name, ownerValue)
+	}
+	mutations := []interface{}{}
+	mirrorSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: mirrorUUID}})
+	mutations = append(mutations, libovsdb.NewMutation("mirrors", "delete", mirrorSet))
+	// the tunnel port of a remote mirror is removed with it
+	for _, portUUID := range rowUUIDs(cachedRow("Mirror", mirrorUUID).Fields["output_port"]) {
+		if ovsMapValue(cachedRow("Port", portUUID).Fields["external_ids"], mirrorKey) == name {
+			portSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: portUUID}})
+			mutations = append(mutations, libovsdb.NewMutation("ports", "delete", portSet))
+		}
+	}
+	mutateOp := libovsdb.Operation{
+		Op:        "mutate",
+		Table:     "Bridge",
+		Mutations: mutations,
+		Where:     []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+	}
+	if err := d.ovsdber.transact(mutateOp); err != nil {
+		return err
+	}
+	log.Infof("Removed mirror [ %s ] from bridge [ %s ]", name, bridgeName)
+	return nil
+}
+
+// bridgeMirrors lists the mirrors of the bridges of all networks, or only
+// of the given network.
+func bridgeMirrors(networkID string) []MirrorInfo {
+	var mirrors []MirrorInfo
+	for bridgeName, id := range pluginBridges() {
+		if networkID != "" && id != networkID {
+			continue
+		}
+		bridge := cachedRow("Bridge", getBridgeUUIDForName(bridgeName))
+		for _, uuid := range rowUUIDs(bridge.Fields["mirrors"]) {
+			row := cachedRow("Mirror", uuid)
+			info := MirrorInfo{Bridge: bridgeName}
+			info.Name, _ = row.Fields["name"].(string)
+			info.All, _ = row.Fields["select_all"].(bool)
+			for _, port := range rowUUIDs(row.Fields["output_port"]) {
+				info.Output = portNameForUUID(port)
+				info.RemoteIP = portRemoteIP(port)
+			}
+			for _, port := range rowUUIDs(row.Fields["select_src_port"]) {
+				info.Sources = append(info.Sources, portNameForUUID(port))
+			}
+			sort.Strings(info.Sources)
+			if stats, ok := row.Fields["statistics"].(libovsdb.OvsMap); ok {
+				if packets, ok := stats.GoMap["tx_packets"].(float64); ok {
+					info.Packets = int64(packets)
+				}
+			}
+			mirrors = append(mirrors, info)
+		}
+	}
+	return mirrors
+}
+
+func mirrorUUIDForName(bridgeName, name string) string {
+	bridge := cachedRow("Bridge", getBridgeUUIDForName(bridgeName))
+	for _, uuid := range rowUUIDs(bridge.Fields["mirrors"]) {
+		if cachedRow("Mirror", uuid).Fields["name"] == name {
+			return uuid
+		}
+	}
+	return ""
+}
+
+// portRemoteIP returns the remote ip of a tunnel port, or an empty string.
+func portRemoteIP(uuid string) string {
+	for _, iface := range rowUUIDs(cachedRow("Port", uuid).Fields["interfaces"]) {
+		if ip := ovsMapValue(cachedRow("Interface", iface).Fields["options"], "remote_ip"); ip != "" {
+			return ip
+		}
+	}
+	return ""
+}
+
+func portNameForUUID(uuid string) string {
+	name, _ := cachedRow("Port", uuid).Fields["name"].(string)
+	return name
+}
+
+// handleMirrors serves /mirrors: GET lists the mirrors, optionally filtered
+// by ?network=, POST creates one from a MirrorRequest and DELETE removes
+// ?network=&name=.
+func (d *Driver) handleMirrors(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, bridgeMirrors(r.URL.Query().Get("network")))
+	case "POST":
+		var req MirrorRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.NetworkID == "" || (req.Analyzer == "") == (req.RemoteIP == "") {
+			writeError(w, http.StatusBadRequest, errors.New("NetworkID and one of Analyzer or RemoteIP are required"))
+			return
+		}
+		res, err := d.addMirror(req)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	case "DELETE":
+		query := r.URL.Query()
+		if err := d.deleteMirror(query.Get("network"), query.Get("name")); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+	}
+}
@@ -0,0 +1,41 @@
+package ovs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// ovsModule is the kernel module of the kernel (system) datapath
+const ovsModule = "openvswitch"
+
+// loadModule is cleared by --skip-modprobe, for hosts where modules are
+// managed otherwise
+var loadModule = true
+
+// moduleLoaded reports whether a kernel module is loaded or built in.
+func moduleLoaded(module string) bool {
+	_, err := os.Stat("/sys/module/" + module)
+	return err == nil
+}
+
+// ensureKernelModule loads the openvswitch module if it is missing. Without
+// it ovs-vswitchd can't create kernel datapath bridges and their links
+// never appear.
+func ensureKernelModule() error {
+	if moduleLoaded(ovsModule) {
+		return nil
+	}
+	if !loadModule {
+		return fmt.Errorf("the %s kernel module is not loaded, run modprobe %s or start the plugin without --skip-modprobe", ovsModule, ovsModule)
+	}
+	out, err := exec.Command("modprobe", ovsModule).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("the %s kernel module is not loaded and modprobe failed: %v %s", ovsModule, err, strings.TrimSpace(string(out)))
+	}
+	log.Infof("Loaded the %s kernel module", ovsModule)
+	return nil
+}
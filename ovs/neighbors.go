@@ -0,0 +1,115 @@
+package ovs
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+)
+
+var neighStates = map[int]string{
+	netlink.NUD_INCOMPLETE: "incomplete",
+	netlink.NUD_REACHABLE:  "reachable",
+	netlink.NUD_STALE:      "stale",
+	netlink.NUD_DELAY:      "delay",
+	netlink.NUD_PROBE:      "probe",
+	netlink.NUD_FAILED:     "failed",
+	netlink.NUD_NOARP:      "noarp",
+	netlink.NUD_PERMANENT:  "permanent",
+}
+
+// NeighborEntry is an ARP or ND entry of a bridge interface. Stale is set
+// when the kernel no longer considers the entry confirmed.
+type NeighborEntry struct {
+	IP       string
+	MAC      string
+	State    string
+	Stale    bool
+	Endpoint string `json:",omitempty"`
+}
+
+// BridgeNeighbors is the neighbor table of a plugin bridge. Endpoints that
+// the host has no entry for are listed by address in Unresolved.
+type BridgeNeighbors struct {
+	Bridge     string
+	NetworkID  string
+	Neighbors  []NeighborEntry
+	Unresolved []string `json:",omitempty"`
+}
+
+// neighborTables returns the IPv4 and IPv6 neighbor entries of the bridges
+// of all networks, or only of the given network.
+func (d *Driver) neighborTables(networkID string) ([]BridgeNeighbors, error) {
+	var tables []BridgeNeighbors
+	for bridgeName, id := range pluginBridges() {
+		if networkID != "" && id != networkID {
+			continue
+		}
+		link, err := netlink.LinkByName(bridgeName)
+		if err != nil {
+			continue
+		}
+		endpoints := make(map[string]string)
+		for endpointID, es := range d.endpointStates() {
+			if es.NetworkID == id {
+				endpoints[es.Address] = endpointID
+			}
+		}
+
+		table := BridgeNeighbors{Bridge: bridgeName, NetworkID: id}
+		for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+			neighs, err := netlink.NeighList(link.Attrs().Index, family)
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range neighs {
+				entry := NeighborEntry{
+					IP:    n.IP.String(),
+					MAC:   n.HardwareAddr.String(),
+					State: neighStateName(n.State),
+					Stale: n.State&(netlink.NUD_REACHABLE|netlink.NUD_PERMANENT|netlink.NUD_NOARP) == 0,
+				}
+				if endpointID, ok := endpoints[entry.IP]; ok {
+					entry.Endpoint = truncateID(endpointID)
+					delete(endpoints, entry.IP)
+				}
+				table.Neighbors = append(table.Neighbors, entry)
+			}
+		}
+		for address := range endpoints {
+			table.Unresolved = append(table.Unresolved, address)
+		}
+		sort.Strings(table.Unresolved)
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func neighStateName(state int) string {
+	var names []string
+	for flag, name := range neighStates {
+		if state&flag != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// handleNeighbors serves /neighbors, optionally filtered by ?network=
+func (d *Driver) handleNeighbors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	tables, err := d.neighborTables(r.URL.Query().Get("network"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tables)
+}
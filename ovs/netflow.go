@@ -0,0 +1,98 @@
+package ovs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// NetFlow collectors the bridge exports flow records to, as a comma
+	// separated list of ip:port
+	netflowOption = "linker.net.ovs.bridge.netflow"
+	// Seconds after which records of long lived flows are exported, the
+	// switch defaults to 600
+	netflowTimeoutOption = "linker.net.ovs.bridge.netflow_active_timeout"
+)
+
+// getNetFlow returns the collectors and active timeout of a network.
+func getNetFlow(r *dknet.CreateNetworkRequest) ([]string, int, error) {
+	targets, err := getCollectors(r, netflowOption)
+	if err != nil {
+		return nil, 0, err
+	}
+	value := getStringOption(r, netflowTimeoutOption)
+	if value == "" {
+		return targets, 0, nil
+	}
+	if len(targets) == 0 {
+		return nil, 0, fmt.Errorf("%s requires %s to be set", netflowTimeoutOption, netflowOption)
+	}
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout < 1 {
+		return nil, 0, fmt.Errorf("%s must be a number of seconds, got %s", netflowTimeoutOption, value)
+	}
+	return targets, timeout, nil
+}
+
+// getCollectors parses a comma separated list of ip:port flow collectors.
+func getCollectors(r *dknet.CreateNetworkRequest, option string) ([]string, error) {
+	var targets []string
+	for _, target := range strings.Split(getStringOption(r, option), ",") {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(target)
+		if err != nil || net.ParseIP(host) == nil {
+			return nil, fmt.Errorf("%s must list ip:port collectors, got %s", option, target)
+		}
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return nil, fmt.Errorf("invalid port in collector %s of %s", target, option)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// collectorSet is the OVSDB set of collector targets.
+func collectorSet(targets []string) *libovsdb.OvsSet {
+	var elems []interface{}
+	for _, target := range targets {
+		elems = append(elems, target)
+	}
+	set, _ := libovsdb.NewOvsSet(elems)
+	return set
+}
+
+// setNetFlow inserts a NetFlow row with the collectors and references it
+// from the bridge. The row the bridge referenced before is garbage
+// collected by OVSDB, and so is this one when the bridge is deleted.
+func (ovsdber *ovsdber) setNetFlow(bridgeName string, targets []string, activeTimeout int) error {
+	netflow := map[string]interface{}{
+		"targets":      collectorSet(targets),
+		"external_ids": ovsdber.ownerExternalIDs(),
+	}
+	if activeTimeout > 0 {
+		netflow["active_timeout"] = activeTimeout
+	}
+	operations := []libovsdb.Operation{
+		{Op: "insert", Table: "NetFlow", Row: netflow, UUIDName: "netflow"},
+		{
+			Op:    "update",
+			Table: "Bridge",
+			Row:   map[string]interface{}{"netflow": libovsdb.UUID{GoUuid: "netflow"}},
+			Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+		},
+	}
+	if err := ovsdber.transact(operations...); err != nil {
+		return err
+	}
+	log.Infof("Exporting NetFlow of bridge [ %s ] to %v", bridgeName, targets)
+	return nil
+}
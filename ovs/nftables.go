@@ -0,0 +1,282 @@
+package ovs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	nftFamily = "ip"
+	nftTable  = "linker_ovs"
+)
+
+// nftablesFirewall programs the rules through the nft binary. Each network
+// owns base chains hooked directly into netfilter, so no jump rules need to
+// be tracked and deleting the chains removes everything.
+type nftablesFirewall struct{}
+
+// nftChains maps the per-network chain suffix to its base chain definition.
+var nftChains = map[string]string{
+	"post": "{ type nat hook postrouting priority 100 ; }",
+	"pre":  "{ type nat hook prerouting priority -100 ; }",
+	"out":  "{ type nat hook output priority -100 ; }",
+	"fwd":  "{ type filter hook forward priority 0 ; }",
+}
+
+func nftChainName(kind, networkID string) string {
+	return kind + "-" + truncateID(networkID)
+}
+
+func (nftablesFirewall) setupNetwork(ctx context.Context, networkID, bridgeName, cidr string) error {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+	if err := nft("add", "table", nftFamily, nftTable); err != nil {
+		return err
+	}
+	for kind, definition := range nftChains {
+		if err := nft("add", "chain", nftFamily, nftTable, nftChainName(kind, networkID), definition); err != nil {
+			return err
+		}
+	}
+
+	post := nftChainName("post", networkID)
+	fwd := nftChainName("fwd", networkID)
+	// the chains may survive a restart, start from a clean slate
+	nft("flush", "chain", nftFamily, nftTable, post)
+	nft("flush", "chain", nftFamily, nftTable, fwd)
+
+	if err := nftRule("add", post, "ip saddr", subnet.String(), "oifname !=", bridgeName, "masquerade"); err != nil {
+		return err
+	}
+	if err := nftRule("add", fwd, "iifname", bridgeName, "accept"); err != nil {
+		return err
+	}
+	return nftRule("add", fwd, "oifname", bridgeName, "ct state related,established accept")
+}
+
+// isolateNetwork puts a rule dropping traffic between the bridge and itself
+// ahead of the accept rules of the network's forward chain.
+func (nftablesFirewall) isolateNetwork(ctx context.Context, networkID, bridgeName string) error {
+	return nftRule("insert", nftChainName("fwd", networkID), "iifname", bridgeName, "oifname", bridgeName, "drop")
+}
+
+func (nftablesFirewall) teardownNetwork(ctx context.Context, networkID, bridgeName string) {
+	for kind := range nftChains {
+		chain := nftChainName(kind, networkID)
+		nft("flush", "chain", nftFamily, nftTable, chain)
+		if err := nft("delete", "chain", nftFamily, nftTable, chain); err != nil {
+			log.Warnf("failed to remove nft chain %s: %v", chain, err)
+		}
+	}
+	log.Debugf("removed nft chains of network %s for bridge %s", truncateID(networkID), bridgeName)
+}
+
+func (nftablesFirewall) programPortMapping(ctx context.Context, enable bool, networkID, bridgeName, containerIP string, b portBinding) error {
+	comment := fmt.Sprintf("pm-%s-%s-%d", containerIP, b.Proto, b.HostPort)
+	pre := nftChainName("pre", networkID)
+	out := nftChainName("out", networkID)
+	fwd := nftChainName("fwd", networkID)
+	if !enable {
+		nftDeleteByComment(pre, comment)
+		nftDeleteByComment(out, comment)
+		nftDeleteByComment(fwd, comment)
+		return nil
+	}
+
+	match := []string{"fib daddr type local"}
+	if b.HostIP != "" && !net.ParseIP(b.HostIP).IsUnspecified() {
+		match = append(match, "ip daddr", b.HostIP)
+	}
+	match = append(match, b.Proto, "dport", strconv.Itoa(b.HostPort))
+	dnat := []string{"dnat to", net.JoinHostPort(containerIP, strconv.Itoa(b.Port)), nftComment(comment)}
+
+	rule := append(append(append([]string{}, match...), "iifname !=", bridgeName), dnat...)
+	if err := nftRule("add", pre, rule...); err != nil {
+		return err
+	}
+	rule = append(append([]string{}, match...), dnat...)
+	err := nftRule("add", out, rule...)
+	if err == nil {
+		err = nftRule("add", fwd, "iifname !=", bridgeName, "oifname", bridgeName,
+			"ip daddr", containerIP, b.Proto, "dport", strconv.Itoa(b.Port), "accept", nftComment(comment))
+	}
+	if err != nil {
+		// take back the rules that were added, the port is not published
+		for _, chain := range []string{pre, out, fwd} {
+			nftDeleteByComment(chain, comment)
+		}
+		return err
+	}
+	return nil
+}
+
+func (nftablesFirewall) programFloatingIP(ctx context.Context, enable bool, es *EndpointState) error {
+	comment := "fip-" + es.FloatingIP
+	pre := nftChainName("pre", es.NetworkID)
+	out := nftChainName("out", es.NetworkID)
+	post := nftChainName("post", es.NetworkID)
+	fwd := nftChainName("fwd", es.NetworkID)
+	if !enable {
+		for _, chain := range []string{pre, out, post, fwd} {
+			nftDeleteByComment(chain, comment)
+		}
+		return nil
+	}
+
+	dnat := []string{"ip daddr", es.FloatingIP, "dnat to", es.Address, nftComment(comment)}
+	if err := nftRule("add", pre, dnat...); err != nil {
+		return err
+	}
+	if err := nftRule("add", out, dnat...); err != nil {
+		return err
+	}
+	// insert puts the SNAT ahead of the network's masquerade rule
+	if err := nftRule("insert", post, "ip saddr", es.Address, "snat to", es.FloatingIP, nftComment(comment)); err != nil {
+		return err
+	}
+	return nftRule("add", fwd, "oifname", es.BridgeName, "ip daddr", es.Address, "accept", nftComment(comment))
+}
+
+func nftComment(comment string) string {
+	return fmt.Sprintf("comment \"%s\"", comment)
+}
+
+func nftRule(action, chain string, rule ...string) error {
+	args := append([]string{action, "rule", nftFamily, nftTable, chain}, rule...)
+	return nft(args...)
+}
+
+// nftDeleteByComment deletes the rules of a chain carrying the comment,
+// nft can only delete rules by their handle.
+func nftDeleteByComment(chain, comment string) {
+	out, err := exec.Command("nft", "-a", "list", "chain", nftFamily, nftTable, chain).Output()
+	if err != nil {
+		log.Warnf("failed to list nft chain %s: %v", chain, err)
+		return
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, nftComment(comment)) {
+			continue
+		}
+		idx := strings.LastIndex(line, "# handle ")
+		if idx < 0 {
+			continue
+		}
+		handle := strings.TrimSpace(line[idx+len("# handle "):])
+		if err := nft("delete", "rule", nftFamily, nftTable, chain, "handle", handle); err != nil {
+			log.Warnf("failed to delete nft rule %s from %s: %v", comment, chain, err)
+		}
+	}
+}
+
+// listRules returns the rules of the network's chains as nft lists them,
+// prefixed with the chain.
+func (nftablesFirewall) listRules(networkID string) ([]string, error) {
+	var kinds []string
+	for kind := range nftChains {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	var rules []string
+	for _, kind := range kinds {
+		chain := nftChainName(kind, networkID)
+		out, err := exec.Command("nft", "list", "chain", nftFamily, nftTable, chain).Output()
+		if err != nil {
+			// the chain is gone or was never created
+			continue
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || line == "}" || strings.HasPrefix(line, "table ") ||
+				strings.HasPrefix(line, "chain ") || strings.HasPrefix(line, "type ") {
+				continue
+			}
+			rules = append(rules, chain+": "+line)
+		}
+	}
+	return rules, nil
+}
+
+// nft runs a single nft command, the arguments are joined so that rule
+// fragments may contain several tokens.
+func nft(args ...string) error {
+	cmd := strings.Join(args, " ")
+	log.Debugf("nft %s", cmd)
+	out, err := exec.Command("nft", strings.Fields(cmd)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("nft %s: %v %s", cmd, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// checkForwardDrops fails when another nft table drops forwarded traffic,
+// as iptables-nft does for docker with its FORWARD policy. A packet must be
+// accepted by every base chain of a hook, so the accept rules of the
+// plugin's table can't override a drop of another table and containers
+// would lose their traffic. Such hosts need --firewall=iptables.
+func checkForwardDrops() error {
+	out, err := exec.Command("nft", "list", "ruleset").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list the nft ruleset: %v", err)
+	}
+	if chains := forwardDrops(string(out), nftFamily+" "+nftTable); len(chains) > 0 {
+		return fmt.Errorf("forwarded traffic is dropped by %s, which the nftables backend can't override, start the plugin with --firewall=iptables",
+			strings.Join(chains, ", "))
+	}
+	return nil
+}
+
+// forwardDrops returns the forward hook base chains of tables other than
+// own that drop everything left by policy or by a rule without a match, as
+// "<table> <chain>". Rules dropping only some traffic are left alone.
+func forwardDrops(ruleset, own string) []string {
+	var drops []string
+	var table, chain string
+	forward, dropping := false, false
+	scanner := bufio.NewScanner(strings.NewReader(ruleset))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 3 && fields[0] == "table":
+			table = fields[1] + " " + fields[2]
+		case len(fields) >= 2 && fields[0] == "chain":
+			chain, forward, dropping = fields[1], false, false
+		case strings.HasPrefix(line, "type filter hook forward"):
+			forward = true
+			dropping = strings.Contains(line, "policy drop")
+		case line == "}":
+			if chain != "" && forward && dropping && table != own {
+				drops = append(drops, table+" "+chain)
+			}
+			if chain == "" {
+				table = ""
+			}
+			chain, forward, dropping = "", false, false
+		case chain != "" && unconditionalDrop(fields):
+			dropping = true
+		}
+	}
+	return drops
+}
+
+// unconditionalDrop reports whether a rule drops every packet, counted or
+// not.
+func unconditionalDrop(fields []string) bool {
+	if len(fields) == 6 && fields[0] == "counter" && fields[1] == "packets" && fields[3] == "bytes" {
+		fields = fields[5:]
+	}
+	return len(fields) == 1 && fields[0] == "drop"
+}
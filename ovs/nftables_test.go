@@ -0,0 +1,80 @@
+package ovs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestForwardDrops(t *testing.T) {
+	const own = "ip linker_ovs"
+	tests := []struct {
+		name    string
+		ruleset string
+		want    []string
+	}{
+		{
+			name: "iptables-nft forward policy",
+			ruleset: `table ip filter {
+	chain DOCKER {
+	}
+
+	chain FORWARD {
+		type filter hook forward priority filter; policy drop;
+		counter packets 0 bytes 0 jump DOCKER-USER
+	}
+}
+table ip linker_ovs {
+	chain fwd-abcde {
+		type filter hook forward priority filter; policy accept;
+		iifname "br0" accept
+	}
+}
+`,
+			want: []string{"ip filter FORWARD"},
+		},
+		{
+			name: "unconditional drop rule",
+			ruleset: `table inet firewalld {
+	chain filter_FORWARD {
+		type filter hook forward priority filter + 10; policy accept;
+		ct state established,related accept
+		counter packets 12 bytes 720 drop
+	}
+}
+`,
+			want: []string{"inet firewalld filter_FORWARD"},
+		},
+		{
+			name: "drops of some traffic and of other hooks",
+			ruleset: `table ip filter {
+	chain INPUT {
+		type filter hook input priority filter; policy drop;
+	}
+
+	chain FORWARD {
+		type filter hook forward priority filter; policy accept;
+		iifname "eth1" drop
+	}
+
+	chain DOCKER-ISOLATION {
+		drop
+	}
+}
+`,
+		},
+		{
+			name: "own table",
+			ruleset: `table ip linker_ovs {
+	chain fwd-abcde {
+		type filter hook forward priority 0; policy drop;
+	}
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		if got := forwardDrops(tt.ruleset, own); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
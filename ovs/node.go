@@ -0,0 +1,429 @@
+package ovs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+// nodeOption creates the network's bridge on a remote node given with
+// --ovsdb-node instead of on this host, e.g. a gateway appliance
+const nodeOption = "linker.net.ovs.bridge.node"
+
+// ovsNode is the OVSDB of a remote hypervisor or gateway appliance. Its
+// connection is opened on first use and again after it was lost. Nodes are
+// not monitored and have no table cache, rows are read with select
+// operations instead.
+type ovsNode struct {
+	name string
+	// mu serializes the operations of the node and guards its connection
+	mu sync.Mutex
+	ovsdber
+}
+
+// NodeStatus is the connection state of a remote node.
+type NodeStatus struct {
+	Name      string
+	Endpoint  string
+	Connected bool
+	Networks  []string `json:",omitempty"`
+}
+
+// nodeNotifier drops the connection of a node when it is lost, the next
+// operation reconnects.
+type nodeNotifier struct {
+	node *ovsNode
+}
+
+func (n nodeNotifier) Update(interface{}, libovsdb.TableUpdates) {
+}
+func (n nodeNotifier) Disconnected(client *libovsdb.OvsdbClient) {
+	n.node.mu.Lock()
+	defer n.node.mu.Unlock()
+	if n.node.client() == client {
+		log.Warnf("lost the connection to node %s", n.node.name)
+		n.node.setClient(nil)
+	}
+}
+func (n nodeNotifier) Locked([]interface{}) {
+}
+func (n nodeNotifier) Stolen([]interface{}) {
+}
+func (n nodeNotifier) Echo([]interface{}) {
+}
+
+// newNodes checks the endpoints of the remote nodes. ssl: endpoints use the
+// same client certificate as the local one.
+func newNodes(config Config) (map[string]*ovsNode, error) {
+	nodes := make(map[string]*ovsNode)
+	var tlsConfig *tls.Config
+	for name, endpoint := range config.Nodes {
+		if err := checkOvsdbEndpoint(endpoint); err != nil {
+			return nil, fmt.Errorf("node %s: %v", name, err)
+		}
+		node := &ovsNode{name: name}
+		node.endpoint = endpoint
+		node.forceOwnership = config.ForceOwnership
+		if strings.HasPrefix(endpoint, "ssl:") {
+			if tlsConfig == nil {
+				var err error
+				if tlsConfig, err = ovsdbTLSConfig(config.OvsdbCert, config.OvsdbKey, config.OvsdbCA); err != nil {
+					return nil, fmt.Errorf("node %s: %v", name, err)
+				}
+			}
+			node.tlsConfig = tlsConfig
+		}
+		nodes[name] = node
+	}
+	return nodes, nil
+}
+
+// connect opens the connection of the node if it has none, with mu held.
+func (node *ovsNode) connect() error {
+	if node.client() != nil {
+		return nil
+	}
+	client, err := dialOvsdb(node.endpoint, node.tlsConfig)
+	if err != nil {
+		return fmt.Errorf("could not connect to node %s at %s: %v", node.name, node.endpoint, err)
+	}
+	client.Register(nodeNotifier{node: node})
+	node.setClient(client)
+	log.Infof("Connected to node [ %s ] at [ %s ]", node.name, node.endpoint)
+	return nil
+}
+
+// selectBridge reads the named bridge row of the node.
+func (node *ovsNode) selectBridge(bridgeName string) (libovsdb.Row, bool, error) {
+	op := libovsdb.Operation{
+		Op:      "select",
+		Table:   "Bridge",
+		Columns: []string{"_uuid", "external_ids"},
+		Where:   []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+	}
+	reply, err := node.transactReply(op)
+	if err != nil {
+		return libovsdb.Row{}, false, fmt.Errorf("select failed on node %s: %v", node.name, err)
+	}
+	if len(reply[0].Rows) == 0 {
+		return libovsdb.Row{}, false, nil
+	}
+	row, err := decodeRow(reply[0].Rows[0])
+	if err != nil {
+		return libovsdb.Row{}, false, err
+	}
+	return row, true, nil
+}
+
+// selectNetworkBridges reads the bridges of the node that record a network
+// and were created by this instance of the plugin, by network id.
+func (node *ovsNode) selectNetworkBridges() (map[string]libovsdb.Row, error) {
+	op := libovsdb.Operation{
+		Op:      "select",
+		Table:   "Bridge",
+		Columns: []string{"name", "datapath_type", "external_ids"},
+		Where:   []interface{}{libovsdb.NewCondition("name", "!=", "")},
+	}
+	reply, err := node.transactReply(op)
+	if err != nil {
+		return nil, fmt.Errorf("select failed on node %s: %v", node.name, err)
+	}
+	bridges := make(map[string]libovsdb.Row)
+	for _, raw := range reply[0].Rows {
+		row, err := decodeRow(raw)
+		if err != nil {
+			return nil, err
+		}
+		networkID := ovsMapValue(row.Fields["external_ids"], networkIDKey)
+		if networkID != "" && node.rowOwned(row) {
+			bridges[networkID] = row
+		}
+	}
+	return bridges, nil
+}
+
+// rootCondition matches the single Open_vSwitch row, whose uuid the node
+// has no cache to look up in.
+func rootCondition() interface{} {
+	return libovsdb.NewCondition("_uuid", "!=", libovsdb.UUID{GoUuid: "00000000-0000-0000-0000-000000000000"})
+}
+
+// addBridge creates the bridge of a network on the node, or records the
+// network on a bridge the plugin created before, like addBridge does
+// locally.
+func (node *ovsNode) addBridge(networkID string, ns *NetworkState) error {
+	meta := networkMetadata(ns.NetworkName, ns.Mode)
+	row, exists, err := node.selectBridge(ns.BridgeName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if !node.forceOwnership && !node.rowOwned(row) {
+			return fmt.Errorf("bridge %s on node %s is not owned by %s, refusing to modify it", ns.BridgeName, node.name, ownerValue)
+		}
+		return node.setBridgeExternalIDs(ns.BridgeName, networkID, ns.NetworkType, meta)
+	}
+
+	bridge := map[string]interface{}{
+		"name":         ns.BridgeName,
+		"stp_enable":   false,
+		"ports":        libovsdb.UUID{GoUuid: "port"},
+		"external_ids": node.bridgeExternalIDs(networkID, ns.NetworkType, meta),
+	}
+	if ns.Datapath != datapathKernel {
+		bridge["datapath_type"] = ns.Datapath
+	}
+	bridgeSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: "bridge"}})
+	operations := []libovsdb.Operation{
+		{
+			Op:       "insert",
+			Table:    "Interface",
+			Row:      map[string]interface{}{"name": ns.BridgeName, "type": "internal", "external_ids": node.ownerExternalIDs()},
+			UUIDName: "intf",
+		},
+		{
+			Op:       "insert",
+			Table:    "Port",
+			Row:      map[string]interface{}{"name": ns.BridgeName, "interfaces": libovsdb.UUID{GoUuid: "intf"}, "external_ids": node.ownerExternalIDs()},
+			UUIDName: "port",
+		},
+		{Op: "insert", Table: "Bridge", Row: bridge, UUIDName: "bridge"},
+		{
+			Op:        "mutate",
+			Table:     "Open_vSwitch",
+			Mutations: []interface{}{libovsdb.NewMutation("bridges", "insert", bridgeSet)},
+			Where:     []interface{}{rootCondition()},
+		},
+	}
+	if node.hasBridgeOpt() {
+		operations = append(operations, libovsdb.Operation{
+			Op:    "insert",
+			Table: "BridgeOpt",
+			Row:   map[string]interface{}{"name": ns.BridgeName, "service_type": ns.NetworkType, "network_id": networkID},
+		})
+	}
+	return node.transact(operations...)
+}
+
+// initBridge creates the bridge of a network on the node and applies its
+// OVSDB settings. Everything initBridge does with netlink, iptables or
+// OpenFlow on this host is not available on a node.
+func (node *ovsNode) initBridge(networkID string, ns *NetworkState) error {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if err := node.connect(); err != nil {
+		return err
+	}
+	if err := node.addBridge(networkID, ns); err != nil {
+		return err
+	}
+	settings := []struct {
+		set  bool
+		name string
+		fn   func() error
+	}{
+		{ns.FailMode != "", "fail mode", func() error { return node.setFailMode(ns.BridgeName, ns.FailMode) }},
+		{ns.SpanningTree != "", ns.SpanningTree, func() error {
+			return node.setSpanningTree(ns.BridgeName, ns.SpanningTree, ns.SpanningTreePrio)
+		}},
+		{ns.Hwaddr != "", "MAC", func() error { return node.setBridgeHwaddr(ns.BridgeName, ns.Hwaddr) }},
+		{ns.MACAgingTime > 0 || ns.MACTableSize > 0, "MAC table", func() error {
+			return node.setMACTable(ns.BridgeName, ns.MACAgingTime, ns.MACTableSize)
+		}},
+		{ns.McastSnooping, "multicast snooping", func() error { return node.setMcastSnooping(ns.BridgeName, true) }},
+		{len(ns.NetFlowTargets) > 0, "NetFlow", func() error {
+			return node.setNetFlow(ns.BridgeName, ns.NetFlowTargets, ns.NetFlowTimeout)
+		}},
+		{ns.SFlow != nil, "sFlow", func() error { return node.setSFlow(ns.BridgeName, ns.SFlow) }},
+		{ns.IPFIX != nil, "IPFIX", func() error { return node.setIPFIX(ns.BridgeName, ns.IPFIX) }},
+	}
+	for _, setting := range settings {
+		if !setting.set {
+			continue
+		}
+		if err := setting.fn(); err != nil {
+			node.deleteBridge(ns.BridgeName)
+			return fmt.Errorf("failed to set %s of bridge %s on node %s: %v", setting.name, ns.BridgeName, node.name, err)
+		}
+	}
+	log.Infof("Created bridge [ %s ] on node [ %s ]", ns.BridgeName, node.name)
+	return nil
+}
+
+// deleteBridge deletes a bridge of the node, with mu held.
+func (node *ovsNode) deleteBridge(bridgeName string) error {
+	row, exists, err := node.selectBridge(bridgeName)
+	if err != nil || !exists {
+		return err
+	}
+	if !node.forceOwnership && !node.rowOwned(row) {
+		return fmt.Errorf("bridge %s on node %s is not owned by %s, refusing to delete it", bridgeName, node.name, ownerValue)
+	}
+	uuid, _ := row.Fields["_uuid"].(libovsdb.UUID)
+	bridgeSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{uuid})
+	condition := libovsdb.NewCondition("name", "==", bridgeName)
+	operations := []libovsdb.Operation{
+		{Op: "delete", Table: "Bridge", Where: []interface{}{condition}},
+		{
+			Op:        "mutate",
+			Table:     "Open_vSwitch",
+			Mutations: []interface{}{libovsdb.NewMutation("bridges", "delete", bridgeSet)},
+			Where:     []interface{}{rootCondition()},
+		},
+	}
+	if node.hasBridgeOpt() {
+		operations = append(operations, libovsdb.Operation{Op: "delete", Table: "BridgeOpt", Where: []interface{}{condition}})
+	}
+	return node.transact(operations...)
+}
+
+// removeBridge connects to the node and deletes a bridge.
+func (node *ovsNode) removeBridge(bridgeName string) error {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if err := node.connect(); err != nil {
+		return err
+	}
+	if err := node.deleteBridge(bridgeName); err != nil {
+		return err
+	}
+	log.Infof("Deleted bridge [ %s ] on node [ %s ]", bridgeName, node.name)
+	return nil
+}
+
+// getNode returns the node a network asks for, empty for this host.
+func (d *Driver) getNode(r *dknet.CreateNetworkRequest) (string, error) {
+	name := getStringOption(r, nodeOption)
+	if name == "" {
+		return "", nil
+	}
+	if _, ok := d.nodes[name]; !ok {
+		return "", fmt.Errorf("%s %s is not a node given with --ovsdb-node", nodeOption, name)
+	}
+	return name, nil
+}
+
+// checkNodeNetwork fails options that are configured on this host rather
+// than in OVSDB, a node's bridge only gets its OVSDB settings. Containers
+// can't attach to a node's bridge either, its ports are the node's own.
+func checkNodeNetwork(ns *NetworkState, chain []string) error {
+	switch {
+	case ns.Mode != modeFlat:
+		return fmt.Errorf("%s is only supported in %s mode", nodeOption, modeFlat)
+	case ns.FlatBindInterface != "" || ns.DPDKUplink != "" || ns.VLAN != 0:
+		return fmt.Errorf("%s networks have no uplink, attach it on the node", nodeOption)
+	case ns.Switchdev || ns.InternalPorts || ns.PortSecurity || !ns.ICC:
+		return fmt.Errorf("%s networks have no endpoints, endpoint options are not supported", nodeOption)
+	case ns.QoSMinRate > 0 || ns.QoSMaxRate > 0 || len(ns.QoSClasses) > 0 || ns.PgwGateway != "" || len(chain) > 0:
+		return fmt.Errorf("QoS, pools and service chains are not supported on %s networks", nodeOption)
+	}
+	return nil
+}
+
+// createNodeNetwork creates a network whose bridge is on a remote node.
+func (d *Driver) createNodeNetwork(networkID string, ns *NetworkState) error {
+	if err := d.nodes[ns.Node].initBridge(networkID, ns); err != nil {
+		log.Errorf("failed to create network %s on node %s: %v", truncateID(networkID), ns.Node, err)
+		return err
+	}
+	d.setNetwork(networkID, ns)
+	d.replicate()
+	d.notifyController()
+	return nil
+}
+
+// deleteNodeNetwork deletes the bridge of a network on its node.
+func (d *Driver) deleteNodeNetwork(networkID string, ns *NetworkState) error {
+	if node, ok := d.nodes[ns.Node]; ok {
+		if err := node.removeBridge(ns.BridgeName); err != nil {
+			log.Errorf("failed to delete network %s on node %s: %v", truncateID(networkID), ns.Node, err)
+			return err
+		}
+	}
+	d.forgetNetwork(networkID)
+	d.replicate()
+	d.notifyController()
+	return nil
+}
+
+// restoreNodeNetworks rebuilds the state of the networks whose bridge is on
+// a node from the node's OVSDB, the local one doesn't know them, so that
+// they can still be deleted and are listed after a restart. The networks
+// of a node that can't be reached are not restored.
+func (d *Driver) restoreNodeNetworks() {
+	for name, node := range d.nodes {
+		node.mu.Lock()
+		bridges, err := func() (map[string]libovsdb.Row, error) {
+			if err := node.connect(); err != nil {
+				return nil, err
+			}
+			return node.selectNetworkBridges()
+		}()
+		node.mu.Unlock()
+		if err != nil {
+			log.Warnf("failed to restore the networks of node %s: %v", name, err)
+			continue
+		}
+		for networkID, row := range bridges {
+			if _, ok := d.network(networkID); ok {
+				continue
+			}
+			ids := row.Fields["external_ids"]
+			ns := &NetworkState{
+				NetworkType: ovsMapValue(ids, networkTypeKey),
+				NetworkName: ovsMapValue(ids, networkNameKey),
+				Mode:        ovsMapValue(ids, networkModeKey),
+				Node:        name,
+				ICC:         true,
+			}
+			ns.BridgeName, _ = row.Fields["name"].(string)
+			ns.Datapath, _ = row.Fields["datapath_type"].(string)
+			if ns.Mode == "" {
+				ns.Mode = modeFlat
+			}
+			d.setNetwork(networkID, ns)
+			log.Infof("Restored network [ %s ] on bridge [ %s ] of node [ %s ]", truncateID(networkID), ns.BridgeName, name)
+		}
+	}
+}
+
+// nodeStatus lists the nodes with their networks.
+func (d *Driver) nodeStatus() []NodeStatus {
+	var nodes []NodeStatus
+	for name, node := range d.nodes {
+		node.mu.Lock()
+		status := NodeStatus{Name: name, Endpoint: node.endpoint, Connected: node.client() != nil}
+		node.mu.Unlock()
+		for id, ns := range d.networkStates() {
+			if ns.Node == name {
+				status.Networks = append(status.Networks, id)
+			}
+		}
+		sort.Strings(status.Networks)
+		nodes = append(nodes, status)
+	}
+	sort.Sort(nodesByName(nodes))
+	return nodes
+}
+
+type nodesByName []NodeStatus
+
+func (s nodesByName) Len() int           { return len(s) }
+func (s nodesByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s nodesByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+// handleNodes serves /nodes, the remote nodes and their networks.
+func (d *Driver) handleNodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, d.nodeStatus())
+}
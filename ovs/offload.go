@@ -0,0 +1,256 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	hwOffloadKey = "hw-offload"
+	tcPolicyKey  = "tc-policy"
+
+	// switchdevOption gives each endpoint of a flat mode network a VF of the
+	// bind interface, a PF in switchdev mode, instead of a veth. The VF's
+	// representor is renamed to the endpoint's port name and attached to
+	// the bridge, so its flows can be offloaded to the NIC with tc flower.
+	switchdevOption = "linker.net.ovs.bridge.switchdev"
+
+	sysClassNet = "/sys/class/net"
+)
+
+// OffloadStatus is the preflight report of hardware offload. Offloaded
+// flows are only counted while traffic keeps them in the datapath.
+type OffloadStatus struct {
+	Enabled        bool
+	TCPolicy       string `json:",omitempty"`
+	OffloadedFlows int
+	Uplinks        []UplinkOffload `json:",omitempty"`
+	Warnings       []string        `json:",omitempty"`
+}
+
+// UplinkOffload is the offload state of the PF of a switchdev network.
+type UplinkOffload struct {
+	Name      string
+	NetworkID string
+	Switchdev bool
+	TCOffload bool
+	VFs       int
+	VFsInUse  int
+}
+
+// setHWOffload sets other_config:hw-offload, the switch only applies it
+// after ovs-vswitchd restarts.
+func (ovsdber *ovsdber) setHWOffload(enabled bool) error {
+	config, _ := libovsdb.NewOvsMap(map[string]string{hwOffloadKey: fmt.Sprintf("%t", enabled)})
+	if err := ovsdber.mutateRootMap("other_config", hwOffloadKey, libovsdb.NewMutation("other_config", "insert", config)); err != nil {
+		return err
+	}
+	log.Infof("Set %s to %t, restart ovs-vswitchd if it was not set before", hwOffloadKey, enabled)
+	return nil
+}
+
+// checkSwitchdev fails unless the PF is in switchdev mode.
+func checkSwitchdev(pf string) error {
+	if pf == "" {
+		return fmt.Errorf("%s requires %s", switchdevOption, bindInterfaceOption)
+	}
+	ok, err := eswitchSwitchdev(pf)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s is not in switchdev mode, run devlink dev eswitch set pci/<pf> mode switchdev", pf)
+	}
+	return nil
+}
+
+func pciAddress(iface string) (string, error) {
+	device, err := filepath.EvalSymlinks(filepath.Join(sysClassNet, iface, "device"))
+	if err != nil {
+		return "", fmt.Errorf("%s is not a PCI device: %v", iface, err)
+	}
+	return filepath.Base(device), nil
+}
+
+func eswitchSwitchdev(pf string) (bool, error) {
+	pci, err := pciAddress(pf)
+	if err != nil {
+		return false, err
+	}
+	out, err := exec.Command("devlink", "dev", "eswitch", "show", "pci/"+pci).CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("devlink dev eswitch show pci/%s: %v %s", pci, err, strings.TrimSpace(string(out)))
+	}
+	return strings.Contains(string(out), "mode switchdev"), nil
+}
+
+func readSysNet(iface, attr string) string {
+	value, _ := ioutil.ReadFile(filepath.Join(sysClassNet, iface, attr))
+	return strings.TrimSpace(string(value))
+}
+
+// pfVFs maps the VF numbers of a PF to their netdev, empty for VFs whose
+// netdev was moved into a container.
+func pfVFs(pf string) map[string]string {
+	vfs := make(map[string]string)
+	paths, _ := filepath.Glob(filepath.Join(sysClassNet, pf, "device", "virtfn*"))
+	for _, path := range paths {
+		vf := strings.TrimPrefix(filepath.Base(path), "virtfn")
+		names, _ := filepath.Glob(filepath.Join(path, "net", "*"))
+		vfs[vf] = ""
+		if len(names) > 0 {
+			vfs[vf] = filepath.Base(names[0])
+		}
+	}
+	return vfs
+}
+
+// vfRepresentor returns the representor of a VF, the netdev on the PF's
+// switch whose port name ends in vf<N>.
+func vfRepresentor(pf, vf string) string {
+	switchID := readSysNet(pf, "phys_switch_id")
+	names, _ := ioutil.ReadDir(sysClassNet)
+	for _, name := range names {
+		if name.Name() == pf || readSysNet(name.Name(), "phys_switch_id") != switchID {
+			continue
+		}
+		if strings.HasSuffix(readSysNet(name.Name(), "phys_port_name"), "vf"+vf) {
+			return name.Name()
+		}
+	}
+	return ""
+}
+
+// allocateVF picks a free VF of the PF for an endpoint and attaches its
+// representor to the bridge under the endpoint's port name. It returns the
+// VF netdev to move into the container and the representor's own name.
+func (d *Driver) allocateVF(ctx context.Context, pf, bridgeName, portName string) (string, string, error) {
+	vfs := pfVFs(pf)
+	numbers := make([]string, 0, len(vfs))
+	for vf := range vfs {
+		numbers = append(numbers, vf)
+	}
+	sort.Strings(numbers)
+	for _, vf := range numbers {
+		netdev := vfs[vf]
+		if netdev == "" {
+			continue
+		}
+		rep := vfRepresentor(pf, vf)
+		if rep == "" || portUUIDForName(rep) != "" {
+			continue
+		}
+		link, err := netlink.LinkByName(rep)
+		if err != nil {
+			continue
+		}
+		if err := netlink.LinkSetName(link, portName); err != nil {
+			return "", "", fmt.Errorf("failed to rename representor %s: %v", rep, err)
+		}
+		if err := netlink.LinkSetUp(link); err != nil {
+			releaseRepresentor(portName, rep)
+			return "", "", err
+		}
+		if err := d.addOvsVethPort(ctx, bridgeName, portName, 0); err != nil {
+			releaseRepresentor(portName, rep)
+			return "", "", err
+		}
+		if err := d.ovsdber.setRowMap("Interface", portName, "external_ids", map[string]string{representorKey: rep}); err != nil {
+			if err := d.ovsdber.deletePort(ctx, bridgeName, portName); err != nil {
+				log.Warnf("failed to delete port %s: %v", portName, err)
+			}
+			releaseRepresentor(portName, rep)
+			return "", "", fmt.Errorf("failed to record representor %s on port %s: %v", rep, portName, err)
+		}
+		log.Infof("Attached representor [ %s ] of VF [ %s ] as [ %s ] to bridge [ %s ]", rep, netdev, portName, bridgeName)
+		return netdev, rep, nil
+	}
+	return "", "", fmt.Errorf("no free VF left on %s", pf)
+}
+
+// portRepresentor returns the representor recorded on an endpoint's port,
+// for an endpoint the driver has no state of.
+func portRepresentor(portName string) string {
+	_, row, _ := ovsdbCache.iface(portName)
+	return ovsMapValue(row.Fields["external_ids"], representorKey)
+}
+
+// releaseRepresentor gives a representor its name back once its port is
+// gone, so the VF can be allocated again.
+func releaseRepresentor(portName, rep string) {
+	link, err := netlink.LinkByName(portName)
+	if err != nil {
+		return
+	}
+	netlink.LinkSetDown(link)
+	if err := netlink.LinkSetName(link, rep); err != nil {
+		log.Warnf("failed to rename representor %s back to %s: %v", portName, rep, err)
+	}
+}
+
+// offloadStatus checks that offload is configured and the NICs are able to
+// do it.
+func (d *Driver) offloadStatus() *OffloadStatus {
+	status := &OffloadStatus{}
+	for _, row := range getTableCache("Open_vSwitch") {
+		status.Enabled = ovsMapValue(row.Fields["other_config"], hwOffloadKey) == "true"
+		status.TCPolicy = ovsMapValue(row.Fields["other_config"], tcPolicyKey)
+	}
+	if !status.Enabled {
+		status.Warnings = append(status.Warnings, "other_config:hw-offload is not true, start the plugin with --hw-offload")
+	}
+	out, err := exec.Command("ovs-appctl", "dpctl/dump-flows", "type=offloaded").Output()
+	if err != nil {
+		status.Warnings = append(status.Warnings, fmt.Sprintf("failed to dump offloaded flows: %v", err))
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) != "" {
+			status.OffloadedFlows++
+		}
+	}
+	for id, ns := range d.networkStates() {
+		if !ns.Switchdev {
+			continue
+		}
+		uplink := UplinkOffload{Name: ns.FlatBindInterface, NetworkID: id}
+		if ok, err := eswitchSwitchdev(uplink.Name); err != nil {
+			status.Warnings = append(status.Warnings, err.Error())
+		} else {
+			uplink.Switchdev = ok
+		}
+		if out, err := exec.Command("ethtool", "-k", uplink.Name).Output(); err == nil {
+			uplink.TCOffload = strings.Contains(string(out), "hw-tc-offload: on")
+		}
+		if !uplink.TCOffload {
+			status.Warnings = append(status.Warnings, fmt.Sprintf("hw-tc-offload is off on %s, run ethtool -K %s hw-tc-offload on", uplink.Name, uplink.Name))
+		}
+		for _, netdev := range pfVFs(uplink.Name) {
+			uplink.VFs++
+			if netdev == "" {
+				uplink.VFsInUse++
+			}
+		}
+		status.Uplinks = append(status.Uplinks, uplink)
+	}
+	sort.Strings(status.Warnings)
+	return status
+}
+
+// handleOffload serves /offload, the preflight report of hardware offload.
+func (d *Driver) handleOffload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, d.offloadStatus())
+}
@@ -0,0 +1,87 @@
+package ovs
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	linkStateUp = "up"
+
+	// Flows are split in two tables. Table 0 holds the per-port security
+	// flows and hands accepted traffic to table 1, which holds the policy
+	// flows and ends in normal switching.
+	portSecurityTable = 0
+	policyTable       = 1
+
+	// pipelineCookie tags the default flows of the tables. Cookie 0 is
+	// left to the switch's own NORMAL flow and to flows added by hand.
+	pipelineCookie = "0x1"
+)
+
+// flowCookie derives the OpenFlow cookie tagging all flows installed for an
+// endpoint or network, so that they can be removed together.
+func flowCookie(id string) string {
+	if len(id) > 16 {
+		id = id[:16]
+	}
+	return "0x" + id
+}
+
+// ctTrack is the action sending a packet through conntrack in the zone,
+// continuing in the table with the connection state set.
+func ctTrack(zone, table int) string {
+	return fmt.Sprintf("ct(zone=%d,table=%d)", zone, table)
+}
+
+// ctCommit is the action committing the connection of a packet to the zone.
+func ctCommit(zone int) string {
+	return fmt.Sprintf("ct(commit,zone=%d)", zone)
+}
+
+// setupPipeline installs the default flows of both tables on a new bridge.
+func setupPipeline(bridgeName string) error {
+	if err := addFlow(bridgeName, portSecurityTable, fmt.Sprintf("cookie=%s,priority=1,actions=resubmit(,%d)", pipelineCookie, policyTable)); err != nil {
+		return err
+	}
+	return addFlow(bridgeName, policyTable, fmt.Sprintf("cookie=%s,priority=0,actions=NORMAL", pipelineCookie))
+}
+
+// addFlow installs a flow in a table of the bridge through ovs-ofctl.
+func addFlow(bridgeName string, table int, flow string) error {
+	flow = fmt.Sprintf("table=%d,%s", table, flow)
+	log.Debugf("add flow on %s: %s", bridgeName, flow)
+	out, err := exec.Command("ovs-ofctl", "add-flow", bridgeName, flow).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ovs-ofctl add-flow %s %s: %v %s", bridgeName, flow, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// delFlows removes every flow carrying the cookie from the bridge.
+func delFlows(bridgeName, cookie string) error {
+	out, err := exec.Command("ovs-ofctl", "del-flows", bridgeName, "cookie="+cookie+"/-1").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ovs-ofctl del-flows %s cookie=%s: %v %s", bridgeName, cookie, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ofportForName returns the OpenFlow port number of an interface, or -1 if
+// the switch has not assigned one yet.
+func ofportForName(name string) int {
+	_, row, _ := ovsdbCache.iface(name)
+	if ofport, ok := row.Fields["ofport"].(float64); ok && ofport > 0 {
+		return int(ofport)
+	}
+	return -1
+}
+
+// linkUp reports whether the switch sees the link of an interface as up.
+func linkUp(name string) bool {
+	_, row, ok := ovsdbCache.iface(name)
+	return ok && row.Fields["link_state"] == linkStateUp
+}
@@ -2,30 +2,80 @@ package ovs
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
-	"github.com/docker/libnetwork/iptables"
-	"github.com/socketplane/libovsdb"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
 )
 
 //  setupBridge If bridge does not exist create it.
-func (d *Driver) initBridge(id string) error {
-	bridgeName := d.networks[id].BridgeName
-	bindInterface := d.networks[id].FlatBindInterface
-	networktype := d.networks[id].NetworkType
-	networkname := d.networks[id].NetworkName
+func (d *Driver) initBridge(ctx context.Context, id string) error {
+	ns, ok := d.network(id)
+	if !ok {
+		return fmt.Errorf("no state for network %s", truncateID(id))
+	}
+	bridgeName := ns.BridgeName
+	bindInterface := ns.FlatBindInterface
+	networktype := ns.NetworkType
+	networkname := ns.NetworkName
+
+	datapath := ns.Datapath
+	if datapath == "" {
+		datapath = defaultDatapath(networktype)
+	}
+	if datapath == datapathKernel {
+		if err := ensureKernelModule(); err != nil {
+			log.Errorf("can't create ovs bridge [ %s ]: %v", bridgeName, err)
+			return err
+		}
+	}
 
-	if err := d.ovsdber.addBridge(bridgeName, networktype, id); err != nil {
+	meta := networkMetadata(networkname, ns.Mode)
+	if err := d.ovsdber.addBridge(ctx, bridgeName, networktype, id, ns.Datapath, meta); err != nil {
 		log.Errorf("error creating ovs bridge [ %s ] : [ %s ]", bridgeName, err)
 		return err
 	}
+	if err := d.ovsdber.setRowMap("Bridge", bridgeName, "external_ids", map[string]string{ctZoneKey: strconv.Itoa(ns.CTZone)}); err != nil {
+		log.Errorf("failed to record the conntrack zone of bridge %s: %v", bridgeName, err)
+		return err
+	}
+
+	if failMode := ns.FailMode; failMode != "" {
+		if err := d.ovsdber.setFailMode(bridgeName, failMode); err != nil {
+			log.Errorf("failed to set fail mode of bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
+
+	if ns.SpanningTree != "" {
+		if err := d.ovsdber.setSpanningTree(bridgeName, ns.SpanningTree, ns.SpanningTreePrio); err != nil {
+			log.Errorf("failed to enable %s on bridge %s: %v", ns.SpanningTree, bridgeName, err)
+			return err
+		}
+	}
+
+	if hwaddr := ns.Hwaddr; hwaddr != "" {
+		if err := d.ovsdber.setBridgeHwaddr(bridgeName, hwaddr); err != nil {
+			log.Errorf("failed to set MAC of bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
+
+	if ns.MACAgingTime > 0 || ns.MACTableSize > 0 {
+		if err := d.ovsdber.setMACTable(bridgeName, ns.MACAgingTime, ns.MACTableSize); err != nil {
+			log.Errorf("failed to tune the MAC table of bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
 
 	retries := 10
 	found := false
+	_, s := startSpan(ctx, "netlink.waitBridgeLink")
 	for i := 0; i < retries; i++ {
 		if found = validateIface(bridgeName); found {
 			break
@@ -34,15 +84,48 @@ func (d *Driver) initBridge(id string) error {
 		time.Sleep(2 * time.Second)
 	}
 	if found == false {
-		return fmt.Errorf("Could not find a link for the OVS bridge named %s", bridgeName)
+		err := fmt.Errorf("Could not find a link for the OVS bridge named %s", bridgeName)
+		finishSpan(s, err)
+		return err
 
 	}
+	finishSpan(s, nil)
+
+	if ns.McastSnooping {
+		if err := d.ovsdber.setMcastSnooping(bridgeName, true); err != nil {
+			log.Errorf("failed to enable multicast snooping on bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
+
+	if len(ns.NetFlowTargets) > 0 {
+		if err := d.ovsdber.setNetFlow(bridgeName, ns.NetFlowTargets, ns.NetFlowTimeout); err != nil {
+			log.Errorf("failed to configure NetFlow on bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
 
-	bridgeMode := d.networks[id].Mode
+	if sflow := ns.SFlow; sflow != nil {
+		if err := d.ovsdber.setSFlow(bridgeName, sflow); err != nil {
+			log.Errorf("failed to configure sFlow on bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
+
+	if ipfix := ns.IPFIX; ipfix != nil {
+		if err := d.ovsdber.setIPFIX(bridgeName, ipfix); err != nil {
+			log.Errorf("failed to configure IPFIX on bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
+
+	// uplinkPort is the OpenFlow port north-south traffic leaves through
+	uplinkPort := "LOCAL"
+	bridgeMode := ns.Mode
 	switch bridgeMode {
 	case modeNAT:
 		{
-			gatewayIP := d.networks[id].Gateway + "/" + d.networks[id].GatewayMask
+			gatewayIP := ns.Gateway + "/" + ns.GatewayMask
 			if err := setInterfaceIP(bridgeName, gatewayIP); err != nil {
 				log.Debugf("Error assigning address: %s on bridge: %s with an error of: %s", gatewayIP, bridgeName, err)
 			}
@@ -54,9 +137,18 @@ func (d *Driver) initBridge(id string) error {
 				return err
 			}
 
-			// Add NAT rules for iptables
-			if err = natOut(gatewayIP); err != nil {
-				log.Fatalf("Could not set NAT rules for bridge %s", bridgeName)
+			// Containers with /32 or nonstandard masks ARP for off-subnet
+			// destinations, the gateway answers for them
+			if ns.ProxyARP {
+				if err := setProxyARP(bridgeName); err != nil {
+					log.Errorf("Could not enable proxy ARP on bridge %s: %v", bridgeName, err)
+					return err
+				}
+			}
+
+			// Add NAT rules in a chain owned by this network
+			if err = d.firewall.setupNetwork(ctx, id, bridgeName, gatewayIP); err != nil {
+				log.Errorf("Could not set NAT rules for bridge %s: %v", bridgeName, err)
 				return err
 			}
 		}
@@ -64,51 +156,121 @@ func (d *Driver) initBridge(id string) error {
 	case modeFlat:
 		{
 			//ToDo: Add NIC to the bridge
+			uplinkPort = bindInterface
+			if devargs := ns.DPDKUplink; devargs != "" {
+				uplink, err := d.ovsdber.addDPDKUplink(bridgeName, devargs)
+				if err != nil {
+					log.Errorf("Could not attach DPDK uplink %s to bridge %s: %v", devargs, bridgeName, err)
+					return err
+				}
+				uplinkPort = uplink
+			}
+			vlan := ns.VLAN
+			svlan := ns.SVLAN
+			if vlan != 0 && bindInterface != "" {
+				var uplink string
+				var err error
+				if svlan != 0 {
+					uplink, err = d.ovsdber.createQinQUplink(ctx, id, bindInterface, ns.Datapath, svlan, vlan)
+				} else {
+					uplink, err = d.ovsdber.createVlanUplink(ctx, bindInterface, vlan)
+				}
+				if err != nil {
+					log.Errorf("Could not create vlan %d uplink on %s: %v", vlan, bindInterface, err)
+					return err
+				}
+				if pcp := ns.PCP; pcp != 0 {
+					if err := setVlanPCP(uplink, pcp); err != nil {
+						log.Errorf("Could not set priority %d on vlan uplink %s: %v", pcp, uplink, err)
+						d.ovsdber.deleteVlanUplink(uplink)
+						return err
+					}
+				}
+				if err := d.addOvsVethPort(ctx, bridgeName, uplink, 0); err != nil {
+					log.Errorf("error attaching uplink [ %s ] to bridge [ %s ]", uplink, bridgeName)
+					d.ovsdber.deleteVlanUplink(uplink)
+					return err
+				}
+				log.Infof("Attached vlan %d uplink [ %s ] to bridge [ %s ]", vlan, uplink, bridgeName)
+				uplinkPort = uplink
+			}
 		}
 	}
 
 	// Bring the bridge up
-	err := interfaceUp(bridgeName)
+	err := interfaceUp(ctx, bridgeName)
 	if err != nil {
 		log.Warnf("Error enabling bridge: [ %s ]", err)
 		return err
 	}
 
-	runOvsScript(bridgeName, networkname, networktype, bindInterface)
+	// Port security, policies and ICC all hang off the two table pipeline,
+	// a bridge without it would switch traffic they should filter
+	if err := setupPipeline(bridgeName); err != nil {
+		log.Errorf("failed to install the OpenFlow pipeline on bridge %s: %v", bridgeName, err)
+		return err
+	}
+	if err := setupARPResponder(id, ns); err != nil {
+		log.Errorf("failed to install the ARP responder flows on bridge %s: %v", bridgeName, err)
+		return err
+	}
+
+	if d.hostsGateway() {
+		runOvsScript(ctx, bridgeName, networkname, networktype, bindInterface)
+	}
+
+	// the script attaches the bind interface in flat mode, so QoS and
+	// isolation flows on the uplink come after it
+	if ns.QoSMinRate > 0 || ns.QoSMaxRate > 0 || len(ns.QoSClasses) > 0 {
+		port := qosPort(bridgeName, uplinkPort)
+		if err := d.ovsdber.setPortQoS(port, ns.QoSMinRate, ns.QoSMaxRate, ns.QoSClasses); err != nil {
+			log.Errorf("failed to set QoS on bridge %s: %v", bridgeName, err)
+			return err
+		}
+		d.updateNetwork(ns, func(ns *NetworkState) { ns.QoSPort = port })
+	}
+	if !ns.ICC {
+		gatewayCIDR := ""
+		if bridgeMode == modeNAT {
+			gatewayCIDR = ns.Gateway + "/" + ns.GatewayMask
+			if err := d.firewall.isolateNetwork(ctx, id, bridgeName); err != nil {
+				log.Errorf("failed to stop forwarding between endpoints on bridge %s: %v", bridgeName, err)
+				return err
+			}
+		}
+		if err := disableICC(id, bridgeName, uplinkPort, gatewayCIDR, ns.CTZone); err != nil {
+			log.Errorf("failed to isolate endpoints on bridge %s: %v", bridgeName, err)
+			return err
+		}
+	}
 
 	return nil
 }
 
-func runOvsScript(bridgeName, networkName, networkType, bindInterface string) {
+func runOvsScript(ctx context.Context, bridgeName, networkName, networkType, bindInterface string) {
 	//if !strings.EqualFold(networkType, type_sgw) && !strings.EqualFold(networkType, type_pgw) {
 	//	log.Infof("network type is not sgw or pgw, no need to run ovs script, type is %s", networkType)
 	//	return
 	//}
 
 	var commandTextBuffer bytes.Buffer
-	commandTextBuffer.WriteString("/usr/sbin/ovsopt.sh ")
+	commandTextBuffer.WriteString(gatewayScript + " ")
 	commandTextBuffer.WriteString(networkType + " ")
 	commandTextBuffer.WriteString(networkName + " ")
 	commandTextBuffer.WriteString(bridgeName + " ")
 	commandTextBuffer.WriteString(bindInterface)
 
-	err := StartOvsService(commandTextBuffer.String())
+	err := StartOvsService(ctx, commandTextBuffer.String())
 	if err != nil {
 		log.Errorf("start ovsopt.sh error %v", err)
 	}
 
 }
 
-func (ovsdber *ovsdber) createBridgeIface(name, servicetype, networkid string) error {
-	err := ovsdber.createOvsdbBridge(name, servicetype, networkid)
-	if err != nil {
-		log.Errorf("Bridge creation failed for the bridge named [ %s ] with errors: %s", name, err)
-	}
-	return nil
-}
-
-// createOvsdbBridge creates the OVS bridge
-func (ovsdber *ovsdber) createOvsdbBridge(bridgeName, servicetype, networkid string) error {
+// createOvsdbBridge creates the OVS bridge on the datapath, the default one
+// of the service type if empty. The network it belongs to is recorded in
+// the bridge's external_ids along with meta.
+func (ovsdber *ovsdber) createOvsdbBridge(ctx context.Context, bridgeName, servicetype, networkid, datapath string, meta map[string]string) error {
 	namedBridgeUUID := "bridge"
 	namedPortUUID := "port"
 	namedIntfUUID := "intf"
@@ -117,6 +279,7 @@ func (ovsdber *ovsdber) createOvsdbBridge(bridgeName, servicetype, networkid str
 	intf := make(map[string]interface{})
 	intf["name"] = bridgeName
 	intf["type"] = "internal"
+	intf["external_ids"] = ovsdber.ownerExternalIDs()
 
 	insertIntfOp := libovsdb.Operation{
 		Op:       "insert",
@@ -129,6 +292,7 @@ func (ovsdber *ovsdber) createOvsdbBridge(bridgeName, servicetype, networkid str
 	port := make(map[string]interface{})
 	port["name"] = bridgeName
 	port["interfaces"] = libovsdb.UUID{namedIntfUUID}
+	port["external_ids"] = ovsdber.ownerExternalIDs()
 
 	insertPortOp := libovsdb.Operation{
 		Op:       "insert",
@@ -142,9 +306,12 @@ func (ovsdber *ovsdber) createOvsdbBridge(bridgeName, servicetype, networkid str
 	bridge["name"] = bridgeName
 	bridge["stp_enable"] = false
 	bridge["ports"] = libovsdb.UUID{namedPortUUID}
-	//for sgw and pgw, set datapath_type to "netdev"
-	if strings.EqualFold(servicetype, type_pgw) || strings.EqualFold(servicetype, type_sgw) {
-		bridge["datapath_type"] = "netdev"
+	bridge["external_ids"] = ovsdber.bridgeExternalIDs(networkid, servicetype, meta)
+	if datapath == "" {
+		datapath = defaultDatapath(servicetype)
+	}
+	if datapath != datapathKernel {
+		bridge["datapath_type"] = datapath
 	}
 
 	//insert bridge opt info, such as servicetype
@@ -180,25 +347,83 @@ func (ovsdber *ovsdber) createOvsdbBridge(bridgeName, servicetype, networkid str
 		Where:     []interface{}{condition},
 	}
 
-	operations := []libovsdb.Operation{insertIntfOp, insertPortOp, insertBridgeOp, insertBridgeOptOp, mutateOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
+	operations := []libovsdb.Operation{insertIntfOp, insertPortOp, insertBridgeOp, mutateOp}
+	if ovsdber.hasBridgeOpt() {
+		operations = append(operations, insertBridgeOptOp)
+	}
+	return ovsdber.transactContext(ctx, operations...)
+}
 
-	if len(reply) < len(operations) {
-		return errors.New("Number of Replies should be atleast equal to number of Operations")
+// setMcastSnooping turns IGMP/MLD snooping of the bridge on or off, with it
+// multicast is only forwarded to ports that joined the group.
+func (ovsdber *ovsdber) setMcastSnooping(bridgeName string, enabled bool) error {
+	updateOp := libovsdb.Operation{
+		Op:    "update",
+		Table: "Bridge",
+		Row:   map[string]interface{}{"mcast_snooping_enable": enabled},
+		Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
 	}
-	for i, o := range reply {
-		if o.Error != "" && i < len(operations) {
-			return errors.New("Transaction Failed due to an error :" + o.Error + " details : " + o.Details)
-		} else if o.Error != "" {
-			return errors.New("Transaction Failed due to an error :" + o.Error + " details : " + o.Details)
-		}
+	if err := ovsdber.transact(updateOp); err != nil {
+		return err
 	}
+	log.Infof("Set multicast snooping of bridge [ %s ] to %t", bridgeName, enabled)
+	return nil
+}
+
+// setFailMode sets what the bridge does when its controller disconnects.
+// A secure bridge keeps its flows, among them the pipeline the plugin
+// installs, instead of falling back to MAC learning.
+func (ovsdber *ovsdber) setFailMode(bridgeName, failMode string) error {
+	updateOp := libovsdb.Operation{
+		Op:    "update",
+		Table: "Bridge",
+		Row:   map[string]interface{}{"fail_mode": failMode},
+		Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+	}
+	if err := ovsdber.transact(updateOp); err != nil {
+		return err
+	}
+	log.Infof("Set fail mode of bridge [ %s ] to %s", bridgeName, failMode)
+	return nil
+}
+
+// setBridgeOtherConfig sets keys of the bridge's other_config, replacing
+// the values a reused bridge has.
+func (ovsdber *ovsdber) setBridgeOtherConfig(bridgeName string, config map[string]string) error {
+	return ovsdber.setRowMap("Bridge", bridgeName, "other_config", config)
+}
+
+// setMACTable sets how long the bridge keeps learned MACs and how many it
+// learns, 0 leaves the switch default. Large flat networks overflow the
+// default table and start flooding.
+func (ovsdber *ovsdber) setMACTable(bridgeName string, agingTime, tableSize int) error {
+	config := make(map[string]string)
+	if agingTime > 0 {
+		config["mac-aging-time"] = strconv.Itoa(agingTime)
+	}
+	if tableSize > 0 {
+		config["mac-table-size"] = strconv.Itoa(tableSize)
+	}
+	if err := ovsdber.setBridgeOtherConfig(bridgeName, config); err != nil {
+		return err
+	}
+	log.Infof("Set MAC table of bridge [ %s ] to %v", bridgeName, config)
+	return nil
+}
+
+// setBridgeHwaddr pins the MAC of the bridge's internal interface, which
+// is the gateway MAC of nat mode networks.
+func (ovsdber *ovsdber) setBridgeHwaddr(bridgeName, hwaddr string) error {
+	if err := ovsdber.setBridgeOtherConfig(bridgeName, map[string]string{"hwaddr": hwaddr}); err != nil {
+		return err
+	}
+	log.Infof("Set MAC of bridge [ %s ] to %s", bridgeName, hwaddr)
 	return nil
 }
 
 // Check if port exists prior to creating a bridge
-func (ovsdber *ovsdber) addBridge(bridgeName, servicetype, networkid string) error {
-	if ovsdber.ovsdb == nil {
+func (ovsdber *ovsdber) addBridge(ctx context.Context, bridgeName, servicetype, networkid, datapath string, meta map[string]string) error {
+	if ovsdber.client() == nil {
 		return errors.New("OVS not connected")
 	}
 	// If the bridge has been created, an internal port with the same name will exist
@@ -206,29 +431,38 @@ func (ovsdber *ovsdber) addBridge(bridgeName, servicetype, networkid string) err
 	if err != nil {
 		return err
 	}
-	if !exists {
-		if err := ovsdber.createBridgeIface(bridgeName, servicetype, networkid); err != nil {
-			return err
-		}
-		exists, err = ovsdber.portExists(bridgeName)
-		if err != nil {
+	if exists {
+		// an existing bridge is only reused if the plugin created it,
+		// bridges of older releases get the network recorded on reuse
+		if err := ovsdber.checkBridgeOwner(bridgeName); err != nil {
 			return err
 		}
-		if !exists {
-			return errors.New("Error creating Bridge")
-		}
+		return ovsdber.setBridgeExternalIDs(bridgeName, networkid, servicetype, meta)
 	}
-	return nil
+	err = converge("create bridge "+bridgeName, ovsdber.created("Bridge", bridgeName), func() error {
+		return ovsdber.createOvsdbBridge(ctx, bridgeName, servicetype, networkid, datapath, meta)
+	})
+	if err != nil {
+		log.Errorf("Bridge creation failed for the bridge named [ %s ] with errors: %s", bridgeName, err)
+	}
+	return err
 }
 
 // deleteBridge deletes the OVS bridge
-func (d *Driver) deleteBridge(bridgeName string) error {
+func (d *Driver) deleteBridge(ctx context.Context, bridgeName string) error {
+	if err := d.ovsdber.checkBridgeOwner(bridgeName); err != nil {
+		return err
+	}
+
 	//get bridge's servicetype
 	serviceType, err := d.ovsdber.getBridgeServiceType(bridgeName)
 	if err != nil {
 		log.Warnf("failed to get network service type,bridge name is %s", bridgeName)
 	}
 
+	// vlan uplinks are plain netlink devices and outlive the bridge
+	d.ovsdber.removeVlanUplinks(ctx, bridgeName)
+
 	// simple delete operation
 	condition := libovsdb.NewCondition("name", "==", bridgeName)
 	deleteOp := libovsdb.Operation{
@@ -244,42 +478,39 @@ func (d *Driver) deleteBridge(bridgeName string) error {
 		Where: []interface{}{condition},
 	}
 
-	bridgeUUID := getBridgeUUIDForName(bridgeName)
-	if bridgeUUID == "" {
-		log.Error("Unable to find a bridge uuid by name : ", bridgeName)
-		return fmt.Errorf("Unable to find a bridge uuid by name : [ %s ]", bridgeName)
-	}
-
-	// Deleting a Bridge row in Bridge table requires mutating the open_vswitch table.
-	mutateUUID := []libovsdb.UUID{libovsdb.UUID{bridgeUUID}}
-	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
-	mutation := libovsdb.NewMutation("bridges", "delete", mutateSet)
-	conditionm := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{d.ovsdber.getRootUUID()})
-
-	log.Debugf("mutation is %v", mutateSet)
-	// simple mutate operation
-	mutateOp := libovsdb.Operation{
-		Op:        "mutate",
-		Table:     "Open_vSwitch",
-		Mutations: []interface{}{mutation},
-		Where:     []interface{}{conditionm},
-	}
+	// the bridge may be gone already, deleted by an attempt whose reply
+	// was lost
+	err = converge("delete bridge "+bridgeName, d.ovsdber.deleted("Bridge", bridgeName), func() error {
+		bridgeUUID := getBridgeUUIDForName(bridgeName)
+		if bridgeUUID == "" {
+			log.Error("Unable to find a bridge uuid by name : ", bridgeName)
+			return fmt.Errorf("Unable to find a bridge uuid by name : [ %s ]", bridgeName)
+		}
 
-	operations := []libovsdb.Operation{deleteOp, deleteOptOp, mutateOp}
-	reply, _ := d.ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
+		// Deleting a Bridge row in Bridge table requires mutating the open_vswitch table.
+		mutateUUID := []libovsdb.UUID{libovsdb.UUID{bridgeUUID}}
+		mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
+		mutation := libovsdb.NewMutation("bridges", "delete", mutateSet)
+		conditionm := libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{d.ovsdber.getRootUUID()})
+
+		log.Debugf("mutation is %v", mutateSet)
+		// simple mutate operation
+		mutateOp := libovsdb.Operation{
+			Op:        "mutate",
+			Table:     "Open_vSwitch",
+			Mutations: []interface{}{mutation},
+			Where:     []interface{}{conditionm},
+		}
 
-	if len(reply) < len(operations) {
-		log.Error("Number of Replies should be atleast equal to number of Operations")
-	}
-	for i, o := range reply {
-		if o.Error != "" && i < len(operations) {
-			log.Error("Transaction Failed due to an error :", o.Error, " in ", operations[i])
-			errMsg := fmt.Sprintf("Transaction Failed due to an error: %s in operation: %v", o.Error, operations[i])
-			return errors.New(errMsg)
-		} else if o.Error != "" {
-			errMsg := fmt.Sprintf("Transaction Failed due to an error : %s", o.Error)
-			return errors.New(errMsg)
+		operations := []libovsdb.Operation{deleteOp, mutateOp}
+		if d.ovsdber.hasBridgeOpt() {
+			operations = append(operations, deleteOptOp)
 		}
+		return d.ovsdber.transactContext(ctx, operations...)
+	})
+	if err != nil {
+		log.Errorf("failed to delete bridge %s: %v", bridgeName, err)
+		return err
 	}
 	log.Debugf("OVSDB delete bridge transaction succesful")
 
@@ -289,7 +520,7 @@ func (d *Driver) deleteBridge(bridgeName string) error {
 		return nil
 	}
 
-	errs := stopOvsService()
+	errs := stopOvsService(ctx)
 	if errs != nil {
 		log.Warnf("stop ovs service error %v", errs)
 	}
@@ -298,36 +529,22 @@ func (d *Driver) deleteBridge(bridgeName string) error {
 }
 
 func getBridgeUUIDForName(name string) string {
-	bridgeCache := ovsdbCache["Bridge"]
-	for key, val := range bridgeCache {
-		if val.Fields["name"] == name {
-			return key
-		}
-	}
-	return ""
-
+	uuid, _, _ := ovsdbCache.bridge(name)
+	return uuid
 }
 
-// todo: reconcile with what libnetwork does and port mappings
-func natOut(cidr string) error {
-	masquerade := []string{
-		"POSTROUTING", "-t", "nat",
-		"-s", cidr,
-		"-j", "MASQUERADE",
-	}
-	if _, err := iptables.Raw(
-		append([]string{"-C"}, masquerade...)...,
-	); err != nil {
-		incl := append([]string{"-I"}, masquerade...)
-		if output, err := iptables.Raw(incl...); err != nil {
-			return err
-		} else if len(output) > 0 {
-			return &iptables.ChainError{
-				Chain:  "POSTROUTING",
-				Output: output,
-			}
+// bridgePortNames returns the names of all ports on the named bridge.
+func bridgePortNames(bridgeName string) []string {
+	_, bridge, ok := ovsdbCache.bridge(bridgeName)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, uuid := range rowUUIDs(bridge.Fields["ports"]) {
+		port, _ := ovsdbCache.row("Port", uuid)
+		if name, ok := port.Fields["name"].(string); ok {
+			names = append(names, name)
 		}
 	}
-	return nil
+	return names
 }
-
@@ -1,28 +1,32 @@
 package ovs
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
-	"github.com/socketplane/libovsdb"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
 )
 
-func (ovsdber *ovsdber) createOvsInternalPort(prefix string, bridge string, tag uint) (port string, err error) {
+func (ovsdber *ovsdber) createOvsInternalPort(ctx context.Context, prefix string, bridge string, tag uint) (port string, err error) {
 	// if you desire a longer hash add using generateRandomName(prefix, 5)
 	port = prefix
-	if ovsdber.ovsdb == nil {
+	if ovsdber.client() == nil {
 		err = errors.New("OVS not connected")
 		return
 	}
 
-	ovsdber.addInternalPort(bridge, port, tag)
+	err = ovsdber.addInternalPort(ctx, bridge, port, tag)
 	return
 }
 
-func (ovsdber *ovsdber) addInternalPort(bridgeName string, portName string, tag uint) error {
-	namedPortUUID := "port"
-	namedIntfUUID := "intf"
+func (ovsdber *ovsdber) addInternalPort(ctx context.Context, bridgeName string, portName string, tag uint) error {
+	namedPortUUID := namedUUID("port")
+	namedIntfUUID := namedUUID("intf")
 
 	// intf row to insert
 	intf := make(map[string]interface{})
@@ -40,6 +44,7 @@ func (ovsdber *ovsdber) addInternalPort(bridgeName string, portName string, tag
 	port := make(map[string]interface{})
 	port["name"] = portName
 	port["interfaces"] = libovsdb.UUID{namedIntfUUID}
+	port["external_ids"] = ovsdber.ownerExternalIDs()
 
 	if tag != 0 {
 		port["tag"] = tag
@@ -67,24 +72,15 @@ func (ovsdber *ovsdber) addInternalPort(bridgeName string, portName string, tag
 	}
 
 	operations := []libovsdb.Operation{insertIntfOp, insertPortOp, mutateOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
-	if len(reply) < len(operations) {
-		log.Error("Number of Replies should be atleast equal to number of Operations")
-		return errors.New("Number of Replies should be atleast equal to number of Operations")
-	}
-	for i, o := range reply {
-		if o.Error != "" && i < len(operations) {
-			msg := fmt.Sprintf("Transaction Failed due to an error : %v details: %v in %v", o.Error, o.Details, operations[i])
-			return errors.New(msg)
-		} else if o.Error != "" {
-			msg := fmt.Sprintf("Transaction Failed due to an error : %v", o.Error)
-			return errors.New(msg)
-		}
-	}
-	return nil
+	return converge("add port "+portName, ovsdber.created("Port", portName), func() error {
+		return ovsdber.transactPort(ctx, operations...)
+	})
 }
 
-func (ovsdber *ovsdber) deletePort(bridgeName string, portName string) error {
+func (ovsdber *ovsdber) deletePort(ctx context.Context, bridgeName string, portName string) error {
+	if err := ovsdber.checkPortOwner(portName); err != nil {
+		return err
+	}
 	condition := libovsdb.NewCondition("name", "==", portName)
 	deleteOp := libovsdb.Operation{
 		Op:    "delete",
@@ -92,57 +88,61 @@ func (ovsdber *ovsdber) deletePort(bridgeName string, portName string) error {
 		Where: []interface{}{condition},
 	}
 
-	portUUID := portUUIDForName(portName)
-	if portUUID == "" {
-		log.Error("Unable to find a matching Port : ", portName)
-		return fmt.Errorf("Unable to find a matching Port : [ %s ]", portName)
-	}
-
-	// Deleting a Bridge row in Bridge table requires mutating the open_vswitch table.
-	mutateUUID := []libovsdb.UUID{libovsdb.UUID{portUUID}}
-	mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
-	mutation := libovsdb.NewMutation("ports", "delete", mutateSet)
-	condition = libovsdb.NewCondition("name", "==", bridgeName)
-
-	// simple mutate operation
-	mutateOp := libovsdb.Operation{
-		Op:        "mutate",
-		Table:     "Bridge",
-		Mutations: []interface{}{mutation},
-		Where:     []interface{}{condition},
-	}
-
-	operations := []libovsdb.Operation{deleteOp, mutateOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
+	// the port may be gone already, deleted by an attempt whose reply was
+	// lost
+	err := converge("delete port "+portName, ovsdber.deleted("Port", portName), func() error {
+		portUUID := portUUIDForName(portName)
+		if portUUID == "" {
+			log.Error("Unable to find a matching Port : ", portName)
+			return fmt.Errorf("Unable to find a matching Port : [ %s ]", portName)
+		}
 
-	if len(reply) < len(operations) {
-		log.Error("Number of Replies should be atleast equal to number of Operations")
-		return fmt.Errorf("Number of Replies should be atleast equal to number of Operations")
-	}
-	for i, o := range reply {
-		if o.Error != "" && i < len(operations) {
-			log.Error("Transaction Failed due to an error :", o.Error, " in ", operations[i])
-			return fmt.Errorf("Transaction Failed due to an error: %s in %v", o.Error, operations[i])
-		} else if o.Error != "" {
-			log.Error("Transaction Failed due to an error :", o.Error)
-			return fmt.Errorf("Transaction Failed due to an error %s", o.Error)
+		// Deleting a Bridge row in Bridge table requires mutating the open_vswitch table.
+		mutateUUID := []libovsdb.UUID{libovsdb.UUID{portUUID}}
+		mutateSet, _ := libovsdb.NewOvsSet(mutateUUID)
+		mutation := libovsdb.NewMutation("ports", "delete", mutateSet)
+		condition = libovsdb.NewCondition("name", "==", bridgeName)
+
+		// simple mutate operation
+		mutateOp := libovsdb.Operation{
+			Op:        "mutate",
+			Table:     "Bridge",
+			Mutations: []interface{}{mutation},
+			Where:     []interface{}{condition},
 		}
+
+		operations := []libovsdb.Operation{deleteOp, mutateOp}
+		return ovsdber.transactPort(ctx, operations...)
+	})
+	if err != nil {
+		log.Errorf("failed to delete port %s: %v", portName, err)
+		return err
 	}
 	return nil
 }
 
-func (ovsdber *ovsdber) addVxlanPort(bridgeName string, portName string, peerAddress string) {
-	namedPortUUID := "port"
-	namedIntfUUID := "intf"
+// addVxlanPort adds a tunnel to a peer node carrying the vni, from
+// localAddress or the route to the peer when it is empty. The port is
+// protected, so traffic coming in over one tunnel is never flooded out of
+// another and the full mesh of tunnels has no loops.
+func (ovsdber *ovsdber) addVxlanPort(ctx context.Context, bridgeName string, portName string, peerAddress string, localAddress string, vni int) error {
+	namedPortUUID := namedUUID("port")
+	namedIntfUUID := namedUUID("intf")
 
 	options := make(map[string]interface{})
 	options["remote_ip"] = peerAddress
+	options["key"] = strconv.Itoa(vni)
+	if localAddress != "" {
+		options["local_ip"] = localAddress
+	}
 
 	// intf row to insert
 	intf := make(map[string]interface{})
 	intf["name"] = portName
 	intf["type"] = `vxlan`
 	intf["options"], _ = libovsdb.NewOvsMap(options)
+	// BFD keepalives detect a dead peer instead of silently dropping packets
+	intf["bfd"], _ = bfdConfig()
 
 	insertIntfOp := libovsdb.Operation{
 		Op:       "insert",
@@ -155,6 +155,8 @@ func (ovsdber *ovsdber) addVxlanPort(bridgeName string, portName string, peerAdd
 	port := make(map[string]interface{})
 	port["name"] = portName
 	port["interfaces"] = libovsdb.UUID{namedIntfUUID}
+	port["external_ids"] = ovsdber.peerExternalIDs(peerAddress)
+	port["protected"] = true
 
 	insertPortOp := libovsdb.Operation{
 		Op:       "insert",
@@ -177,24 +179,15 @@ func (ovsdber *ovsdber) addVxlanPort(bridgeName string, portName string, peerAdd
 		Where:     []interface{}{condition},
 	}
 	operations := []libovsdb.Operation{insertIntfOp, insertPortOp, mutateOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
-	if len(reply) < len(operations) {
-		fmt.Println("Number of Replies should be atleast equal to number of Operations")
-	}
-	for i, o := range reply {
-		if o.Error != "" && i < len(operations) {
-			fmt.Println("Transaction Failed due to an error :", o.Error, " details:", o.Details, " in ", operations[i])
-		} else if o.Error != "" {
-			fmt.Println("Transaction Failed due to an error :", o.Error)
-		}
-	}
+	return converge("add port "+portName, ovsdber.created("Port", portName), func() error {
+		return ovsdber.transactPort(ctx, operations...)
+	})
 }
 
-// Silently fails :/
-func (ovsdber *ovsdber) addOvsVethPort(bridgeName string, portName string, tag uint) error {
+func (ovsdber *ovsdber) addOvsVethPort(ctx context.Context, bridgeName string, portName string, tag uint) error {
 
-	namedPortUUID := "port"
-	namedIntfUUID := "intf"
+	namedPortUUID := namedUUID("port")
+	namedIntfUUID := namedUUID("intf")
 
 	// intf row to insert
 	intf := make(map[string]interface{})
@@ -212,6 +205,7 @@ func (ovsdber *ovsdber) addOvsVethPort(bridgeName string, portName string, tag u
 	port := make(map[string]interface{})
 	port["name"] = portName
 	port["interfaces"] = libovsdb.UUID{namedIntfUUID}
+	port["external_ids"] = ovsdber.ownerExternalIDs()
 
 	insertPortOp := libovsdb.Operation{
 		Op:       "insert",
@@ -233,29 +227,109 @@ func (ovsdber *ovsdber) addOvsVethPort(bridgeName string, portName string, tag u
 		Where:     []interface{}{condition},
 	}
 	operations := []libovsdb.Operation{insertIntfOp, insertPortOp, mutateOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
+	return converge("add port "+portName, ovsdber.created("Port", portName), func() error {
+		return ovsdber.transactPort(ctx, operations...)
+	})
+}
+
+func portUUIDForName(portName string) string {
+	uuid, _, _ := ovsdbCache.port(portName)
+	return uuid
+}
+
+// portRow returns the row of the named port, empty if there is none.
+func portRow(portName string) libovsdb.Row {
+	_, row, _ := ovsdbCache.port(portName)
+	return row
+}
 
-	if len(reply) < len(operations) {
-		log.Error("Number of Replies should be atleast equal to number of Operations")
+// addEndpointInternalPort adds an internal port for an endpoint and waits
+// for its link, which Join hands to docker to move into the container in
+// place of a veth peer.
+func (ovsdber *ovsdber) addEndpointInternalPort(ctx context.Context, bridgeName, portName string) error {
+	if err := ovsdber.addInternalPort(ctx, bridgeName, portName, 0); err != nil {
+		return err
 	}
-	for i, o := range reply {
-		if o.Error != "" && i < len(operations) {
-			msg := fmt.Sprintf("Transaction Failed due to an error ]", o.Error, " details:", o.Details, " in ", operations[i])
-			return errors.New(msg)
-		} else if o.Error != "" {
-			msg := fmt.Sprintf("Transaction Failed due to an error :", o.Error)
-			return errors.New(msg)
+	for i := 0; i < 10; i++ {
+		if validateIface(portName) {
+			if err := ovsdber.markLink(portName); err != nil {
+				ovsdber.deletePort(ctx, bridgeName, portName)
+				return err
+			}
+			return nil
 		}
+		time.Sleep(200 * time.Millisecond)
 	}
+	return fmt.Errorf("could not find a link for the internal port %s", portName)
+}
+
+// portIsInternal reports whether the named port is an OVS internal port.
+func portIsInternal(portName string) bool {
+	_, row, ok := ovsdbCache.iface(portName)
+	return ok && row.Fields["type"] == "internal"
+}
+
+// Keys of the external_ids of an endpoint's Interface row, so that ports
+// can be traced back to their endpoint with plain ovs-vsctl
+const (
+	endpointIDKey  = "docker-endpoint-id"
+	containerIDKey = "docker-container-id"
+	attachedMACKey = "attached-mac"
+	ipAddressKey   = "ip-address"
+	// representorKey records the original name of a VF representor
+	// attached under an endpoint's port name, to give it back at leave
+	representorKey = "linker-representor"
+)
+
+// recordEndpoint records the endpoint behind a port on its Interface row.
+// The MAC is the one requested for the endpoint or else the one of the
+// link docker moves into the container.
+func (d *Driver) recordEndpoint(portName, networkID, endpointID, peerName string) error {
+	ids := map[string]string{
+		networkIDKey:  networkID,
+		endpointIDKey: endpointID,
+	}
+	if es, ok := d.endpoint(endpointID); ok {
+		if es.Address != "" {
+			ids[ipAddressKey] = es.Address
+		}
+		if es.MacAddress != "" {
+			ids[attachedMACKey] = es.MacAddress
+		}
+	}
+	if _, ok := ids[attachedMACKey]; !ok && peerName != "" {
+		if link, err := netlink.LinkByName(peerName); err == nil {
+			ids[attachedMACKey] = link.Attrs().HardwareAddr.String()
+		}
+	}
+	return d.ovsdber.setRowMap("Interface", portName, "external_ids", ids)
+}
+
+// recordContainer records the container of an endpoint once docker can
+// tell it, in external_ids and in the other_config keys read into the
+// context cache, which maps the container to its endpoint.
+func (ovsdber *ovsdber) recordContainer(portName, containerID, endpointID string) error {
+	if err := ovsdber.setRowMap("Interface", portName, "external_ids", map[string]string{containerIDKey: containerID}); err != nil {
+		return err
+	}
+	config := map[string]string{contextKey: containerID, contextValue: endpointID}
+	if err := ovsdber.setRowMap("Interface", portName, "other_config", config); err != nil {
+		return err
+	}
+	contextMu.Lock()
+	contextCache[containerID] = endpointID
+	contextMu.Unlock()
 	return nil
 }
 
-func portUUIDForName(portName string) string {
-	portCache := ovsdbCache["Port"]
-	for key, val := range portCache {
-		if val.Fields["name"] == portName {
-			return key
+// forgetContainer drops the context cache entry of an endpoint whose port
+// is gone.
+func forgetContainer(endpointID string) {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	for containerID, id := range contextCache {
+		if id == endpointID {
+			delete(contextCache, containerID)
 		}
 	}
-	return ""
 }
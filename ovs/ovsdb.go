@@ -1,42 +1,190 @@
 package ovs
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"reflect"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
-	"github.com/socketplane/libovsdb"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
 )
 
 const (
-	localhost    = "127.0.0.1"
-	ovsdbPort    = 6640
 	contextKey   = "container_id"
 	contextValue = "container_data"
 	minMTU       = 68
+
+	// ovsdbSocket is where ovsdb-server listens on most distros, the plugin
+	// falls back to ovsdbTCP, the manager port, if it is missing
+	ovsdbSocket = "/var/run/openvswitch/db.sock"
+	ovsdbTCP    = "tcp:127.0.0.1:6640"
 )
 
 var (
 	quit         chan bool
-	update       chan *libovsdb.TableUpdates
-	ovsdbCache   map[string]map[string]libovsdb.Row
 	contextCache map[string]string
+	contextMu    sync.Mutex
 )
 
 type ovsdber struct {
-	ovsdb *libovsdb.OvsdbClient
+	// ovsdb is replaced by reconnect while other goroutines use it, it is
+	// read through client and written through setClient
+	clientMu sync.RWMutex
+	ovsdb    *libovsdb.OvsdbClient
+	// instance is recorded next to the owner marker of created resources
+	instance string
+	// forceOwnership lets the plugin modify resources it did not create
+	forceOwnership bool
+	// endpoint is the ovsdb-server address the plugin connects, and
+	// reconnects, to. tlsConfig is set for ssl: endpoints.
+	endpoint  string
+	tlsConfig *tls.Config
+	// monitorAll monitors every column of every table instead of
+	// monitoredColumns
+	monitorAll bool
+	// batcher merges port changes into shared transactions, nil when
+	// batching is off
+	batcher *txnBatcher
+}
+
+// client returns the current connection, nil if there is none.
+func (ovsdber *ovsdber) client() *libovsdb.OvsdbClient {
+	ovsdber.clientMu.RLock()
+	defer ovsdber.clientMu.RUnlock()
+	return ovsdber.ovsdb
+}
+
+// setClient makes client the connection operations use.
+func (ovsdber *ovsdber) setClient(client *libovsdb.OvsdbClient) {
+	ovsdber.clientMu.Lock()
+	ovsdber.ovsdb = client
+	ovsdber.clientMu.Unlock()
+}
+
+// hasBridgeOpt reports whether the schema has the custom BridgeOpt table,
+// stock schemas don't and bridges only record their network in
+// external_ids.
+func (ovsdber *ovsdber) hasBridgeOpt() bool {
+	client := ovsdber.client()
+	if client == nil {
+		return false
+	}
+	_, ok := client.Schema["Open_vSwitch"].Tables["BridgeOpt"]
+	return ok
+}
+
+// ovsdbEndpoint returns the ovsdb-server address to use, the unix socket if
+// none is given and it exists.
+func ovsdbEndpoint(endpoint string) string {
+	if endpoint != "" {
+		return endpoint
+	}
+	if _, err := os.Stat(ovsdbSocket); err == nil {
+		return "unix:" + ovsdbSocket
+	}
+	return ovsdbTCP
+}
+
+// checkOvsdbEndpoint validates an address in the ovs-vsctl --db syntax,
+// unix:<path>, tcp:<ip>:<port> or ssl:<ip>:<port>.
+func checkOvsdbEndpoint(endpoint string) error {
+	parts := strings.SplitN(endpoint, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return fmt.Errorf("ovsdb endpoint must be unix:<path>, tcp:<ip>:<port> or ssl:<ip>:<port>, got %s", endpoint)
+	}
+	switch parts[0] {
+	case "unix":
+		return nil
+	case "tcp", "ssl":
+		host, port, err := net.SplitHostPort(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid ovsdb endpoint %s: %v", endpoint, err)
+		}
+		if _, err := strconv.Atoi(port); err != nil || net.ParseIP(host) == nil {
+			return fmt.Errorf("ovsdb endpoint %s must be %s:<ip>:<port>", endpoint, parts[0])
+		}
+		return nil
+	}
+	return fmt.Errorf("ovsdb endpoint must be unix:<path>, tcp:<ip>:<port> or ssl:<ip>:<port>, got %s", endpoint)
+}
+
+// dialOvsdb connects to ovsdb-server at a checked endpoint, ssl: endpoints
+// with the TLS config given.
+func dialOvsdb(endpoint string, tlsConfig *tls.Config) (*libovsdb.OvsdbClient, error) {
+	parts := strings.SplitN(endpoint, ":", 2)
+	var conn net.Conn
+	var err error
+	if parts[0] == "ssl" {
+		conn, err = tls.Dial("tcp", parts[1], tlsConfig)
+	} else {
+		// unix:<path> and tcp:<ip>:<port> are what net.Dial takes
+		conn, err = net.Dial(parts[0], parts[1])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return libovsdb.ConnectWithConn(conn)
 }
 
+// connectOvsdb connects to the ovsdb-server of the config, retrying as
+// its OvsdbRetry says, and returns the endpoint and TLS config it used.
+func connectOvsdb(config Config) (*libovsdb.OvsdbClient, string, *tls.Config, error) {
+	endpoint := ovsdbEndpoint(config.OvsdbEndpoint)
+	if err := checkOvsdbEndpoint(endpoint); err != nil {
+		return nil, "", nil, err
+	}
+	var tlsConfig *tls.Config
+	var err error
+	if strings.HasPrefix(endpoint, "ssl:") {
+		if tlsConfig, err = ovsdbTLSConfig(config.OvsdbCert, config.OvsdbKey, config.OvsdbCA); err != nil {
+			return nil, "", nil, err
+		}
+	}
+	var ovsdb *libovsdb.OvsdbClient
+	retry := config.OvsdbRetry.withDefaults()
+	for i := 0; ; i++ {
+		ovsdb, err = dialOvsdb(endpoint, tlsConfig)
+		if err == nil || !retry.retry(i) {
+			break
+		}
+		delay := retry.delay(i)
+		log.Errorf("could not connect to openvswitch at [ %s ]: %s. Retrying in %s", endpoint, err, delay)
+		time.Sleep(delay)
+	}
+
+	if ovsdb == nil {
+		return nil, "", nil, fmt.Errorf("could not connect to open vswitch")
+	}
+	return ovsdb, endpoint, tlsConfig, nil
+}
+
+// reconnectPolicy is how the connection to ovsdb-server is retried once it
+// was lost, the plugin can't work without it
+var reconnectPolicy = RetryPolicy{Forever: true, Backoff: time.Second, MaxBackoff: 30 * time.Second, Jitter: 0.1}
+
+// OvsdbNotifier feeds the table updates of an OVSDB connection to the cache
+// and reconnects the ovsdber it belongs to when the connection is lost.
 type OvsdbNotifier struct {
+	db *ovsdber
 }
 
 func (o OvsdbNotifier) Update(context interface{}, tableUpdates libovsdb.TableUpdates) {
 	populateCache(tableUpdates)
-	update <- &tableUpdates
+	updates.push(tableUpdates)
 }
 func (o OvsdbNotifier) Disconnected(ovsClient *libovsdb.OvsdbClient) {
+	if o.db == nil || o.db.client() != ovsClient {
+		return
+	}
+	log.Warnf("lost the connection to ovsdb-server, reconnecting")
+	go o.db.reconnect()
 }
 func (o OvsdbNotifier) Locked([]interface{}) {
 }
@@ -45,28 +193,105 @@ func (o OvsdbNotifier) Stolen([]interface{}) {
 func (o OvsdbNotifier) Echo([]interface{}) {
 }
 
-func (ovsdber *ovsdber) initDBCache() {
+// rootTimeout is how long initDBCache waits for the Open_vSwitch row
+const rootTimeout = 30 * time.Second
+
+func (ovsdber *ovsdber) initDBCache() error {
 	quit = make(chan bool)
-	update = make(chan *libovsdb.TableUpdates)
-	ovsdbCache = make(map[string]map[string]libovsdb.Row)
+	ovsdbCache.reset()
+	contextCache = make(map[string]string)
 
+	if err := ovsdber.monitor(ovsdber.client()); err != nil {
+		log.Errorf("Error populating initial OVSDB cache: %s", err)
+	}
+
+	// async monitoring of the ovs bridge(s) for table updates
+	go ovsdber.monitorBridges()
+	return ovsdbCache.waitRoot(rootTimeout)
+}
+
+// monitoredColumns are the columns the plugin reads from the cache, the
+// only ones monitored unless monitorAll is set. Hosts with thousands of
+// ports would otherwise send every column of every table, statistics
+// included, on each change.
+var monitoredColumns = map[string][]string{
+	"Open_vSwitch": {"bridges", "external_ids", "other_config", "ovs_version", "datapath_types", "iface_types"},
+	"Bridge":       {"name", "ports", "mirrors", "datapath_type", "external_ids"},
+	"Port":         {"name", "interfaces", "tag", "qos", "external_ids"},
+	"Interface":    {"name", "type", "options", "ofport", "link_state", "bfd_status", "external_ids", "other_config"},
+	"BridgeOpt":    {"name", "service_type", "network_id"},
+	"Mirror":       {"name", "select_all", "select_src_port", "output_port", "statistics"},
+	"QoS":          {"queues"},
+}
+
+// monitorRequests returns the monitor requests for monitoredColumns, less
+// the tables and columns the schema doesn't have.
+func monitorRequests(schema libovsdb.DatabaseSchema) map[string]libovsdb.MonitorRequest {
+	requests := make(map[string]libovsdb.MonitorRequest)
+	for table, columns := range monitoredColumns {
+		tableSchema, ok := schema.Tables[table]
+		if !ok {
+			continue
+		}
+		var present []string
+		for _, column := range columns {
+			if _, ok := tableSchema.Columns[column]; ok {
+				present = append(present, column)
+			}
+		}
+		requests[table] = libovsdb.MonitorRequest{
+			Columns: present,
+			Select:  libovsdb.MonitorSelect{Initial: true, Insert: true, Delete: true, Modify: true},
+		}
+	}
+	return requests
+}
+
+// monitor registers for the table updates of a connection, rebuilds the
+// cache from the tables it returns and makes it the ovsdber's connection.
+func (ovsdber *ovsdber) monitor(client *libovsdb.OvsdbClient) error {
 	// Register for ovsdb table notifications
-	var notifier OvsdbNotifier
-	ovsdber.ovsdb.Register(notifier)
+	client.Register(OvsdbNotifier{db: ovsdber})
 	// Populate ovsdb cache for the default Open_vSwitch db
-	initCache, err := ovsdber.ovsdb.MonitorAll("Open_vSwitch", "")
+	var initCache *libovsdb.TableUpdates
+	var err error
+	if ovsdber.monitorAll {
+		initCache, err = client.MonitorAll("Open_vSwitch", "")
+	} else {
+		initCache, err = client.Monitor("Open_vSwitch", "", monitorRequests(client.Schema["Open_vSwitch"]))
+	}
 	if err != nil {
-		log.Errorf("Error populating initial OVSDB cache: %s", err)
+		return err
 	}
+	ovsdber.setClient(client)
 	log.Debugf("MonitorAll is %v", *initCache)
-	populateCache(*initCache)
-	contextCache = make(map[string]string)
-	populateContextCache(ovsdber.ovsdb)
+	ovsdbCache.replace(*initCache)
+	if !ovsdber.hasBridgeOpt() {
+		log.Infof("OVSDB schema has no BridgeOpt table, bridges record their network in external_ids only")
+	}
+	populateContextCache(client)
+	return nil
+}
 
-	// async monitoring of the ovs bridge(s) for table updates
-	go ovsdber.monitorBridges()
-	for ovsdber.getRootUUID() == "" {
-		time.Sleep(time.Second * 1)
+// reconnect connects to ovsdb-server again, with a backoff, and monitors
+// the new connection. The bridge monitor keeps running across connections,
+// and the reconciler is signalled since changes made while the plugin was
+// disconnected were never reported.
+func (ovsdber *ovsdber) reconnect() {
+	time.Sleep(reconnectPolicy.Backoff)
+	for i := 0; ; i++ {
+		client, err := dialOvsdb(ovsdber.endpoint, ovsdber.tlsConfig)
+		if err == nil {
+			if err = ovsdber.monitor(client); err == nil {
+				log.Infof("Reconnected to ovsdb-server")
+				signalReconcile()
+				return
+			}
+			client.Disconnect()
+		}
+		delay := reconnectPolicy.delay(i)
+		log.Warnf("could not reconnect to ovsdb-server: %s. Retrying in %s", err, delay)
+		time.Sleep(delay)
 	}
 }
 
@@ -75,50 +300,98 @@ func populateContextCache(ovs *libovsdb.OvsdbClient) {
 		return
 
 	}
+	contextMu.Lock()
+	defer contextMu.Unlock()
 	tableCache := getTableCache("Interface")
 	for _, row := range tableCache {
-		config, ok := row.Fields["other_config"]
-		ovsMap := config.(libovsdb.OvsMap)
-		otherConfig := map[interface{}]interface{}(ovsMap.GoMap)
-		if ok {
-			containerID, ok := otherConfig[contextKey]
-			if ok {
-				contextCache[containerID.(string)] = otherConfig[contextValue].(string)
-			}
+		containerID := ovsMapValue(row.Fields["other_config"], contextKey)
+		if containerID != "" {
+			contextCache[containerID] = ovsMapValue(row.Fields["other_config"], contextValue)
 		}
 	}
 }
 
+// getTableCache returns a snapshot of a table, safe to range over while the
+// cache is updated.
 func getTableCache(tableName string) map[string]libovsdb.Row {
-	return ovsdbCache[tableName]
+	return ovsdbCache.table(tableName)
 }
 
-func (ovsdber *ovsdber) portExists(portName string) (bool, error) {
-	condition := libovsdb.NewCondition("name", "==", portName)
-	selectOp := libovsdb.Operation{
-		Op:    "select",
-		Table: "Port",
-		Where: []interface{}{condition},
-	}
-	operations := []libovsdb.Operation{selectOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
+// transact runs the operations in one transaction and returns the first
+// error reported for them.
+func (ovsdber *ovsdber) transact(operations ...libovsdb.Operation) error {
+	return ovsdber.transactContext(context.Background(), operations...)
+}
+
+// transactContext is transact for a step of the driver call traced in ctx.
+func (ovsdber *ovsdber) transactContext(ctx context.Context, operations ...libovsdb.Operation) error {
+	_, err := ovsdber.transactReplyContext(ctx, operations...)
+	return err
+}
 
-	if len(reply) < len(operations) {
-		return false, errors.New("Number of Replies should be at least equal to number of Operations")
+// mutateRootExternalIDs deletes the key from the external_ids of the root
+// Open_vSwitch row, then applies the mutations. A map insert does not
+// overwrite keys, so the delete comes first.
+func (ovsdber *ovsdber) mutateRootExternalIDs(key string, mutations ...interface{}) error {
+	return ovsdber.mutateRootMap("external_ids", key, mutations...)
+}
+
+// mutateRootMap is mutateRootExternalIDs for any map column of the root row.
+func (ovsdber *ovsdber) mutateRootMap(column, key string, mutations ...interface{}) error {
+	keySet, _ := libovsdb.NewOvsSet([]string{key})
+	mutateOp := libovsdb.Operation{
+		Op:        "mutate",
+		Table:     "Open_vSwitch",
+		Mutations: append([]interface{}{libovsdb.NewMutation(column, "delete", keySet)}, mutations...),
+		Where:     []interface{}{libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUuid: ovsdber.getRootUUID()})},
 	}
+	return ovsdber.transact(mutateOp)
+}
 
-	if reply[0].Error != "" {
-		errMsg := fmt.Sprintf("Transaction Failed due to an error: %v", reply[0].Error)
-		return false, errors.New(errMsg)
+// setRowMap sets keys of a map column of the named row, replacing the
+// values the keys had.
+func (ovsdber *ovsdber) setRowMap(table, name, column string, values map[string]string) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	keySet, _ := libovsdb.NewOvsSet(keys)
+	valueMap, _ := libovsdb.NewOvsMap(values)
+	mutateOp := libovsdb.Operation{
+		Op:    "mutate",
+		Table: table,
+		Mutations: []interface{}{
+			libovsdb.NewMutation(column, "delete", keySet),
+			libovsdb.NewMutation(column, "insert", valueMap),
+		},
+		Where: []interface{}{libovsdb.NewCondition("name", "==", name)},
 	}
+	return ovsdber.transact(mutateOp)
+}
 
-	if len(reply[0].Rows) == 0 {
-		return false, nil
+// deleteRowMapKeys deletes keys of a map column of the named row.
+func (ovsdber *ovsdber) deleteRowMapKeys(table, name, column string, keys ...string) error {
+	keySet, _ := libovsdb.NewOvsSet(keys)
+	mutateOp := libovsdb.Operation{
+		Op:        "mutate",
+		Table:     table,
+		Mutations: []interface{}{libovsdb.NewMutation(column, "delete", keySet)},
+		Where:     []interface{}{libovsdb.NewCondition("name", "==", name)},
 	}
-	return true, nil
+	return ovsdber.transact(mutateOp)
+}
+
+func (ovsdber *ovsdber) portExists(portName string) (bool, error) {
+	return ovsdber.rowExists("Port", portName)
 }
 
 func (ovsdber *ovsdber) getBridgeServiceType(bridgenName string) (string, error) {
+	if serviceType := bridgeExternalID(bridgenName, networkTypeKey); serviceType != "" {
+		return serviceType, nil
+	}
+	if !ovsdber.hasBridgeOpt() {
+		return "", errors.New("no record with bridge name")
+	}
 	condition := libovsdb.NewCondition("name", "==", bridgenName)
 	selectOp := libovsdb.Operation{
 		Op:    "select",
@@ -126,15 +399,9 @@ func (ovsdber *ovsdber) getBridgeServiceType(bridgenName string) (string, error)
 		Where: []interface{}{condition},
 	}
 	operations := []libovsdb.Operation{selectOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
-
-	if len(reply) < len(operations) {
-		return "", errors.New("Number of Replies should be at least equal to number of Operations")
-	}
-
-	if reply[0].Error != "" {
-		errMsg := fmt.Sprintf("Transaction Failed due to an error: %v", reply[0].Error)
-		return "", errors.New(errMsg)
+	reply, err := ovsdber.transactReply(operations...)
+	if err != nil {
+		return "", err
 	}
 
 	rets := reply[0].Rows
@@ -151,6 +418,12 @@ func (ovsdber *ovsdber) getBridgeServiceType(bridgenName string) (string, error)
 
 func (ovsdber *ovsdber) getNetworkidByBridgeName(bridgenName string) (string, error) {
 	log.Debugf("get networid by bridgeName %s", bridgenName)
+	if networkid := bridgeExternalID(bridgenName, networkIDKey); networkid != "" {
+		return networkid, nil
+	}
+	if !ovsdber.hasBridgeOpt() {
+		return "", errors.New("no record with bridge name")
+	}
 	condition := libovsdb.NewCondition("name", "==", bridgenName)
 	selectOp := libovsdb.Operation{
 		Op:    "select",
@@ -158,15 +431,9 @@ func (ovsdber *ovsdber) getNetworkidByBridgeName(bridgenName string) (string, er
 		Where: []interface{}{condition},
 	}
 	operations := []libovsdb.Operation{selectOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
-
-	if len(reply) < len(operations) {
-		return "", errors.New("Number of Replies should be at least equal to number of Operations")
-	}
-
-	if reply[0].Error != "" {
-		errMsg := fmt.Sprintf("Transaction Failed due to an error: %v", reply[0].Error)
-		return "", errors.New(errMsg)
+	reply, err := ovsdber.transactReply(operations...)
+	if err != nil {
+		return "", err
 	}
 
 	rets := reply[0].Rows
@@ -183,22 +450,24 @@ func (ovsdber *ovsdber) getNetworkidByBridgeName(bridgenName string) (string, er
 
 func (ovsdber *ovsdber) getBridgeNameByNetworkId(networkid string) (string, error) {
 	log.Debugf("get bridgeName by networkid %s", networkid)
-	condition := libovsdb.NewCondition("network_id", "==", networkid)
+	if name, ok := ovsdbCache.networkBridge(networkid); ok {
+		return name, nil
+	}
+	// the cache may not have caught up with a bridge created just now
+	table, condition := "BridgeOpt", libovsdb.NewCondition("network_id", "==", networkid)
+	if !ovsdber.hasBridgeOpt() {
+		ids, _ := libovsdb.NewOvsMap(map[string]string{networkIDKey: networkid})
+		table, condition = "Bridge", libovsdb.NewCondition("external_ids", "includes", ids)
+	}
 	selectOp := libovsdb.Operation{
 		Op:    "select",
-		Table: "BridgeOpt",
+		Table: table,
 		Where: []interface{}{condition},
 	}
 	operations := []libovsdb.Operation{selectOp}
-	reply, _ := ovsdber.ovsdb.Transact("Open_vSwitch", operations...)
-
-	if len(reply) < len(operations) {
-		return "", errors.New("Number of Replies should be at least equal to number of Operations")
-	}
-
-	if reply[0].Error != "" {
-		errMsg := fmt.Sprintf("Transaction Failed due to an error: %v", reply[0].Error)
-		return "", errors.New(errMsg)
+	reply, err := ovsdber.transactReply(operations...)
+	if err != nil {
+		return "", err
 	}
 
 	rets := reply[0].Rows
@@ -212,32 +481,25 @@ func (ovsdber *ovsdber) getBridgeNameByNetworkId(networkid string) (string, erro
 	return bridgeName, nil
 }
 
+// pluginBridges maps the name of every bridge created by the plugin to the
+// id of its network, recorded in the bridge's external_ids or, by older
+// releases, in its BridgeOpt row.
+func pluginBridges() map[string]string {
+	return ovsdbCache.pluginBridges()
+}
+
 func (ovsdber *ovsdber) monitorBridges() {
 	for {
-		select {
-		case currUpdate := <-update:
+		for _, currUpdate := range updates.drain() {
 			for table, tableUpdate := range currUpdate.Updates {
-				if table == "Bridge" {
-					for _, row := range tableUpdate.Rows {
-						empty := libovsdb.Row{}
-						if !reflect.DeepEqual(row.New, empty) {
-							oldRow := row.Old
-							if _, ok := oldRow.Fields["name"]; ok {
-								name := oldRow.Fields["name"].(string)
-								servicetype, err := ovsdber.getBridgeServiceType(name)
-								if err != nil {
-									log.Warnf("get servicetpye for bridgeName %s, error %v", name, err)
-									servicetype = "none"
-								}
-								networkid, err := ovsdber.getNetworkidByBridgeName(name)
-								if err != nil {
-									log.Warnf("get networkid for bridgeName %s, error %v", name, err)
-									networkid = "none"
-								}
-								ovsdber.createOvsdbBridge(name, servicetype, networkid)
-							}
-						}
-					}
+				if table == "Interface" {
+					watchTunnelState(tableUpdate)
+				}
+				// the reconciler compares the whole host with the desired
+				// state, repairing bridges from a single row would fight
+				// DeleteNetwork
+				if table == "Bridge" || table == "Port" {
+					signalReconcile()
 				}
 			}
 		}
@@ -245,26 +507,7 @@ func (ovsdber *ovsdber) monitorBridges() {
 }
 
 func (ovsdber *ovsdber) getRootUUID() string {
-	for uuid := range ovsdbCache["Open_vSwitch"] {
-		return uuid
-	}
-	return ""
+	return ovsdbCache.rootUUID()
 }
 
-func populateCache(updates libovsdb.TableUpdates) {
-	log.Debugf("udpates is %v", updates)
-	for table, tableUpdate := range updates.Updates {
-		if _, ok := ovsdbCache[table]; !ok {
-			ovsdbCache[table] = make(map[string]libovsdb.Row)
-		}
-		for uuid, row := range tableUpdate.Rows {
-			empty := libovsdb.Row{}
-			if !reflect.DeepEqual(row.New, empty) {
-				ovsdbCache[table][uuid] = row.New
-			} else {
-				delete(ovsdbCache[table], uuid)
-			}
-		}
-	}
-}
 
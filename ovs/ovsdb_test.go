@@ -0,0 +1,89 @@
+package ovs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckOvsdbEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{endpoint: "unix:/var/run/openvswitch/db.sock"},
+		{endpoint: "tcp:127.0.0.1:6640"},
+		{endpoint: "ssl:10.0.0.1:6640"},
+		{endpoint: "tcp:[::1]:6640"},
+		{endpoint: "unix:", wantErr: true},
+		{endpoint: "/var/run/openvswitch/db.sock", wantErr: true},
+		{endpoint: "tcp:127.0.0.1", wantErr: true},
+		{endpoint: "tcp:localhost:6640", wantErr: true},
+		{endpoint: "ssl:10.0.0.1:ovsdb", wantErr: true},
+		{endpoint: "ptcp:6640", wantErr: true},
+		{endpoint: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := checkOvsdbEndpoint(tt.endpoint); (err != nil) != tt.wantErr {
+			t.Errorf("checkOvsdbEndpoint(%q) = %v, want error %v", tt.endpoint, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOvsdbEndpoint(t *testing.T) {
+	if got := ovsdbEndpoint("tcp:10.0.0.1:6640"); got != "tcp:10.0.0.1:6640" {
+		t.Errorf("ovsdbEndpoint kept %s, want tcp:10.0.0.1:6640", got)
+	}
+	if got := ovsdbEndpoint(""); got != "unix:"+ovsdbSocket && got != ovsdbTCP {
+		t.Errorf("ovsdbEndpoint defaulted to %s, want the unix socket or %s", got, ovsdbTCP)
+	}
+}
+
+// TestDialOvsdbUnix connects to a server on a unix socket that answers
+// list_dbs with no database, as ovsdb-server would with none open.
+func TestDialOvsdbUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ovsdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "db.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	methods := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var req struct {
+			ID     interface{}
+			Method string
+		}
+		if err := json.NewDecoder(conn).Decode(&req); err != nil {
+			return
+		}
+		methods <- req.Method
+		json.NewEncoder(conn).Encode(map[string]interface{}{"id": req.ID, "result": []string{}, "error": nil})
+		// hold the connection until the client is done
+		ioutil.ReadAll(conn)
+	}()
+	client, err := dialOvsdb("unix:"+socket, nil)
+	if err != nil {
+		t.Fatalf("dialOvsdb failed: %v", err)
+	}
+	defer client.Disconnect()
+	if method := <-methods; method != "list_dbs" {
+		t.Errorf("client asked %s, want list_dbs", method)
+	}
+	if len(client.Schema) != 0 {
+		t.Errorf("client read schemas %v of no database", client.Schema)
+	}
+}
@@ -0,0 +1,165 @@
+package ovs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// Rows and links the plugin creates carry an owner marker. The plugin
+	// refuses to change or delete bridges, ports and links without it, so
+	// that it never clobbers what OpenStack or an operator set up, unless
+	// ownership checks are disabled.
+	ownerKey         = "owner"
+	ownerInstanceKey = "owner_instance"
+	ownerValue       = "docker-ovs-plugin"
+
+	// Bridges also record the docker network they belong to, so that
+	// ovs-vsctl list bridge is self-describing and tooling can map bridges
+	// back to networks without the BridgeOpt table.
+	networkIDKey   = "docker-network-id"
+	networkNameKey = "docker-network-name"
+	networkModeKey = "docker-network-mode"
+	networkTypeKey = "docker-network-type"
+)
+
+// ownerExternalIDs returns the external_ids stamped on new OVSDB rows.
+func (ovsdber *ovsdber) ownerExternalIDs() *libovsdb.OvsMap {
+	ids, _ := libovsdb.NewOvsMap(map[string]string{
+		ownerKey:         ownerValue,
+		ownerInstanceKey: ovsdber.instance,
+	})
+	return ids
+}
+
+// networkMetadata is the name and mode of a network recorded on its bridge,
+// unknown values are left out.
+func networkMetadata(name, mode string) map[string]string {
+	meta := make(map[string]string)
+	if name != "" {
+		meta[networkNameKey] = name
+	}
+	if mode != "" {
+		meta[networkModeKey] = mode
+	}
+	return meta
+}
+
+// bridgeIDs returns the network keys of a bridge's external_ids.
+func bridgeIDs(networkid, servicetype string, meta map[string]string) map[string]string {
+	ids := make(map[string]string)
+	for key, value := range meta {
+		ids[key] = value
+	}
+	if networkid != "" && networkid != "none" {
+		ids[networkIDKey] = networkid
+	}
+	if servicetype != "" && servicetype != "none" {
+		ids[networkTypeKey] = servicetype
+	}
+	return ids
+}
+
+// bridgeExternalIDs returns the external_ids of a new bridge, the owner
+// marker and its network.
+func (ovsdber *ovsdber) bridgeExternalIDs(networkid, servicetype string, meta map[string]string) *libovsdb.OvsMap {
+	ids := bridgeIDs(networkid, servicetype, meta)
+	ids[ownerKey] = ownerValue
+	ids[ownerInstanceKey] = ovsdber.instance
+	externalIDs, _ := libovsdb.NewOvsMap(ids)
+	return externalIDs
+}
+
+// setBridgeExternalIDs records the network of an existing bridge.
+func (ovsdber *ovsdber) setBridgeExternalIDs(bridgeName, networkid, servicetype string, meta map[string]string) error {
+	ids := bridgeIDs(networkid, servicetype, meta)
+	if len(ids) == 0 {
+		return nil
+	}
+	return ovsdber.setRowMap("Bridge", bridgeName, "external_ids", ids)
+}
+
+// bridgeExternalID returns a key of a bridge's external_ids, empty if the
+// bridge or key doesn't exist.
+func bridgeExternalID(bridgeName, key string) string {
+	_, row, ok := ovsdbCache.bridge(bridgeName)
+	if !ok {
+		return ""
+	}
+	return ovsMapValue(row.Fields["external_ids"], key)
+}
+
+// rowOwned reports whether a row was created by this instance of the
+// plugin, rows of another instance sharing the switch are not its own.
+func (ovsdber *ovsdber) rowOwned(row libovsdb.Row) bool {
+	ids := row.Fields["external_ids"]
+	return ovsMapValue(ids, ownerKey) == ownerValue && ovsMapValue(ids, ownerInstanceKey) == ovsdber.instance
+}
+
+// checkBridgeOwner fails if the named bridge exists and was not created by
+// the plugin. Bridges of older releases are recognized by their BridgeOpt row.
+func (ovsdber *ovsdber) checkBridgeOwner(bridgeName string) error {
+	if ovsdber.forceOwnership {
+		return nil
+	}
+	_, row, ok := ovsdbCache.bridge(bridgeName)
+	if !ok || ovsdber.rowOwned(row) {
+		return nil
+	}
+	if _, ok := pluginBridges()[bridgeName]; ok {
+		return nil
+	}
+	return fmt.Errorf("bridge %s is not owned by %s, refusing to modify it", bridgeName, ownerValue)
+}
+
+// checkPortOwner fails if the named port exists and was not created by the
+// plugin. Endpoint ports of older releases are recognized by their name.
+func (ovsdber *ovsdber) checkPortOwner(portName string) error {
+	if ovsdber.forceOwnership {
+		return nil
+	}
+	_, row, ok := ovsdbCache.port(portName)
+	if !ok || ovsdber.rowOwned(row) || strings.HasPrefix(portName, ovsPortPrefix) {
+		return nil
+	}
+	return fmt.Errorf("port %s is not owned by %s, refusing to modify it", portName, ownerValue)
+}
+
+// linkOwnerAlias is the alias marking a kernel link as created by the plugin,
+// links have no other place for free form metadata.
+func (ovsdber *ovsdber) linkOwnerAlias() string {
+	return ownerValue + ":" + ovsdber.instance
+}
+
+// markLink stamps the owner marker on a link the plugin created. A link
+// that can't be marked is one the plugin could never delete again, so
+// callers delete it when this fails.
+func (ovsdber *ovsdber) markLink(name string) error {
+	out, err := exec.Command("ip", "link", "set", "dev", name, "alias", ovsdber.linkOwnerAlias()).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to mark link %s: %v %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// checkLinkOwner fails if the named link does not carry the owner marker
+// of this instance. Veths of older releases are recognized by their name.
+func (ovsdber *ovsdber) checkLinkOwner(name string) error {
+	if ovsdber.forceOwnership || strings.HasPrefix(name, ovsPortPrefix) {
+		return nil
+	}
+	alias, err := ioutil.ReadFile(filepath.Join("/sys/class/net", name, "ifalias"))
+	if err != nil {
+		log.Debugf("could not read alias of link %s: %v", name, err)
+	}
+	if strings.TrimSpace(string(alias)) == ovsdber.linkOwnerAlias() {
+		return nil
+	}
+	return fmt.Errorf("link %s is not owned by %s, refusing to modify it", name, ownerValue)
+}
@@ -0,0 +1,31 @@
+package ovs
+
+import (
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+func TestRowOwned(t *testing.T) {
+	ovsdber := &ovsdber{instance: "ovs"}
+	tests := []struct {
+		ids  map[string]string
+		want bool
+	}{
+		{ids: map[string]string{ownerKey: ownerValue, ownerInstanceKey: "ovs"}, want: true},
+		{ids: map[string]string{ownerKey: ownerValue, ownerInstanceKey: "linker/ovs:latest"}, want: false},
+		{ids: map[string]string{ownerKey: ownerValue}, want: false},
+		{ids: map[string]string{ownerKey: "neutron", ownerInstanceKey: "ovs"}, want: false},
+		{ids: nil, want: false},
+	}
+	for _, tt := range tests {
+		row := libovsdb.Row{Fields: map[string]interface{}{}}
+		if tt.ids != nil {
+			ids, _ := libovsdb.NewOvsMap(tt.ids)
+			row.Fields["external_ids"] = *ids
+		}
+		if got := ovsdber.rowOwned(row); got != tt.want {
+			t.Errorf("rowOwned(%v) = %v, want %v", tt.ids, got, tt.want)
+		}
+	}
+}
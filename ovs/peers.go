@@ -0,0 +1,390 @@
+package ovs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// peerKey marks the tunnel ports to a peer node with the node's
+	// address
+	peerKey = "linker-peer"
+	// vniKey records the VNI of an overlay network on its bridge
+	vniKey = "linker-vni"
+	// vniOption sets the VNI of an overlay network, for networks whose
+	// derived VNIs collide
+	vniOption = "linker.net.ovs.overlay.vni"
+	// vniMask keeps a network's VXLAN id within 24 bits
+	vniMask = 1<<24 - 1
+	// below every policy flow, known remote MACs skip the flood to all
+	// tunnels NORMAL would do
+	remoteForwardPriority = 50
+)
+
+// DiscoverNew learns a node joining the cluster from docker's node
+// discovery and builds a tunnel to it on the bridge of every overlay
+// network. The notification about the host itself gives the address
+// tunnels start from.
+func (d *Driver) DiscoverNew(n *dknet.DiscoveryNotification) error {
+	node, ok, err := nodeDiscovery(n)
+	if err != nil || !ok {
+		return err
+	}
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	if node.Self {
+		if ip := net.ParseIP(node.BindAddress); ip != nil && !ip.IsUnspecified() {
+			d.localAddress = node.BindAddress
+		}
+		log.Infof("This node is [ %s ] in the cluster", node.Address)
+		return nil
+	}
+	return d.addPeer(node.Address)
+}
+
+// DiscoverDelete removes the tunnels to a node that left the cluster.
+func (d *Driver) DiscoverDelete(n *dknet.DiscoveryNotification) error {
+	node, ok, err := nodeDiscovery(n)
+	if err != nil || !ok || node.Self {
+		return err
+	}
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	return d.removePeer(node.Address)
+}
+
+// addPeer tunnels the bridge of every overlay network to a node that
+// joined, with reconcileMu held.
+func (d *Driver) addPeer(peer string) error {
+	if d.peers[peer] {
+		return nil
+	}
+	d.peers[peer] = true
+	log.Infof("Node [ %s ] joined the cluster", peer)
+	var failed []string
+	for id, ns := range d.networkStates() {
+		if !d.overlayNetwork(id, ns) {
+			continue
+		}
+		if err := d.addTunnel(context.Background(), id, ns, peer); err != nil {
+			log.Errorf("failed to tunnel network %s to node %s: %v", truncateID(id), peer, err)
+			failed = append(failed, truncateID(id))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("networks %v could not be tunnelled to node %s", failed, peer)
+	}
+	return nil
+}
+
+// removePeer removes the tunnels to a node that left, with reconcileMu
+// held.
+func (d *Driver) removePeer(peer string) error {
+	delete(d.peers, peer)
+	log.Infof("Node [ %s ] left the cluster", peer)
+	var failed []string
+	for _, row := range getTableCache("Port") {
+		if ovsMapValue(row.Fields["external_ids"], peerKey) != peer || !d.ovsdber.rowOwned(row) {
+			continue
+		}
+		portName, _ := row.Fields["name"].(string)
+		bridgeName := bridgeNameForPort(portName)
+		if bridgeName == "" {
+			continue
+		}
+		if err := d.ovsdber.deletePort(context.Background(), bridgeName, portName); err != nil {
+			log.Errorf("failed to remove tunnel %s to node %s: %v", portName, peer, err)
+			failed = append(failed, portName)
+			continue
+		}
+		delete(tunnelRepaired, portName)
+		log.Infof("Removed tunnel [ %s ] to node [ %s ] from bridge [ %s ]", portName, peer, bridgeName)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("tunnels %v to node %s could not be removed", failed, peer)
+	}
+	return nil
+}
+
+// nodeDiscovery returns the node of a node discovery notification, false
+// for other kinds of notification.
+func nodeDiscovery(n *dknet.DiscoveryNotification) (dknet.NodeDiscoveryData, bool, error) {
+	var node dknet.NodeDiscoveryData
+	if n.DiscoveryType != dknet.NodeDiscovery {
+		return node, false, nil
+	}
+	// the data arrives decoded into a generic map
+	raw, err := json.Marshal(n.DiscoveryData)
+	if err != nil {
+		return node, false, err
+	}
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return node, false, fmt.Errorf("invalid node discovery data: %v", err)
+	}
+	if net.ParseIP(node.Address) == nil {
+		return node, false, fmt.Errorf("invalid node address %s", node.Address)
+	}
+	return node, true, nil
+}
+
+// overlayNetwork reports whether a network's bridge is tunnelled to the
+// other nodes: flat networks without an uplink, whose containers only
+// reach each other, of the global scope. NAT networks have a gateway on
+// every node and uplinked networks already share the physical network.
+// Local networks are never overlaid, as every host allocates their
+// addresses from the same subnet on its own.
+func (d *Driver) overlayNetwork(networkID string, ns *NetworkState) bool {
+	if ns.Mode != modeFlat || ns.FlatBindInterface != "" || ns.Node != "" {
+		return false
+	}
+	return d.scope == scopeGlobal
+}
+
+// connectPeers tunnels a new overlay network to every known node.
+func (d *Driver) connectPeers(ctx context.Context, networkID string, ns *NetworkState) {
+	if !d.overlayNetwork(networkID, ns) {
+		return
+	}
+	for peer := range d.peers {
+		if err := d.addTunnel(ctx, networkID, ns, peer); err != nil {
+			log.Errorf("failed to tunnel network %s to node %s: %v", truncateID(networkID), peer, err)
+		}
+	}
+}
+
+// addTunnel adds the tunnel of a network to a peer unless it is there.
+func (d *Driver) addTunnel(ctx context.Context, networkID string, ns *NetworkState, peer string) error {
+	portName := tunnelPortName(networkID, peer)
+	if portUUIDForName(portName) != "" {
+		return nil
+	}
+	if !d.ovsdber.hasColumn("Port", "protected") {
+		return fmt.Errorf("the switch has no protected ports, which the tunnels of overlay networks need to avoid loops")
+	}
+	if err := d.ovsdber.addVxlanPort(ctx, ns.BridgeName, portName, peer, d.localAddress, ns.VNI); err != nil {
+		return err
+	}
+	log.Infof("Tunnelled bridge [ %s ] to node [ %s ] through [ %s ]", ns.BridgeName, peer, portName)
+	return nil
+}
+
+// tunnelPortName names the tunnel of a network to a peer, from the network
+// id and a hash of the peer address within IFNAMSIZ.
+func tunnelPortName(networkID, peer string) string {
+	h := fnv.New32a()
+	h.Write([]byte(peer))
+	return fmt.Sprintf("vx%s%08x", truncateID(networkID), h.Sum32())
+}
+
+// networkVNI derives the VXLAN id of a network from its id, the same on
+// every node. Networks of a cluster store get theirs from the store.
+func networkVNI(networkID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(networkID))
+	return int(h.Sum32() & vniMask)
+}
+
+// getVNI returns the VNI a network sets with linker.net.ovs.overlay.vni, 0
+// if it doesn't.
+func getVNI(r *dknet.CreateNetworkRequest) (int, error) {
+	vni, err := getPositiveIntOption(r, vniOption)
+	if err != nil {
+		return 0, err
+	}
+	if vni > vniMask {
+		return 0, fmt.Errorf("%s %d is out of range 1-%d", vniOption, vni, vniMask)
+	}
+	return vni, nil
+}
+
+// overlayVNI returns the VNI of a new overlay network: vni if the network
+// set one or got it from the cluster store, else the one derived from its
+// id. Without a store nodes can't agree on another VNI, so a VNI another
+// network of the host has, in the driver's state or recorded on its
+// bridge, is an error rather than a bridge that leaks into the other
+// network.
+func (d *Driver) overlayVNI(networkID string, vni int) (int, error) {
+	if vni == 0 {
+		vni = networkVNI(networkID)
+	}
+	used := make(map[int]string)
+	for _, row := range getTableCache("Bridge") {
+		id := ovsMapValue(row.Fields["external_ids"], networkIDKey)
+		if n, err := strconv.Atoi(ovsMapValue(row.Fields["external_ids"], vniKey)); err == nil && id != "" {
+			used[n] = id
+		}
+	}
+	for id, ns := range d.networkStates() {
+		if ns.VNI != 0 {
+			used[ns.VNI] = id
+		}
+	}
+	if other, ok := used[vni]; ok && other != networkID {
+		return 0, fmt.Errorf("VNI %d of network %s is that of network %s, set another with %s", vni, truncateID(networkID), truncateID(other), vniOption)
+	}
+	return vni, nil
+}
+
+// peerExternalIDs marks a tunnel port as the plugin's and records its peer.
+func (ovsdber *ovsdber) peerExternalIDs(peer string) *libovsdb.OvsMap {
+	ids, _ := libovsdb.NewOvsMap(map[string]string{
+		ownerKey:         ownerValue,
+		ownerInstanceKey: ovsdber.instance,
+		peerKey:          peer,
+	})
+	return ids
+}
+
+// hasColumn reports whether the switch's schema has a column.
+func (ovsdber *ovsdber) hasColumn(table, column string) bool {
+	client := ovsdber.client()
+	if client == nil {
+		return false
+	}
+	tableSchema, ok := client.Schema["Open_vSwitch"].Tables[table]
+	if !ok {
+		return false
+	}
+	_, ok = tableSchema.Columns[column]
+	return ok
+}
+
+// OverlayEndpoint is an endpoint of an overlay network as the other hosts
+// learn it, through gossip or the cluster store. VTEP is the tunnel address
+// of the host it lives on.
+type OverlayEndpoint struct {
+	Network    string
+	EndpointID string
+	IP         string
+	MAC        string
+	Node       string
+	VTEP       string
+	Deleted    bool `json:",omitempty"`
+}
+
+// nodeName names the host, and the plugin instance on it, to the others.
+func (d *Driver) nodeName() string {
+	hostname, _ := os.Hostname()
+	return hostname + "/" + d.name
+}
+
+// announceEndpoint spreads a new endpoint of an overlay network to the
+// other hosts.
+func (d *Driver) announceEndpoint(endpointID string, es *EndpointState) {
+	if (d.gossip == nil && d.cluster == nil) || es.MacAddress == "" {
+		return
+	}
+	ns, ok := d.network(es.NetworkID)
+	if !ok || !d.overlayNetwork(es.NetworkID, ns) {
+		return
+	}
+	ep := OverlayEndpoint{
+		Network:    es.NetworkID,
+		EndpointID: endpointID,
+		IP:         es.Address,
+		MAC:        es.MacAddress,
+		Node:       d.nodeName(),
+		VTEP:       d.localAddress,
+	}
+	if d.gossip != nil {
+		gossiped := ep
+		gossiped.VTEP = d.gossip.list.LocalNode().Addr.String()
+		d.gossip.announce(gossiped)
+	}
+	if d.cluster != nil {
+		d.publishEndpoint(ep)
+	}
+}
+
+// withdrawEndpoint tells the other hosts that an endpoint is gone.
+func (d *Driver) withdrawEndpoint(endpointID string) {
+	if d.gossip != nil {
+		d.gossip.withdraw(endpointID)
+	}
+	if d.cluster != nil {
+		d.unpublishEndpoint(endpointID)
+	}
+}
+
+// programOverlay programs the remote endpoints already known on a new
+// overlay network, with reconcileMu held.
+func (d *Driver) programOverlay(networkID string, ns *NetworkState) {
+	if !d.overlayNetwork(networkID, ns) {
+		return
+	}
+	var eps []OverlayEndpoint
+	if d.gossip != nil {
+		eps = d.gossip.remoteEndpoints()
+	}
+	for _, ep := range d.clusterEndpoints {
+		eps = append(eps, ep)
+	}
+	for _, ep := range eps {
+		if ep.Network == networkID {
+			d.programRemoteOn(networkID, ns, ep)
+		}
+	}
+}
+
+// programRemote answers ARP for a remote endpoint and forwards its MAC to
+// the tunnel of its host on the bridges of its overlay network.
+func (d *Driver) programRemote(ep OverlayEndpoint) {
+	for id, ns := range d.networkStates() {
+		if id == ep.Network && d.overlayNetwork(id, ns) {
+			d.programRemoteOn(id, ns, ep)
+		}
+	}
+}
+
+func (d *Driver) programRemoteOn(networkID string, ns *NetworkState, ep OverlayEndpoint) {
+	if err := d.addTunnel(context.Background(), networkID, ns, ep.VTEP); err != nil {
+		log.Errorf("failed to tunnel network %s to node %s: %v", truncateID(networkID), ep.VTEP, err)
+		return
+	}
+	if _, err := d.updateARPEntries(networkID, []ARPEntry{{IP: ep.IP, MAC: ep.MAC}}, nil); err != nil {
+		log.Errorf("failed to answer ARP for %s on network %s: %v", ep.IP, truncateID(networkID), err)
+	}
+	ofport := ofportForName(tunnelPortName(networkID, ep.VTEP))
+	if ofport < 0 {
+		// flooded by NORMAL until the next announcement
+		log.Debugf("tunnel to %s has no OpenFlow port yet", ep.VTEP)
+		return
+	}
+	flow := fmt.Sprintf("cookie=%s,priority=%d,dl_dst=%s,actions=output:%d", remoteCookie(networkID, ep.MAC), remoteForwardPriority, ep.MAC, ofport)
+	if err := addFlow(ns.BridgeName, policyTable, flow); err != nil {
+		log.Errorf("failed to forward %s to node %s: %v", ep.MAC, ep.VTEP, err)
+		return
+	}
+	log.Debugf("Forwarding [ %s %s ] to node [ %s ] on bridge [ %s ]", ep.IP, ep.MAC, ep.VTEP, ns.BridgeName)
+}
+
+// unprogramRemote removes the flows of a remote endpoint.
+func (d *Driver) unprogramRemote(ep OverlayEndpoint) {
+	for id, ns := range d.networkStates() {
+		if id != ep.Network || !d.overlayNetwork(id, ns) {
+			continue
+		}
+		if _, err := d.updateARPEntries(id, nil, []ARPEntry{{IP: ep.IP, MAC: ep.MAC}}); err != nil {
+			log.Warnf("failed to stop answering ARP for %s on network %s: %v", ep.IP, truncateID(id), err)
+		}
+		if err := delFlows(ns.BridgeName, remoteCookie(id, ep.MAC)); err != nil {
+			log.Warnf("failed to stop forwarding %s: %v", ep.MAC, err)
+		}
+	}
+}
+
+// remoteCookie tags the forwarding flow of a remote MAC.
+func remoteCookie(networkID, mac string) string {
+	h := fnv.New64a()
+	h.Write([]byte("fwd" + networkID + mac))
+	return fmt.Sprintf("0x%016x", h.Sum64())
+}
@@ -0,0 +1,298 @@
+package ovs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// UE and tenant address pools served by the gateway of a pgw network,
+	// as a comma separated list of CIDRs. The host routes them via the
+	// address of the gateway container on the network's bridge.
+	pgwPoolsOption   = "linker.net.ovs.pgw.pools"
+	pgwGatewayOption = "linker.net.ovs.pgw.gateway"
+
+	// the gateway and the routed pools of a pgw network, recorded on its
+	// bridge so the routes can be found again after a restart
+	pgwGatewayKey = "linker-pgw-gateway"
+	pgwPoolsKey   = "linker-pgw-pools"
+)
+
+// poolMu serializes pool changes of the admin API and the driver callbacks
+var poolMu sync.Mutex
+
+// PoolRoutes lists the pools routed to the gateway of a pgw network.
+type PoolRoutes struct {
+	NetworkID string
+	Bridge    string `json:",omitempty"`
+	Gateway   string `json:",omitempty"`
+	Pools     []string
+}
+
+// getPgwRoutes returns the gateway address and the pools of a network,
+// which must be a pgw network in nat mode to have any: in flat mode the
+// host has no address on the bridge to route via.
+func getPgwRoutes(r *dknet.CreateNetworkRequest, networkType, mode string) (string, []string, error) {
+	gateway := getStringOption(r, pgwGatewayOption)
+	pools, err := parsePools(getStringOption(r, pgwPoolsOption))
+	if err != nil {
+		return "", nil, err
+	}
+	if gateway == "" && len(pools) == 0 {
+		return "", nil, nil
+	}
+	if !strings.EqualFold(networkType, type_pgw) {
+		return "", nil, fmt.Errorf("%s and %s require %s=%s", pgwGatewayOption, pgwPoolsOption, typeOption, type_pgw)
+	}
+	if mode != modeNAT {
+		return "", nil, fmt.Errorf("%s and %s are only supported in %s mode", pgwGatewayOption, pgwPoolsOption, modeNAT)
+	}
+	if ip := net.ParseIP(gateway); ip == nil || ip.To4() == nil {
+		return "", nil, fmt.Errorf("%s must be the IPv4 address of the gateway container, got %q", pgwGatewayOption, gateway)
+	}
+	return gateway, pools, nil
+}
+
+// parsePools parses a comma separated list of CIDRs into their canonical
+// form.
+func parsePools(value string) ([]string, error) {
+	var pools []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		_, pool, err := net.ParseCIDR(entry)
+		if err != nil || pool.IP.To4() == nil {
+			return nil, fmt.Errorf("%s is not a valid IPv4 pool", entry)
+		}
+		pools = append(pools, pool.String())
+	}
+	return pools, nil
+}
+
+// poolRoute is the host route of a pool towards the gateway.
+func poolRoute(bridgeName, gateway, pool string) (*netlink.Route, error) {
+	link, err := netlink.LinkByName(bridgeName)
+	if err != nil {
+		return nil, err
+	}
+	_, dst, err := net.ParseCIDR(pool)
+	if err != nil {
+		return nil, err
+	}
+	return &netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       dst,
+		Gw:        net.ParseIP(gateway),
+	}, nil
+}
+
+// addPoolRoute routes a pool via the gateway. A route of the pool on the
+// network's bridge is the plugin's and is replaced, a route of the pool
+// out of any other interface is left alone and is an error.
+func addPoolRoute(bridgeName, gateway, pool string) error {
+	route, err := poolRoute(bridgeName, gateway, pool)
+	if err != nil {
+		return err
+	}
+	routes, err := netlink.RouteList(nil, netlink.FAMILY_V4)
+	if err != nil {
+		return err
+	}
+	for _, existing := range routes {
+		if existing.Dst == nil || existing.Dst.String() != pool {
+			continue
+		}
+		if existing.LinkIndex != route.LinkIndex {
+			return fmt.Errorf("pool %s is already routed by another interface, remove that route first", pool)
+		}
+		if existing.Gw.Equal(route.Gw) {
+			return nil
+		}
+		if err := netlink.RouteDel(&existing); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("failed to remove the old route of %s: %v", pool, err)
+		}
+	}
+	if err := netlink.RouteAdd(route); err != nil && err != syscall.EEXIST {
+		return fmt.Errorf("failed to route %s via %s: %v", pool, gateway, err)
+	}
+	log.Infof("Routed pool [ %s ] via [ %s ] on bridge [ %s ]", pool, gateway, bridgeName)
+	return nil
+}
+
+func delPoolRoute(bridgeName, gateway, pool string) error {
+	route, err := poolRoute(bridgeName, gateway, pool)
+	if err != nil {
+		return err
+	}
+	if err := netlink.RouteDel(route); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("failed to remove route of %s: %v", pool, err)
+	}
+	log.Infof("Removed route of pool [ %s ] from bridge [ %s ]", pool, bridgeName)
+	return nil
+}
+
+// setupPoolRoutes routes the pools of a new network and records them on
+// its bridge.
+func (d *Driver) setupPoolRoutes(ns *NetworkState) error {
+	if ns.PgwGateway == "" {
+		return nil
+	}
+	for _, pool := range ns.Pools {
+		if err := addPoolRoute(ns.BridgeName, ns.PgwGateway, pool); err != nil {
+			return err
+		}
+	}
+	return d.ovsdber.recordPools(ns.BridgeName, ns.PgwGateway, ns.Pools)
+}
+
+// recordPools records the gateway and the pools of a pgw network on its
+// bridge.
+func (ovsdber *ovsdber) recordPools(bridgeName, gateway string, pools []string) error {
+	return ovsdber.setRowMap("Bridge", bridgeName, "external_ids", map[string]string{
+		pgwGatewayKey: gateway,
+		pgwPoolsKey:   strings.Join(pools, ","),
+	})
+}
+
+// bridgePools returns the gateway and the pools recorded on a bridge, for
+// a network the driver has no state of.
+func bridgePools(bridgeName string) (string, []string) {
+	pools, err := parsePools(bridgeExternalID(bridgeName, pgwPoolsKey))
+	if err != nil {
+		log.Warnf("bridge %s records invalid pools: %v", bridgeName, err)
+	}
+	return bridgeExternalID(bridgeName, pgwGatewayKey), pools
+}
+
+// teardownPoolRoutes removes the routes of all pools of a network.
+func teardownPoolRoutes(ns *NetworkState) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	for _, pool := range ns.Pools {
+		if err := delPoolRoute(ns.BridgeName, ns.PgwGateway, pool); err != nil {
+			log.Warnf("%v", err)
+		}
+	}
+}
+
+// updatePools adds and removes pools of a pgw network and its routes.
+func (d *Driver) updatePools(networkID string, add, remove []string) (*PoolRoutes, error) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	ns, ok := d.network(networkID)
+	if !ok {
+		return nil, fmt.Errorf("no network with id %s", networkID)
+	}
+	if ns.PgwGateway == "" {
+		return nil, fmt.Errorf("network %s has no %s", truncateID(networkID), pgwGatewayOption)
+	}
+	current := make(map[string]bool)
+	for _, pool := range ns.Pools {
+		current[pool] = true
+	}
+	// the pools reflect the routes even if a change fails half way
+	defer func() {
+		pools := sortedPools(current)
+		d.updateNetwork(ns, func(ns *NetworkState) {
+			ns.Pools = pools
+		})
+		if err := d.ovsdber.recordPools(ns.BridgeName, ns.PgwGateway, pools); err != nil {
+			log.Warnf("failed to record the pools of network %s: %v", truncateID(networkID), err)
+		}
+	}()
+	for _, pool := range remove {
+		if !current[pool] {
+			continue
+		}
+		if err := delPoolRoute(ns.BridgeName, ns.PgwGateway, pool); err != nil {
+			return nil, err
+		}
+		delete(current, pool)
+	}
+	for _, pool := range add {
+		if current[pool] {
+			continue
+		}
+		if err := addPoolRoute(ns.BridgeName, ns.PgwGateway, pool); err != nil {
+			return nil, err
+		}
+		current[pool] = true
+	}
+	return &PoolRoutes{NetworkID: networkID, Bridge: ns.BridgeName, Gateway: ns.PgwGateway, Pools: sortedPools(current)}, nil
+}
+
+func sortedPools(set map[string]bool) []string {
+	pools := make([]string, 0, len(set))
+	for pool := range set {
+		pools = append(pools, pool)
+	}
+	sort.Strings(pools)
+	return pools
+}
+
+// poolRoutes returns the pools of all pgw networks, or only of the given
+// network.
+func (d *Driver) poolRoutes(networkID string) []PoolRoutes {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	var routes []PoolRoutes
+	for id, ns := range d.networkStates() {
+		if ns.PgwGateway == "" || (networkID != "" && id != networkID) {
+			continue
+		}
+		routes = append(routes, PoolRoutes{NetworkID: id, Bridge: ns.BridgeName, Gateway: ns.PgwGateway, Pools: ns.Pools})
+	}
+	return routes
+}
+
+// handlePools serves /pools: GET lists the pools, optionally filtered by
+// ?network=, POST adds the pools of a PoolRoutes and DELETE removes them.
+func (d *Driver) handlePools(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		writeJSON(w, http.StatusOK, d.poolRoutes(r.URL.Query().Get("network")))
+		return
+	}
+	if r.Method != "POST" && r.Method != "DELETE" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	var req PoolRoutes
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.NetworkID == "" {
+		writeError(w, http.StatusBadRequest, errors.New("NetworkID is required"))
+		return
+	}
+	pools, err := parsePools(strings.Join(req.Pools, ","))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var res *PoolRoutes
+	if r.Method == "POST" {
+		res, err = d.updatePools(req.NetworkID, pools, nil)
+	} else {
+		res, err = d.updatePools(req.NetworkID, nil, pools)
+	}
+	d.replicate()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,123 @@
+package ovs
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	dockertypes "github.com/docker/docker/api/types"
+)
+
+const (
+	// egressViaLabel names the gateway container, on the same network, that
+	// all off-subnet traffic of the labelled container is steered through
+	egressViaLabel = "linker.net.ovs.egress_via"
+
+	policyFlowPriority = 200
+	labelLookupRetries = 30
+)
+
+// applyEndpointPolicies resolves the labels of the container behind an
+// endpoint and installs the OpenFlow policies they ask for. It runs in the
+// background since docker cannot inspect the container while Join is pending,
+// which is why security groups are endpoint options instead.
+func (d *Driver) applyEndpointPolicies(networkID, endpointID, bridgeName string) {
+	if ns, ok := d.network(networkID); ok && ns.Runtime != "" {
+		// docker doesn't know the container
+		return
+	}
+	var info dockertypes.ContainerJSON
+	var err error
+	for i := 0; i < labelLookupRetries; i++ {
+		if info, err = d.dockerer.endpointContainer(networkID, endpointID); err == nil {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		log.Warnf("could not resolve container of endpoint %s, skipping policies: %v", truncateID(endpointID), err)
+		return
+	}
+	if err := d.ovsdber.recordContainer(ovsPortPrefix+truncateID(endpointID), info.ID, endpointID); err != nil {
+		log.Warnf("failed to record container of endpoint %s on its port: %v", truncateID(endpointID), err)
+	}
+	if info.Config == nil {
+		return
+	}
+	labels := info.Config.Labels
+
+	for _, key := range []string{allowOption, allowEgressOption} {
+		if _, ok := labels[key]; ok {
+			log.Warnf("ignoring label %s of container %s, pass it as a driver option of its endpoint", key, truncateID(info.ID))
+		}
+	}
+	if gateway, ok := labels[egressViaLabel]; ok {
+		if err := d.steerEgress(networkID, endpointID, bridgeName, gateway); err != nil {
+			log.Errorf("failed to steer endpoint %s via %s: %v", truncateID(endpointID), gateway, err)
+		}
+	}
+}
+
+// removeEndpointPolicies deletes every flow installed for the endpoint.
+func removeEndpointPolicies(endpointID, bridgeName string) {
+	if err := delFlows(bridgeName, flowCookie(endpointID)); err != nil {
+		log.Warnf("failed to remove policy flows of endpoint %s: %v", truncateID(endpointID), err)
+	}
+}
+
+// steerEgress rewrites the destination MAC of the endpoint's off-subnet
+// traffic to the gateway container and outputs it on the gateway's port.
+// Traffic within the subnet keeps being switched normally.
+func (d *Driver) steerEgress(networkID, endpointID, bridgeName, gateway string) error {
+	gw, err := d.dockerer.containerEndpoint(networkID, gateway)
+	if err != nil {
+		return err
+	}
+	gwPort := ofportForName(ovsPortPrefix + truncateID(gw.EndpointID))
+	srcPort := ofportForName(ovsPortPrefix + truncateID(endpointID))
+	if gwPort < 0 || srcPort < 0 {
+		return fmt.Errorf("no ofport assigned to endpoint or gateway ports yet")
+	}
+
+	cookie := flowCookie(endpointID)
+	zone, err := d.ctZone(networkID)
+	if err != nil {
+		return err
+	}
+	if subnet := d.networkSubnet(networkID, bridgeName); subnet != "" {
+		local := fmt.Sprintf("cookie=%s,priority=%d,ip,in_port=%d,nw_dst=%s,actions=%s,NORMAL",
+			cookie, policyFlowPriority+10, srcPort, subnet, ctCommit(zone))
+		if err := addFlow(bridgeName, policyTable, local); err != nil {
+			return err
+		}
+	}
+	match := fmt.Sprintf("ip,in_port=%d", srcPort)
+	if es, ok := d.endpoint(endpointID); ok {
+		match += ",nw_src=" + es.Address
+	}
+	// connections are committed like in applySecGroup so that replies are
+	// seen as established by the security group of the endpoint
+	steer := fmt.Sprintf("cookie=%s,priority=%d,%s,actions=%s,mod_dl_dst:%s,output:%d",
+		cookie, policyFlowPriority, match, ctCommit(zone), gw.MacAddress, gwPort)
+	if err := addFlow(bridgeName, policyTable, steer); err != nil {
+		return err
+	}
+	log.Infof("Steering egress of endpoint [ %s ] via gateway [ %s ]", truncateID(endpointID), gateway)
+	return nil
+}
+
+// networkSubnet returns the subnet of a network in CIDR notation, or an
+// empty string if it is unknown.
+func (d *Driver) networkSubnet(networkID, bridgeName string) string {
+	if ns, ok := d.network(networkID); ok && ns.Gateway != "" {
+		if _, subnet, err := net.ParseCIDR(ns.Gateway + "/" + ns.GatewayMask); err == nil {
+			return subnet.String()
+		}
+	}
+	if addr, err := getIfaceAddr(bridgeName); err == nil {
+		subnet := &net.IPNet{IP: addr.IP.Mask(addr.Mask), Mask: addr.Mask}
+		return subnet.String()
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package ovs
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// portSecurityAllowPriority marks, in the port security table, traffic
+	// from the endpoint's addresses as admitted in reg3, then sends it
+	// through the table again, so that the anti-spoofing check comes ahead
+	// of the egress rules. Unmarked traffic of the port is dropped.
+	portSecurityAllowPriority = 1100
+	portSecurityDropPriority  = 1090
+)
+
+// applyPortSecurity only lets traffic sourced from the endpoint's assigned
+// MAC and IP addresses leave its port, which also drops gratuitous ARP and
+// neighbor advertisements for other addresses. Accepted traffic goes
+// through the port security table again, where egress rules may apply,
+// before it reaches the policy table.
+func (d *Driver) applyPortSecurity(endpointID, bridgeName string, veth *netlink.Veth) error {
+	es, ok := d.endpoint(endpointID)
+	if !ok || es.Address == "" {
+		return fmt.Errorf("no address known for endpoint %s", truncateID(endpointID))
+	}
+	mac := es.MacAddress
+	if mac == "" {
+		// docker keeps the MAC of the veth peer unless one was requested
+		peer, err := netlink.LinkByName(veth.PeerName)
+		if err != nil {
+			return err
+		}
+		mac = peer.Attrs().HardwareAddr.String()
+		d.updateEndpoint(es, func(es *EndpointState) { es.MacAddress = mac })
+	}
+
+	flows := portSecurityFlows(endpointID, veth.Name, mac, es)
+	for _, flow := range flows {
+		if err := addFlow(bridgeName, portSecurityTable, flow); err != nil {
+			return err
+		}
+	}
+	log.Infof("Port [ %s ] only accepts traffic from [ %s %s ]", veth.Name, mac, strings.Join(endpointAddresses(es), " "))
+	return nil
+}
+
+// portSecurityFlows returns the port security table flows of an endpoint
+// with the MAC address. An endpoint with an IPv6 address may also send
+// neighbor solicitations from its MAC, duplicate address detection for its
+// address included, and advertise its address.
+func portSecurityFlows(endpointID, port, mac string, es *EndpointState) []string {
+	cookie := flowCookie(endpointID)
+	admit := fmt.Sprintf("load:1->NXM_NX_REG3[],resubmit(,%d)", portSecurityTable)
+	ipActions := admit
+	if es.DSCP > 0 {
+		ipActions = dscpAction(es.DSCP) + "," + ipActions
+	}
+	flows := []string{
+		fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,dl_src=%s,ip,nw_src=%s,actions=%s",
+			cookie, portSecurityAllowPriority, port, mac, es.Address, ipActions),
+		fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,dl_src=%s,arp,arp_spa=%s,arp_sha=%s,actions=%s",
+			cookie, portSecurityAllowPriority, port, mac, es.Address, mac, admit),
+	}
+	if es.AddressIPv6 != "" {
+		flows = append(flows,
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,dl_src=%s,ipv6,ipv6_src=%s,actions=%s",
+				cookie, portSecurityAllowPriority, port, mac, es.AddressIPv6, ipActions),
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,dl_src=%s,icmp6,icmp_type=135,icmp_code=0,nd_sll=%s,actions=%s",
+				cookie, portSecurityAllowPriority, port, mac, mac, admit),
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,dl_src=%s,icmp6,ipv6_src=::,icmp_type=135,icmp_code=0,nd_target=%s,actions=%s",
+				cookie, portSecurityAllowPriority, port, mac, es.AddressIPv6, admit),
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,dl_src=%s,icmp6,icmp_type=136,icmp_code=0,nd_target=%s,actions=%s",
+				cookie, portSecurityAllowPriority, port, mac, es.AddressIPv6, admit),
+		)
+	}
+	return append(flows, fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg3=0,actions=drop",
+		cookie, portSecurityDropPriority, port))
+}
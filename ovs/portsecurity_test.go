@@ -0,0 +1,33 @@
+package ovs
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPortSecurityFlowsIPv6 checks that an endpoint with an IPv6 address
+// keeps its IPv6 traffic and neighbor discovery, and that one without
+// admits no IPv6 at all.
+func TestPortSecurityFlowsIPv6(t *testing.T) {
+	const mac = "02:42:0a:01:00:02"
+	es := &EndpointState{Address: "10.1.0.2", AddressIPv6: "fd00::2"}
+	flows := strings.Join(portSecurityFlows("ep-a00000000", "ovs-veth-a", mac, es), "\n")
+	for _, want := range []string{
+		"ipv6,ipv6_src=fd00::2,actions=load:1->NXM_NX_REG3[]",
+		"icmp_type=135,icmp_code=0,nd_sll=" + mac + ",actions=load:1->NXM_NX_REG3[]",
+		"ipv6_src=::,icmp_type=135,icmp_code=0,nd_target=fd00::2,actions=load:1->NXM_NX_REG3[]",
+		"icmp_type=136,icmp_code=0,nd_target=fd00::2,actions=load:1->NXM_NX_REG3[]",
+		"in_port=ovs-veth-a,reg3=0,actions=drop",
+	} {
+		if !strings.Contains(flows, want) {
+			t.Errorf("port security flows miss %q:\n%s", want, flows)
+		}
+	}
+
+	es.AddressIPv6 = ""
+	for _, flow := range portSecurityFlows("ep-a00000000", "ovs-veth-a", mac, es) {
+		if strings.Contains(flow, "ipv6") || strings.Contains(flow, "icmp6") {
+			t.Errorf("endpoint without IPv6 address admits %q", flow)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package ovs
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Status of a preflight check. Warnings are about features only some
+// networks need, such as the gateway service of sgw and pgw networks.
+const (
+	CheckOK   = "ok"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// defaultPreflightTimeout bounds the connection to ovsdb-server when
+// --ovsdb-timeout is not set
+const defaultPreflightTimeout = 5 * time.Second
+
+// PreflightCheck is the outcome of one check of the host.
+type PreflightCheck struct {
+	Name   string
+	Status string
+	Detail string `json:",omitempty"`
+}
+
+// PreflightReport is what `check` prints. OK is false if any check failed.
+type PreflightReport struct {
+	OK     bool
+	Checks []PreflightCheck
+}
+
+// Preflight checks that the host has what the plugin needs before it
+// registers with docker: the openvswitch kernel module, a reachable
+// ovsdb-server, the firewall targets NAT uses, the gateway script and
+// systemd for the gateway service. Nothing on the host is changed.
+func Preflight(config Config) *PreflightReport {
+	report := &PreflightReport{OK: true}
+	add := func(name, status, detail string) {
+		report.Checks = append(report.Checks, PreflightCheck{Name: name, Status: status, Detail: detail})
+		if status == CheckFail {
+			report.OK = false
+		}
+	}
+	if err := applyNaming(config); err != nil {
+		add("config", CheckFail, err.Error())
+		return report
+	}
+	applyManaged(config.Managed, config.HostRoot)
+	loadModule = !config.SkipModprobe
+
+	add(checkKernelModule())
+	add(checkOvsdb(config))
+	add(checkFirewall(config.FirewallBackend))
+	add(checkGatewayScript(config.HostRoot))
+	add(checkSystemd(config.Managed, config.HostRoot))
+	return report
+}
+
+// checkKernelModule looks for the openvswitch module, loaded or built in.
+// The plugin loads a module that is only available, unless
+// --skip-modprobe.
+func checkKernelModule() (string, string, string) {
+	const name = "kernel-module"
+	if moduleLoaded(ovsModule) {
+		return name, CheckOK, ovsModule + " is loaded"
+	}
+	if !moduleAvailable(ovsModule) {
+		return name, CheckFail, fmt.Sprintf("the %s module is neither loaded nor available", ovsModule)
+	}
+	if !loadModule {
+		return name, CheckFail, fmt.Sprintf("%s is available but not loaded, and --skip-modprobe is set", ovsModule)
+	}
+	return name, CheckWarn, fmt.Sprintf("%s is available but not loaded, the plugin loads it", ovsModule)
+}
+
+// moduleAvailable reports whether modprobe finds a kernel module, without
+// loading it.
+func moduleAvailable(module string) bool {
+	return exec.Command("modprobe", "-n", "-q", module).Run() == nil
+}
+
+// checkOvsdb connects to ovsdb-server as the plugin would and looks for
+// the Open_vSwitch database.
+func checkOvsdb(config Config) (string, string, string) {
+	const name = "ovsdb"
+	endpoint := ovsdbEndpoint(config.OvsdbEndpoint)
+	if err := checkOvsdbEndpoint(endpoint); err != nil {
+		return name, CheckFail, err.Error()
+	}
+	var tlsConfig *tls.Config
+	if strings.HasPrefix(endpoint, "ssl:") {
+		var err error
+		if tlsConfig, err = ovsdbTLSConfig(config.OvsdbCert, config.OvsdbKey, config.OvsdbCA); err != nil {
+			return name, CheckFail, err.Error()
+		}
+	}
+	timeout := config.OvsdbTimeout
+	if timeout == 0 {
+		timeout = defaultPreflightTimeout
+	}
+	type result struct {
+		found bool
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// the client fetches the schemas of the databases it connects to
+		client, err := dialOvsdb(endpoint, tlsConfig)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		_, found := client.Schema["Open_vSwitch"]
+		client.Disconnect()
+		done <- result{found: found}
+	}()
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(timeout):
+		return name, CheckFail, fmt.Sprintf("no answer from %s within %s", endpoint, timeout)
+	}
+	if res.err != nil {
+		return name, CheckFail, fmt.Sprintf("could not reach %s: %v", endpoint, res.err)
+	}
+	if res.found {
+		return name, CheckOK, "reachable at " + endpoint
+	}
+	return name, CheckFail, fmt.Sprintf("%s serves no Open_vSwitch database", endpoint)
+}
+
+// natTargets are the iptables targets of the NAT rules of nat networks,
+// port mappings and floating ips
+var natTargets = []string{"MASQUERADE", "DNAT", "SNAT"}
+
+// checkFirewall checks the tool of the firewall backend and, for iptables,
+// that the kernel has or can load the targets NAT uses.
+func checkFirewall(backend string) (string, string, string) {
+	const name = "firewall"
+	if backend == firewallNftables {
+		if _, err := exec.LookPath("nft"); err != nil {
+			return name, CheckFail, "nft is not installed"
+		}
+		if !moduleLoaded("nf_tables") && !moduleAvailable("nf_tables") {
+			return name, CheckFail, "the nf_tables module is neither loaded nor available"
+		}
+		if err := checkForwardDrops(); err != nil {
+			return name, CheckFail, err.Error()
+		}
+		return name, CheckOK, "nftables"
+	}
+	if _, err := exec.LookPath("iptables"); err != nil {
+		return name, CheckFail, "iptables is not installed"
+	}
+	loaded, _ := ioutil.ReadFile("/proc/net/ip_tables_targets")
+	registered := make(map[string]bool)
+	for _, target := range strings.Fields(string(loaded)) {
+		registered[target] = true
+	}
+	var missing []string
+	for _, target := range natTargets {
+		if !registered[target] && !moduleAvailable("xt_"+target) && !moduleAvailable("ipt_"+target) {
+			missing = append(missing, target)
+		}
+	}
+	if len(missing) > 0 {
+		return name, CheckFail, fmt.Sprintf("iptables targets %s are not available", strings.Join(missing, ", "))
+	}
+	return name, CheckOK, "iptables targets " + strings.Join(natTargets, ", ")
+}
+
+// checkGatewayScript looks for the script the gateway service of sgw and
+// pgw networks runs. A systemd unit runs it on the host, under hostRoot
+// from the plugin's view.
+func checkGatewayScript(hostRoot string) (string, string, string) {
+	const name = "gateway-script"
+	path := gatewayScript
+	if gatewayUnits && hostRoot != "" {
+		path = filepath.Join(hostRoot, gatewayScript)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return name, CheckWarn, fmt.Sprintf("%s is missing, sgw and pgw networks won't work", gatewayScript)
+	}
+	if fi.IsDir() || fi.Mode()&0111 == 0 {
+		return name, CheckWarn, fmt.Sprintf("%s is not executable, sgw and pgw networks won't work", gatewayScript)
+	}
+	return name, CheckOK, gatewayScript
+}
+
+// checkSystemd checks that the gateway service can run as a systemd unit.
+// Without systemd it runs as a child process of the plugin and stops with
+// it.
+func checkSystemd(managed bool, hostRoot string) (string, string, string) {
+	const name = "systemd"
+	if reason := gatewayUnitsUnusable(managed, hostRoot); reason != "" {
+		return name, CheckWarn, "the gateway service will run as a child process of the plugin, " + reason
+	}
+	return name, CheckOK, "the gateway service runs as " + serviceName
+}
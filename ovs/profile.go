@@ -0,0 +1,60 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+// Profile is an additional driver name served by the process. Networks
+// created with it default to the profile's options, so that e.g. an
+// "ovs-flat" driver needs no mode and bind interface options.
+type Profile struct {
+	Name    string
+	Options map[string]string
+}
+
+// ParseProfile parses name=key=value[,key=value...], where the keys are the
+// linker.net.ovs.* network options.
+func ParseProfile(value string) (Profile, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return Profile{}, fmt.Errorf("invalid profile %s, expected name=key=value[,key=value]", value)
+	}
+	p := Profile{Name: parts[0], Options: make(map[string]string)}
+	for _, option := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return Profile{}, fmt.Errorf("invalid option %s in profile %s", option, p.Name)
+		}
+		p.Options[kv[0]] = kv[1]
+	}
+	return p, nil
+}
+
+// profileDriver is the driver served under a profile's name. It shares the
+// state, OVSDB connection and caches of the main driver.
+type profileDriver struct {
+	*Driver
+	profile Profile
+}
+
+// CreateNetwork fills in the profile's options the request does not set.
+func (p *profileDriver) CreateNetwork(ctx context.Context, r *dknet.CreateNetworkRequest) error {
+	if r.Options == nil {
+		r.Options = make(map[string]interface{})
+	}
+	generic, ok := r.Options[optionKey].(map[string]interface{})
+	if !ok {
+		generic = make(map[string]interface{})
+		r.Options[optionKey] = generic
+	}
+	for key, value := range p.profile.Options {
+		if _, set := generic[key]; !set {
+			generic[key] = value
+		}
+	}
+	return p.Driver.CreateNetwork(ctx, r)
+}
@@ -0,0 +1,48 @@
+package ovs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProfile(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Profile
+		wantErr bool
+	}{
+		{
+			value: "ovs-flat=linker.net.ovs.bridge.mode=flat",
+			want:  Profile{Name: "ovs-flat", Options: map[string]string{"linker.net.ovs.bridge.mode": "flat"}},
+		},
+		{
+			value: "ovs-vlan=linker.net.ovs.bridge.mode=flat,linker.net.ovs.bridge.bind_interface=eth1,linker.net.ovs.bridge.vlan=100",
+			want: Profile{Name: "ovs-vlan", Options: map[string]string{
+				"linker.net.ovs.bridge.mode":           "flat",
+				"linker.net.ovs.bridge.bind_interface": "eth1",
+				"linker.net.ovs.bridge.vlan":           "100",
+			}},
+		},
+		// an option may be set to the empty string
+		{
+			value: "ovs-plain=linker.net.ovs.bridge.bind_interface=",
+			want:  Profile{Name: "ovs-plain", Options: map[string]string{"linker.net.ovs.bridge.bind_interface": ""}},
+		},
+		{value: "ovs-flat", wantErr: true},
+		{value: "=linker.net.ovs.bridge.mode=flat", wantErr: true},
+		{value: "ovs-flat=", wantErr: true},
+		{value: "ovs-flat=linker.net.ovs.bridge.mode", wantErr: true},
+		{value: "ovs-flat=linker.net.ovs.bridge.mode=flat,,", wantErr: true},
+		{value: "ovs-flat==flat", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseProfile(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseProfile(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseProfile(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package ovs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+// latencyBuckets are the upper bounds in seconds of the latency histograms,
+// from a cached lookup to a Join waiting on a slow ovsdb-server.
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// histogram counts observations per latency bucket.
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(seconds float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(latencyBuckets))
+	}
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// pluginMetrics are the counters and histograms served by ServeMetrics.
+// Gauges are read from the driver and the cache when scraped.
+var pluginMetrics = struct {
+	sync.Mutex
+	requests      map[string]*histogram
+	requestErrors map[string]uint64
+	transactions  histogram
+	txnErrors     uint64
+	txnTimeouts   uint64
+}{
+	requests:      make(map[string]*histogram),
+	requestErrors: make(map[string]uint64),
+}
+
+// observeRequest records the latency and outcome of a driver call, and logs
+// it with the network, endpoint and bridge as fields for structured log
+// destinations.
+func observeRequest(method, networkID, endpointID string, start time.Time, err error) {
+	logRequest(method, networkID, endpointID, time.Since(start), err)
+	pluginMetrics.Lock()
+	defer pluginMetrics.Unlock()
+	h, ok := pluginMetrics.requests[method]
+	if !ok {
+		h = &histogram{}
+		pluginMetrics.requests[method] = h
+	}
+	h.observe(time.Since(start).Seconds())
+	if err != nil {
+		pluginMetrics.requestErrors[method]++
+	}
+}
+
+// observeTransaction records the latency and outcome of an OVSDB
+// transaction.
+func observeTransaction(start time.Time, err error) {
+	pluginMetrics.Lock()
+	defer pluginMetrics.Unlock()
+	pluginMetrics.transactions.observe(time.Since(start).Seconds())
+	if err != nil {
+		pluginMetrics.txnErrors++
+		if txnErr, ok := err.(*TransactionError); ok && txnErr.Timeout {
+			pluginMetrics.txnTimeouts++
+		}
+	}
+}
+
+// instrumentedDriver records the latency and errors of every call docker
+// makes to a driver, logs and traces it. Lifecycle calls also go to the
+// audit log.
+type instrumentedDriver struct {
+	dknet.Driver
+	name  string
+	scope string
+}
+
+// Scope answers docker's capabilities request.
+func (i instrumentedDriver) Scope() string {
+	return i.scope
+}
+
+func (i instrumentedDriver) DiscoverNew(n *dknet.DiscoveryNotification) error {
+	if d, ok := i.Driver.(dknet.Discoverer); ok {
+		return d.DiscoverNew(n)
+	}
+	return nil
+}
+
+func (i instrumentedDriver) DiscoverDelete(n *dknet.DiscoveryNotification) error {
+	if d, ok := i.Driver.(dknet.Discoverer); ok {
+		return d.DiscoverDelete(n)
+	}
+	return nil
+}
+
+func (i instrumentedDriver) CreateNetwork(ctx context.Context, r *dknet.CreateNetworkRequest) error {
+	start := time.Now()
+	ctx, s := i.startSpan(ctx, "CreateNetwork", r.NetworkID, "")
+	err := i.Driver.CreateNetwork(ctx, r)
+	finishSpan(s, err)
+	observeRequest("CreateNetwork", r.NetworkID, "", start, err)
+	auditCall(AuditRecord{Driver: i.name, Operation: "CreateNetwork", NetworkID: r.NetworkID, Options: r.Options, Pools: ipamPools(r.IPv4Data)}, start, err)
+	return err
+}
+
+func (i instrumentedDriver) DeleteNetwork(ctx context.Context, r *dknet.DeleteNetworkRequest) error {
+	start := time.Now()
+	ctx, s := i.startSpan(ctx, "DeleteNetwork", r.NetworkID, "")
+	err := i.Driver.DeleteNetwork(ctx, r)
+	finishSpan(s, err)
+	observeRequest("DeleteNetwork", r.NetworkID, "", start, err)
+	auditCall(AuditRecord{Driver: i.name, Operation: "DeleteNetwork", NetworkID: r.NetworkID}, start, err)
+	return err
+}
+
+func (i instrumentedDriver) CreateEndpoint(ctx context.Context, r *dknet.CreateEndpointRequest) error {
+	start := time.Now()
+	ctx, s := i.startSpan(ctx, "CreateEndpoint", r.NetworkID, r.EndpointID)
+	err := i.Driver.CreateEndpoint(ctx, r)
+	finishSpan(s, err)
+	observeRequest("CreateEndpoint", r.NetworkID, r.EndpointID, start, err)
+	rec := AuditRecord{Driver: i.name, Operation: "CreateEndpoint", NetworkID: r.NetworkID, EndpointID: r.EndpointID, Options: r.Options}
+	if r.Interface != nil {
+		rec.Address = r.Interface.Address
+	}
+	auditCall(rec, start, err)
+	return err
+}
+
+func (i instrumentedDriver) DeleteEndpoint(ctx context.Context, r *dknet.DeleteEndpointRequest) error {
+	start := time.Now()
+	ctx, s := i.startSpan(ctx, "DeleteEndpoint", r.NetworkID, r.EndpointID)
+	err := i.Driver.DeleteEndpoint(ctx, r)
+	finishSpan(s, err)
+	observeRequest("DeleteEndpoint", r.NetworkID, r.EndpointID, start, err)
+	auditCall(AuditRecord{Driver: i.name, Operation: "DeleteEndpoint", NetworkID: r.NetworkID, EndpointID: r.EndpointID}, start, err)
+	return err
+}
+
+func (i instrumentedDriver) EndpointInfo(ctx context.Context, r *dknet.InfoRequest) (*dknet.InfoResponse, error) {
+	start := time.Now()
+	ctx, s := i.startSpan(ctx, "EndpointInfo", r.NetworkID, r.EnpointID)
+	res, err := i.Driver.EndpointInfo(ctx, r)
+	finishSpan(s, err)
+	observeRequest("EndpointInfo", r.NetworkID, r.EnpointID, start, err)
+	return res, err
+}
+
+func (i instrumentedDriver) Join(ctx context.Context, r *dknet.JoinRequest) (*dknet.JoinResponse, error) {
+	start := time.Now()
+	ctx, s := i.startSpan(ctx, "Join", r.NetworkID, r.EndpointID)
+	res, err := i.Driver.Join(ctx, r)
+	finishSpan(s, err)
+	observeRequest("Join", r.NetworkID, r.EndpointID, start, err)
+	auditCall(AuditRecord{Driver: i.name, Operation: "Join", NetworkID: r.NetworkID, EndpointID: r.EndpointID, SandboxKey: r.SandboxKey, Options: r.Options}, start, err)
+	return res, err
+}
+
+func (i instrumentedDriver) Leave(ctx context.Context, r *dknet.LeaveRequest) error {
+	start := time.Now()
+	ctx, s := i.startSpan(ctx, "Leave", r.NetworkID, r.EndpointID)
+	err := i.Driver.Leave(ctx, r)
+	finishSpan(s, err)
+	observeRequest("Leave", r.NetworkID, r.EndpointID, start, err)
+	auditCall(AuditRecord{Driver: i.name, Operation: "Leave", NetworkID: r.NetworkID, EndpointID: r.EndpointID}, start, err)
+	return err
+}
+
+// logRequest logs a driver call. The bridge is looked up after the call, so
+// it is known for networks just created.
+func logRequest(method, networkID, endpointID string, took time.Duration, err error) {
+	fields := log.Fields{"operation": method, "network_id": networkID, "duration": took.String()}
+	if endpointID != "" {
+		fields["endpoint_id"] = endpointID
+	}
+	if bridge, ok := ovsdbCache.networkBridge(networkID); ok {
+		fields["bridge"] = bridge
+	}
+	entry := log.WithFields(fields)
+	if err != nil {
+		entry.WithField("error", err.Error()).Warnf("%s failed", method)
+		return
+	}
+	if method == "EndpointInfo" {
+		// docker asks on every inspect
+		entry.Debugf("%s done", method)
+		return
+	}
+	entry.Infof("%s done", method)
+}
+
+// ServeMetrics serves the plugin's metrics in the Prometheus text format, and
+// /healthz for monitors that can't reach the admin socket, on addr until it
+// fails.
+func (d *Driver) ServeMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", d.handlePrometheus)
+	mux.HandleFunc("/healthz", d.handleHealth)
+	log.Infof("Serving metrics on %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// handlePrometheus writes all metrics.
+func (d *Driver) handlePrometheus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	pluginMetrics.Lock()
+	methods := make([]string, 0, len(pluginMetrics.requests))
+	for method := range pluginMetrics.requests {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	writeHeader(out, "docker_ovs_plugin_request_duration_seconds", "histogram", "Latency of the calls docker makes to the driver.")
+	for _, method := range methods {
+		writeHistogram(out, "docker_ovs_plugin_request_duration_seconds", `method="`+method+`",`, pluginMetrics.requests[method])
+	}
+	writeHeader(out, "docker_ovs_plugin_request_errors_total", "counter", "Calls docker made to the driver that failed.")
+	for _, method := range methods {
+		fmt.Fprintf(out, "docker_ovs_plugin_request_errors_total{method=%q} %d\n", method, pluginMetrics.requestErrors[method])
+	}
+	writeHeader(out, "docker_ovs_plugin_ovsdb_transaction_duration_seconds", "histogram", "Latency of OVSDB transactions.")
+	writeHistogram(out, "docker_ovs_plugin_ovsdb_transaction_duration_seconds", "", &pluginMetrics.transactions)
+	writeHeader(out, "docker_ovs_plugin_ovsdb_transaction_errors_total", "counter", "OVSDB transactions that failed, timeouts included.")
+	fmt.Fprintf(out, "docker_ovs_plugin_ovsdb_transaction_errors_total %d\n", pluginMetrics.txnErrors)
+	writeHeader(out, "docker_ovs_plugin_ovsdb_transaction_timeouts_total", "counter", "OVSDB transactions that got no reply in time.")
+	fmt.Fprintf(out, "docker_ovs_plugin_ovsdb_transaction_timeouts_total %d\n", pluginMetrics.txnTimeouts)
+	pluginMetrics.Unlock()
+
+	ports := 0
+	for _, row := range getTableCache("Port") {
+		if d.ovsdber.rowOwned(row) {
+			ports++
+		}
+	}
+	writeHeader(out, "docker_ovs_plugin_bridges", "gauge", "Bridges of the plugin's networks.")
+	fmt.Fprintf(out, "docker_ovs_plugin_bridges %d\n", len(pluginBridges()))
+	writeHeader(out, "docker_ovs_plugin_ports", "gauge", "OVS ports created by the plugin.")
+	fmt.Fprintf(out, "docker_ovs_plugin_ports %d\n", ports)
+	writeHeader(out, "docker_ovs_plugin_cache_rows", "gauge", "Rows in the OVSDB cache.")
+	sizes := ovsdbCache.sizes()
+	tables := make([]string, 0, len(sizes))
+	for table := range sizes {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Fprintf(out, "docker_ovs_plugin_cache_rows{table=%q} %d\n", table, sizes[table])
+	}
+	writeHeader(out, "docker_ovs_plugin_ovsdb_update_queue_depth", "gauge", "OVSDB notifications waiting for the bridge monitor.")
+	fmt.Fprintf(out, "docker_ovs_plugin_ovsdb_update_queue_depth %d\n", updates.snapshot().Depth)
+}
+
+func writeHeader(out *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeHistogram writes the cumulative buckets, sum and count of a
+// histogram, labels ends with a comma if not empty.
+func writeHistogram(out *bufio.Writer, name, labels string, h *histogram) {
+	for i, bound := range latencyBuckets {
+		var count uint64
+		if h.counts != nil {
+			count = h.counts[i]
+		}
+		fmt.Fprintf(out, "%s_bucket{%sle=%q} %d\n", name, labels, strconv.FormatFloat(bound, 'g', -1, 64), count)
+	}
+	fmt.Fprintf(out, "%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, h.count)
+	if labels != "" {
+		labels = "{" + labels[:len(labels)-1] + "}"
+	}
+	fmt.Fprintf(out, "%s_sum%s %g\n", name, labels, h.sum)
+	fmt.Fprintf(out, "%s_count%s %d\n", name, labels, h.count)
+}
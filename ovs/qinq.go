@@ -0,0 +1,287 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// qinqEthType is the ethertype of the service tag, 802.1ad
+	qinqEthType = "802.1ad"
+
+	// A QinQ network reaches the carrier bridge of its bind interface
+	// through a veth, named after the network: the network end carries
+	// the customer tag sub-interface, the carrier end is a dot1q-tunnel
+	// port of the carrier bridge.
+	qinqNetworkPrefix = "qn"
+	qinqCarrierPrefix = "qc"
+	carrierPrefix     = "qq-"
+
+	// vlanLimit is how many VLAN headers the switch parses, dot1q-tunnel
+	// ports need two
+	vlanLimitKey = "vlan-limit"
+	vlanLimit    = "2"
+)
+
+// createQinQUplink returns the uplink of a network whose traffic leaves the
+// bind interface with the service tag svlan outside the customer tag
+// cvlan. Switches with dot1q-tunnel ports push the service tag themselves,
+// on the carrier bridge of the bind interface. Older switches get an 802.1Q
+// sub-interface stacked on an 802.1ad sub-interface instead.
+func (ovsdber *ovsdber) createQinQUplink(ctx context.Context, networkID, bindInterface, datapath string, svlan, cvlan int) (string, error) {
+	if _, err := netlink.LinkByName(bindInterface); err != nil {
+		return "", err
+	}
+	if switchCapabilities().require(featureQinQ, datapath) != nil {
+		return ovsdber.createKernelQinQUplink(ctx, bindInterface, svlan, cvlan)
+	}
+	carrier, err := ovsdber.ensureCarrierBridge(ctx, bindInterface, datapath)
+	if err != nil {
+		return "", err
+	}
+	suffix := truncateID(networkID)
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: qinqNetworkPrefix + suffix},
+		PeerName:  qinqCarrierPrefix + suffix,
+	}
+	if _, err := netlink.LinkByName(veth.Name); err != nil {
+		if err := netlink.LinkAdd(veth); err != nil {
+			return "", fmt.Errorf("failed to create veth %s to carrier bridge %s: %v", veth.Name, carrier, err)
+		}
+		if err := ovsdber.markLink(veth.Name); err != nil {
+			netlink.LinkDel(veth)
+			return "", err
+		}
+	} else if err := ovsdber.checkLinkOwner(veth.Name); err != nil {
+		return "", err
+	}
+	for _, name := range []string{veth.Name, veth.PeerName} {
+		if err := interfaceUp(ctx, name); err != nil {
+			return "", err
+		}
+	}
+	if portUUIDForName(veth.PeerName) == "" {
+		if err := ovsdber.addOvsVethPort(ctx, carrier, veth.PeerName, 0); err != nil {
+			return "", err
+		}
+	}
+	if err := ovsdber.setTunnelPort(veth.PeerName, svlan, cvlan); err != nil {
+		return "", err
+	}
+	log.Infof("Service vlan %d of [ %s ] is pushed by dot1q-tunnel port [ %s ] of carrier bridge [ %s ]",
+		svlan, veth.Name, veth.PeerName, carrier)
+	return ovsdber.createVlanUplink(ctx, veth.Name, cvlan)
+}
+
+// createKernelQinQUplink stacks an 802.1Q sub-interface for the customer
+// tag on top of an 802.1ad sub-interface for the service tag. The service
+// sub-interface is shared by all networks using the same service tag, one
+// of another protocol is refused.
+func (ovsdber *ovsdber) createKernelQinQUplink(ctx context.Context, bindInterface string, svlan, cvlan int) (string, error) {
+	outer := vlanUplinkName(bindInterface, svlan)
+	if _, err := netlink.LinkByName(outer); err != nil {
+		// netlink.Vlan has no protocol attribute, fall back to iproute2
+		out, err := exec.Command("ip", "link", "add", "link", bindInterface, "name", outer,
+			"type", "vlan", "protocol", qinqEthType, "id", fmt.Sprintf("%d", svlan)).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("failed to create %s interface %s: %v %s", qinqEthType, outer, err, out)
+		}
+		if err := ovsdber.markLink(outer); err != nil {
+			if link, lerr := netlink.LinkByName(outer); lerr == nil {
+				netlink.LinkDel(link)
+			}
+			return "", err
+		}
+	} else {
+		if err := ovsdber.checkLinkOwner(outer); err != nil {
+			return "", err
+		}
+		protocol, err := vlanProtocol(outer)
+		if err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(protocol, qinqEthType) {
+			return "", fmt.Errorf("service vlan interface %s tags with %s, not %s", outer, protocol, qinqEthType)
+		}
+	}
+	if err := interfaceUp(ctx, outer); err != nil {
+		return "", err
+	}
+	return ovsdber.createVlanUplink(ctx, outer, cvlan)
+}
+
+// vlanProtocol returns the tag protocol of a vlan interface, from
+// "vlan protocol 802.1ad id 100" in the details of ip link.
+func vlanProtocol(name string) (string, error) {
+	out, err := exec.Command("ip", "-d", "-o", "link", "show", "dev", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read vlan protocol of %s: %v", name, err)
+	}
+	return parseVlanProtocol(string(out))
+}
+
+func parseVlanProtocol(details string) (string, error) {
+	fields := strings.Fields(details)
+	for i := 0; i+2 < len(fields); i++ {
+		if fields[i] == "vlan" && fields[i+1] == "protocol" {
+			return fields[i+2], nil
+		}
+	}
+	return "", fmt.Errorf("not a vlan interface")
+}
+
+// carrierBridgeName keeps the name of the carrier bridge of a bind
+// interface within IFNAMSIZ.
+func carrierBridgeName(bindInterface string) string {
+	if len(carrierPrefix)+len(bindInterface) > maxIfNameLen {
+		bindInterface = bindInterface[:maxIfNameLen-len(carrierPrefix)]
+	}
+	return carrierPrefix + bindInterface
+}
+
+// ensureCarrierBridge returns the carrier bridge of a bind interface,
+// creating it with the bind interface as a trunk port. The bridge belongs
+// to no network, it is shared by the QinQ networks of the interface.
+func (ovsdber *ovsdber) ensureCarrierBridge(ctx context.Context, bindInterface, datapath string) (string, error) {
+	if err := ovsdber.setVlanLimit(); err != nil {
+		return "", err
+	}
+	name := carrierBridgeName(bindInterface)
+	if getBridgeUUIDForName(name) == "" {
+		if err := ovsdber.addBridge(ctx, name, "none", "none", datapath, nil); err != nil {
+			return "", err
+		}
+		log.Infof("Created carrier bridge [ %s ] for QinQ networks on %s", name, bindInterface)
+	} else if err := ovsdber.checkBridgeOwner(name); err != nil {
+		return "", err
+	}
+	switch bridge := bridgeNameForPort(bindInterface); bridge {
+	case name:
+	case "":
+		if err := ovsdber.addOvsVethPort(ctx, name, bindInterface, 0); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("%s is a port of bridge %s, it can't carry QinQ networks", bindInterface, bridge)
+	}
+	if err := interfaceUp(ctx, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// setVlanLimit lets the switch parse both tags of QinQ traffic. A limit
+// of 0, no limit, is left alone.
+func (ovsdber *ovsdber) setVlanLimit() error {
+	for _, row := range getTableCache("Open_vSwitch") {
+		if limit := ovsMapValue(row.Fields["other_config"], vlanLimitKey); limit == "0" || limit == vlanLimit {
+			return nil
+		}
+	}
+	config, _ := libovsdb.NewOvsMap(map[string]string{vlanLimitKey: vlanLimit})
+	if err := ovsdber.mutateRootMap("other_config", vlanLimitKey, libovsdb.NewMutation("other_config", "insert", config)); err != nil {
+		return err
+	}
+	log.Infof("Set %s to %s for QinQ", vlanLimitKey, vlanLimit)
+	return nil
+}
+
+// setTunnelPort makes a port of a carrier bridge a dot1q-tunnel port: the
+// customer tag cvlan it receives gets the service tag svlan pushed outside
+// with the 802.1ad ethertype, and the service tag is popped again towards
+// the port.
+func (ovsdber *ovsdber) setTunnelPort(portName string, svlan, cvlan int) error {
+	cvlans, _ := libovsdb.NewOvsSet([]int{cvlan})
+	otherConfig, _ := libovsdb.NewOvsMap(map[string]string{"qinq-ethtype": qinqEthType})
+	updateOp := libovsdb.Operation{
+		Op:    "update",
+		Table: "Port",
+		Row: map[string]interface{}{
+			"vlan_mode":    "dot1q-tunnel",
+			"tag":          svlan,
+			"cvlans":       cvlans,
+			"other_config": otherConfig,
+		},
+		Where: []interface{}{libovsdb.NewCondition("name", "==", portName)},
+	}
+	return ovsdber.transact(updateOp)
+}
+
+// removeQinQLink deletes the veth of a QinQ network with its dot1q-tunnel
+// port, and the carrier bridge once no network uses it.
+func (ovsdber *ovsdber) removeQinQLink(ctx context.Context, link netlink.Link) {
+	name := link.Attrs().Name
+	if link.Type() != "veth" || !strings.HasPrefix(name, qinqNetworkPrefix) {
+		return
+	}
+	if err := ovsdber.checkLinkOwner(name); err != nil {
+		log.Warnf("not deleting QinQ link: %v", err)
+		return
+	}
+	peer := qinqCarrierPrefix + strings.TrimPrefix(name, qinqNetworkPrefix)
+	carrier := bridgeNameForPort(peer)
+	if carrier != "" {
+		if err := ovsdber.deletePort(ctx, carrier, peer); err != nil {
+			log.Warnf("failed to remove dot1q-tunnel port %s: %v", peer, err)
+		}
+	}
+	if err := netlink.LinkDel(link); err != nil {
+		log.Warnf("failed to delete QinQ link %s: %v", name, err)
+	}
+	log.Infof("Deleted QinQ link [ %s ]", name)
+	if carrier == "" || !strings.HasPrefix(carrier, carrierPrefix) {
+		return
+	}
+	for _, port := range bridgePortNames(carrier) {
+		if strings.HasPrefix(port, qinqCarrierPrefix) && port != peer {
+			return
+		}
+	}
+	if err := ovsdber.deleteCarrierBridge(carrier); err != nil {
+		log.Warnf("failed to delete unused carrier bridge %s: %v", carrier, err)
+		return
+	}
+	log.Infof("Deleted unused carrier bridge [ %s ]", carrier)
+}
+
+// deleteCarrierBridge deletes a carrier bridge, which has neither a
+// network nor a gateway service to stop.
+func (ovsdber *ovsdber) deleteCarrierBridge(bridgeName string) error {
+	if err := ovsdber.checkBridgeOwner(bridgeName); err != nil {
+		return err
+	}
+	return converge("delete bridge "+bridgeName, ovsdber.deleted("Bridge", bridgeName), func() error {
+		bridgeUUID := getBridgeUUIDForName(bridgeName)
+		if bridgeUUID == "" {
+			return fmt.Errorf("Unable to find a bridge uuid by name : [ %s ]", bridgeName)
+		}
+		mutateSet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{{GoUuid: bridgeUUID}})
+		operations := []libovsdb.Operation{
+			{
+				Op:    "delete",
+				Table: "Bridge",
+				Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+			},
+			{
+				Op:        "mutate",
+				Table:     "Open_vSwitch",
+				Mutations: []interface{}{libovsdb.NewMutation("bridges", "delete", mutateSet)},
+				Where:     []interface{}{libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUuid: ovsdber.getRootUUID()})},
+			},
+		}
+		if ovsdber.hasBridgeOpt() {
+			operations = append(operations, libovsdb.Operation{
+				Op:    "delete",
+				Table: "BridgeOpt",
+				Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+			})
+		}
+		return ovsdber.transact(operations...)
+	})
+}
@@ -0,0 +1,43 @@
+package ovs
+
+import "testing"
+
+func TestParseVlanProtocol(t *testing.T) {
+	tests := []struct {
+		details string
+		want    string
+		wantErr bool
+	}{
+		{
+			details: "7: eth0.100@eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default qlen 1000\\    link/ether 52:54:00:12:34:56 brd ff:ff:ff:ff:ff:ff promiscuity 0 \\    vlan protocol 802.1ad id 100 <REORDER_HDR> addrgenmode eui64",
+			want:    "802.1ad",
+		},
+		{
+			details: "8: eth0.200@eth0: <BROADCAST> mtu 1500 \\    vlan protocol 802.1Q id 200 <REORDER_HDR>",
+			want:    "802.1Q",
+		},
+		{
+			details: "2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc mq state UP",
+			wantErr: true,
+		},
+		{details: "vlan protocol", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseVlanProtocol(tt.details)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseVlanProtocol(%q) = %q, %v, want %q", tt.details, got, err, tt.want)
+		}
+	}
+}
+
+func TestCarrierBridgeName(t *testing.T) {
+	for bind, want := range map[string]string{
+		"eth0":            "qq-eth0",
+		"enp0s31f6":       "qq-enp0s31f6",
+		"enx001122334455": "qq-enx001122334",
+	} {
+		if got := carrierBridgeName(bind); got != want || len(got) > maxIfNameLen {
+			t.Errorf("carrierBridgeName(%s) = %s, want %s", bind, got, want)
+		}
+	}
+}
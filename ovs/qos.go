@@ -0,0 +1,244 @@
+package ovs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// Rates of the network's queue in kbps. min_rate is the bandwidth the
+	// network is guaranteed on its uplink, max_rate caps it.
+	qosMinRateOption = "linker.net.ovs.qos.min_rate"
+	qosMaxRateOption = "linker.net.ovs.qos.max_rate"
+	// qosClassesOption adds a queue per class to the network's QoS, as a
+	// comma separated list of <class>:<min_rate>:<max_rate>, e.g.
+	// voice:50000:100000,batch:0:20000. Endpoints pick their class with
+	// endpointQoSClassOption, the others use the default queue.
+	qosClassesOption       = "linker.net.ovs.qos.classes"
+	endpointQoSClassOption = "linker.net.ovs.endpoint.qos.class"
+
+	qosType = "linux-htb"
+
+	// qosQueuePriority puts the traffic of an endpoint's port in the queue
+	// of its class, then sends it through the port security table again
+	// with reg2 set so it is not queued twice.
+	qosQueuePriority = 60000
+)
+
+// QoSClass is a queue of a network's QoS. Its queue id is its position in
+// the network's classes plus one, queue 0 being the default queue.
+type QoSClass struct {
+	Name    string
+	MinRate int
+	MaxRate int
+}
+
+// getQoS returns the queue rates requested for a network, 0 if unset.
+func getQoS(r *dknet.CreateNetworkRequest) (int, int, error) {
+	minRate, err := getRateOption(r, qosMinRateOption)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxRate, err := getRateOption(r, qosMaxRateOption)
+	if err != nil {
+		return 0, 0, err
+	}
+	if maxRate > 0 && minRate > maxRate {
+		return 0, 0, fmt.Errorf("%s must not exceed %s", qosMinRateOption, qosMaxRateOption)
+	}
+	return minRate, maxRate, nil
+}
+
+// getQoSClasses returns the queue classes requested for a network.
+func getQoSClasses(r *dknet.CreateNetworkRequest) ([]QoSClass, error) {
+	var classes []QoSClass
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(getStringOption(r, qosClassesOption), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("%s entries are <class>:<min_rate>:<max_rate>, got %s", qosClassesOption, entry)
+		}
+		class := QoSClass{Name: parts[0]}
+		var err error
+		if class.MinRate, err = parseRate(parts[1]); err != nil {
+			return nil, fmt.Errorf("min rate of %s class %s: %v", qosClassesOption, class.Name, err)
+		}
+		if class.MaxRate, err = parseRate(parts[2]); err != nil {
+			return nil, fmt.Errorf("max rate of %s class %s: %v", qosClassesOption, class.Name, err)
+		}
+		if class.MaxRate > 0 && class.MinRate > class.MaxRate {
+			return nil, fmt.Errorf("min rate of %s class %s exceeds its max rate", qosClassesOption, class.Name)
+		}
+		if seen[class.Name] {
+			return nil, fmt.Errorf("%s lists class %s twice", qosClassesOption, class.Name)
+		}
+		seen[class.Name] = true
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// parseRate parses a rate in kbps, empty being 0.
+func parseRate(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	rate, err := strconv.Atoi(value)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("%s is not a rate in kbps", value)
+	}
+	return rate, nil
+}
+
+// getEndpointQueue returns the queue of the class an endpoint asks for, 0
+// for the default queue.
+func getEndpointQueue(options map[string]interface{}, classes []QoSClass) (int, error) {
+	name := endpointStringOption(options, endpointQoSClassOption)
+	if name == "" {
+		return 0, nil
+	}
+	for i, class := range classes {
+		if class.Name == name {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%s %s is not one of the %s of the network", endpointQoSClassOption, name, qosClassesOption)
+}
+
+func getRateOption(r *dknet.CreateNetworkRequest, key string) (int, error) {
+	value := getStringOption(r, key)
+	if value == "" {
+		return 0, nil
+	}
+	rate, err := strconv.Atoi(value)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("%s must be a rate in kbps, got %s", key, value)
+	}
+	return rate, nil
+}
+
+// qosPort returns the OVS port shaping the traffic a network sends out of
+// its bridge. In nat mode this is the bridge's internal port.
+func qosPort(bridgeName, uplinkPort string) string {
+	if uplinkPort == "LOCAL" {
+		return bridgeName
+	}
+	return uplinkPort
+}
+
+// queueRow returns a Queue row with the rates in kbps.
+func queueRow(minRate, maxRate int) map[string]interface{} {
+	queueConfig := make(map[string]string)
+	if minRate > 0 {
+		queueConfig["min-rate"] = strconv.Itoa(minRate * 1000)
+	}
+	if maxRate > 0 {
+		queueConfig["max-rate"] = strconv.Itoa(maxRate * 1000)
+	}
+	queueOtherConfig, _ := libovsdb.NewOvsMap(queueConfig)
+	return map[string]interface{}{
+		"other_config": queueOtherConfig,
+	}
+}
+
+// setPortQoS puts a linux-htb QoS on the port. Its default queue, which
+// the traffic of endpoints without a class is mapped to, gets the
+// network's rates, and each class gets a queue of its own.
+func (ovsdber *ovsdber) setPortQoS(portName string, minRate, maxRate int, classes []QoSClass) error {
+	operations := []libovsdb.Operation{{Op: "insert", Table: "Queue", Row: queueRow(minRate, maxRate), UUIDName: "queue0"}}
+	queueIDs := map[int]libovsdb.UUID{0: {GoUuid: "queue0"}}
+	for i, class := range classes {
+		name := fmt.Sprintf("queue%d", i+1)
+		operations = append(operations, libovsdb.Operation{Op: "insert", Table: "Queue", Row: queueRow(class.MinRate, class.MaxRate), UUIDName: name})
+		queueIDs[i+1] = libovsdb.UUID{GoUuid: name}
+	}
+
+	queues, _ := libovsdb.NewOvsMap(queueIDs)
+	qos := map[string]interface{}{
+		"type":   qosType,
+		"queues": queues,
+	}
+	if maxRate > 0 {
+		qosOtherConfig, _ := libovsdb.NewOvsMap(map[string]string{"max-rate": strconv.Itoa(maxRate * 1000)})
+		qos["other_config"] = qosOtherConfig
+	}
+
+	operations = append(operations,
+		libovsdb.Operation{Op: "insert", Table: "QoS", Row: qos, UUIDName: "qos"},
+		libovsdb.Operation{
+			Op:    "update",
+			Table: "Port",
+			Row:   map[string]interface{}{"qos": libovsdb.UUID{GoUuid: "qos"}},
+			Where: []interface{}{libovsdb.NewCondition("name", "==", portName)},
+		},
+	)
+	if err := ovsdber.transact(operations...); err != nil {
+		return err
+	}
+	log.Infof("Set QoS on port [ %s ]: min %d kbps, max %d kbps, %d classes", portName, minRate, maxRate, len(classes))
+	return nil
+}
+
+// applyQueue puts the traffic an endpoint sends in the queue of its class.
+// The queue is applied on whichever port the traffic leaves by, ports
+// without a QoS ignore it.
+func applyQueue(endpointID, bridgeName, port string, queue int) error {
+	flow := fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,reg2=0,actions=set_queue:%d,load:1->NXM_NX_REG2[],resubmit(,%d)",
+		flowCookie(endpointID), qosQueuePriority, port, queue, portSecurityTable)
+	if err := addFlow(bridgeName, portSecurityTable, flow); err != nil {
+		return err
+	}
+	log.Infof("Queueing traffic of port [ %s ] in queue %d", port, queue)
+	return nil
+}
+
+// clearPortQoS detaches the QoS of the port and deletes it with its queues,
+// the QoS and Queue tables are not garbage collected by OVSDB.
+func (ovsdber *ovsdber) clearPortQoS(portName string) error {
+	portUUID := portUUIDForName(portName)
+	if portUUID == "" {
+		return fmt.Errorf("unable to find a matching port %s", portName)
+	}
+	qosUUIDs := rowUUIDs(cachedRow("Port", portUUID).Fields["qos"])
+	if len(qosUUIDs) == 0 {
+		return nil
+	}
+	emptySet, _ := libovsdb.NewOvsSet([]libovsdb.UUID{})
+	operations := []libovsdb.Operation{{
+		Op:    "update",
+		Table: "Port",
+		Row:   map[string]interface{}{"qos": emptySet},
+		Where: []interface{}{libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUuid: portUUID})},
+	}}
+	for _, qosUUID := range qosUUIDs {
+		operations = append(operations, libovsdb.Operation{
+			Op:    "delete",
+			Table: "QoS",
+			Where: []interface{}{libovsdb.NewCondition("_uuid", "==", libovsdb.UUID{GoUuid: qosUUID})},
+		})
+		queues, _ := cachedRow("QoS", qosUUID).Fields["queues"].(libovsdb.OvsMap)
+		for _, queue := range queues.GoMap {
+			if queueUUID, ok := queue.(libovsdb.UUID); ok {
+				operations = append(operations, libovsdb.Operation{
+					Op:    "delete",
+					Table: "Queue",
+					Where: []interface{}{libovsdb.NewCondition("_uuid", "==", queueUUID)},
+				})
+			}
+		}
+	}
+	if err := ovsdber.transact(operations...); err != nil {
+		return err
+	}
+	log.Infof("Removed QoS from port [ %s ]", portName)
+	return nil
+}
@@ -0,0 +1,60 @@
+package ovs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetQoSClasses(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []QoSClass
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "voice:50000:100000", want: []QoSClass{{Name: "voice", MinRate: 50000, MaxRate: 100000}}},
+		{value: "voice:50000:100000, batch::20000", want: []QoSClass{
+			{Name: "voice", MinRate: 50000, MaxRate: 100000},
+			{Name: "batch", MaxRate: 20000},
+		}},
+		{value: "voice:50000:0", want: []QoSClass{{Name: "voice", MinRate: 50000}}},
+		{value: "voice:100000:50000", wantErr: true},
+		{value: "voice:50000", wantErr: true},
+		{value: ":1:2", wantErr: true},
+		{value: "voice:fast:2", wantErr: true},
+		{value: "voice:1:2,voice:3:4", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := getQoSClasses(networkRequest(map[string]string{qosClassesOption: tt.value}))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getQoSClasses(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("getQoSClasses(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetEndpointQueue(t *testing.T) {
+	classes := []QoSClass{{Name: "voice"}, {Name: "batch"}}
+	tests := []struct {
+		class   string
+		classes []QoSClass
+		want    int
+		wantErr bool
+	}{
+		{class: "", classes: classes, want: 0},
+		{class: "voice", classes: classes, want: 1},
+		{class: "batch", classes: classes, want: 2},
+		{class: "bulk", classes: classes, wantErr: true},
+		{class: "voice", classes: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		options := map[string]interface{}{endpointQoSClassOption: tt.class}
+		got, err := getEndpointQueue(options, tt.classes)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("getEndpointQueue(%q) = %d, %v, want %d, error %v", tt.class, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
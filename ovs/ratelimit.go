@@ -0,0 +1,95 @@
+package ovs
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// Policing applies to what the switch receives from the endpoint, i.e.
+	// the container's outbound traffic. The rate is in kbps, the burst in kb.
+	ingressRateOption  = "linker.net.ovs.endpoint.ingress_rate"
+	ingressBurstOption = "linker.net.ovs.endpoint.ingress_burst"
+)
+
+// getIngressPolicing returns the policing rate and burst requested for an
+// endpoint, either as plain driver options or nested in the generic options.
+func getIngressPolicing(options map[string]interface{}) (int, int, error) {
+	rate, err := endpointIntOption(options, ingressRateOption)
+	if err != nil {
+		return 0, 0, err
+	}
+	burst, err := endpointIntOption(options, ingressBurstOption)
+	if err != nil {
+		return 0, 0, err
+	}
+	if burst > 0 && rate == 0 {
+		return 0, 0, fmt.Errorf("%s requires %s to be set", ingressBurstOption, ingressRateOption)
+	}
+	return rate, burst, nil
+}
+
+func endpointStringOption(options map[string]interface{}, key string) string {
+	if options == nil {
+		return ""
+	}
+	v, ok := options[key]
+	if !ok {
+		if option, isMap := options[optionKey].(map[string]interface{}); isMap {
+			v = option[key]
+		}
+	}
+	value, _ := v.(string)
+	return value
+}
+
+func endpointIntOption(options map[string]interface{}, key string) (int, error) {
+	if options == nil {
+		return 0, nil
+	}
+	v, ok := options[key]
+	if !ok {
+		if option, isMap := options[optionKey].(map[string]interface{}); isMap {
+			v = option[key]
+		}
+	}
+	var n int
+	switch value := v.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		var err error
+		if n, err = strconv.Atoi(value); err != nil {
+			return 0, fmt.Errorf("%s must be a number, got %s", key, value)
+		}
+	default:
+		n = toInt(v)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s must not be negative", key)
+	}
+	return n, nil
+}
+
+// setIngressPolicing sets the policing of an interface, a rate of 0
+// disables it.
+func (ovsdber *ovsdber) setIngressPolicing(ifaceName string, rate, burst int) error {
+	row := map[string]interface{}{
+		"ingress_policing_rate":  rate,
+		"ingress_policing_burst": burst,
+	}
+	updateOp := libovsdb.Operation{
+		Op:    "update",
+		Table: "Interface",
+		Row:   row,
+		Where: []interface{}{libovsdb.NewCondition("name", "==", ifaceName)},
+	}
+	if err := ovsdber.transact(updateOp); err != nil {
+		return err
+	}
+	log.Debugf("set ingress policing of %s to %d kbps burst %d kb", ifaceName, rate, burst)
+	return nil
+}
@@ -0,0 +1,90 @@
+package ovs
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// Seconds Join waits for the endpoint's dataplane to work before it
+	// fails, 0 returns as soon as the port is attached.
+	readinessTimeoutOption = "linker.net.ovs.bridge.readiness_timeout"
+
+	readinessPollInterval = 500 * time.Millisecond
+)
+
+// getReadinessTimeout returns how long endpoints of the network are given
+// to become ready, 0 if they are not checked.
+func getReadinessTimeout(r *dknet.CreateNetworkRequest) (time.Duration, error) {
+	value := getStringOption(r, readinessTimeoutOption)
+	if value == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("%s must be a number of seconds, got %s", readinessTimeoutOption, value)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// waitEndpointReady blocks until the switch assigned the endpoint's port an
+// OpenFlow port, sees its link up and has its flows installed. The
+// container side of the veth is still in the host namespace and has no
+// address there, so nothing is sent through it. The last check that failed
+// is returned on timeout.
+func (d *Driver) waitEndpointReady(endpointID, bridgeName string, veth *netlink.Veth, timeout time.Duration) error {
+	es, ok := d.endpoint(endpointID)
+	if !ok {
+		return fmt.Errorf("unknown endpoint %s", truncateID(endpointID))
+	}
+	ns, _ := d.network(es.NetworkID)
+	wantFlows := es.DSCP > 0 || es.Allow != "" || es.AllowEgress != "" || (ns != nil && ns.PortSecurity)
+
+	peer, err := netlink.LinkByName(veth.PeerName)
+	if err != nil {
+		return err
+	}
+	if err := netlink.LinkSetUp(peer); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err = endpointReady(endpointID, bridgeName, veth.Name, wantFlows)
+		if err == nil {
+			log.Infof("Endpoint [ %s ] is ready", truncateID(endpointID))
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("endpoint %s not ready after %s: %v", truncateID(endpointID), timeout, err)
+		}
+		log.Debugf("endpoint %s not ready yet: %v", truncateID(endpointID), err)
+		time.Sleep(readinessPollInterval)
+	}
+}
+
+func endpointReady(endpointID, bridgeName, portName string, wantFlows bool) error {
+	if ofportForName(portName) < 0 {
+		return fmt.Errorf("port %s has no ofport", portName)
+	}
+	if !linkUp(portName) {
+		return fmt.Errorf("link of port %s is not up", portName)
+	}
+	if wantFlows {
+		out, err := exec.Command("ovs-ofctl", "dump-flows", bridgeName, "cookie="+flowCookie(endpointID)+"/-1").Output()
+		if err != nil {
+			return fmt.Errorf("ovs-ofctl dump-flows %s: %v", bridgeName, err)
+		}
+		if !strings.Contains(string(out), "cookie=") {
+			return fmt.Errorf("flows of port %s are not installed", portName)
+		}
+	}
+	return nil
+}
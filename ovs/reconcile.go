@@ -0,0 +1,237 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// reconcileInterval is how often the host is repaired even when OVSDB
+	// reported no change, addresses and firewall rules have no monitor
+	reconcileInterval = 30 * time.Second
+	// reconcileSettle lets a burst of OVSDB updates, e.g. a bridge and all
+	// of its ports being deleted, arrive before the host is compared
+	reconcileSettle = time.Second
+)
+
+var (
+	// reconcileMu serializes repairs with CreateNetwork, DeleteNetwork and
+	// replicated networks, so a network is never repaired halfway through
+	// being created or deleted
+	reconcileMu sync.Mutex
+	// bridgesChanged is signalled by the OVSDB monitor when bridges or
+	// ports change
+	bridgesChanged = make(chan struct{}, 1)
+	// reconcileStatus is the outcome of the last run, for /healthz
+	reconcileStatus struct {
+		sync.Mutex
+		last   time.Time
+		failed int
+	}
+)
+
+// signalReconcile asks the reconciler to run, it never blocks.
+func signalReconcile() {
+	select {
+	case bridgesChanged <- struct{}{}:
+	default:
+	}
+}
+
+// runReconciler repairs the host whenever bridges or ports change and every
+// reconcileInterval.
+func (d *Driver) runReconciler() {
+	ticker := time.NewTicker(reconcileInterval)
+	for {
+		select {
+		case <-bridgesChanged:
+			time.Sleep(reconcileSettle)
+			// the updates that arrived while settling are covered by this run
+			select {
+			case <-bridgesChanged:
+			default:
+			}
+		case <-ticker.C:
+		}
+		d.reconcile()
+	}
+}
+
+// reconcile compares every network of the driver with OVSDB and the kernel
+// and repairs what differs. Networks DeleteNetwork removed are gone from
+// the driver's state by the time the lock is released, so they are never
+// brought back.
+func (d *Driver) reconcile() {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	failed := 0
+	defer func() {
+		reconcileStatus.Lock()
+		reconcileStatus.last = time.Now()
+		reconcileStatus.failed = failed
+		reconcileStatus.Unlock()
+	}()
+	var gateway *NetworkState
+	for id, ns := range d.networkStates() {
+		if ns.Node != "" {
+			// the node's bridge is not monitored
+			continue
+		}
+		if strings.EqualFold(ns.NetworkType, type_sgw) || strings.EqualFold(ns.NetworkType, type_pgw) {
+			gateway = ns
+		}
+		if err := d.reconcileNetwork(id, ns); err != nil {
+			log.Errorf("failed to repair network %s: %v", truncateID(id), err)
+			failed++
+		}
+	}
+	// an evacuated host keeps its gateway stopped until it is resumed
+	if gateway != nil && d.hostsGateway() && !hostInMaintenance() && !gatewayServiceActive() {
+		log.Warnf("%s is not active, starting it again for bridge [ %s ]", serviceName, gateway.BridgeName)
+		runOvsScript(context.Background(), gateway.BridgeName, gateway.NetworkName, gateway.NetworkType, gateway.FlatBindInterface)
+	}
+}
+
+// reconcileNetwork repairs the bridge of a network, then its address and
+// firewall rules, then the ports of its endpoints and its tunnels. A missing bridge is set
+// up again from scratch, initBridge reprograms everything that went with
+// it, gateway service included.
+func (d *Driver) reconcileNetwork(id string, ns *NetworkState) error {
+	if getBridgeUUIDForName(ns.BridgeName) == "" {
+		log.Warnf("bridge [ %s ] of network [ %s ] is gone, setting it up again", ns.BridgeName, truncateID(id))
+		if err := d.initBridge(context.Background(), id); err != nil {
+			return err
+		}
+		if err := d.setupPoolRoutes(ns); err != nil {
+			return fmt.Errorf("failed to route the pools of network %s: %v", truncateID(id), err)
+		}
+	}
+
+	link, err := netlink.LinkByName(ns.BridgeName)
+	if err != nil {
+		return err
+	}
+	if link.Attrs().Flags&net.FlagUp == 0 {
+		log.Warnf("bridge [ %s ] is down, bringing it up", ns.BridgeName)
+		if err := netlink.LinkSetUp(link); err != nil {
+			return err
+		}
+	}
+	if ns.Mode == modeNAT {
+		gatewayIP := ns.Gateway + "/" + ns.GatewayMask
+		if !linkHasAddr(link, gatewayIP) {
+			log.Warnf("bridge [ %s ] lost its address, assigning %s", ns.BridgeName, gatewayIP)
+			if err := setInterfaceIP(ns.BridgeName, gatewayIP); err != nil {
+				return err
+			}
+		}
+		if rules, err := d.firewall.listRules(id); err != nil || len(rules) == 0 {
+			log.Warnf("NAT rules of bridge [ %s ] are gone, programming them again", ns.BridgeName)
+			if err := d.firewall.setupNetwork(context.Background(), id, ns.BridgeName, gatewayIP); err != nil {
+				return err
+			}
+			if !ns.ICC {
+				if err := d.firewall.isolateNetwork(context.Background(), id, ns.BridgeName); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for endpointID, es := range d.endpointStates() {
+		if es.NetworkID == id {
+			d.reconcileEndpoint(endpointID, es, ns)
+		}
+	}
+	return d.repairTunnels(id, ns)
+}
+
+// reconcileEndpoint attaches the veth of a joined endpoint to the bridge
+// again if its port is gone, with the flows Join put on it. An endpoint is joined once docker moved the
+// veth's peer into the container, until then, and after Leave deleted the
+// veth, its port is left to Join and Leave.
+func (d *Driver) reconcileEndpoint(endpointID string, es *EndpointState, ns *NetworkState) {
+	if es.VhostSocket != "" || es.Representor != "" || ns.InternalPorts {
+		return
+	}
+	veth := vethPair(truncateID(endpointID))
+	if portUUIDForName(veth.Name) != "" {
+		return
+	}
+	link, err := netlink.LinkByName(veth.Name)
+	if err != nil || link.Type() != "veth" {
+		return
+	}
+	if _, err := netlink.LinkByName(veth.PeerName); err == nil {
+		return
+	}
+	log.Warnf("port [ %s ] of endpoint [ %s ] is gone, attaching it to bridge [ %s ] again", veth.Name, truncateID(endpointID), ns.BridgeName)
+	if err := d.addOvsVethPort(context.Background(), ns.BridgeName, veth.Name, 0); err != nil {
+		log.Errorf("failed to attach %s to bridge %s: %v", veth.Name, ns.BridgeName, err)
+		return
+	}
+	if err := d.recordEndpoint(veth.Name, es.NetworkID, endpointID, veth.PeerName); err != nil {
+		log.Warnf("failed to record endpoint %s on its port: %v", truncateID(endpointID), err)
+	}
+	if es.IngressRate > 0 {
+		if err := d.ovsdber.setIngressPolicing(veth.Name, es.IngressRate, es.IngressBurst); err != nil {
+			log.Errorf("failed to rate limit endpoint %s: %v", truncateID(endpointID), err)
+		}
+	}
+	if ns.PortSecurity {
+		if err := d.applyPortSecurity(endpointID, ns.BridgeName, veth); err != nil {
+			log.Errorf("failed to apply port security to endpoint %s: %v", truncateID(endpointID), err)
+		}
+	} else if es.DSCP > 0 {
+		if err := applyDSCP(endpointID, ns.BridgeName, veth.Name, es.DSCP); err != nil {
+			log.Errorf("failed to mark traffic of endpoint %s: %v", truncateID(endpointID), err)
+		}
+	}
+	if es.QoSQueue > 0 {
+		if err := applyQueue(endpointID, ns.BridgeName, veth.Name, es.QoSQueue); err != nil {
+			log.Errorf("failed to queue traffic of endpoint %s: %v", truncateID(endpointID), err)
+		}
+	}
+	if err := d.applyEndpointSecurity(endpointID, ns.BridgeName); err != nil {
+		log.Errorf("%v", err)
+	}
+	go d.applyEndpointPolicies(es.NetworkID, endpointID, ns.BridgeName)
+}
+
+// linkHasAddr reports whether a link has the address given in CIDR
+// notation.
+func linkHasAddr(link netlink.Link, cidr string) bool {
+	want, err := netlink.ParseAddr(cidr)
+	if err != nil {
+		return false
+	}
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if addr.IPNet.String() == want.IPNet.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// gatewayServiceActive reports whether the gateway unit exists and runs.
+func gatewayServiceActive() bool {
+	if !gatewayServiceInstalled() {
+		return false
+	}
+	if !gatewayUnits {
+		return true
+	}
+	return exec.Command("systemctl", "is-active", "--quiet", gatewayUnit()).Run() == nil
+}
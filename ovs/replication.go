@@ -0,0 +1,298 @@
+package ovs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	replicationSecretHeader = "X-Replication-Secret"
+	replicationResync       = 30 * time.Second
+	replicationTimeout      = 10 * time.Second
+)
+
+// replicaMu serializes applying and activating replicated state
+var replicaMu sync.Mutex
+
+// ReplicaState is the state an active gateway host pushes to its warm
+// standby: its networks and endpoints, including the floating ip and port
+// mapping bindings of the endpoints.
+type ReplicaState struct {
+	Source    string
+	Networks  map[string]*NetworkState
+	Endpoints map[string]*EndpointState
+}
+
+// ActivateResult lists the replicated networks and endpoints activated on
+// failover and those that failed, with the reason.
+type ActivateResult struct {
+	Activated []string          `json:",omitempty"`
+	Failed    map[string]string `json:",omitempty"`
+}
+
+// replicate schedules a push of the state to the standby peer, if any.
+// Pushes are coalesced, so it is cheap to call after every change.
+func (d *Driver) replicate() {
+	if d.standbyPeer == "" {
+		return
+	}
+	select {
+	case d.replicaSignal <- struct{}{}:
+	default:
+	}
+}
+
+// runReplication pushes the full state to the standby peer after every
+// change, and periodically so that a restarted standby catches up.
+func (d *Driver) runReplication() {
+	ticker := time.NewTicker(replicationResync)
+	for {
+		select {
+		case <-d.replicaSignal:
+		case <-ticker.C:
+		}
+		if err := d.pushReplica(); err != nil {
+			log.Warnf("failed to replicate state to %s: %v", d.standbyPeer, err)
+		}
+	}
+}
+
+// replicaSnapshot returns copies of the networks and endpoints the host
+// runs, taken under the driver's lock so they can be encoded while docker
+// calls change them. Those it holds as a standby are not active yet.
+func (d *Driver) replicaSnapshot() ReplicaState {
+	host, _ := os.Hostname()
+	networks, endpoints := d.copyStates()
+	return ReplicaState{Source: host, Networks: networks, Endpoints: endpoints}
+}
+
+// replicationTLSConfig returns the TLS config of the replication listener
+// or of pushes to it. The hosts of the pair authenticate each other with
+// certificates of the pair's CA.
+func (d *Driver) replicationTLSConfig(server bool) (*tls.Config, error) {
+	if d.replicationCert == "" || d.replicationKey == "" || d.replicationCA == "" {
+		return nil, errors.New("replication requires a certificate, key and CA certificate")
+	}
+	if server {
+		return serverTLSConfig(d.replicationCert, d.replicationKey, d.replicationCA)
+	}
+	return clientTLSConfig(d.replicationCert, d.replicationKey, d.replicationCA)
+}
+
+func (d *Driver) pushReplica() error {
+	body, err := json.Marshal(d.replicaSnapshot())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", "https://"+d.standbyPeer+"/replica", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(replicationSecretHeader, d.replicationSecret)
+	client := &http.Client{
+		Timeout:   replicationTimeout,
+		Transport: &http.Transport{TLSClientConfig: d.replicationTLS},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var res map[string]string
+		json.NewDecoder(resp.Body).Decode(&res)
+		return fmt.Errorf("standby answered %s: %s", resp.Status, res["Err"])
+	}
+	log.Debugf("replicated %d bytes of state to %s", len(body), d.standbyPeer)
+	return nil
+}
+
+// applyReplica keeps the networks and endpoints of the active host until
+// the standby is activated. Nothing is programmed before: the bridges, NAT
+// rules and pool routes of the networks would compete with those of the
+// active host for the same addresses.
+func (d *Driver) applyReplica(state ReplicaState) error {
+	replicaMu.Lock()
+	defer replicaMu.Unlock()
+
+	networks := make(map[string]*NetworkState)
+	for id, ns := range state.Networks {
+		if _, ok := d.network(id); ok {
+			log.Warnf("network %s of %s is also active on this host, not replicating it", truncateID(id), state.Source)
+			continue
+		}
+		networks[id] = ns
+	}
+	endpoints := make(map[string]*EndpointState)
+	for id, es := range state.Endpoints {
+		if _, ok := networks[es.NetworkID]; ok {
+			endpoints[id] = es
+		}
+	}
+	d.replicaNetworks = networks
+	d.replicaEndpoints = endpoints
+	log.Debugf("holding %d replicated networks and %d endpoints of %s", len(networks), len(endpoints), state.Source)
+	return nil
+}
+
+// activateNetwork sets up a replicated network like CreateNetwork does.
+// Host local settings are reset.
+func (d *Driver) activateNetwork(id string, ns *NetworkState) error {
+	reconcileMu.Lock()
+	defer reconcileMu.Unlock()
+	if _, ok := d.network(id); ok {
+		return errors.New("network is already active on this host")
+	}
+	if ns.Node != "" {
+		// the bridge on the node outlives the failed host
+		d.setNetwork(id, ns)
+		return nil
+	}
+	ns.QoSPort = ""
+	if err := d.ovsdber.claimBindInterface(id, ns); err != nil {
+		return err
+	}
+	ns.CTZone = d.allocateCTZone(id)
+	d.setNetwork(id, ns)
+	if err := d.initBridge(context.Background(), id); err != nil {
+		d.ovsdber.releaseBindInterface(id, ns)
+		d.forgetNetwork(id)
+		return err
+	}
+	if err := d.setupPoolRoutes(ns); err != nil {
+		return fmt.Errorf("failed to route the pools of replicated network %s: %v", truncateID(id), err)
+	}
+	log.Infof("Activated replicated network [ %s ] on bridge [ %s ]", truncateID(id), ns.BridgeName)
+	return nil
+}
+
+// activateReplica takes over from the failed active host: the networks
+// are set up, then published ports are mapped and floating ips claimed and
+// announced.
+func (d *Driver) activateReplica() *ActivateResult {
+	replicaMu.Lock()
+	defer replicaMu.Unlock()
+
+	res := &ActivateResult{Failed: make(map[string]string)}
+	for id, ns := range d.replicaNetworks {
+		if err := d.activateNetwork(id, ns); err != nil {
+			log.Errorf("failed to activate replicated network %s: %v", truncateID(id), err)
+			res.Failed[id] = err.Error()
+			continue
+		}
+		delete(d.replicaNetworks, id)
+		res.Activated = append(res.Activated, id)
+	}
+	for id, es := range d.replicaEndpoints {
+		if _, ok := d.replicaNetworks[es.NetworkID]; ok {
+			res.Failed[id] = "its network could not be activated"
+			continue
+		}
+		bindings := es.PortMappings
+		es.PortMappings = nil
+		es.Uplink = ""
+		err := d.activateEndpoint(id, es, bindings)
+		if err != nil {
+			res.Failed[id] = err.Error()
+			continue
+		}
+		d.setEndpoint(id, es)
+		delete(d.replicaEndpoints, id)
+		res.Activated = append(res.Activated, id)
+	}
+	log.Infof("Activated %d replicated networks and endpoints, %d failed", len(res.Activated), len(res.Failed))
+	d.notifyController()
+	return res
+}
+
+func (d *Driver) activateEndpoint(endpointID string, es *EndpointState, bindings []portBinding) error {
+	for _, b := range bindings {
+		if err := d.firewall.programPortMapping(context.Background(), true, es.NetworkID, es.BridgeName, es.Address, b); err != nil {
+			d.removePortMappings(context.Background(), es)
+			return err
+		}
+		es.PortMappings = append(es.PortMappings, b)
+	}
+	if len(bindings) > 0 {
+		if err := d.ovsdber.recordPortMappings(endpointID, es); err != nil {
+			d.removePortMappings(context.Background(), es)
+			return err
+		}
+	}
+	if es.FloatingIP != "" {
+		if err := d.bindFloatingIP(context.Background(), endpointID, es); err != nil {
+			d.removePortMappings(context.Background(), es)
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeReplication serves the replication API to the active host of the
+// pair on a TCP address, over TLS. Clients must present a certificate of
+// the pair's CA and requests must carry the shared secret.
+func (d *Driver) ServeReplication(addr string) error {
+	tlsConfig, err := d.replicationTLSConfig(true)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/replica", d.handleReplica)
+	mux.HandleFunc("/replica/activate", d.handleActivate)
+	server := &http.Server{Addr: addr, Handler: d.checkReplicationSecret(mux), TLSConfig: tlsConfig}
+	log.Infof("Serving replication API on [ %s ]", addr)
+	return server.ListenAndServeTLS("", "")
+}
+
+func (d *Driver) checkReplicationSecret(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hmac.Equal([]byte(r.Header.Get(replicationSecretHeader)), []byte(d.replicationSecret)) {
+			writeError(w, http.StatusForbidden, errors.New("invalid replication secret"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// handleReplica serves /replica: POST applies the ReplicaState of the
+// active host, GET returns the state this host would replicate.
+func (d *Driver) handleReplica(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, d.replicaSnapshot())
+	case "POST":
+		var state ReplicaState
+		if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := d.applyReplica(state); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+	}
+}
+
+// handleActivate serves /replica/activate, POST fails over to this host.
+func (d *Driver) handleActivate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, d.activateReplica())
+}
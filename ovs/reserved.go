@@ -0,0 +1,122 @@
+package ovs
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+)
+
+const (
+	reservedOption = "linker.net.ovs.reserved"
+)
+
+// ipRange is an inclusive range of addresses
+type ipRange struct {
+	Start net.IP
+	End   net.IP
+}
+
+func (r ipRange) contains(ip net.IP) bool {
+	ip = ip.To16()
+	return bytes.Compare(ip, r.Start.To16()) >= 0 && bytes.Compare(ip, r.End.To16()) <= 0
+}
+
+func (r ipRange) String() string {
+	if r.Start.Equal(r.End) {
+		return r.Start.String()
+	}
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// getReservedRanges returns the addresses no endpoint may use: the network
+// and broadcast addresses of the IPv4 pools, the auxiliary addresses and the
+// ranges given with linker.net.ovs.reserved as a comma separated list of
+// addresses, start-end ranges or CIDRs. Docker still allocates the addresses
+// so it has to be told about them, e.g. with --ip-range or --aux-address,
+// the plugin refuses endpoints it hands out anyway. Port security never
+// accepts a reserved address as a consequence.
+func getReservedRanges(r *dknet.CreateNetworkRequest) ([]ipRange, error) {
+	var ranges []ipRange
+	for _, data := range r.IPv4Data {
+		if data == nil {
+			continue
+		}
+		if _, pool, err := net.ParseCIDR(data.Pool); err == nil {
+			ranges = append(ranges, ipRange{Start: pool.IP, End: pool.IP})
+			broadcast := make(net.IP, len(pool.IP))
+			for i := range pool.IP {
+				broadcast[i] = pool.IP[i] | ^pool.Mask[i]
+			}
+			ranges = append(ranges, ipRange{Start: broadcast, End: broadcast})
+		}
+		for _, aux := range data.AuxAddresses {
+			if s, ok := aux.(string); ok {
+				if ip := parseAddress(s); ip != nil {
+					ranges = append(ranges, ipRange{Start: ip, End: ip})
+				}
+			}
+		}
+	}
+
+	value := getStringOption(r, reservedOption)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		reserved, err := parseIPRange(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %v", reservedOption, entry, err)
+		}
+		ranges = append(ranges, reserved)
+	}
+	return ranges, nil
+}
+
+func parseIPRange(entry string) (ipRange, error) {
+	if strings.Contains(entry, "/") {
+		_, subnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return ipRange{}, err
+		}
+		end := make(net.IP, len(subnet.IP))
+		for i := range subnet.IP {
+			end[i] = subnet.IP[i] | ^subnet.Mask[i]
+		}
+		return ipRange{Start: subnet.IP, End: end}, nil
+	}
+	parts := strings.SplitN(entry, "-", 2)
+	start := net.ParseIP(strings.TrimSpace(parts[0]))
+	end := start
+	if len(parts) == 2 {
+		end = net.ParseIP(strings.TrimSpace(parts[1]))
+	}
+	if start == nil || end == nil {
+		return ipRange{}, fmt.Errorf("not an address or range")
+	}
+	if bytes.Compare(start.To16(), end.To16()) > 0 {
+		return ipRange{}, fmt.Errorf("range start is after its end")
+	}
+	return ipRange{Start: start, End: end}, nil
+}
+
+// parseAddress accepts an address with or without a prefix length.
+func parseAddress(s string) net.IP {
+	if ip, _, err := net.ParseCIDR(s); err == nil {
+		return ip
+	}
+	return net.ParseIP(s)
+}
+
+// reservedRange returns the reserved range holding ip, if any.
+func (ns *NetworkState) reservedRange(ip net.IP) (ipRange, bool) {
+	for _, r := range ns.Reserved {
+		if r.contains(ip) {
+			return r, true
+		}
+	}
+	return ipRange{}, false
+}
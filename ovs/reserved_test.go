@@ -0,0 +1,37 @@
+package ovs
+
+import "testing"
+
+func TestParseIPRange(t *testing.T) {
+	tests := []struct {
+		entry   string
+		start   string
+		end     string
+		wantErr bool
+	}{
+		{entry: "10.0.0.5", start: "10.0.0.5", end: "10.0.0.5"},
+		{entry: "10.0.0.10-10.0.0.20", start: "10.0.0.10", end: "10.0.0.20"},
+		{entry: "10.0.0.10 - 10.0.0.20", start: "10.0.0.10", end: "10.0.0.20"},
+		{entry: "10.0.1.0/24", start: "10.0.1.0", end: "10.0.1.255"},
+		{entry: "10.0.1.7/30", start: "10.0.1.4", end: "10.0.1.7"},
+		{entry: "fd00::/126", start: "fd00::", end: "fd00::3"},
+		{entry: "fd00::1-fd00::9", start: "fd00::1", end: "fd00::9"},
+		{entry: "10.0.0.20-10.0.0.10", wantErr: true},
+		{entry: "10.0.0.10-", wantErr: true},
+		{entry: "10.0.1.0/33", wantErr: true},
+		{entry: "gateway", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseIPRange(tt.entry)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseIPRange(%q) error = %v, want error %v", tt.entry, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got.Start.String() != tt.start || got.End.String() != tt.end {
+			t.Errorf("parseIPRange(%q) = %s-%s, want %s-%s", tt.entry, got.Start, got.End, tt.start, tt.end)
+		}
+	}
+}
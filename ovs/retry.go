@@ -0,0 +1,61 @@
+package ovs
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy is how connecting to ovsdb-server is retried. Each delay is
+// twice the one before, up to MaxBackoff.
+type RetryPolicy struct {
+	// Retries is how many times a failed connection is retried, ignored
+	// when Forever is set
+	Retries int
+	// Forever retries until ovsdb-server is up, for plugins started before
+	// openvswitch on boot
+	Forever    bool
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction of it, so hosts
+	// started together don't retry in step
+	Jitter float64
+}
+
+// defaultRetryPolicy is used when Config leaves the policy empty, three
+// attempts five seconds apart.
+var defaultRetryPolicy = RetryPolicy{Retries: 2, Backoff: 5 * time.Second, MaxBackoff: 5 * time.Second}
+
+// withDefaults fills in an empty policy, and a MaxBackoff below Backoff.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Backoff <= 0 {
+		p.Backoff = defaultRetryPolicy.Backoff
+		if p.Retries == 0 && !p.Forever {
+			p.Retries = defaultRetryPolicy.Retries
+		}
+	}
+	if p.MaxBackoff < p.Backoff {
+		p.MaxBackoff = p.Backoff
+	}
+	return p
+}
+
+// retry reports whether another attempt follows the given failed one,
+// counted from 0.
+func (p RetryPolicy) retry(attempt int) bool {
+	return p.Forever || attempt < p.Retries
+}
+
+// delay is the wait after the given failed attempt, counted from 0.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 0; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
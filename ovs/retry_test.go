@@ -0,0 +1,42 @@
+package ovs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: time.Second}, 0, time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: time.Second}, 5, time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: time.Minute}, 0, time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: time.Minute}, 1, 2 * time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: time.Minute}, 3, 8 * time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: 10 * time.Second}, 4, 10 * time.Second},
+		// doubling stops at the cap, however many attempts failed
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: time.Minute}, 1000, time.Minute},
+		{RetryPolicy{}.withDefaults(), 2, defaultRetryPolicy.Backoff},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.delay(tt.attempt); got != tt.want {
+			t.Errorf("%+v.delay(%d) = %s, want %s", tt.policy, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 8 * time.Second, Jitter: 0.25}
+	for attempt := 0; attempt < 5; attempt++ {
+		base := RetryPolicy{Backoff: p.Backoff, MaxBackoff: p.MaxBackoff}.delay(attempt)
+		for i := 0; i < 100; i++ {
+			got := p.delay(attempt)
+			if got < base*3/4 || got > base*5/4 {
+				t.Fatalf("delay(%d) = %s, want within 25%% of %s", attempt, got, base)
+			}
+		}
+	}
+}
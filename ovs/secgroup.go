@@ -0,0 +1,308 @@
+package ovs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	// allowOption lists the inbound traffic an endpoint accepts, as
+	// proto[:port[:cidr]] rules separated by commas or semicolons, e.g.
+	// tcp:80:10.0.0.0/8,icmp. Everything else towards the endpoint is
+	// dropped, replies to connections the endpoint opened are always
+	// accepted. Rules without a cidr apply to IPv4 and IPv6.
+	allowOption = "linker.net.ovs.allow"
+	// allowEgressOption lists the outbound connections an endpoint may
+	// open, with the same syntax where the cidr is the destination.
+	// Replies to inbound connections the endpoint accepted are always let
+	// out.
+	allowEgressOption = "linker.net.ovs.allow_egress"
+
+	secGroupAllowPriority = 320
+	secGroupDropPriority  = 315
+	secGroupTrackPriority = 150
+
+	// egress rules are checked in the port security table, after the
+	// anti-spoofing and DSCP flows admitted the traffic
+	egressAllowPriority = 110
+	egressDropPriority  = 105
+)
+
+// ipFamily holds the OpenFlow names of the protocol and address fields of
+// IPv4 or IPv6.
+type ipFamily struct {
+	ip, src, dst string
+	protos       map[string]string
+}
+
+var (
+	ipv4Family = ipFamily{ip: "ip", src: "nw_src", dst: "nw_dst",
+		protos: map[string]string{"ip": "ip", "tcp": "tcp", "udp": "udp", "sctp": "sctp", "icmp": "icmp"}}
+	ipv6Family = ipFamily{ip: "ipv6", src: "ipv6_src", dst: "ipv6_dst",
+		protos: map[string]string{"ip": "ipv6", "tcp": "tcp6", "udp": "udp6", "sctp": "sctp6", "icmp": "icmp6"}}
+)
+
+// familyOf returns the family of an address or CIDR.
+func familyOf(address string) ipFamily {
+	if strings.Contains(address, ":") {
+		return ipv6Family
+	}
+	return ipv4Family
+}
+
+// secGroupRule is a single allow rule of a security group
+type secGroupRule struct {
+	Proto string
+	Port  int
+	CIDR  string
+}
+
+// parseSecGroupRules parses the value of the allow options.
+func parseSecGroupRules(value string) ([]secGroupRule, error) {
+	var rules []secGroupRule
+	for _, entry := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// the cidr of an IPv6 rule has colons of its own
+		parts := strings.SplitN(entry, ":", 3)
+		rule := secGroupRule{Proto: strings.ToLower(parts[0])}
+		switch rule.Proto {
+		case "tcp", "udp", "sctp", "icmp", "ip":
+		default:
+			return nil, fmt.Errorf("unsupported protocol in security group rule %s", entry)
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			port, err := strconv.Atoi(parts[1])
+			if err != nil || port < 1 || port > 65535 {
+				return nil, fmt.Errorf("invalid port in security group rule %s", entry)
+			}
+			if rule.Proto == "icmp" || rule.Proto == "ip" {
+				return nil, fmt.Errorf("a port is not allowed for %s in security group rule %s", rule.Proto, entry)
+			}
+			rule.Port = port
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			_, cidr, err := net.ParseCIDR(parts[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid cidr in security group rule %s", entry)
+			}
+			rule.CIDR = cidr.String()
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// getSecGroupOptions returns the inbound and outbound rules of an endpoint,
+// checked so that a typo fails the endpoint rather than its Join.
+func getSecGroupOptions(options map[string]interface{}) (string, string, error) {
+	allow := endpointStringOption(options, allowOption)
+	allowEgress := endpointStringOption(options, allowEgressOption)
+	for _, value := range []string{allow, allowEgress} {
+		if _, err := parseSecGroupRules(value); err != nil {
+			return "", "", err
+		}
+	}
+	return allow, allowEgress, nil
+}
+
+// match renders the rule as an OpenFlow match towards the address, false
+// if the rule is for the other address family.
+func (r secGroupRule) match(address string) (string, bool) {
+	return r.render(address, true)
+}
+
+// egressMatch renders the rule as an OpenFlow match from the address.
+func (r secGroupRule) egressMatch(address string) (string, bool) {
+	return r.render(address, false)
+}
+
+func (r secGroupRule) render(address string, inbound bool) (string, bool) {
+	family := familyOf(address)
+	if r.CIDR != "" && familyOf(r.CIDR).ip != family.ip {
+		return "", false
+	}
+	local, remote := family.dst, family.src
+	if !inbound {
+		local, remote = family.src, family.dst
+	}
+	m := fmt.Sprintf("%s,%s=%s", family.protos[r.Proto], local, address)
+	if r.CIDR != "" {
+		m += "," + remote + "=" + r.CIDR
+	}
+	if r.Port != 0 {
+		m += fmt.Sprintf(",tp_dst=%d", r.Port)
+	}
+	return m, true
+}
+
+// endpointAddresses returns the IPv4 and, if it has one, the IPv6 address
+// of an endpoint.
+func endpointAddresses(es *EndpointState) []string {
+	addresses := []string{es.Address}
+	if es.AddressIPv6 != "" {
+		addresses = append(addresses, es.AddressIPv6)
+	}
+	return addresses
+}
+
+// applyEndpointSecurity installs the security group and egress rules an
+// endpoint was created with. It runs in Join, so the endpoint never
+// carries traffic its rules would not allow.
+func (d *Driver) applyEndpointSecurity(endpointID, bridgeName string) error {
+	es, ok := d.endpoint(endpointID)
+	if !ok {
+		return nil
+	}
+	if es.Allow != "" {
+		if err := d.applySecGroup(endpointID, bridgeName, es.Allow); err != nil {
+			return fmt.Errorf("failed to apply security group to endpoint %s: %v", truncateID(endpointID), err)
+		}
+	}
+	if es.AllowEgress != "" {
+		if err := d.applyEgressACL(endpointID, bridgeName, es.AllowEgress); err != nil {
+			return fmt.Errorf("failed to apply egress rules to endpoint %s: %v", truncateID(endpointID), err)
+		}
+	}
+	return nil
+}
+
+// applySecGroup installs the conntrack based security group of an endpoint.
+// New inbound connections are only let through when a rule allows them,
+// all traffic the endpoint sends is committed so replies are established.
+// An endpoint without an IPv6 address accepts no IPv6 traffic but
+// neighbor discovery.
+func (d *Driver) applySecGroup(endpointID, bridgeName, value string) error {
+	rules, err := parseSecGroupRules(value)
+	if err != nil {
+		return err
+	}
+	es, ok := d.endpoint(endpointID)
+	if !ok || es.Address == "" {
+		return fmt.Errorf("no address known for endpoint %s", truncateID(endpointID))
+	}
+	zone, err := d.ctZone(es.NetworkID)
+	if err != nil {
+		return err
+	}
+	icc := true
+	if ns, ok := d.network(es.NetworkID); ok {
+		icc = ns.ICC
+	}
+
+	flows := secGroupFlows(endpointID, es, rules, zone, icc)
+	for _, flow := range flows {
+		if err := addFlow(bridgeName, policyTable, flow); err != nil {
+			return err
+		}
+	}
+	log.Infof("Applied security group [ %s ] to endpoint [ %s ]", value, truncateID(endpointID))
+	return nil
+}
+
+// secGroupFlows returns the policy table flows of applySecGroup. On a
+// bridge without inter-container communication the traffic a rule lets
+// through is marked in reg4 and goes back to the policy table instead of
+// being switched, and the flows only match unmarked traffic, so the ICC
+// flows still keep endpoints apart.
+func secGroupFlows(endpointID string, es *EndpointState, rules []secGroupRule, zone int, icc bool) []string {
+	cookie := flowCookie(endpointID)
+	allow, unmarked := "NORMAL", ""
+	if !icc {
+		allow, unmarked = fmt.Sprintf("load:1->NXM_NX_REG4[],resubmit(,%d)", policyTable), "reg4=0,"
+	}
+
+	var flows []string
+	for _, addr := range endpointAddresses(es) {
+		family := familyOf(addr)
+		flows = append(flows,
+			fmt.Sprintf("cookie=%s,priority=%d,%s%s,%s=%s,ct_state=-trk,actions=%s", cookie, secGroupAllowPriority, unmarked, family.ip, family.dst, addr, ctTrack(zone, policyTable)),
+			fmt.Sprintf("cookie=%s,priority=%d,%s%s,%s=%s,ct_state=+trk+est,actions=%s", cookie, secGroupAllowPriority, unmarked, family.ip, family.dst, addr, allow),
+			fmt.Sprintf("cookie=%s,priority=%d,%s%s,%s=%s,ct_state=+trk+rel,actions=%s", cookie, secGroupAllowPriority, unmarked, family.ip, family.dst, addr, allow),
+		)
+		for _, rule := range rules {
+			if match, ok := rule.match(addr); ok {
+				flows = append(flows, fmt.Sprintf("cookie=%s,priority=%d,%s%s,ct_state=+trk+new,actions=%s,%s",
+					cookie, secGroupAllowPriority, unmarked, match, ctCommit(zone), allow))
+			}
+		}
+		flows = append(flows,
+			fmt.Sprintf("cookie=%s,priority=%d,%s%s,%s=%s,ct_state=+trk,actions=drop", cookie, secGroupDropPriority, unmarked, family.ip, family.dst, addr),
+			fmt.Sprintf("cookie=%s,priority=%d,%s,%s=%s,actions=%s,NORMAL", cookie, secGroupTrackPriority, family.ip, family.src, addr, ctCommit(zone)),
+		)
+	}
+	if es.AddressIPv6 == "" && es.MacAddress != "" {
+		flows = append(flows,
+			fmt.Sprintf("cookie=%s,priority=%d,%sicmp6,dl_dst=%s,icmp_type=135,actions=%s", cookie, secGroupAllowPriority, unmarked, es.MacAddress, allow),
+			fmt.Sprintf("cookie=%s,priority=%d,%sicmp6,dl_dst=%s,icmp_type=136,actions=%s", cookie, secGroupAllowPriority, unmarked, es.MacAddress, allow),
+			fmt.Sprintf("cookie=%s,priority=%d,%sipv6,dl_dst=%s,actions=drop", cookie, secGroupDropPriority, unmarked, es.MacAddress),
+		)
+	}
+	return flows
+}
+
+// applyEgressACL restricts the connections an endpoint may open. IP traffic
+// from its port is sent through conntrack before it reaches the policy
+// table, new connections must match a rule while established and related
+// traffic passes, so replies need no rule of their own. IP traffic from
+// any other source address is dropped as well, IPv6 traffic too but for
+// neighbor discovery.
+func (d *Driver) applyEgressACL(endpointID, bridgeName, value string) error {
+	rules, err := parseSecGroupRules(value)
+	if err != nil {
+		return err
+	}
+	es, ok := d.endpoint(endpointID)
+	if !ok || es.Address == "" {
+		return fmt.Errorf("no address known for endpoint %s", truncateID(endpointID))
+	}
+	cookie := flowCookie(endpointID)
+	zone, err := d.ctZone(es.NetworkID)
+	if err != nil {
+		return err
+	}
+	port := ovsPortPrefix + truncateID(endpointID)
+
+	var flows, commits []string
+	for _, addr := range endpointAddresses(es) {
+		family := familyOf(addr)
+		flows = append(flows,
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,%s,ct_state=-trk,actions=%s", cookie, egressAllowPriority, port, family.ip, ctTrack(zone, portSecurityTable)),
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,%s,%s=%s,ct_state=+trk+est,actions=resubmit(,%d)", cookie, egressAllowPriority, port, family.ip, family.src, addr, policyTable),
+			fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,%s,%s=%s,ct_state=+trk+rel,actions=resubmit(,%d)", cookie, egressAllowPriority, port, family.ip, family.src, addr, policyTable),
+		)
+		for _, rule := range rules {
+			if match, ok := rule.egressMatch(addr); ok {
+				flows = append(flows, fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,%s,ct_state=+trk+new,actions=resubmit(,%d)",
+					cookie, egressAllowPriority, port, match, policyTable))
+			}
+		}
+		// the policy table commits what was let out, as for security groups
+		commits = append(commits, fmt.Sprintf("cookie=%s,priority=%d,%s,%s=%s,actions=%s,NORMAL",
+			cookie, secGroupTrackPriority, family.ip, family.src, addr, ctCommit(zone)))
+	}
+	flows = append(flows,
+		fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,icmp6,icmp_type=135,actions=resubmit(,%d)", cookie, egressAllowPriority+5, port, policyTable),
+		fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,icmp6,icmp_type=136,actions=resubmit(,%d)", cookie, egressAllowPriority+5, port, policyTable),
+		fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,ip,actions=drop", cookie, egressDropPriority, port),
+		fmt.Sprintf("cookie=%s,priority=%d,in_port=%s,ipv6,actions=drop", cookie, egressDropPriority, port),
+	)
+	for _, flow := range flows {
+		if err := addFlow(bridgeName, portSecurityTable, flow); err != nil {
+			return err
+		}
+	}
+	for _, flow := range commits {
+		if err := addFlow(bridgeName, policyTable, flow); err != nil {
+			return err
+		}
+	}
+	log.Infof("Applied egress rules [ %s ] to endpoint [ %s ]", value, truncateID(endpointID))
+	return nil
+}
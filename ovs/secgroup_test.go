@@ -0,0 +1,184 @@
+package ovs
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSecGroupRuleMatch(t *testing.T) {
+	tests := []struct {
+		rule    secGroupRule
+		address string
+		inbound string
+		egress  string
+		skipped bool
+	}{
+		{
+			rule:    secGroupRule{Proto: "tcp", Port: 80},
+			address: "10.0.0.2",
+			inbound: "tcp,nw_dst=10.0.0.2,tp_dst=80",
+			egress:  "tcp,nw_src=10.0.0.2,tp_dst=80",
+		},
+		{
+			rule:    secGroupRule{Proto: "tcp", Port: 80},
+			address: "fd00::2",
+			inbound: "tcp6,ipv6_dst=fd00::2,tp_dst=80",
+			egress:  "tcp6,ipv6_src=fd00::2,tp_dst=80",
+		},
+		{
+			rule:    secGroupRule{Proto: "icmp", CIDR: "fd00::/64"},
+			address: "fd00::2",
+			inbound: "icmp6,ipv6_dst=fd00::2,ipv6_src=fd00::/64",
+			egress:  "icmp6,ipv6_src=fd00::2,ipv6_dst=fd00::/64",
+		},
+		{
+			rule:    secGroupRule{Proto: "ip", CIDR: "10.0.0.0/8"},
+			address: "10.0.0.2",
+			inbound: "ip,nw_dst=10.0.0.2,nw_src=10.0.0.0/8",
+			egress:  "ip,nw_src=10.0.0.2,nw_dst=10.0.0.0/8",
+		},
+		// a rule for one family doesn't apply to the other
+		{rule: secGroupRule{Proto: "udp", CIDR: "10.0.0.0/8"}, address: "fd00::2", skipped: true},
+		{rule: secGroupRule{Proto: "udp", CIDR: "fd00::/64"}, address: "10.0.0.2", skipped: true},
+	}
+	for _, tt := range tests {
+		inbound, ok := tt.rule.match(tt.address)
+		if ok == tt.skipped {
+			t.Errorf("%+v.match(%s) applies = %t, want %t", tt.rule, tt.address, ok, !tt.skipped)
+			continue
+		}
+		egress, _ := tt.rule.egressMatch(tt.address)
+		if inbound != tt.inbound || egress != tt.egress {
+			t.Errorf("%+v on %s = %q, %q, want %q, %q", tt.rule, tt.address, inbound, egress, tt.inbound, tt.egress)
+		}
+	}
+}
+
+func TestParseSecGroupRules(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []secGroupRule
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "tcp:80", want: []secGroupRule{{Proto: "tcp", Port: 80}}},
+		{
+			value: "TCP:443:10.0.0.0/8, udp:53;icmp",
+			want:  []secGroupRule{{Proto: "tcp", Port: 443, CIDR: "10.0.0.0/8"}, {Proto: "udp", Port: 53}, {Proto: "icmp"}},
+		},
+		// the cidr is normalized to its network address
+		{value: "ip::192.168.1.7/24", want: []secGroupRule{{Proto: "ip", CIDR: "192.168.1.0/24"}}},
+		{value: "tcp:22:fd00::/64", want: []secGroupRule{{Proto: "tcp", Port: 22, CIDR: "fd00::/64"}}},
+		{value: "gre", wantErr: true},
+		{value: "tcp:0", wantErr: true},
+		{value: "tcp:65536", wantErr: true},
+		{value: "tcp:http", wantErr: true},
+		{value: "icmp:8", wantErr: true},
+		{value: "udp:53:10.0.0.0/33", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSecGroupRules(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSecGroupRules(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSecGroupRules(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// walkPolicyTable follows a packet through the policy table flows as the
+// switch would, through conntrack recirculations and reg4 resubmits, and
+// returns the actions of the flow that finally handles it. Match fields
+// compare as strings, ct_state flags must all be set on the packet.
+func walkPolicyTable(t *testing.T, flows []string, packet map[string]string) string {
+	for pass := 0; pass < 4; pass++ {
+		best, bestPriority := "", -1
+		for _, flow := range flows {
+			parts := strings.SplitN(flow, ",actions=", 2)
+			priority, matches := 0, true
+			for _, field := range strings.Split(parts[0], ",") {
+				kv := strings.SplitN(field, "=", 2)
+				switch {
+				case kv[0] == "cookie":
+				case kv[0] == "priority":
+					priority, _ = strconv.Atoi(kv[1])
+				case len(kv) == 1:
+					_, ok := packet[kv[0]]
+					matches = matches && ok
+				case kv[0] == "ct_state":
+					for _, flag := range []string{"+trk", "-trk", "+new", "+est", "+rel"} {
+						if strings.Contains(kv[1], flag) && !strings.Contains(packet["ct_state"], flag) {
+							matches = false
+						}
+					}
+				default:
+					matches = matches && packet[kv[0]] == kv[1]
+				}
+			}
+			if matches && priority > bestPriority {
+				best, bestPriority = parts[1], priority
+			}
+		}
+		switch {
+		case strings.Contains(best, "ct(zone="):
+			packet["ct_state"] = "+trk" + packet["ct_next"]
+		case strings.Contains(best, "load:1->NXM_NX_REG4[]"):
+			packet["reg4"] = "1"
+		default:
+			return best
+		}
+	}
+	t.Fatalf("packet %v loops in the policy table", packet)
+	return ""
+}
+
+// TestSecGroupICC checks that a security group rule doesn't let endpoints
+// of a bridge without inter-container communication reach each other,
+// while traffic from the uplink still gets through.
+func TestSecGroupICC(t *testing.T) {
+	const uplink = "LOCAL"
+	es := &EndpointState{NetworkID: "net-a0000000", Address: "10.1.0.2", MacAddress: "02:42:0a:01:00:02"}
+	rules, _ := parseSecGroupRules("tcp:80")
+	fromEndpoint := func() map[string]string {
+		return map[string]string{"in_port": "ovs-veth-b", "ip": "", "tcp": "", "nw_src": "10.1.0.3", "nw_dst": es.Address,
+			"tp_dst": "80", "ct_state": "-trk", "ct_next": "+new", "reg4": "0"}
+	}
+
+	for _, icc := range []bool{true, false} {
+		flows := secGroupFlows("ep-a00000000", es, rules, 7, icc)
+		if !icc {
+			iccFlowSet, err := iccFlows(es.NetworkID, uplink, "10.1.0.1/24", 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flows = append(flows, iccFlowSet...)
+		}
+		flows = append(flows, fmt.Sprintf("cookie=%s,priority=0,actions=NORMAL", pipelineCookie))
+
+		actions := walkPolicyTable(t, flows, fromEndpoint())
+		if icc && !strings.HasSuffix(actions, "NORMAL") {
+			t.Errorf("allowed traffic between endpoints is handled by %q, want it switched normally", actions)
+		}
+		if !icc && strings.Contains(actions, "NORMAL") {
+			t.Errorf("traffic between endpoints without ICC is handled by %q, want it sent to the uplink only", actions)
+		}
+
+		packet := fromEndpoint()
+		packet["in_port"] = uplink
+		packet["nw_src"] = "10.1.0.1"
+		if actions := walkPolicyTable(t, flows, packet); !strings.HasSuffix(actions, "NORMAL") {
+			t.Errorf("allowed traffic from the uplink with icc %t is handled by %q, want it switched normally", icc, actions)
+		}
+
+		packet = fromEndpoint()
+		packet["tp_dst"] = "22"
+		if actions := walkPolicyTable(t, flows, packet); actions != "drop" {
+			t.Errorf("traffic no rule allows with icc %t is handled by %q, want drop", icc, actions)
+		}
+	}
+}
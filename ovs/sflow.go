@@ -0,0 +1,101 @@
+package ovs
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// sFlow collectors the bridge sends samples to, as a comma separated
+	// list of ip:port
+	sflowOption = "linker.net.ovs.bridge.sflow"
+	// One in this many packets is sampled, the switch defaults to 400
+	sflowSamplingOption = "linker.net.ovs.bridge.sflow_sampling"
+	// Bytes of each sampled packet's headers sent, the switch defaults to 128
+	sflowHeaderOption = "linker.net.ovs.bridge.sflow_header"
+	// Interface whose address identifies the agent, e.g. the host's uplink
+	sflowAgentOption = "linker.net.ovs.bridge.sflow_agent"
+)
+
+// SFlowConfig is the sFlow export of a network's bridge.
+type SFlowConfig struct {
+	Targets  []string
+	Sampling int    `json:",omitempty"`
+	Header   int    `json:",omitempty"`
+	Agent    string `json:",omitempty"`
+}
+
+// getSFlow returns the sFlow settings of a network, nil if it has none.
+func getSFlow(r *dknet.CreateNetworkRequest) (*SFlowConfig, error) {
+	targets, err := getCollectors(r, sflowOption)
+	if err != nil {
+		return nil, err
+	}
+	sampling, err := getPositiveIntOption(r, sflowSamplingOption)
+	if err != nil {
+		return nil, err
+	}
+	header, err := getPositiveIntOption(r, sflowHeaderOption)
+	if err != nil {
+		return nil, err
+	}
+	agent := getStringOption(r, sflowAgentOption)
+	if len(targets) == 0 {
+		if sampling != 0 || header != 0 || agent != "" {
+			return nil, fmt.Errorf("sFlow options require %s to be set", sflowOption)
+		}
+		return nil, nil
+	}
+	if agent != "" && !validateIface(agent) {
+		return nil, fmt.Errorf("%s %s is not an interface of this host", sflowAgentOption, agent)
+	}
+	return &SFlowConfig{Targets: targets, Sampling: sampling, Header: header, Agent: agent}, nil
+}
+
+func getPositiveIntOption(r *dknet.CreateNetworkRequest, option string) (int, error) {
+	value := getStringOption(r, option)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive number, got %s", option, value)
+	}
+	return n, nil
+}
+
+// setSFlow inserts an sFlow row and references it from the bridge, like
+// setNetFlow.
+func (ovsdber *ovsdber) setSFlow(bridgeName string, config *SFlowConfig) error {
+	sflow := map[string]interface{}{
+		"targets":      collectorSet(config.Targets),
+		"external_ids": ovsdber.ownerExternalIDs(),
+	}
+	if config.Sampling > 0 {
+		sflow["sampling"] = config.Sampling
+	}
+	if config.Header > 0 {
+		sflow["header"] = config.Header
+	}
+	if config.Agent != "" {
+		sflow["agent"] = config.Agent
+	}
+	operations := []libovsdb.Operation{
+		{Op: "insert", Table: "sFlow", Row: sflow, UUIDName: "sflow"},
+		{
+			Op:    "update",
+			Table: "Bridge",
+			Row:   map[string]interface{}{"sflow": libovsdb.UUID{GoUuid: "sflow"}},
+			Where: []interface{}{libovsdb.NewCondition("name", "==", bridgeName)},
+		},
+	}
+	if err := ovsdber.transact(operations...); err != nil {
+		return err
+	}
+	log.Infof("Sampling bridge [ %s ] to sFlow collectors %v", bridgeName, config.Targets)
+	return nil
+}
@@ -0,0 +1,118 @@
+package ovs
+
+// network returns the state of a network.
+func (d *Driver) network(id string) (*NetworkState, bool) {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	ns, ok := d.networks[id]
+	return ns, ok
+}
+
+// setNetwork records the state of a network.
+func (d *Driver) setNetwork(id string, ns *NetworkState) {
+	d.stateMu.Lock()
+	d.networks[id] = ns
+	d.stateMu.Unlock()
+}
+
+// forgetNetwork forgets the state of a network.
+func (d *Driver) forgetNetwork(id string) {
+	d.stateMu.Lock()
+	delete(d.networks, id)
+	d.stateMu.Unlock()
+}
+
+// networkStates returns a copy of the network map, safe to range over
+// while networks come and go.
+func (d *Driver) networkStates() map[string]*NetworkState {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	networks := make(map[string]*NetworkState, len(d.networks))
+	for id, ns := range d.networks {
+		networks[id] = ns
+	}
+	return networks
+}
+
+// endpoint returns the state of an endpoint.
+func (d *Driver) endpoint(id string) (*EndpointState, bool) {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	es, ok := d.endpoints[id]
+	return es, ok
+}
+
+// setEndpoint records the state of an endpoint.
+func (d *Driver) setEndpoint(id string, es *EndpointState) {
+	d.stateMu.Lock()
+	d.endpoints[id] = es
+	d.stateMu.Unlock()
+}
+
+// forgetEndpoint forgets the state of an endpoint.
+func (d *Driver) forgetEndpoint(id string) {
+	d.stateMu.Lock()
+	delete(d.endpoints, id)
+	d.stateMu.Unlock()
+}
+
+// endpointStates returns a copy of the endpoint map, safe to range over
+// while endpoints come and go.
+func (d *Driver) endpointStates() map[string]*EndpointState {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	endpoints := make(map[string]*EndpointState, len(d.endpoints))
+	for id, es := range d.endpoints {
+		endpoints[id] = es
+	}
+	return endpoints
+}
+
+// setChain records the service chain of a network.
+func (d *Driver) setChain(networkID string, chain *serviceChain) {
+	d.stateMu.Lock()
+	d.chains[networkID] = chain
+	d.stateMu.Unlock()
+}
+
+// takeChain removes the service chain of a network and returns it.
+func (d *Driver) takeChain(networkID string) (*serviceChain, bool) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	chain, ok := d.chains[networkID]
+	delete(d.chains, networkID)
+	return chain, ok
+}
+
+// updateNetwork changes the state of a network under the lock, so a
+// snapshot taken meanwhile never sees a half written field.
+func (d *Driver) updateNetwork(ns *NetworkState, update func(ns *NetworkState)) {
+	d.stateMu.Lock()
+	update(ns)
+	d.stateMu.Unlock()
+}
+
+// updateEndpoint changes the state of an endpoint under the lock.
+func (d *Driver) updateEndpoint(es *EndpointState, update func(es *EndpointState)) {
+	d.stateMu.Lock()
+	update(es)
+	d.stateMu.Unlock()
+}
+
+// copyStates returns copies of the network and endpoint states, taken
+// under the lock, that can be encoded while the originals change.
+func (d *Driver) copyStates() (map[string]*NetworkState, map[string]*EndpointState) {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	networks := make(map[string]*NetworkState, len(d.networks))
+	for id, ns := range d.networks {
+		c := *ns
+		networks[id] = &c
+	}
+	endpoints := make(map[string]*EndpointState, len(d.endpoints))
+	for id, es := range d.endpoints {
+		c := *es
+		endpoints[id] = &c
+	}
+	return networks, endpoints
+}
@@ -0,0 +1,81 @@
+package ovs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// spanningTreeOption runs stp or rstp on the bridge, for flat networks
+	// whose uplinks are redundant and would otherwise form a loop
+	spanningTreeOption = "linker.net.ovs.bridge.spanning_tree"
+	// spanningTreePriorityOption is the bridge priority, the lowest becomes
+	// the root. STP takes 0-65535, RSTP a multiple of 4096 up to 61440.
+	spanningTreePriorityOption = "linker.net.ovs.bridge.spanning_tree_priority"
+
+	spanningTreeSTP  = "stp"
+	spanningTreeRSTP = "rstp"
+)
+
+// getSpanningTree returns the spanning tree protocol of a network, empty
+// if it runs none, and the bridge priority.
+func getSpanningTree(r *dknet.CreateNetworkRequest) (string, int, error) {
+	protocol := strings.ToLower(getStringOption(r, spanningTreeOption))
+	switch protocol {
+	case "", "none":
+		if getStringOption(r, spanningTreePriorityOption) != "" {
+			return "", 0, fmt.Errorf("%s requires %s to be set", spanningTreePriorityOption, spanningTreeOption)
+		}
+		return "", 0, nil
+	case spanningTreeSTP, spanningTreeRSTP:
+	default:
+		return "", 0, fmt.Errorf("%s must be %s or %s, got %s", spanningTreeOption, spanningTreeSTP, spanningTreeRSTP, protocol)
+	}
+	value := getStringOption(r, spanningTreePriorityOption)
+	if value == "" {
+		return protocol, -1, nil
+	}
+	priority, err := strconv.Atoi(value)
+	switch {
+	case err != nil || priority < 0:
+		return "", 0, fmt.Errorf("%s must be a number, got %s", spanningTreePriorityOption, value)
+	case protocol == spanningTreeSTP && priority > 65535:
+		return "", 0, fmt.Errorf("%s of %s must be at most 65535, got %d", spanningTreePriorityOption, spanningTreeSTP, priority)
+	case protocol == spanningTreeRSTP && (priority > 61440 || priority%4096 != 0):
+		return "", 0, fmt.Errorf("%s of %s must be a multiple of 4096 up to 61440, got %d", spanningTreePriorityOption, spanningTreeRSTP, priority)
+	}
+	return protocol, priority, nil
+}
+
+// setSpanningTree enables stp or rstp on the bridge, with the priority
+// unless it is negative, which leaves the switch default of 32768.
+func (ovsdber *ovsdber) setSpanningTree(bridgeName, protocol string, priority int) error {
+	condition := libovsdb.NewCondition("name", "==", bridgeName)
+	operations := []libovsdb.Operation{
+		{
+			Op:    "update",
+			Table: "Bridge",
+			Row:   map[string]interface{}{protocol + "_enable": true},
+			Where: []interface{}{condition},
+		},
+	}
+	if priority >= 0 {
+		config, _ := libovsdb.NewOvsMap(map[string]string{protocol + "-priority": strconv.Itoa(priority)})
+		operations = append(operations, libovsdb.Operation{
+			Op:        "mutate",
+			Table:     "Bridge",
+			Mutations: []interface{}{libovsdb.NewMutation("other_config", "insert", config)},
+			Where:     []interface{}{condition},
+		})
+	}
+	if err := ovsdber.transact(operations...); err != nil {
+		return err
+	}
+	log.Infof("Enabled %s on bridge [ %s ]", strings.ToUpper(protocol), bridgeName)
+	return nil
+}
@@ -0,0 +1,38 @@
+package ovs
+
+import "testing"
+
+func TestGetSpanningTree(t *testing.T) {
+	tests := []struct {
+		options  map[string]string
+		protocol string
+		priority int
+		wantErr  bool
+	}{
+		{options: map[string]string{}},
+		{options: map[string]string{spanningTreeOption: "none"}},
+		{options: map[string]string{spanningTreeOption: "stp"}, protocol: "stp", priority: -1},
+		{options: map[string]string{spanningTreeOption: "RSTP"}, protocol: "rstp", priority: -1},
+		{options: map[string]string{spanningTreeOption: "stp", spanningTreePriorityOption: "0"}, protocol: "stp", priority: 0},
+		{options: map[string]string{spanningTreeOption: "stp", spanningTreePriorityOption: "65535"}, protocol: "stp", priority: 65535},
+		{options: map[string]string{spanningTreeOption: "rstp", spanningTreePriorityOption: "61440"}, protocol: "rstp", priority: 61440},
+		{options: map[string]string{spanningTreeOption: "stp", spanningTreePriorityOption: "65536"}, wantErr: true},
+		{options: map[string]string{spanningTreeOption: "rstp", spanningTreePriorityOption: "4000"}, wantErr: true},
+		{options: map[string]string{spanningTreeOption: "rstp", spanningTreePriorityOption: "65536"}, wantErr: true},
+		{options: map[string]string{spanningTreeOption: "stp", spanningTreePriorityOption: "-1"}, wantErr: true},
+		{options: map[string]string{spanningTreeOption: "stp", spanningTreePriorityOption: "high"}, wantErr: true},
+		{options: map[string]string{spanningTreeOption: "mstp"}, wantErr: true},
+		// a priority without a protocol is a mistake, not a default
+		{options: map[string]string{spanningTreePriorityOption: "4096"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		protocol, priority, err := getSpanningTree(networkRequest(tt.options))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getSpanningTree(%v) error = %v, want error %v", tt.options, err, tt.wantErr)
+			continue
+		}
+		if protocol != tt.protocol || priority != tt.priority {
+			t.Errorf("getSpanningTree(%v) = %q, %d, want %q, %d", tt.options, protocol, priority, tt.protocol, tt.priority)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package ovs
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	portRoleInternal = "internal"
+	portRoleEndpoint = "endpoint"
+	portRoleUplink   = "uplink"
+	portRoleTunnel   = "tunnel"
+	portRoleOther    = "other"
+)
+
+// Topology describes the networks of the plugin on a host. Lists are sorted
+// and no timestamps are included, so two documents can be diffed.
+type Topology struct {
+	Host    string
+	Bridges []BridgeTopology
+}
+
+// BridgeTopology is the bridge of one network with its ports and the NAT
+// and filter rules of the network.
+type BridgeTopology struct {
+	Name          string
+	NetworkID     string
+	NetworkName   string `json:",omitempty"`
+	Type          string `json:",omitempty"`
+	Mode          string `json:",omitempty"`
+	Gateway       string `json:",omitempty"`
+	BindInterface string `json:",omitempty"`
+	VLAN          int    `json:",omitempty"`
+	SVLAN         int    `json:",omitempty"`
+	Ports         []PortTopology
+	Rules         []string `json:",omitempty"`
+}
+
+// PortTopology is an OVS port. Role tells endpoints, uplinks, tunnels and
+// the bridge's internal port apart.
+type PortTopology struct {
+	Name      string
+	Role      string
+	Type      string `json:",omitempty"`
+	OFPort    int    `json:",omitempty"`
+	LinkState string `json:",omitempty"`
+	Tag       int    `json:",omitempty"`
+	VLAN      int    `json:",omitempty"`
+	RemoteIP  string `json:",omitempty"`
+	Endpoint  string `json:",omitempty"`
+	Container string `json:",omitempty"`
+	Address   string `json:",omitempty"`
+	MAC       string `json:",omitempty"`
+}
+
+// topology builds the document for all networks, or only the given one,
+// from the driver's state and live OVSDB and netlink data. Networks the
+// driver has no state for, e.g. after a restart, are described from their
+// BridgeOpt row.
+func (d *Driver) topology(networkID string) (*Topology, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	topo := &Topology{Host: host}
+	for bridgeName, id := range pluginBridges() {
+		if networkID != "" && id != networkID {
+			continue
+		}
+		bridge := BridgeTopology{Name: bridgeName, NetworkID: id}
+		if ns, ok := d.network(id); ok {
+			bridge.NetworkName = ns.NetworkName
+			bridge.Type = ns.NetworkType
+			bridge.Mode = ns.Mode
+			bridge.BindInterface = ns.FlatBindInterface
+			bridge.VLAN = ns.VLAN
+			bridge.SVLAN = ns.SVLAN
+			if ns.Gateway != "" {
+				bridge.Gateway = ns.Gateway + "/" + ns.GatewayMask
+			}
+		} else if serviceType, err := d.ovsdber.getBridgeServiceType(bridgeName); err == nil {
+			bridge.Type = serviceType
+		}
+		bridge.Ports = d.bridgePorts(id, bridgeName, bridge.BindInterface)
+		rules, err := d.firewall.listRules(id)
+		if err != nil {
+			log.Warnf("failed to list rules of network %s: %v", truncateID(id), err)
+		}
+		bridge.Rules = rules
+		topo.Bridges = append(topo.Bridges, bridge)
+	}
+	sort.Sort(bridgesByName(topo.Bridges))
+	return topo, nil
+}
+
+// bridgePorts describes the ports of a bridge, endpoints are resolved to
+// their containers through docker.
+func (d *Driver) bridgePorts(networkID, bridgeName, bindInterface string) []PortTopology {
+	// endpoints the driver has no state for are described from docker
+	endpoints := make(map[string]PortTopology)
+	if network, err := d.dockerer.inspectNetwork(networkID); err == nil {
+		for containerID, ep := range network.Containers {
+			port := PortTopology{Endpoint: ep.EndpointID, Container: containerID, MAC: ep.MacAddress}
+			if ip, _, err := net.ParseCIDR(ep.IPv4Address); err == nil {
+				port.Address = ip.String()
+			}
+			if info, err := d.dockerer.inspectContainer(containerID); err == nil {
+				port.Container = strings.TrimPrefix(info.Name, "/")
+			}
+			endpoints[ovsPortPrefix+truncateID(ep.EndpointID)] = port
+		}
+	}
+	for endpointID, es := range d.endpointStates() {
+		if es.NetworkID != networkID {
+			continue
+		}
+		name := ovsPortPrefix + truncateID(endpointID)
+		port := endpoints[name]
+		port.Endpoint = endpointID
+		port.Address = es.Address
+		if es.MacAddress != "" {
+			port.MAC = es.MacAddress
+		}
+		endpoints[name] = port
+	}
+
+	var ports []PortTopology
+	portCache := getTableCache("Port")
+	ifaceCache := getTableCache("Interface")
+	for _, name := range bridgePortNames(bridgeName) {
+		port := PortTopology{Name: name, Role: portRoleOther}
+		row := portCache[portUUIDForName(name)]
+		if tag, ok := row.Fields["tag"].(float64); ok {
+			port.Tag = int(tag)
+		}
+		for _, uuid := range rowUUIDs(row.Fields["interfaces"]) {
+			iface := ifaceCache[uuid]
+			port.Type, _ = iface.Fields["type"].(string)
+			port.LinkState, _ = iface.Fields["link_state"].(string)
+			if ofport, ok := iface.Fields["ofport"].(float64); ok && ofport > 0 {
+				port.OFPort = int(ofport)
+			}
+			port.RemoteIP = ovsMapValue(iface.Fields["options"], "remote_ip")
+		}
+		link, _ := netlink.LinkByName(name)
+		switch {
+		case name == bridgeName:
+			port.Role = portRoleInternal
+		case tunnelTypes[port.Type]:
+			port.Role = portRoleTunnel
+		case strings.HasPrefix(name, ovsPortPrefix):
+			port.Role = portRoleEndpoint
+			if ep, ok := endpoints[name]; ok {
+				port.Endpoint = ep.Endpoint
+				port.Container = ep.Container
+				port.Address = ep.Address
+				port.MAC = ep.MAC
+			}
+		case name == bindInterface:
+			port.Role = portRoleUplink
+		default:
+			if vlan, ok := link.(*netlink.Vlan); ok {
+				port.Role = portRoleUplink
+				port.VLAN = vlan.VlanId
+			}
+		}
+		ports = append(ports, port)
+	}
+	sort.Sort(portsByName(ports))
+	return ports
+}
+
+type bridgesByName []BridgeTopology
+
+func (b bridgesByName) Len() int           { return len(b) }
+func (b bridgesByName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b bridgesByName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+type portsByName []PortTopology
+
+func (p portsByName) Len() int           { return len(p) }
+func (p portsByName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p portsByName) Less(i, j int) bool { return p[i].Name < p[j].Name }
+
+// handleTopology serves /topology, optionally filtered by ?network=
+func (d *Driver) handleTopology(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	topo, err := d.topology(r.URL.Query().Get("network"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, topo)
+}
@@ -0,0 +1,294 @@
+package ovs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	traceFlowPriority  = 65000
+	defaultTraceExpiry = 60 * time.Second
+	maxTraceExpiry     = 3600 * time.Second
+)
+
+var (
+	traceMu  sync.Mutex
+	traces   = make(map[string]*packetTrace)
+	traceSeq uint64
+)
+
+// TraceRequest selects the 5-tuple to trace on a network. Zero values are
+// wildcards.
+type TraceRequest struct {
+	NetworkID string
+	Proto     string
+	SrcIP     string
+	DstIP     string
+	SrcPort   int
+	DstPort   int
+	Seconds   int
+}
+
+// TraceStage holds the counters of a single stage of a trace.
+type TraceStage struct {
+	Stage   string
+	Packets int64
+	Flows   []string `json:",omitempty"`
+}
+
+// TraceResult is returned when a trace is started or dumped.
+type TraceResult struct {
+	ID      string
+	Bridge  string
+	Expires time.Time
+	Stages  []TraceStage `json:",omitempty"`
+}
+
+type packetTrace struct {
+	id      string
+	bridge  string
+	request TraceRequest
+	expires time.Time
+	timer   *time.Timer
+}
+
+// match renders the 5-tuple as an OpenFlow match.
+func (r TraceRequest) match() (string, error) {
+	proto := strings.ToLower(r.Proto)
+	switch proto {
+	case "":
+		proto = "ip"
+	case "ip", "icmp", "tcp", "udp", "sctp":
+	default:
+		return "", fmt.Errorf("unsupported protocol %s", r.Proto)
+	}
+	if (r.SrcPort != 0 || r.DstPort != 0) && (proto == "ip" || proto == "icmp") {
+		return "", fmt.Errorf("ports can not be traced for %s", proto)
+	}
+	m := proto
+	if r.SrcIP != "" {
+		m += ",nw_src=" + r.SrcIP
+	}
+	if r.DstIP != "" {
+		m += ",nw_dst=" + r.DstIP
+	}
+	if r.SrcPort != 0 {
+		m += fmt.Sprintf(",tp_src=%d", r.SrcPort)
+	}
+	if r.DstPort != 0 {
+		m += fmt.Sprintf(",tp_dst=%d", r.DstPort)
+	}
+	return m, nil
+}
+
+// startTrace installs marker flows in both tables of the network's bridge.
+// Each marker counts the packet, labels the stage it reached in reg0 and
+// hands it back to its table, so forwarding is not changed.
+func (d *Driver) startTrace(req TraceRequest) (*TraceResult, error) {
+	match, err := req.match()
+	if err != nil {
+		return nil, err
+	}
+	bridgeName, err := d.networkBridge(req.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	expiry := defaultTraceExpiry
+	if req.Seconds > 0 {
+		expiry = time.Duration(req.Seconds) * time.Second
+	}
+	if expiry > maxTraceExpiry {
+		expiry = maxTraceExpiry
+	}
+
+	traceMu.Lock()
+	traceSeq++
+	t := &packetTrace{
+		id:      fmt.Sprintf("7ace%012x", traceSeq),
+		bridge:  bridgeName,
+		request: req,
+		expires: time.Now().Add(expiry),
+	}
+	traces[t.id] = t
+	traceMu.Unlock()
+
+	portMatch := match
+	if port := d.endpointPortForIP(req.NetworkID, req.SrcIP); port != "" {
+		portMatch += ",in_port=" + port
+	}
+	cookie := flowCookie(t.id)
+	flows := []struct {
+		table int
+		flow  string
+	}{
+		{portSecurityTable, fmt.Sprintf("cookie=%s,priority=%d,%s,reg0=0,actions=load:1->NXM_NX_REG0[],resubmit(,%d)",
+			cookie, traceFlowPriority, portMatch, portSecurityTable)},
+		{policyTable, fmt.Sprintf("cookie=%s,priority=%d,%s,reg0=1,actions=load:2->NXM_NX_REG0[],resubmit(,%d)",
+			cookie, traceFlowPriority, match, policyTable)},
+	}
+	for _, f := range flows {
+		if err := addFlow(bridgeName, f.table, f.flow); err != nil {
+			stopTrace(t.id)
+			return nil, err
+		}
+	}
+	t.timer = time.AfterFunc(expiry, func() { stopTrace(t.id) })
+	log.Infof("Started trace [ %s ] of [ %s ] on bridge [ %s ]", t.id, match, bridgeName)
+	return &TraceResult{ID: t.id, Bridge: bridgeName, Expires: t.expires}, nil
+}
+
+// stopTrace removes the marker flows of a trace.
+func stopTrace(id string) error {
+	traceMu.Lock()
+	t, ok := traces[id]
+	delete(traces, id)
+	traceMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no trace with id %s", id)
+	}
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	log.Infof("Stopped trace [ %s ] on bridge [ %s ]", id, t.bridge)
+	return delFlows(t.bridge, flowCookie(id))
+}
+
+// dumpTrace reads the per-stage counters of a trace: packets seen on the
+// container port, packets that reached the policy table and the datapath
+// flows carrying the tuple, whose actions show where packets end up.
+func dumpTrace(id string) (*TraceResult, error) {
+	traceMu.Lock()
+	t, ok := traces[id]
+	traceMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no trace with id %s", id)
+	}
+
+	out, err := exec.Command("ovs-ofctl", "dump-flows", t.bridge, "cookie="+flowCookie(id)+"/-1").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ovs-ofctl dump-flows %s: %v", t.bridge, err)
+	}
+	counters := parseFlowCounters(string(out))
+
+	res := &TraceResult{ID: id, Bridge: t.bridge, Expires: t.expires}
+	res.Stages = append(res.Stages,
+		TraceStage{Stage: "port", Packets: counters[portSecurityTable]},
+		TraceStage{Stage: "bridge", Packets: counters[policyTable]},
+		datapathStage(t.request),
+	)
+	return res, nil
+}
+
+// parseFlowCounters sums n_packets per table of an ovs-ofctl flow dump.
+func parseFlowCounters(dump string) map[int]int64 {
+	counters := make(map[int]int64)
+	scanner := bufio.NewScanner(strings.NewReader(dump))
+	for scanner.Scan() {
+		table, packets := -1, int64(0)
+		for _, field := range strings.Split(scanner.Text(), ",") {
+			kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "table":
+				table, _ = strconv.Atoi(kv[1])
+			case "n_packets":
+				packets, _ = strconv.ParseInt(kv[1], 10, 64)
+			}
+		}
+		if table >= 0 {
+			counters[table] += packets
+		}
+	}
+	return counters
+}
+
+// datapathStage collects the kernel datapath flows matching the addresses.
+func datapathStage(req TraceRequest) TraceStage {
+	stage := TraceStage{Stage: "datapath"}
+	out, err := exec.Command("ovs-appctl", "dpctl/dump-flows").Output()
+	if err != nil {
+		log.Warnf("failed to dump datapath flows: %v", err)
+		return stage
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if req.SrcIP != "" && !strings.Contains(line, "src="+req.SrcIP) {
+			continue
+		}
+		if req.DstIP != "" && !strings.Contains(line, "dst="+req.DstIP) {
+			continue
+		}
+		stage.Flows = append(stage.Flows, line)
+		if idx := strings.Index(line, "packets:"); idx >= 0 {
+			if fields := strings.Fields(line[idx+len("packets:"):]); len(fields) > 0 {
+				n, _ := strconv.ParseInt(strings.TrimRight(fields[0], ","), 10, 64)
+				stage.Packets += n
+			}
+		}
+	}
+	return stage
+}
+
+// endpointPortForIP returns the OVS port of the endpoint with the address.
+func (d *Driver) endpointPortForIP(networkID, ip string) string {
+	if ip == "" {
+		return ""
+	}
+	for endpointID, es := range d.endpointStates() {
+		if es.NetworkID == networkID && es.Address == ip {
+			return ovsPortPrefix + truncateID(endpointID)
+		}
+	}
+	return ""
+}
+
+// handleTrace serves /trace: POST starts a trace from a TraceRequest, GET
+// dumps the counters of ?id= and DELETE removes it.
+func (d *Driver) handleTrace(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		var req TraceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.NetworkID == "" {
+			writeError(w, http.StatusBadRequest, errors.New("NetworkID is required"))
+			return
+		}
+		res, err := d.startTrace(req)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	case "GET":
+		res, err := dumpTrace(r.URL.Query().Get("id"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	case "DELETE":
+		if err := stopTrace(r.URL.Query().Get("id")); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+	}
+}
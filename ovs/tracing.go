@@ -0,0 +1,316 @@
+package ovs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracingServiceName = "docker-ovs-plugin"
+	// otlpTracesPath is where an OTLP/HTTP collector takes spans
+	otlpTracesPath = "/v1/traces"
+	// maxQueuedSpans is how many finished spans wait for the collector
+	// before new ones are dropped
+	maxQueuedSpans = 2048
+
+	// OTLP numbers the status codes the other way round than the SDK
+	spanStatusOK    = 1
+	spanStatusError = 2
+)
+
+// tracer starts the spans of driver calls, it records nothing until
+// startTracing points it at a collector.
+var tracer trace.Tracer = trace.NewNoopTracerProvider().Tracer(tracingServiceName)
+
+// startTracing sends the spans of driver calls to the OTLP/HTTP collector
+// at endpoint, e.g. http://localhost:4318.
+func startTracing(endpoint string) error {
+	exporter, err := newOTLPExporter(endpoint)
+	if err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithMaxQueueSize(maxQueuedSpans)),
+		sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(tracingServiceName),
+			semconv.HostNameKey.String(host))),
+	)
+	tracer = provider.Tracer(tracingServiceName)
+	log.Infof("Exporting traces to %s", exporter.url)
+	return nil
+}
+
+// startRequestSpan starts the trace of a driver call.
+func startRequestSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// startSpan starts a step of the driver call traced in ctx. Steps taken
+// outside of a driver call, by reconcile or garbage collection, are not
+// traced and get a span that records nothing.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// finishSpan ends a span with the outcome of its step.
+func finishSpan(s trace.Span, err error) {
+	if err != nil {
+		s.SetStatus(codes.Error, err.Error())
+	}
+	s.End()
+}
+
+// otlpExporter sends finished spans to an OpenTelemetry collector over
+// OTLP/HTTP with JSON encoding.
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+// newOTLPExporter returns an exporter to the collector at endpoint.
+func newOTLPExporter(endpoint string) (*otlpExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s, want http://<host>:<port>", endpoint)
+	}
+	if !strings.HasSuffix(u.Path, otlpTracesPath) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + otlpTracesPath
+	}
+	return &otlpExporter{
+		url:    u.String(),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Links             []otlpLink      `json:"links,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpLink struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpValue holds one of the values, the others are left out.
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func otlpAttributes(attrs []attribute.KeyValue) []otlpAttribute {
+	var out []otlpAttribute
+	for _, kv := range attrs {
+		var value otlpValue
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			b := kv.Value.AsBool()
+			value.BoolValue = &b
+		case attribute.INT64:
+			i := strconv.FormatInt(kv.Value.AsInt64(), 10)
+			value.IntValue = &i
+		case attribute.FLOAT64:
+			f := kv.Value.AsFloat64()
+			value.DoubleValue = &f
+		default:
+			str := kv.Value.Emit()
+			value.StringValue = &str
+		}
+		out = append(out, otlpAttribute{Key: string(kv.Key), Value: value})
+	}
+	return out
+}
+
+// ExportSpans posts a batch of finished spans to the collector.
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	scope := otlpScopeSpans{Scope: otlpScope{Name: tracingServiceName}}
+	for _, s := range spans {
+		sc := s.SpanContext()
+		out := otlpSpan{
+			TraceID:           sc.TraceID().String(),
+			SpanID:            sc.SpanID().String(),
+			Name:              s.Name(),
+			Kind:              int(s.SpanKind()),
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime().UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime().UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes()),
+			Status:            otlpStatus{Code: spanStatusOK},
+		}
+		if parent := s.Parent(); parent.IsValid() {
+			out.ParentSpanID = parent.SpanID().String()
+		}
+		for _, l := range s.Links() {
+			out.Links = append(out.Links, otlpLink{
+				TraceID: l.SpanContext.TraceID().String(),
+				SpanID:  l.SpanContext.SpanID().String(),
+			})
+		}
+		if status := s.Status(); status.Code == codes.Error {
+			out.Status = otlpStatus{Code: spanStatusError, Message: status.Description}
+		}
+		scope.Spans = append(scope.Spans, out)
+	}
+	body, err := json.Marshal(otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes(spans[0].Resource().Attributes())},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector replied %s", resp.Status)
+	}
+	return nil
+}
+
+// Shutdown has nothing to release, the client's connections close with
+// the plugin.
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// tracedFirewall records a span for each rule change of a firewall backend.
+type tracedFirewall struct {
+	firewaller
+	backend string
+}
+
+func (f tracedFirewall) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return startSpan(ctx, "firewall."+name, attribute.String("firewall.backend", f.backend))
+}
+
+func (f tracedFirewall) setupNetwork(ctx context.Context, networkID, bridgeName, cidr string) error {
+	ctx, s := f.startSpan(ctx, "setupNetwork")
+	err := f.firewaller.setupNetwork(ctx, networkID, bridgeName, cidr)
+	finishSpan(s, err)
+	return err
+}
+
+func (f tracedFirewall) isolateNetwork(ctx context.Context, networkID, bridgeName string) error {
+	ctx, s := f.startSpan(ctx, "isolateNetwork")
+	err := f.firewaller.isolateNetwork(ctx, networkID, bridgeName)
+	finishSpan(s, err)
+	return err
+}
+
+func (f tracedFirewall) teardownNetwork(ctx context.Context, networkID, bridgeName string) {
+	ctx, s := f.startSpan(ctx, "teardownNetwork")
+	f.firewaller.teardownNetwork(ctx, networkID, bridgeName)
+	finishSpan(s, nil)
+}
+
+func (f tracedFirewall) programPortMapping(ctx context.Context, enable bool, networkID, bridgeName, containerIP string, b portBinding) error {
+	ctx, s := f.startSpan(ctx, "programPortMapping")
+	err := f.firewaller.programPortMapping(ctx, enable, networkID, bridgeName, containerIP, b)
+	finishSpan(s, err)
+	return err
+}
+
+func (f tracedFirewall) programFloatingIP(ctx context.Context, enable bool, es *EndpointState) error {
+	ctx, s := f.startSpan(ctx, "programFloatingIP")
+	err := f.firewaller.programFloatingIP(ctx, enable, es)
+	finishSpan(s, err)
+	return err
+}
+
+// startSpan starts the trace of a driver call.
+func (i instrumentedDriver) startSpan(ctx context.Context, method, networkID, endpointID string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("driver", i.name), attribute.String("network_id", networkID)}
+	if endpointID != "" {
+		attrs = append(attrs, attribute.String("endpoint_id", endpointID))
+	}
+	return startRequestSpan(ctx, method, attrs...)
+}
+
+// describeOperations summarizes a transaction for its span, e.g.
+// "insert Interface, insert Port, mutate Bridge".
+func describeOperations(operations []libovsdb.Operation) string {
+	ops := make([]string, len(operations))
+	for i, o := range operations {
+		ops[i] = o.Op + " " + o.Table
+	}
+	return strings.Join(ops, ", ")
+}
+
+func firewallBackend(backend string) string {
+	if backend == "" {
+		return firewallIptables
+	}
+	return backend
+}
@@ -0,0 +1,115 @@
+package ovs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// defaultTransactTimeout is how long an OVSDB transaction may take unless
+// Config.OvsdbTimeout says otherwise
+const defaultTransactTimeout = 10 * time.Second
+
+var transactTimeout = defaultTransactTimeout
+
+var errNotConnected = errors.New("OVS not connected")
+
+// TransactionError is an OVSDB transaction that ovsdb-server rejected,
+// timed out or never got an answer to. Operation is the failed operation
+// of the transaction, counted from 0, or -1 when the transaction as a
+// whole failed. Lost is set when the transaction may not have reached
+// ovsdb-server or its reply was lost, its outcome is unknown.
+type TransactionError struct {
+	Operation int
+	Op        string
+	Table     string
+	Reason    string
+	Details   string
+	Timeout   bool
+	Lost      bool
+}
+
+func (e *TransactionError) Error() string {
+	msg := "ovsdb transaction failed"
+	if e.Operation >= 0 {
+		msg = fmt.Sprintf("ovsdb %s on %s failed", e.Op, e.Table)
+	}
+	msg = fmt.Sprintf("%s: %s", msg, e.Reason)
+	if e.Details != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Details)
+	}
+	return msg
+}
+
+// transactContext runs a transaction until ctx is done. The RPC library
+// can't cancel a call, so a timed out call is left to finish on its own
+// and its reply dropped.
+func transactContext(ctx context.Context, client *libovsdb.OvsdbClient, operations ...libovsdb.Operation) ([]libovsdb.OperationResult, error) {
+	if client == nil {
+		return nil, errNotConnected
+	}
+	_, s := startSpan(ctx, "ovsdb.transact", attribute.String("ovsdb.operations", describeOperations(operations)))
+	start := time.Now()
+	reply, err := waitTransact(ctx, client, operations)
+	observeTransaction(start, err)
+	finishSpan(s, err)
+	return reply, err
+}
+
+// waitTransact is transactContext without the metrics.
+func waitTransact(ctx context.Context, client *libovsdb.OvsdbClient, operations []libovsdb.Operation) ([]libovsdb.OperationResult, error) {
+	type result struct {
+		reply []libovsdb.OperationResult
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		reply, err := client.Transact("Open_vSwitch", operations...)
+		done <- result{reply, err}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, &TransactionError{Operation: -1, Reason: r.err.Error(), Lost: true}
+		}
+		return r.reply, checkReply(r.reply, operations)
+	case <-ctx.Done():
+		return nil, &TransactionError{Operation: -1, Reason: fmt.Sprintf("no reply from ovsdb-server: %v", ctx.Err()), Timeout: true, Lost: true}
+	}
+}
+
+// checkReply returns the first failed operation of a reply.
+func checkReply(reply []libovsdb.OperationResult, operations []libovsdb.Operation) error {
+	for i, o := range reply {
+		if o.Error == "" {
+			continue
+		}
+		if i < len(operations) {
+			return &TransactionError{Operation: i, Op: operations[i].Op, Table: operations[i].Table, Reason: o.Error, Details: o.Details}
+		}
+		// ovsdb-server appends one result when a commit fails as a whole
+		return &TransactionError{Operation: -1, Reason: o.Error, Details: o.Details}
+	}
+	if len(reply) < len(operations) {
+		return &TransactionError{Operation: -1, Reason: "Number of Replies should be atleast equal to number of Operations"}
+	}
+	return nil
+}
+
+// transactReply runs a transaction within transactTimeout and returns the
+// results of its operations.
+func (ovsdber *ovsdber) transactReply(operations ...libovsdb.Operation) ([]libovsdb.OperationResult, error) {
+	return ovsdber.transactReplyContext(context.Background(), operations...)
+}
+
+// transactReplyContext is transactReply for a step of the driver call
+// traced in ctx.
+func (ovsdber *ovsdber) transactReplyContext(ctx context.Context, operations ...libovsdb.Operation) ([]libovsdb.OperationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	return transactContext(ctx, ovsdber.client(), operations...)
+}
@@ -0,0 +1,142 @@
+package ovs
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+const (
+	// updateQueueSize is how many notifications wait for the bridge
+	// monitor before new ones are merged into the last one
+	updateQueueSize = 256
+)
+
+// queuedTables are the tables whose updates the bridge monitor acts on,
+// updates of other tables only go to the cache.
+var queuedTables = map[string]bool{"Bridge": true, "Port": true, "Interface": true}
+
+// UpdateQueueStats is the state of the queue between the OVSDB notifier and
+// the bridge monitor.
+type UpdateQueueStats struct {
+	Depth     int
+	Capacity  int
+	HighWater int
+	Received  uint64
+	Coalesced uint64
+	Dropped   uint64
+}
+
+// updateQueue hands OVSDB notifications to the bridge monitor without ever
+// blocking the notifier, which runs on the RPC connection's goroutine.
+// When the monitor falls behind the newest notification is merged into the
+// last queued one, so the queue stays bounded and no row change is lost.
+type updateQueue struct {
+	mu      sync.Mutex
+	pending []libovsdb.TableUpdates
+	ready   chan struct{}
+	stats   UpdateQueueStats
+}
+
+var updates = newUpdateQueue(updateQueueSize)
+
+func newUpdateQueue(size int) *updateQueue {
+	return &updateQueue{ready: make(chan struct{}, 1), stats: UpdateQueueStats{Capacity: size}}
+}
+
+// push queues the updates of the tables the monitor acts on.
+func (q *updateQueue) push(tableUpdates libovsdb.TableUpdates) {
+	q.mu.Lock()
+	q.stats.Received++
+	queued := libovsdb.TableUpdates{Updates: make(map[string]libovsdb.TableUpdate)}
+	for table, tableUpdate := range tableUpdates.Updates {
+		if queuedTables[table] {
+			queued.Updates[table] = tableUpdate
+		}
+	}
+	switch {
+	case len(queued.Updates) == 0:
+		q.stats.Dropped++
+	case len(q.pending) >= q.stats.Capacity:
+		mergeUpdates(&q.pending[len(q.pending)-1], queued)
+		q.stats.Coalesced++
+	default:
+		q.pending = append(q.pending, queued)
+		if len(q.pending) > q.stats.HighWater {
+			q.stats.HighWater = len(q.pending)
+		}
+	}
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// drain waits for updates and returns all of them.
+func (q *updateQueue) drain() []libovsdb.TableUpdates {
+	for {
+		<-q.ready
+		q.mu.Lock()
+		batch := q.pending
+		q.pending = nil
+		q.mu.Unlock()
+		if len(batch) > 0 {
+			return batch
+		}
+	}
+}
+
+// snapshot returns the queue's statistics.
+func (q *updateQueue) snapshot() UpdateQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := q.stats
+	stats.Depth = len(q.pending)
+	return stats
+}
+
+// mergeUpdates folds later updates into queued ones. A row keeps its
+// earliest old values, so a change that was merged away is still seen as
+// a change, and takes its newest new values.
+func mergeUpdates(into *libovsdb.TableUpdates, later libovsdb.TableUpdates) {
+	empty := libovsdb.Row{}
+	for table, tableUpdate := range later.Updates {
+		current, ok := into.Updates[table]
+		if !ok {
+			into.Updates[table] = tableUpdate
+			continue
+		}
+		for uuid, row := range tableUpdate.Rows {
+			earlier, ok := current.Rows[uuid]
+			if !ok {
+				current.Rows[uuid] = row
+				continue
+			}
+			merged := libovsdb.RowUpdate{Uuid: row.Uuid, New: row.New, Old: earlier.Old}
+			if !reflect.DeepEqual(earlier.Old, empty) {
+				// an insert stays an insert
+				merged.Old = libovsdb.Row{Fields: make(map[string]interface{})}
+				for column, value := range row.Old.Fields {
+					merged.Old.Fields[column] = value
+				}
+				for column, value := range earlier.Old.Fields {
+					merged.Old.Fields[column] = value
+				}
+			}
+			current.Rows[uuid] = merged
+		}
+	}
+}
+
+// handleUpdateQueue serves /update-queue, the depth and counters of the
+// OVSDB update queue.
+func (d *Driver) handleUpdateQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, updates.snapshot())
+}
@@ -0,0 +1,72 @@
+package ovs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gopher-net/docker-ovs-plugin/libovsdb"
+)
+
+func TestMergeUpdates(t *testing.T) {
+	tests := []struct {
+		name    string
+		earlier libovsdb.TableUpdates
+		later   libovsdb.TableUpdates
+		table   string
+		uuid    string
+		want    libovsdb.RowUpdate
+	}{
+		{
+			name:    "other table",
+			earlier: rowUpdate("Bridge", "b1", nil, map[string]interface{}{"name": "br0"}),
+			later:   rowUpdate("Port", "p1", nil, map[string]interface{}{"name": "veth0"}),
+			table:   "Port", uuid: "p1",
+			want: libovsdb.RowUpdate{New: libovsdb.Row{Fields: map[string]interface{}{"name": "veth0"}}},
+		},
+		{
+			name:    "other row",
+			earlier: rowUpdate("Port", "p1", nil, map[string]interface{}{"name": "veth0"}),
+			later:   rowUpdate("Port", "p2", nil, map[string]interface{}{"name": "veth1"}),
+			table:   "Port", uuid: "p2",
+			want: libovsdb.RowUpdate{New: libovsdb.Row{Fields: map[string]interface{}{"name": "veth1"}}},
+		},
+		{
+			// an insert followed by a modify stays an insert
+			name:    "insert then modify",
+			earlier: rowUpdate("Port", "p1", nil, map[string]interface{}{"name": "veth0", "tag": 0}),
+			later:   rowUpdate("Port", "p1", map[string]interface{}{"tag": 0}, map[string]interface{}{"name": "veth0", "tag": 100}),
+			table:   "Port", uuid: "p1",
+			want: libovsdb.RowUpdate{New: libovsdb.Row{Fields: map[string]interface{}{"name": "veth0", "tag": 100}}},
+		},
+		{
+			// the earliest old value of a column wins, so a column changed
+			// and changed back still shows as changed
+			name:    "modify then modify",
+			earlier: rowUpdate("Port", "p1", map[string]interface{}{"tag": 0}, map[string]interface{}{"name": "veth0", "tag": 100, "qos": "q1"}),
+			later:   rowUpdate("Port", "p1", map[string]interface{}{"tag": 100, "qos": ""}, map[string]interface{}{"name": "veth0", "tag": 0, "qos": "q1"}),
+			table:   "Port", uuid: "p1",
+			want: libovsdb.RowUpdate{
+				Old: libovsdb.Row{Fields: map[string]interface{}{"tag": 0, "qos": ""}},
+				New: libovsdb.Row{Fields: map[string]interface{}{"name": "veth0", "tag": 0, "qos": "q1"}},
+			},
+		},
+		{
+			name:    "modify then delete",
+			earlier: rowUpdate("Port", "p1", map[string]interface{}{"tag": 0}, map[string]interface{}{"name": "veth0", "tag": 100}),
+			later:   rowUpdate("Port", "p1", map[string]interface{}{"name": "veth0", "tag": 100}, nil),
+			table:   "Port", uuid: "p1",
+			want: libovsdb.RowUpdate{Old: libovsdb.Row{Fields: map[string]interface{}{"name": "veth0", "tag": 0}}},
+		},
+	}
+	for _, tt := range tests {
+		mergeUpdates(&tt.earlier, tt.later)
+		got, ok := tt.earlier.Updates[tt.table].Rows[tt.uuid]
+		if !ok {
+			t.Errorf("%s: row %s of %s is missing after the merge", tt.name, tt.uuid, tt.table)
+			continue
+		}
+		if !reflect.DeepEqual(got.Old, tt.want.Old) || !reflect.DeepEqual(got.New, tt.want.New) {
+			t.Errorf("%s: merged row = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package ovs
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"text/template"
 	"time"
@@ -14,9 +16,13 @@ import (
 	"github.com/vishvananda/netlink"
 )
 
-const (
-	serviceName = "/etc/systemd/system/linkerGateway.service"
-)
+// serviceName is the unit file of the gateway service
+var serviceName = "/etc/systemd/system/linkerGateway.service"
+
+// gatewayUnit is the systemd unit name of the gateway service.
+func gatewayUnit() string {
+	return filepath.Base(serviceName)
+}
 
 var systemDConfig = `[Unit]
 Description=Linker pgateway or sgateway process
@@ -79,6 +85,12 @@ func setInterfaceIP(name string, rawIP string) error {
 	return netlink.AddrAdd(iface, addr)
 }
 
+// Turn proxy ARP on for an interface, the host then answers ARP for any
+// address it routes out of another interface
+func setProxyARP(name string) error {
+	return ioutil.WriteFile(fmt.Sprintf("/proc/sys/net/ipv4/conf/%s/proxy_arp", name), []byte("1"), 0644)
+}
+
 // Increment an IP in a subnet
 func ipIncrement(networkAddr net.IP) net.IP {
 	for i := 15; i >= 0; i-- {
@@ -177,8 +189,13 @@ func ExecCommandWithComplete(input string) (output string, errput string, err er
 // 	return err
 // }
 
-func StartOvsService(input string) (err error) {
+func StartOvsService(ctx context.Context, input string) (err error) {
 	log.Infof("start ovs service, command is %s", input)
+	_, s := startSpan(ctx, "systemd.startGateway")
+	defer func() { finishSpan(s, err) }()
+	if !gatewayUnits {
+		return startGatewayProcess(input)
+	}
 	serviceFile, err := os.Create(serviceName)
 	if err != nil {
 		log.Warnf("failed to create sgw or pgw service file %v", err)
@@ -210,25 +227,44 @@ func StartOvsService(input string) (err error) {
 		return err
 	}
 
-	if err := exec.Command("systemctl", "start", "linkerGateway.service").Run(); err != nil {
+	if err := exec.Command("systemctl", "start", gatewayUnit()).Run(); err != nil {
 		log.Warnf("systemctl start linkerGateway error %v", err)
 		return err
 	}
-        if err := exec.Command("systemctl", "enable", "linkerGateway.service").Run(); err != nil {
+        if err := exec.Command("systemctl", "enable", gatewayUnit()).Run(); err != nil {
 		log.Warnf("systemctl enable linkerGateway error %v", err)
 	}
 
 	return nil
 }
 
-func stopOvsService() (err error) {
+// stopGatewayService stops the gateway service for maintenance. Its unit
+// stays enabled and in place, so the gateway comes back with the host.
+func stopGatewayService(ctx context.Context) (err error) {
+	_, s := startSpan(ctx, "systemd.stopGateway")
+	defer func() { finishSpan(s, err) }()
+	if !gatewayUnits {
+		return stopGatewayProcess()
+	}
+	if out, err := exec.Command("systemctl", "stop", gatewayUnit()).CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl stop %s: %v %s", gatewayUnit(), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func stopOvsService(ctx context.Context) (err error) {
 	log.Infof("stop and remove linkerGateway process")
+	_, s := startSpan(ctx, "systemd.stopGateway")
+	defer func() { finishSpan(s, err) }()
+	if !gatewayUnits {
+		return stopGatewayProcess()
+	}
 
-	if err := exec.Command("systemctl", "stop", "linkerGateway.service").Run(); err != nil {
+	if err := exec.Command("systemctl", "stop", gatewayUnit()).Run(); err != nil {
 		log.Warnf("systemctl stop linkerGateway error %v", err)
 		return err
 	}
-        if err := exec.Command("systemctl", "disable", "linkerGateway.service").Run(); err != nil {
+        if err := exec.Command("systemctl", "disable", gatewayUnit()).Run(); err != nil {
 		log.Warnf("systemctl disable linkerGateway error %v", err)
 	}
 
@@ -0,0 +1,227 @@
+package ovs
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// visibilityMap is the pinned map the visibility program, built from
+	// bpf/visibility.c, counts flows in. Its key is struct { u32 ifindex;
+	// u32 saddr; u32 daddr; u16 sport; u16 dport; u8 proto; } with
+	// addresses in network byte order and ports in host byte order, its
+	// value starts with struct { u64 packets; u64 bytes; u64 retransmits;
+	// u64 drops; }. The object must carry BTF so bpftool can format the
+	// entries.
+	visibilityMap = "/sys/fs/bpf/tc/globals/linker_flows"
+
+	visibilityIngressSection = "tc/ingress"
+	visibilityEgressSection  = "tc/egress"
+)
+
+// FlowSummary is the L4 flow counters of an endpoint, seen from the
+// container.
+type FlowSummary struct {
+	Protocol    string
+	Source      string
+	Destination string
+	Packets     uint64
+	Bytes       uint64
+	Retransmits uint64 `json:",omitempty"`
+	Drops       uint64 `json:",omitempty"`
+}
+
+// EndpointMetrics is what the visibility programs counted on the veth of
+// an endpoint.
+type EndpointMetrics struct {
+	EndpointID  string
+	NetworkID   string
+	Port        string
+	Retransmits uint64
+	Drops       uint64
+	Flows       []FlowSummary `json:",omitempty"`
+}
+
+type visibilityEntry struct {
+	// Key is the raw key, bytes as hex strings, to delete the entry with
+	Key       []string `json:"key"`
+	Formatted struct {
+		Key struct {
+			Ifindex uint32 `json:"ifindex"`
+			Saddr   uint32 `json:"saddr"`
+			Daddr   uint32 `json:"daddr"`
+			Sport   uint16 `json:"sport"`
+			Dport   uint16 `json:"dport"`
+			Proto   uint8  `json:"proto"`
+		} `json:"key"`
+		Value struct {
+			Packets     uint64 `json:"packets"`
+			Bytes       uint64 `json:"bytes"`
+			Retransmits uint64 `json:"retransmits"`
+			Drops       uint64 `json:"drops"`
+		} `json:"value"`
+	} `json:"formatted"`
+}
+
+// attachVisibility attaches the visibility program to both directions of
+// an endpoint's veth with tc. The veth goes away on Leave and takes the
+// filters with it.
+func (d *Driver) attachVisibility(portName string) error {
+	if out, err := exec.Command("tc", "qdisc", "replace", "dev", portName, "clsact").CombinedOutput(); err != nil {
+		return fmt.Errorf("tc qdisc replace dev %s clsact: %v %s", portName, err, strings.TrimSpace(string(out)))
+	}
+	for direction, section := range map[string]string{"ingress": visibilityIngressSection, "egress": visibilityEgressSection} {
+		args := []string{"filter", "replace", "dev", portName, direction, "prio", "1", "handle", "1",
+			"bpf", "da", "obj", d.visibilityObject, "sec", section}
+		if out, err := exec.Command("tc", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("tc %s: %v %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	log.Infof("Attached visibility program to [ %s ]", portName)
+	return nil
+}
+
+// forgetVisibility deletes the entries of an endpoint's veth from the flow
+// map before the veth goes away, a later veth may get the same ifindex.
+func forgetVisibility(portName string) error {
+	link, err := netlink.LinkByName(portName)
+	if err != nil {
+		return err
+	}
+	entries, err := visibilityEntries()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Formatted.Key.Ifindex != uint32(link.Attrs().Index) {
+			continue
+		}
+		args := []string{"map", "delete", "pinned", visibilityMap, "key", "hex"}
+		for _, b := range entry.Key {
+			args = append(args, strings.TrimPrefix(b, "0x"))
+		}
+		if out, err := exec.Command("bpftool", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("bpftool %s: %v %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// visibilityEntries dumps the flow map of the visibility programs.
+func visibilityEntries() ([]visibilityEntry, error) {
+	out, err := exec.Command("bpftool", "-j", "map", "dump", "pinned", visibilityMap).Output()
+	if err != nil {
+		return nil, fmt.Errorf("bpftool map dump %s: %v", visibilityMap, err)
+	}
+	var entries []visibilityEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", visibilityMap, err)
+	}
+	return entries, nil
+}
+
+// endpointMetrics aggregates the flow map per endpoint, for all endpoints
+// or only those of the given network or endpoint. Entries of deleted veths
+// are not matched by any endpoint and are skipped.
+func (d *Driver) endpointMetrics(networkID, endpointID string) ([]EndpointMetrics, error) {
+	if d.visibilityObject == "" {
+		return nil, fmt.Errorf("endpoint visibility is not enabled")
+	}
+	byIndex := make(map[uint32]*EndpointMetrics)
+	for id, es := range d.endpointStates() {
+		if (networkID != "" && es.NetworkID != networkID) || (endpointID != "" && id != endpointID) {
+			continue
+		}
+		name := ovsPortPrefix + truncateID(id)
+		link, err := netlink.LinkByName(name)
+		if err != nil {
+			continue
+		}
+		byIndex[uint32(link.Attrs().Index)] = &EndpointMetrics{EndpointID: id, NetworkID: es.NetworkID, Port: name}
+	}
+	entries, err := visibilityEntries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		key, value := entry.Formatted.Key, entry.Formatted.Value
+		m, ok := byIndex[key.Ifindex]
+		if !ok {
+			continue
+		}
+		m.Retransmits += value.Retransmits
+		m.Drops += value.Drops
+		m.Flows = append(m.Flows, FlowSummary{
+			Protocol:    protocolName(key.Proto),
+			Source:      net.JoinHostPort(beIPv4(key.Saddr).String(), fmt.Sprint(key.Sport)),
+			Destination: net.JoinHostPort(beIPv4(key.Daddr).String(), fmt.Sprint(key.Dport)),
+			Packets:     value.Packets,
+			Bytes:       value.Bytes,
+			Retransmits: value.Retransmits,
+			Drops:       value.Drops,
+		})
+	}
+	metrics := make([]EndpointMetrics, 0, len(byIndex))
+	for _, m := range byIndex {
+		sort.Sort(flowsByBytes(m.Flows))
+		metrics = append(metrics, *m)
+	}
+	sort.Sort(metricsByEndpoint(metrics))
+	return metrics, nil
+}
+
+// beIPv4 converts an address the kernel stored in network byte order and
+// bpftool printed as an integer in the byte order of the host.
+func beIPv4(addr uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.NativeEndian.PutUint32(ip, addr)
+	return ip
+}
+
+func protocolName(proto uint8) string {
+	switch proto {
+	case 6:
+		return "tcp"
+	case 17:
+		return "udp"
+	case 1:
+		return "icmp"
+	}
+	return fmt.Sprint(proto)
+}
+
+type flowsByBytes []FlowSummary
+
+func (f flowsByBytes) Len() int           { return len(f) }
+func (f flowsByBytes) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f flowsByBytes) Less(i, j int) bool { return f[i].Bytes > f[j].Bytes }
+
+type metricsByEndpoint []EndpointMetrics
+
+func (m metricsByEndpoint) Len() int           { return len(m) }
+func (m metricsByEndpoint) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m metricsByEndpoint) Less(i, j int) bool { return m[i].EndpointID < m[j].EndpointID }
+
+// handleMetrics serves /metrics, optionally filtered by ?network= and
+// ?endpoint=
+func (d *Driver) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, errMethodNotAllowed(r.Method))
+		return
+	}
+	metrics, err := d.endpointMetrics(r.URL.Query().Get("network"), r.URL.Query().Get("endpoint"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, metrics)
+}
@@ -0,0 +1,19 @@
+package ovs
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+func TestBeIPv4(t *testing.T) {
+	tests := []string{"10.0.0.2", "172.18.0.1", "255.255.255.0", "0.0.0.0"}
+	for _, addr := range tests {
+		// the kernel stores the address in network byte order, bpftool
+		// prints those bytes as an integer of the host's byte order
+		printed := binary.NativeEndian.Uint32(net.ParseIP(addr).To4())
+		if got := beIPv4(printed).String(); got != addr {
+			t.Errorf("beIPv4(%#x) = %s, want %s", printed, got, addr)
+		}
+	}
+}
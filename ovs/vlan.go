@@ -0,0 +1,225 @@
+package ovs
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gopher-net/docker-ovs-plugin/dknet"
+	"github.com/vishvananda/netlink"
+)
+
+// createVlanUplink creates the 802.1Q sub-interface of the bind interface
+// that is attached to a flat mode bridge. The kernel pushes the tag on
+// egress and strips it on ingress, so several networks can share one trunk.
+// An existing sub-interface is only reused if the plugin created it.
+func (ovsdber *ovsdber) createVlanUplink(ctx context.Context, bindInterface string, vlan int) (string, error) {
+	parent, err := netlink.LinkByName(bindInterface)
+	if err != nil {
+		return "", err
+	}
+	name := vlanUplinkName(bindInterface, vlan)
+	if _, err := netlink.LinkByName(name); err != nil {
+		link := &netlink.Vlan{
+			LinkAttrs: netlink.LinkAttrs{
+				Name:        name,
+				ParentIndex: parent.Attrs().Index,
+			},
+			VlanId: vlan,
+		}
+		if err := netlink.LinkAdd(link); err != nil {
+			return "", err
+		}
+		if err := ovsdber.markLink(name); err != nil {
+			netlink.LinkDel(link)
+			return "", err
+		}
+	} else if err := ovsdber.checkLinkOwner(name); err != nil {
+		return "", err
+	}
+	if err := interfaceUp(ctx, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// getPCP returns the 802.1p priority of the network's VLAN tag, 0 if unset.
+func getPCP(r *dknet.CreateNetworkRequest) (int, error) {
+	value := getStringOption(r, pcpOption)
+	if value == "" {
+		return 0, nil
+	}
+	pcp, err := strconv.Atoi(value)
+	if err != nil || pcp < 0 || pcp > 7 {
+		return 0, fmt.Errorf("%s must be a priority between 0 and 7, got %s", pcpOption, value)
+	}
+	return pcp, nil
+}
+
+// setVlanPCP maps every socket priority to the PCP, so all traffic leaving
+// through the vlan uplink is tagged with it. With QinQ the priority goes in
+// the customer tag, the service tag interface is shared between networks.
+func setVlanPCP(uplink string, pcp int) error {
+	args := []string{"link", "set", "dev", uplink, "type", "vlan", "egress-qos-map"}
+	for prio := 0; prio <= 7; prio++ {
+		args = append(args, fmt.Sprintf("%d:%d", prio, pcp))
+	}
+	out, err := exec.Command("ip", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v %s", err, strings.TrimSpace(string(out)))
+	}
+	log.Infof("Tagging traffic of vlan uplink [ %s ] with priority %d", uplink, pcp)
+	return nil
+}
+
+// checkUplinkIsolation makes sure a new network does not end up in the L2
+// domain of an existing one. Networks can't share a bridge, and flat mode
+// networks sharing a bind interface must each use their own VLAN, an
+// untagged network would see the traffic of all of them. The bridges in
+// OVSDB are checked as well, the driver has no state of networks created
+// before a restart or of bridges other software created.
+func (d *Driver) checkUplinkIsolation(networkID string, ns *NetworkState) error {
+	if err := checkSwitchIsolation(networkID, ns); err != nil {
+		return err
+	}
+	for id, other := range d.networkStates() {
+		if id == networkID {
+			continue
+		}
+		if other.BridgeName == ns.BridgeName {
+			return fmt.Errorf("bridge %s is already used by network %s", ns.BridgeName, truncateID(id))
+		}
+		if ns.Mode != modeFlat || other.Mode != modeFlat || ns.FlatBindInterface == "" ||
+			other.FlatBindInterface != ns.FlatBindInterface {
+			continue
+		}
+		if ns.VLAN == 0 || other.VLAN == 0 {
+			return fmt.Errorf("bind interface %s is shared with network %s, both networks need a distinct %s",
+				ns.FlatBindInterface, truncateID(id), vlanOption)
+		}
+		if ns.VLAN == other.VLAN && ns.SVLAN == other.SVLAN {
+			return fmt.Errorf("vlan %d on %s is already used by network %s", ns.VLAN, ns.FlatBindInterface, truncateID(id))
+		}
+	}
+	return nil
+}
+
+// checkSwitchIsolation is checkUplinkIsolation against the bridges in
+// OVSDB: the network's bridge must not record another network, and no
+// other bridge may hold the bind interface, or a vlan of it that the
+// network would share. QinQ networks share the service tag interface on
+// their carrier bridge by design and are only checked by the driver state.
+func checkSwitchIsolation(networkID string, ns *NetworkState) error {
+	if other := bridgeExternalID(ns.BridgeName, networkIDKey); other != "" && other != networkID {
+		return fmt.Errorf("bridge %s is already used by network %s", ns.BridgeName, truncateID(other))
+	}
+	if ns.Mode != modeFlat || ns.FlatBindInterface == "" || ns.SVLAN != 0 {
+		return nil
+	}
+	bind, err := netlink.LinkByName(ns.FlatBindInterface)
+	if err != nil {
+		// the bridge setup reports the missing interface
+		return nil
+	}
+	for _, row := range getTableCache("Bridge") {
+		bridgeName, _ := row.Fields["name"].(string)
+		if bridgeName == ns.BridgeName {
+			continue
+		}
+		for _, port := range bridgePortNames(bridgeName) {
+			if port == ns.FlatBindInterface {
+				return fmt.Errorf("bind interface %s is attached to bridge %s", port, bridgeName)
+			}
+			link, err := netlink.LinkByName(port)
+			if err != nil {
+				continue
+			}
+			vlan, ok := link.(*netlink.Vlan)
+			if !ok || vlan.Attrs().ParentIndex != bind.Attrs().Index {
+				continue
+			}
+			if ns.VLAN == 0 {
+				return fmt.Errorf("bind interface %s has vlan uplink %s on bridge %s, the network needs a distinct %s",
+					ns.FlatBindInterface, port, bridgeName, vlanOption)
+			}
+			if vlan.VlanId == ns.VLAN {
+				return fmt.Errorf("vlan %d on %s is already attached to bridge %s", ns.VLAN, ns.FlatBindInterface, bridgeName)
+			}
+		}
+	}
+	return nil
+}
+
+// vlanUplinkName keeps the sub-interface name within IFNAMSIZ.
+func vlanUplinkName(bindInterface string, vlan int) string {
+	suffix := fmt.Sprintf(".%d", vlan)
+	if len(bindInterface)+len(suffix) > 15 {
+		bindInterface = bindInterface[:15-len(suffix)]
+	}
+	return bindInterface + suffix
+}
+
+// deleteVlanUplink deletes a vlan sub-interface the plugin created.
+func (ovsdber *ovsdber) deleteVlanUplink(name string) {
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return
+	}
+	if err := ovsdber.checkLinkOwner(name); err != nil {
+		log.Warnf("not deleting vlan uplink: %v", err)
+		return
+	}
+	if err := netlink.LinkDel(link); err != nil {
+		log.Warnf("failed to delete vlan uplink %s: %v", name, err)
+	}
+}
+
+// removeVlanUplinks deletes the vlan sub-interfaces attached to a bridge,
+// along with a service tag sub-interface or QinQ link that is no longer
+// used. Interfaces the plugin did not create are left alone.
+func (ovsdber *ovsdber) removeVlanUplinks(ctx context.Context, bridgeName string) {
+	for _, port := range bridgePortNames(bridgeName) {
+		link, err := netlink.LinkByName(port)
+		if err != nil || link.Type() != "vlan" {
+			continue
+		}
+		if err := ovsdber.checkLinkOwner(port); err != nil {
+			log.Warnf("not deleting vlan uplink: %v", err)
+			continue
+		}
+		ovsdber.deleteVlanUplink(port)
+		log.Infof("Deleted vlan uplink [ %s ] of bridge [ %s ]", port, bridgeName)
+
+		parent, err := netlink.LinkByIndex(link.Attrs().ParentIndex)
+		if err != nil {
+			continue
+		}
+		if parent.Type() == "veth" {
+			// the link of a QinQ network carries its uplink alone
+			ovsdber.removeQinQLink(ctx, parent)
+			continue
+		}
+		if parent.Type() != "vlan" || hasChildren(parent) || ovsdber.checkLinkOwner(parent.Attrs().Name) != nil {
+			continue
+		}
+		ovsdber.deleteVlanUplink(parent.Attrs().Name)
+		log.Infof("Deleted unused service vlan interface [ %s ]", parent.Attrs().Name)
+	}
+}
+
+func hasChildren(parent netlink.Link) bool {
+	links, err := netlink.LinkList()
+	if err != nil {
+		// be conservative, the interface may still be in use
+		return true
+	}
+	for _, link := range links {
+		if link.Attrs().ParentIndex == parent.Attrs().Index {
+			return true
+		}
+	}
+	return false
+}